@@ -0,0 +1,221 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+)
+
+const awsTimeFormat = "20060102T150405Z"
+
+var (
+	// ErrAWSNoCredentials returned when AWS credentials is not found in the
+	// credential chain.
+	ErrAWSNoCredentials = errors.New("log: aws credentials not found")
+
+	awsECSCredentialsHost = "http://169.254.170.2"
+	awsEC2MetadataHost    = "http://169.254.169.254"
+)
+
+type awsCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	SessionToken    string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+// awsCredentialChain resolves the AWS credentials in the order of config
+// `log.<section>.access_key_id` and `secret_access_key`, environment
+// variables, ECS container credentials and EC2 instance role. Temporary
+// credentials are cached till it's about to expire.
+type awsCredentialChain struct {
+	static *awsCredentials
+	client *http.Client
+	cached *awsCredentials
+	mu     sync.Mutex
+}
+
+func newAWSCredentialChain(cfg *config.Config, section string) *awsCredentialChain {
+	c := &awsCredentialChain{client: &http.Client{Timeout: 2 * time.Second}}
+	keyPrefix := "log." + section + "."
+	if accessKeyID := cfg.StringDefault(keyPrefix+"access_key_id", ""); len(accessKeyID) > 0 {
+		c.static = &awsCredentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: cfg.StringDefault(keyPrefix+"secret_access_key", ""),
+			SessionToken:    cfg.StringDefault(keyPrefix+"session_token", ""),
+		}
+	}
+	return c
+}
+
+// Get method returns the credentials from first successful provider.
+func (c *awsCredentialChain) Get() (*awsCredentials, error) {
+	if c.static != nil {
+		return c.static, nil
+	}
+
+	// Environment variables, it's how Lambda provides the credentials
+	if accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID"); len(accessKeyID) > 0 {
+		return &awsCredentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached != nil && time.Now().Add(5*time.Minute).Before(c.cached.Expiration) {
+		return c.cached, nil
+	}
+
+	var (
+		creds *awsCredentials
+		err   error
+	)
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); len(uri) > 0 {
+		creds, err = c.fetch(http.MethodGet, awsECSCredentialsHost+uri, nil)
+	} else if uri = os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"); len(uri) > 0 {
+		creds, err = c.fetch(http.MethodGet, uri, http.Header{"Authorization": {os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")}})
+	} else {
+		creds, err = c.fetchEC2RoleCredentials()
+	}
+	if err != nil {
+		return nil, ErrAWSNoCredentials
+	}
+
+	c.cached = creds
+	return creds, nil
+}
+
+// fetchEC2RoleCredentials method fetches the instance role credentials from
+// EC2 instance metadata service using IMDSv2 session token.
+func (c *awsCredentialChain) fetchEC2RoleCredentials() (*awsCredentials, error) {
+	token, err := c.request(http.MethodPut, awsEC2MetadataHost+"/latest/api/token",
+		http.Header{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {"21600"}})
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{"X-Aws-Ec2-Metadata-Token": {string(token)}}
+	roleURL := awsEC2MetadataHost + "/latest/meta-data/iam/security-credentials/"
+	role, err := c.request(http.MethodGet, roleURL, header)
+	if err != nil {
+		return nil, err
+	}
+	return c.fetch(http.MethodGet, roleURL+strings.TrimSpace(strings.SplitN(string(role), "\n", 2)[0]), header)
+}
+
+func (c *awsCredentialChain) fetch(method, url string, header http.Header) (*awsCredentials, error) {
+	body, err := c.request(method, url, header)
+	if err != nil {
+		return nil, err
+	}
+	creds := &awsCredentials{}
+	if err = json.Unmarshal(body, creds); err != nil {
+		return nil, err
+	}
+	if len(creds.AccessKeyID) == 0 {
+		return nil, ErrAWSNoCredentials
+	}
+	return creds, nil
+}
+
+func (c *awsCredentialChain) request(method, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("log: aws credentials status %d", res.StatusCode)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// awsSignV4 method signs the request using AWS Signature Version 4, it sets
+// `X-Amz-Date`, `X-Amz-Security-Token` and `Authorization` into given
+// header. All the headers in the given header are signed along with host.
+func awsSignV4(method, rawurl string, body []byte, header http.Header, creds *awsCredentials, region, service string, now time.Time) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+
+	amzDate := now.UTC().Format(awsTimeFormat)
+	header.Set("X-Amz-Date", amzDate)
+	if len(creds.SessionToken) > 0 {
+		header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	canonicalHeaders := map[string]string{"host": u.Host}
+	for k, v := range header {
+		if k == "Authorization" {
+			continue
+		}
+		canonicalHeaders[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(canonicalHeaders))
+	for k := range canonicalHeaders {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	path := u.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+
+	buf := acquireBuffer()
+	defer releaseBuffer(buf)
+	_, _ = buf.WriteString(method + "\n" + path + "\n" + u.Query().Encode() + "\n")
+	for _, k := range names {
+		_, _ = buf.WriteString(k + ":" + canonicalHeaders[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	_, _ = buf.WriteString("\n" + signedHeaders + "\n" + hex.EncodeToString(bodyHash[:]))
+
+	scope := amzDate[:8] + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256(buf.Bytes())
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), amzDate[:8])
+	for _, v := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+
+	header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))))
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
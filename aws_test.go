@@ -0,0 +1,73 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestAWSSignV4(t *testing.T) {
+	// AWS Signature Version 4 test suite, get-vanilla
+	now, _ := time.Parse(awsTimeFormat, "20150830T123600Z")
+	header := http.Header{}
+	creds := &awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	err := awsSignV4(http.MethodGet, "https://example.amazonaws.com/", nil, header, creds, "us-east-1", "service", now)
+	assert.Nil(t, err)
+	assert.Equal(t, "20150830T123600Z", header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		header.Get("Authorization"))
+
+	header = http.Header{}
+	creds.SessionToken = "session-token"
+	_ = awsSignV4(http.MethodGet, "https://example.amazonaws.com/", nil, header, creds, "us-east-1", "service", now)
+	assert.Equal(t, "session-token", header.Get("X-Amz-Security-Token"))
+}
+
+func TestAWSCredentialChain(t *testing.T) {
+	cfg, _ := config.ParseString(`log { cloudwatch { access_key_id = "AKID", secret_access_key = "secret" } }`)
+	creds, err := newAWSCredentialChain(cfg, "cloudwatch").Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "AKID", creds.AccessKeyID)
+	assert.Equal(t, "secret", creds.SecretAccessKey)
+
+	cfg, _ = config.ParseString(`log { }`)
+	_ = os.Setenv("AWS_ACCESS_KEY_ID", "ENVAKID")
+	_ = os.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+	creds, err = newAWSCredentialChain(cfg, "cloudwatch").Get()
+	_ = os.Unsetenv("AWS_ACCESS_KEY_ID")
+	_ = os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	assert.Nil(t, err)
+	assert.Equal(t, "ENVAKID", creds.AccessKeyID)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/credentials/uuid", r.URL.Path)
+		_, _ = w.Write([]byte(`{"AccessKeyId":"ECSAKID","SecretAccessKey":"ecssecret","Token":"token",
+			"Expiration":"` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`))
+	}))
+	defer server.Close()
+
+	host := awsECSCredentialsHost
+	awsECSCredentialsHost = server.URL
+	_ = os.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/v2/credentials/uuid")
+	defer func() {
+		awsECSCredentialsHost = host
+		_ = os.Unsetenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI")
+	}()
+
+	chain := newAWSCredentialChain(cfg, "cloudwatch")
+	creds, err = chain.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "ECSAKID", creds.AccessKeyID)
+	assert.Equal(t, "token", creds.SessionToken)
+	assert.NotNil(t, chain.cached)
+}
@@ -0,0 +1,174 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"aahframework.org/config.v0"
+)
+
+// ErrContextKeyIsNil returned when supplied context key is nil.
+var ErrContextKeyIsNil = errors.New("log: context key is nil")
+
+// BaggageFunc type is used to extract the baggage members from context, it
+// returns nil if context has no baggage.
+type BaggageFunc func(ctx context.Context) map[string]string
+
+var (
+	baggageFn BaggageFunc
+	baggageMu = &sync.RWMutex{}
+)
+
+// contextField is lifted into entry fields from context value of key or
+// baggage member.
+type contextField struct {
+	name   string
+	key    interface{}
+	member string
+}
+
+// SetBaggageFunc method sets the func to extract the baggage members from
+// context. Members configured as list `log.baggage` are lifted into entry
+// fields by `FromContext` and `WithContext`, mapping is `<member>` or
+// `<member>=<field>`. For e.g. with OpenTelemetry:
+//
+//	log {
+//	  baggage = ["tenant_id", "user.id=user_id"]
+//	}
+//
+//	log.SetBaggageFunc(func(ctx context.Context) map[string]string {
+//		members := make(map[string]string)
+//		for _, m := range baggage.FromContext(ctx).Members() {
+//			members[m.Key()] = m.Value()
+//		}
+//		return members
+//	})
+//
+// Nil func disables it.
+func SetBaggageFunc(fn BaggageFunc) {
+	baggageMu.Lock()
+	defer baggageMu.Unlock()
+	baggageFn = fn
+}
+
+// AddContextField method adds the mapping of context value key to field
+// name, value is lifted into entry fields by `FromContext` and
+// `WithContext`, so cross-cutting identifiers appear in every log line
+// without explicit `WithField`. For e.g.:
+//
+//	logger.AddContextField("tenant_id", tenantKey{})
+func (l *Logger) AddContextField(name string, key interface{}) error {
+	if len(strings.TrimSpace(name)) == 0 {
+		return errors.New("log: context field name is empty")
+	}
+	if key == nil {
+		return ErrContextKeyIsNil
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	for _, f := range l.ctxFields {
+		if f.name == name {
+			return fmt.Errorf("log: context field name '%v' is already added, skip it", name)
+		}
+	}
+	ctxFields := make([]contextField, len(l.ctxFields), len(l.ctxFields)+1)
+	copy(ctxFields, l.ctxFields)
+	l.ctxFields = append(ctxFields, contextField{name: name, key: key})
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+func newBaggageFields(cfg *config.Config) ([]contextField, error) {
+	mappings, _ := cfg.StringList("log.baggage")
+	var ctxFields []contextField
+	for _, m := range mappings {
+		parts := strings.SplitN(m, "=", 2)
+		member := strings.TrimSpace(parts[0])
+		name := member
+		if len(parts) == 2 {
+			name = strings.TrimSpace(parts[1])
+		}
+		if len(member) == 0 || len(name) == 0 {
+			return nil, fmt.Errorf("log: invalid baggage mapping '%s'", m)
+		}
+		ctxFields = append(ctxFields, contextField{name: name, member: member})
+	}
+	return ctxFields, nil
+}
+
+// baggageFields method returns the fields lifted from context values and
+// baggage members, field already present on the logger is not lifted.
+func baggageFields(ctx context.Context, logger Loggerer) Fields {
+	var (
+		l      *Logger
+		fields Fields
+	)
+	switch t := logger.(type) {
+	case *Logger:
+		l, fields = t, t.ctx
+	case *Entry:
+		l, fields = t.logger, t.Fields
+	default:
+		return nil
+	}
+
+	l.m.RLock()
+	ctxFields := l.ctxFields
+	l.m.RUnlock()
+	if len(ctxFields) == 0 {
+		return nil
+	}
+
+	var (
+		members Fields
+		lifted  Fields
+	)
+	for _, f := range ctxFields {
+		if _, found := fields[f.name]; found {
+			continue
+		}
+
+		var value interface{}
+		if f.key != nil {
+			value = ctx.Value(f.key)
+		} else {
+			if members == nil {
+				members = baggageMembers(ctx)
+			}
+			value = members[f.member]
+		}
+		if value == nil || value == "" {
+			continue
+		}
+		if lifted == nil {
+			lifted = make(Fields)
+		}
+		lifted[f.name] = value
+	}
+	return lifted
+}
+
+func baggageMembers(ctx context.Context) Fields {
+	baggageMu.RLock()
+	fn := baggageFn
+	baggageMu.RUnlock()
+
+	members := make(Fields)
+	if fn != nil {
+		for k, v := range fn(ctx) {
+			members[k] = v
+		}
+	}
+	return members
+}
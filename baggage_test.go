@@ -0,0 +1,69 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogContextBaggage(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %reqid %message %fields"
+    baggage = ["tenant_id", "user.id=user_id", "region"]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	type baggageKey struct{}
+	type tenantKey struct{}
+	SetBaggageFunc(func(ctx context.Context) map[string]string {
+		members, _ := ctx.Value(baggageKey{}).(map[string]string)
+		return members
+	})
+	defer SetBaggageFunc(nil)
+
+	ctx := context.WithValue(context.Background(), baggageKey{}, map[string]string{
+		"tenant_id": "acme",
+		"user.id":   "u-42",
+		"other":     "ignored",
+	})
+
+	logger.WithContext(ctx).Info("order placed")
+	assert.Equal(t, "INFO  order placed fields[tenant_id: acme, user_id: u-42] \n", buf.String())
+
+	// explicit field wins
+	buf.Reset()
+	ctx = NewContext(ctx, logger.WithFields(Fields{"reqid": "req-1", "tenant_id": "explicit"}))
+	FromContext(ctx).Info("order shipped")
+	assert.Equal(t, "INFO  req-1 order shipped fields[tenant_id: explicit, user_id: u-42] \n", buf.String())
+
+	// context value key
+	assert.Nil(t, logger.AddContextField("tenant", tenantKey{}))
+	buf.Reset()
+	logger.WithContext(context.WithValue(context.Background(), tenantKey{}, "globex")).Info("invoice")
+	assert.Equal(t, "INFO  invoice fields[tenant: globex] \n", buf.String())
+
+	// logger in context
+	buf.Reset()
+	ctx = context.WithValue(NewContext(context.Background(), logger), tenantKey{}, "initech")
+	FromContext(ctx).Info("refund")
+	assert.Equal(t, "INFO  refund fields[tenant: initech] \n", buf.String())
+
+	err = logger.AddContextField("tenant", tenantKey{})
+	assert.Equal(t, "log: context field name 'tenant' is already added, skip it", err.Error())
+	assert.Equal(t, ErrContextKeyIsNil, logger.AddContextField("user", nil))
+	assert.Equal(t, "log: context field name is empty", logger.AddContextField(" ", tenantKey{}).Error())
+
+	cfg, _ = config.ParseString(`log { baggage = ["=user"] }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid baggage mapping '=user'", err.Error())
+}
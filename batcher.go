@@ -0,0 +1,118 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// batcher queues the items and hands them over to flush func in batches,
+// batch is flushed when it reaches batch size or on flush interval
+// whichever comes first. Queued items are flushed on flush and close, flush
+// after close is no-op.
+type batcher struct {
+	size      int
+	interval  time.Duration
+	flushFn   func(items []interface{})
+	queue     chan interface{}
+	flushReq  chan chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func newBatcher(size, queueSize int, interval time.Duration, flushFn func([]interface{})) *batcher {
+	if size <= 0 {
+		size = 1
+	}
+	if queueSize < size {
+		queueSize = size
+	}
+	b := &batcher{
+		size:     size,
+		interval: interval,
+		flushFn:  flushFn,
+		queue:    make(chan interface{}, queueSize),
+		flushReq: make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.run()
+	return b
+}
+
+// add method queues the item, returns false if queue is full and item
+// is dropped.
+func (b *batcher) add(item interface{}) bool {
+	select {
+	case b.queue <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+// flush method flushes the queued items and waits for it to complete.
+func (b *batcher) flush() {
+	ack := make(chan struct{})
+	select {
+	case b.flushReq <- ack:
+		<-ack
+	case <-b.done:
+	}
+}
+
+// close method flushes the queued items and stops the batcher, it's safe to
+// call more than once.
+func (b *batcher) close() {
+	b.closeOnce.Do(func() { close(b.done) })
+	b.wg.Wait()
+}
+
+func (b *batcher) run() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	batch := make([]interface{}, 0, b.size)
+	flush := func() {
+		if len(batch) > 0 {
+			b.flushFn(batch)
+			batch = make([]interface{}, 0, b.size)
+		}
+	}
+
+	drain := func() {
+		for {
+			select {
+			case item := <-b.queue:
+				if batch = append(batch, item); len(batch) >= b.size {
+					flush()
+				}
+			default:
+				flush()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case item := <-b.queue:
+			if batch = append(batch, item); len(batch) >= b.size {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case ack := <-b.flushReq:
+			drain()
+			close(ack)
+		case <-b.done:
+			drain()
+			return
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// CBOR major types
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborBytes  = 2 << 5
+	cborString = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+)
+
+// cborEncoder is minimal CBOR (RFC 8949) encoder which covers the types used
+// by log entry. Map keys are sorted, so encoding is deterministic.
+type cborEncoder struct {
+	bytes.Buffer
+}
+
+func (e *cborEncoder) encode(v interface{}) {
+	switch t := v.(type) {
+	case nil:
+		_ = e.WriteByte(0xf6)
+	case bool:
+		if t {
+			_ = e.WriteByte(0xf5)
+		} else {
+			_ = e.WriteByte(0xf4)
+		}
+	case int:
+		e.int(int64(t))
+	case int8:
+		e.int(int64(t))
+	case int16:
+		e.int(int64(t))
+	case int32:
+		e.int(int64(t))
+	case int64:
+		e.int(t)
+	case uint:
+		e.head(cborUint, uint64(t))
+	case uint8:
+		e.head(cborUint, uint64(t))
+	case uint16:
+		e.head(cborUint, uint64(t))
+	case uint32:
+		e.head(cborUint, uint64(t))
+	case uint64:
+		e.head(cborUint, t)
+	case float32:
+		e.float(float64(t))
+	case float64:
+		e.float(t)
+	case string:
+		e.string(t)
+	case []byte:
+		e.head(cborBytes, uint64(len(t)))
+		_, _ = e.Write(t)
+	case time.Time:
+		e.time(t)
+	case time.Duration:
+		e.int(int64(t))
+	case []interface{}:
+		e.head(cborArray, uint64(len(t)))
+		for _, i := range t {
+			e.encode(i)
+		}
+	case []string:
+		e.head(cborArray, uint64(len(t)))
+		for _, i := range t {
+			e.string(i)
+		}
+	case Fields:
+		e.encode(map[string]interface{}(t))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		e.head(cborMap, uint64(len(t)))
+		for _, k := range keys {
+			e.string(k)
+			e.encode(t[k])
+		}
+	case error:
+		e.string(t.Error())
+	case fmt.Stringer:
+		e.string(t.String())
+	default:
+		e.string(fmt.Sprint(t))
+	}
+}
+
+// head method writes the major type with argument in the shortest form.
+func (e *cborEncoder) head(major byte, v uint64) {
+	switch {
+	case v < 24:
+		_ = e.WriteByte(major | byte(v))
+	case v <= math.MaxUint8:
+		_, _ = e.Write([]byte{major | 24, byte(v)})
+	case v <= math.MaxUint16:
+		_ = e.WriteByte(major | 25)
+		_ = binary.Write(e, binary.BigEndian, uint16(v))
+	case v <= math.MaxUint32:
+		_ = e.WriteByte(major | 26)
+		_ = binary.Write(e, binary.BigEndian, uint32(v))
+	default:
+		_ = e.WriteByte(major | 27)
+		_ = binary.Write(e, binary.BigEndian, v)
+	}
+}
+
+func (e *cborEncoder) int(v int64) {
+	if v < 0 {
+		e.head(cborNegInt, uint64(-(v + 1)))
+		return
+	}
+	e.head(cborUint, uint64(v))
+}
+
+func (e *cborEncoder) float(v float64) {
+	_ = e.WriteByte(0xfb)
+	_ = binary.Write(e, binary.BigEndian, math.Float64bits(v))
+}
+
+func (e *cborEncoder) string(v string) {
+	e.head(cborString, uint64(len(v)))
+	_, _ = e.WriteString(v)
+}
+
+// time method encodes the time as epoch-based date/time, tag 1 with integer
+// seconds or float seconds if time has fraction.
+func (e *cborEncoder) time(t time.Time) {
+	e.head(cborTag, 1)
+	if t.Nanosecond() == 0 {
+		e.int(t.Unix())
+		return
+	}
+	e.float(float64(t.UnixNano()) / float64(time.Second))
+}
@@ -0,0 +1,53 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestCBOREncode(t *testing.T) {
+	// expected values are from RFC 8949 Appendix A
+	testcases := []struct {
+		value  interface{}
+		expect string
+	}{
+		{value: nil, expect: "f6"},
+		{value: true, expect: "f5"},
+		{value: false, expect: "f4"},
+		{value: 0, expect: "00"},
+		{value: 23, expect: "17"},
+		{value: 24, expect: "1818"},
+		{value: uint16(1000), expect: "1903e8"},
+		{value: 1000000, expect: "1a000f4240"},
+		{value: uint64(1000000000000), expect: "1b000000e8d4a51000"},
+		{value: -1, expect: "20"},
+		{value: -1000, expect: "3903e7"},
+		{value: int64(math.MinInt64), expect: "3b7fffffffffffffff"},
+		{value: 1.1, expect: "fb3ff199999999999a"},
+		{value: "", expect: "60"},
+		{value: "IETF", expect: "6449455446"},
+		{value: []byte{1, 2, 3, 4}, expect: "4401020304"},
+		{value: []string{"a", "b"}, expect: "8261616162"},
+		{value: []interface{}{1, []interface{}{2, 3}}, expect: "8201820203"},
+		{value: Fields{"b": 2, "a": 1}, expect: "a2616101616202"},
+		{value: errors.New("a"), expect: "6161"},
+		{value: LevelInfo, expect: "64494e464f"},
+		{value: time.Unix(1363896240, 0), expect: "c11a514b67b0"},
+		{value: time.Unix(1363896240, 500000000), expect: "c1fb41d452d9ec200000"},
+	}
+
+	for _, tc := range testcases {
+		e := &cborEncoder{}
+		e.encode(tc.value)
+		assert.Equal(t, tc.expect, hex.EncodeToString(e.Bytes()))
+	}
+}
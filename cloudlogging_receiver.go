@@ -0,0 +1,296 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var (
+	levelToCloudSeverity = map[level]string{
+		LevelFatal: "CRITICAL",
+		LevelPanic: "ALERT",
+		LevelError: "ERROR",
+		LevelWarn:  "WARNING",
+		LevelInfo:  "INFO",
+		LevelDebug: "DEBUG",
+		LevelTrace: "DEBUG",
+	}
+
+	_ Receiver = (*CloudLoggingReceiver)(nil)
+)
+
+// CloudLoggingReceiver writes the log entry into Google Cloud Logging
+// (formerly Stackdriver) using v2 `entries:write` API. Entry fields are sent
+// as `jsonPayload` and monitored resource is auto detected on GCE, GKE and
+// Cloud Run unless configured.
+type CloudLoggingReceiver struct {
+	errorReporter
+	endpoint     string
+	projectID    string
+	logID        string
+	logName      string
+	resource     *cloudResource
+	ready        bool
+	tokens       *gcpTokenSource
+	metadata     *http.Client
+	sender       *httpSender
+	batcher      *batcher
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+}
+
+type cloudResource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type cloudLogEntry struct {
+	Severity       string                 `json:"severity"`
+	Timestamp      string                 `json:"timestamp"`
+	JSONPayload    map[string]interface{} `json:"jsonPayload"`
+	SourceLocation *cloudSourceLocation   `json:"sourceLocation,omitempty"`
+}
+
+type cloudSourceLocation struct {
+	File string `json:"file"`
+	Line string `json:"line"`
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// CloudLoggingReceiver methods
+//___________________________________
+
+// Init method initializes the Google Cloud Logging receiver instance.
+func (c *CloudLoggingReceiver) Init(cfg *config.Config) error {
+	c.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(c.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", c.formatter)
+	}
+
+	c.endpoint = cfg.StringDefault("log.cloudlogging.endpoint", "https://logging.googleapis.com/v2/entries:write")
+	c.logID = cfg.StringDefault("log.cloudlogging.log_id", "aah")
+	if ess.IsStrEmpty(c.logID) {
+		return errors.New("log: cloudlogging log_id is required")
+	}
+
+	var err error
+	c.tokens, err = newGCPTokenSource(cfg.StringDefault("log.cloudlogging.credentials_file",
+		os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")))
+	if err != nil {
+		return err
+	}
+
+	c.projectID = cfg.StringDefault("log.cloudlogging.project_id", os.Getenv("GOOGLE_CLOUD_PROJECT"))
+	if ess.IsStrEmpty(c.projectID) && c.tokens.account != nil {
+		c.projectID = c.tokens.account.ProjectID
+	}
+	if resType := cfg.StringDefault("log.cloudlogging.resource.type", ""); len(resType) > 0 {
+		c.resource = &cloudResource{Type: resType, Labels: make(map[string]string)}
+		for _, k := range cfg.KeysByPath("log.cloudlogging.resource.labels") {
+			c.resource.Labels[k] = cfg.StringDefault("log.cloudlogging.resource.labels."+k, "")
+		}
+	}
+
+	if c.sender, err = newHTTPSender(cfg, "cloudlogging"); err != nil {
+		return err
+	}
+	c.metadata = &http.Client{Timeout: time.Second}
+
+	flushInterval, err := parseInterval(cfg, "log.cloudlogging.flush_interval", "1s")
+	if err != nil {
+		return err
+	}
+	c.batcher = newBatcher(cfg.IntDefault("log.cloudlogging.batch_size", 500),
+		cfg.IntDefault("log.cloudlogging.queue_size", 5000), flushInterval, c.write)
+
+	c.SetWriter(writerFunc(func(p []byte) (int, error) {
+		c.batcher.add(&cloudLogEntry{
+			Severity:    levelToCloudSeverity[LevelInfo],
+			Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+			JSONPayload: map[string]interface{}{"message": string(bytes.TrimRight(p, "\n"))},
+		})
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (c *CloudLoggingReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	c.flags = flags
+	if c.formatter != jsonFmt {
+		c.isCallerInfo = isCallerInfo(c.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into Cloud Logging receiver.
+func (c *CloudLoggingReceiver) SetWriter(w io.Writer) {
+	c.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (c *CloudLoggingReceiver) IsCallerInfo() bool {
+	return c.isCallerInfo
+}
+
+// Log method queues the log entry to be written into Cloud Logging. Level is
+// mapped to Cloud Logging severity.
+func (c *CloudLoggingReceiver) Log(entry *Entry) {
+	payload := make(map[string]interface{}, len(entry.Fields)+1)
+	for k, v := range entry.Fields {
+		payload[k] = v
+	}
+	payload["message"] = string(bytes.TrimRight(formatEntry(c.formatter, c.flags, entry), " \n"))
+
+	e := &cloudLogEntry{
+		Severity:    levelToCloudSeverity[builtinLevel(entry.Level)],
+		Timestamp:   entry.Time.UTC().Format(time.RFC3339Nano),
+		JSONPayload: payload,
+	}
+	if len(entry.File) > 0 {
+		e.SourceLocation = &cloudSourceLocation{File: entry.File, Line: fmt.Sprint(entry.Line)}
+	}
+	if !c.batcher.add(e) {
+		c.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (c *CloudLoggingReceiver) Writer() io.Writer {
+	return c.out
+}
+
+// Close method writes the queued entries and stops the receiver.
+func (c *CloudLoggingReceiver) Close() {
+	c.batcher.close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// CloudLoggingReceiver Unexported methods
+//___________________________________
+
+func (c *CloudLoggingReceiver) write(items []interface{}) {
+	if !c.ready {
+		if err := c.prepare(); err != nil {
+			c.reportError(err, nil)
+			return
+		}
+	}
+
+	entries := make([]*cloudLogEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, item.(*cloudLogEntry))
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"logName":        c.logName,
+		"resource":       c.resource,
+		"entries":        entries,
+		"partialSuccess": true,
+	})
+	if err != nil {
+		c.reportError(err, nil)
+		return
+	}
+
+	token, err := c.tokens.Token()
+	if err != nil {
+		c.reportError(err, nil)
+		return
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Authorization", "Bearer "+token)
+	if _, err = c.sender.send(http.MethodPost, c.endpoint, body, header); err != nil {
+		c.reportError(err, nil)
+	}
+}
+
+// prepare method resolves the project ID and monitored resource.
+func (c *CloudLoggingReceiver) prepare() error {
+	if ess.IsStrEmpty(c.projectID) {
+		projectID, err := gcpMetadata(c.metadata, "project/project-id")
+		if err != nil {
+			return errors.New("log: cloudlogging project_id is required")
+		}
+		c.projectID = projectID
+	}
+	if c.resource == nil {
+		c.resource = c.detectResource()
+	}
+
+	c.logName = "projects/" + c.projectID + "/logs/" + url.PathEscape(c.logID)
+	c.ready = true
+	return nil
+}
+
+// detectResource method detects the monitored resource in the order of
+// Cloud Run, GKE and GCE otherwise `global`.
+func (c *CloudLoggingReceiver) detectResource() *cloudResource {
+	metadata := func(p string) string {
+		v, _ := gcpMetadata(c.metadata, p)
+		return v
+	}
+
+	if service := os.Getenv("K_SERVICE"); len(service) > 0 {
+		return &cloudResource{Type: "cloud_run_revision", Labels: map[string]string{
+			"project_id":         c.projectID,
+			"service_name":       service,
+			"revision_name":      os.Getenv("K_REVISION"),
+			"configuration_name": os.Getenv("K_CONFIGURATION"),
+			"location":           path.Base(metadata("instance/region")),
+		}}
+	}
+
+	zone := metadata("instance/zone")
+	if len(zone) == 0 {
+		return &cloudResource{Type: "global", Labels: map[string]string{"project_id": c.projectID}}
+	}
+
+	if len(os.Getenv("KUBERNETES_SERVICE_HOST")) > 0 {
+		namespace := os.Getenv("POD_NAMESPACE")
+		if len(namespace) == 0 {
+			b, _ := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+			namespace = strings.TrimSpace(string(b))
+		}
+		hostname, _ := os.Hostname()
+		return &cloudResource{Type: "k8s_container", Labels: map[string]string{
+			"project_id":     c.projectID,
+			"location":       metadata("instance/attributes/cluster-location"),
+			"cluster_name":   metadata("instance/attributes/cluster-name"),
+			"namespace_name": namespace,
+			"pod_name":       hostname,
+			"container_name": os.Getenv("CONTAINER_NAME"),
+		}}
+	}
+
+	return &cloudResource{Type: "gce_instance", Labels: map[string]string{
+		"project_id":  c.projectID,
+		"instance_id": metadata("instance/id"),
+		"zone":        path.Base(zone),
+	}}
+}
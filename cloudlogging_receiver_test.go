@@ -0,0 +1,123 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestCloudLoggingLogger(t *testing.T) {
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		switch r.URL.Path {
+		case "/computeMetadata/v1/project/project-id":
+			_, _ = w.Write([]byte("my-project"))
+		case "/computeMetadata/v1/instance/zone":
+			_, _ = w.Write([]byte("projects/123456/zones/us-central1-a"))
+		case "/computeMetadata/v1/instance/id":
+			_, _ = w.Write([]byte("4520031799277581759"))
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			_, _ = w.Write([]byte(`{"access_token":"metadata-token","expires_in":3600}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer metadata.Close()
+	host := gcpMetadataHost
+	gcpMetadataHost = metadata.URL
+	defer func() { gcpMetadataHost = host }()
+	_ = os.Unsetenv("K_SERVICE")
+	_ = os.Unsetenv("KUBERNETES_SERVICE_HOST")
+
+	var (
+		mu   sync.Mutex
+		auth string
+		req  struct {
+			LogName  string           `json:"logName"`
+			Resource cloudResource    `json:"resource"`
+			Entries  []*cloudLogEntry `json:"entries"`
+		}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "cloudlogging"
+    pattern = "%%level:-5 %%message"
+    cloudlogging {
+      endpoint = "%s"
+      credentials_file = ""
+      flush_interval = "1h"
+    }
+  }
+  `, server.URL)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("key1", "value1").Warn("Yes, yes it's an warning")
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.receiver.(*CloudLoggingReceiver).Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "Bearer metadata-token", auth)
+	assert.Equal(t, "projects/my-project/logs/aah", req.LogName)
+	assert.Equal(t, "gce_instance", req.Resource.Type)
+	assert.Equal(t, "us-central1-a", req.Resource.Labels["zone"])
+	assert.Equal(t, "4520031799277581759", req.Resource.Labels["instance_id"])
+
+	assert.Equal(t, 2, len(req.Entries))
+	assert.Equal(t, "WARNING", req.Entries[0].Severity)
+	assert.Equal(t, "WARN  Yes, yes it's an warning", req.Entries[0].JSONPayload["message"])
+	assert.Equal(t, "value1", req.Entries[0].JSONPayload["key1"])
+	assert.Equal(t, "ERROR", req.Entries[1].Severity)
+}
+
+func TestCloudLoggingDetectResource(t *testing.T) {
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/region":
+			_, _ = w.Write([]byte("projects/123456/regions/us-central1"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer metadata.Close()
+	host := gcpMetadataHost
+	gcpMetadataHost = metadata.URL
+	defer func() { gcpMetadataHost = host }()
+
+	c := &CloudLoggingReceiver{projectID: "my-project", metadata: &http.Client{}}
+	assert.Equal(t, &cloudResource{Type: "global", Labels: map[string]string{"project_id": "my-project"}}, c.detectResource())
+
+	_ = os.Setenv("K_SERVICE", "api")
+	_ = os.Setenv("K_REVISION", "api-00001")
+	defer func() {
+		_ = os.Unsetenv("K_SERVICE")
+		_ = os.Unsetenv("K_REVISION")
+	}()
+	res := c.detectResource()
+	assert.Equal(t, "cloud_run_revision", res.Type)
+	assert.Equal(t, "api", res.Labels["service_name"])
+	assert.Equal(t, "api-00001", res.Labels["revision_name"])
+	assert.Equal(t, "us-central1", res.Labels["location"])
+}
@@ -0,0 +1,331 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+// CloudWatch Logs PutLogEvents limits
+const (
+	cwMaxBatchEvents = 10000
+	cwMaxBatchBytes  = 1048576
+	cwEventOverhead  = 26
+	cwMaxEventBytes  = 262144 - cwEventOverhead
+	cwMaxBatchSpan   = 24 * time.Hour
+)
+
+var _ Receiver = (*CloudWatchReceiver)(nil)
+
+// CloudWatchReceiver sends the log entry into AWS CloudWatch Logs using
+// PutLogEvents API. Log group and stream are created if not exists, batches
+// are split as per CloudWatch limits. Credentials are resolved from config,
+// environment, ECS container or EC2 instance role.
+type CloudWatchReceiver struct {
+	errorReporter
+	endpoint      string
+	region        string
+	group         string
+	stream        string
+	createGroup   bool
+	retentionDays int
+	seqToken      string
+	ready         bool
+	creds         *awsCredentialChain
+	sender        *httpSender
+	batcher       *batcher
+	out           io.Writer
+	formatter     string
+	flags         []ess.FmtFlagPart
+	isCallerInfo  bool
+}
+
+type cwEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+// cloudWatchError represents the CloudWatch Logs API error response.
+type cloudWatchError struct {
+	Type                  string `json:"__type"`
+	Message               string `json:"message"`
+	ExpectedSequenceToken string `json:"expectedSequenceToken"`
+}
+
+func (e *cloudWatchError) Error() string {
+	return fmt.Sprintf("log: cloudwatch %s: %s", e.Type, e.Message)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// CloudWatchReceiver methods
+//___________________________________
+
+// Init method initializes the CloudWatch Logs receiver instance.
+func (cw *CloudWatchReceiver) Init(cfg *config.Config) error {
+	cw.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(cw.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", cw.formatter)
+	}
+
+	cw.region = cfg.StringDefault("log.cloudwatch.region", os.Getenv("AWS_REGION"))
+	if ess.IsStrEmpty(cw.region) {
+		cw.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if ess.IsStrEmpty(cw.region) {
+		return errors.New("log: cloudwatch region is required")
+	}
+	cw.endpoint = cfg.StringDefault("log.cloudwatch.endpoint", "https://logs."+cw.region+".amazonaws.com/")
+
+	cw.group = cfg.StringDefault("log.cloudwatch.group", "")
+	if ess.IsStrEmpty(cw.group) {
+		return errors.New("log: cloudwatch group is required")
+	}
+	hostname, _ := os.Hostname()
+	cw.stream = cfg.StringDefault("log.cloudwatch.stream", hostname)
+	if ess.IsStrEmpty(cw.stream) {
+		return errors.New("log: cloudwatch stream is required")
+	}
+	cw.createGroup = cfg.BoolDefault("log.cloudwatch.create_group", true)
+	cw.retentionDays = cfg.IntDefault("log.cloudwatch.retention_days", 0)
+
+	var err error
+	if cw.sender, err = newHTTPSender(cfg, "cloudwatch"); err != nil {
+		return err
+	}
+	cw.creds = newAWSCredentialChain(cfg, "cloudwatch")
+
+	flushInterval, err := parseInterval(cfg, "log.cloudwatch.flush_interval", "5s")
+	if err != nil {
+		return err
+	}
+	cw.batcher = newBatcher(cfg.IntDefault("log.cloudwatch.batch_size", 1000),
+		cfg.IntDefault("log.cloudwatch.queue_size", 10000), flushInterval, cw.flush)
+
+	cw.SetWriter(writerFunc(func(p []byte) (int, error) {
+		cw.batcher.add(&cwEvent{Timestamp: toMillis(time.Now()), Message: string(bytes.TrimRight(p, "\n"))})
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (cw *CloudWatchReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	cw.flags = flags
+	if cw.formatter != jsonFmt {
+		cw.isCallerInfo = isCallerInfo(cw.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into CloudWatch receiver.
+func (cw *CloudWatchReceiver) SetWriter(w io.Writer) {
+	cw.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (cw *CloudWatchReceiver) IsCallerInfo() bool {
+	return cw.isCallerInfo
+}
+
+// Log method queues the log entry to be sent to CloudWatch Logs.
+func (cw *CloudWatchReceiver) Log(entry *Entry) {
+	if !cw.batcher.add(&cwEvent{
+		Timestamp: toMillis(entry.Time),
+		Message:   string(bytes.TrimRight(formatEntry(cw.formatter, cw.flags, entry), " \n")),
+	}) {
+		cw.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (cw *CloudWatchReceiver) Writer() io.Writer {
+	return cw.out
+}
+
+// Close method sends the queued entries and stops the receiver.
+func (cw *CloudWatchReceiver) Close() {
+	cw.batcher.close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// CloudWatchReceiver Unexported methods
+//___________________________________
+
+func (cw *CloudWatchReceiver) flush(items []interface{}) {
+	if !cw.ready {
+		if err := cw.ensureStream(); err != nil {
+			cw.reportError(err, nil)
+			return
+		}
+	}
+
+	events := make([]*cwEvent, 0, len(items))
+	for _, item := range items {
+		e := item.(*cwEvent)
+		if len(e.Message) > cwMaxEventBytes {
+			e.Message = e.Message[:cwMaxEventBytes]
+		}
+		events = append(events, e)
+	}
+
+	// events in a batch must be in chronological order
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp < events[j].Timestamp })
+	for _, batch := range cwSplitBatches(events) {
+		if err := cw.putLogEvents(batch); err != nil {
+			cw.reportError(err, nil)
+		}
+	}
+}
+
+func (cw *CloudWatchReceiver) putLogEvents(events []*cwEvent) error {
+	req := map[string]interface{}{
+		"logGroupName":  cw.group,
+		"logStreamName": cw.stream,
+		"logEvents":     events,
+	}
+
+	// one retry is done for sequence token mismatch or missing stream
+	for attempt := 0; ; attempt++ {
+		if len(cw.seqToken) > 0 {
+			req["sequenceToken"] = cw.seqToken
+		}
+
+		body, err := cw.call("PutLogEvents", req)
+		if err == nil {
+			var res struct {
+				NextSequenceToken string `json:"nextSequenceToken"`
+			}
+			_ = json.Unmarshal(body, &res)
+			cw.seqToken = res.NextSequenceToken
+			return nil
+		}
+
+		cwErr, ok := err.(*cloudWatchError)
+		if !ok || attempt > 0 {
+			return err
+		}
+		switch cwErr.Type {
+		case "InvalidSequenceTokenException":
+			cw.seqToken = cwErr.ExpectedSequenceToken
+		case "DataAlreadyAcceptedException":
+			cw.seqToken = cwErr.ExpectedSequenceToken
+			return nil
+		case "ResourceNotFoundException":
+			cw.seqToken = ""
+			if err = cw.ensureStream(); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+}
+
+// ensureStream method creates the log group and stream, already exists
+// error is ignored.
+func (cw *CloudWatchReceiver) ensureStream() error {
+	if cw.createGroup {
+		if err := cw.create("CreateLogGroup", map[string]interface{}{"logGroupName": cw.group}); err != nil {
+			return err
+		}
+		if cw.retentionDays > 0 {
+			if _, err := cw.call("PutRetentionPolicy", map[string]interface{}{
+				"logGroupName":    cw.group,
+				"retentionInDays": cw.retentionDays,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cw.create("CreateLogStream", map[string]interface{}{
+		"logGroupName":  cw.group,
+		"logStreamName": cw.stream,
+	}); err != nil {
+		return err
+	}
+	cw.ready = true
+	return nil
+}
+
+func (cw *CloudWatchReceiver) create(action string, req map[string]interface{}) error {
+	_, err := cw.call(action, req)
+	if cwErr, ok := err.(*cloudWatchError); ok && cwErr.Type == "ResourceAlreadyExistsException" {
+		return nil
+	}
+	return err
+}
+
+// call method invokes the CloudWatch Logs API action with signed request.
+func (cw *CloudWatchReceiver) call(action string, req interface{}) ([]byte, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := cw.creds.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-amz-json-1.1")
+	header.Set("X-Amz-Target", "Logs_20140328."+action)
+	if err = awsSignV4(http.MethodPost, cw.endpoint, body, header, creds, cw.region, "logs", time.Now()); err != nil {
+		return nil, err
+	}
+
+	resBody, err := cw.sender.send(http.MethodPost, cw.endpoint, body, header)
+	if se, ok := err.(*httpStatusError); ok {
+		cwErr := &cloudWatchError{}
+		if json.Unmarshal([]byte(se.body), cwErr) == nil && len(cwErr.Type) > 0 {
+			if idx := strings.LastIndex(cwErr.Type, "#"); idx >= 0 {
+				cwErr.Type = cwErr.Type[idx+1:]
+			}
+			return nil, cwErr
+		}
+	}
+	return resBody, err
+}
+
+// cwSplitBatches method splits the sorted events as per PutLogEvents limits
+// of event count, batch size and time span.
+func cwSplitBatches(events []*cwEvent) [][]*cwEvent {
+	var (
+		batches [][]*cwEvent
+		start   int
+		size    int
+	)
+	for i, e := range events {
+		eventSize := len(e.Message) + cwEventOverhead
+		if i > start && (i-start >= cwMaxBatchEvents || size+eventSize > cwMaxBatchBytes ||
+			e.Timestamp-events[start].Timestamp > int64(cwMaxBatchSpan/time.Millisecond)) {
+			batches = append(batches, events[start:i])
+			start, size = i, 0
+		}
+		size += eventSize
+	}
+	if start < len(events) {
+		batches = append(batches, events[start:])
+	}
+	return batches
+}
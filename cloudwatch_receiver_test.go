@@ -0,0 +1,119 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestCloudWatchLogger(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		actions []string
+		events  []cwEvent
+		tokens  []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+
+		action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "Logs_20140328.")
+		actions = append(actions, action)
+		var req struct {
+			SequenceToken string    `json:"sequenceToken"`
+			LogEvents     []cwEvent `json:"logEvents"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		switch action {
+		case "CreateLogGroup":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.logs#ResourceAlreadyExistsException","message":"exists"}`))
+		case "PutLogEvents":
+			tokens = append(tokens, req.SequenceToken)
+			if len(tokens) == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"InvalidSequenceTokenException","message":"invalid","expectedSequenceToken":"token-1"}`))
+				return
+			}
+			events = append(events, req.LogEvents...)
+			_, _ = w.Write([]byte(`{"nextSequenceToken":"token-2"}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "cloudwatch"
+    pattern = "%%level:-5 %%message"
+    cloudwatch {
+      endpoint = "%s/"
+      region = "us-east-1"
+      group = "/aah/app"
+      stream = "node-1"
+      access_key_id = "AKID"
+      secret_access_key = "secret"
+      flush_interval = "1h"
+    }
+  }
+  `, server.URL)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.Info("Yes, I would love to see")
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.receiver.(*CloudWatchReceiver).Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"CreateLogGroup", "CreateLogStream", "PutLogEvents", "PutLogEvents"}, actions)
+	assert.Equal(t, []string{"", "token-1"}, tokens)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "INFO  Yes, I would love to see", events[0].Message)
+	assert.Equal(t, "token-2", logger.receiver.(*CloudWatchReceiver).seqToken)
+}
+
+func TestCloudWatchSplitBatches(t *testing.T) {
+	now := toMillis(time.Now())
+	events := []*cwEvent{
+		{Timestamp: now, Message: strings.Repeat("a", cwMaxEventBytes)},
+		{Timestamp: now, Message: strings.Repeat("b", cwMaxEventBytes)},
+		{Timestamp: now, Message: strings.Repeat("c", cwMaxEventBytes)},
+		{Timestamp: now, Message: strings.Repeat("d", cwMaxEventBytes)},
+		{Timestamp: now, Message: "e"},
+		{Timestamp: now + int64(25*time.Hour/time.Millisecond), Message: "f"},
+	}
+	batches := cwSplitBatches(events)
+	assert.Equal(t, 3, len(batches))
+	assert.Equal(t, 4, len(batches[0]))
+	assert.Equal(t, 1, len(batches[1]))
+	assert.Equal(t, "f", batches[2][0].Message)
+
+	events = make([]*cwEvent, cwMaxBatchEvents+1)
+	for i := range events {
+		events[i] = &cwEvent{Timestamp: now, Message: "m"}
+	}
+	assert.Equal(t, 2, len(cwSplitBatches(events)))
+}
+
+func TestCloudWatchLoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "cloudwatch", cloudwatch { region = "us-east-1" } }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: cloudwatch group is required", err.Error())
+}
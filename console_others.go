@@ -0,0 +1,15 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package log
+
+import "os"
+
+// enableConsoleColor method returns true, terminals support ANSI colors.
+func enableConsoleColor(f *os.File) bool {
+	return true
+}
@@ -5,11 +5,9 @@
 package log
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"runtime"
 	"sync"
 
 	"aahframework.org/config.v0"
@@ -19,6 +17,7 @@ import (
 var (
 	// ANSI color codes
 	resetColor   = []byte("\033[0m")
+	dimColor     = []byte("\033[2m")
 	levelToColor = [][]byte{
 		LevelFatal: []byte("\033[0;31m"), // red
 		LevelPanic: []byte("\033[0;31m"), // red
@@ -33,13 +32,27 @@ var (
 )
 
 // ConsoleReceiver writes the log entry into os.Stderr.
-// For non-windows it  writes with color.
+//
+// Color is enabled when output is a terminal and `NO_COLOR` environment
+// variable is not set, `log.color` overrides the detection. For text format
+// level is colored and caller info is dimmed, other formats are colored
+// as whole line. On Windows console virtual terminal processing is enabled.
+//
+// When `log.console.split` is enabled, entries of level `log.console.stderr_level`
+// (default ERROR) and above are written into os.Stderr and rest of the entries
+// into os.Stdout.
 type ConsoleReceiver struct {
 	out          io.Writer
+	errOut       io.Writer
+	isSplit      bool
+	stderrLevel  level
 	formatter    string
 	flags        []ess.FmtFlagPart
 	isCallerInfo bool
 	isColor      bool
+	isColorAuto  bool
+	multiline    string
+	errorFn      ErrorHandlerFunc
 	mu           sync.Mutex
 }
 
@@ -50,16 +63,30 @@ type ConsoleReceiver struct {
 // Init method initializes the console logger.
 func (c *ConsoleReceiver) Init(cfg *config.Config) error {
 	c.out = os.Stderr
-	c.isColor = runtime.GOOS != "windows"
-
+	c.errOut = os.Stderr
 	if v, found := cfg.Bool("log.color"); found {
 		c.isColor = v
+	} else {
+		c.isColorAuto = true
 	}
 
 	c.formatter = cfg.StringDefault("log.format", "text")
-	if !(c.formatter == textFmt || c.formatter == jsonFmt) {
+	if !isFormatSupported(c.formatter) {
 		return fmt.Errorf("log: unsupported format '%s'", c.formatter)
 	}
+	c.multiline = cfg.StringDefault("log.text.multiline", "")
+
+	if c.isSplit = cfg.BoolDefault("log.console.split", false); c.isSplit {
+		c.out = os.Stdout
+		levelName := cfg.StringDefault("log.console.stderr_level", "error")
+		if c.stderrLevel = levelByName(levelName); c.stderrLevel == LevelUnknown {
+			return fmt.Errorf("log: unknown console stderr level '%s'", levelName)
+		}
+	}
+
+	if c.isColorAuto {
+		c.isColor = isColorTerminal(c.out)
+	}
 
 	c.mu = sync.Mutex{}
 
@@ -73,15 +100,25 @@ func (c *ConsoleReceiver) SetPattern(pattern string) error {
 		return err
 	}
 	c.flags = flags
-	if c.formatter == textFmt {
+	if c.formatter != jsonFmt {
 		c.isCallerInfo = isCallerInfo(c.flags)
 	}
 	return nil
 }
 
-// SetWriter method sets the given writer into console receiver.
+// SetWriter method sets the given writer into console receiver. Color
+// detection is applied on the given writer unless `log.color` is configured.
 func (c *ConsoleReceiver) SetWriter(w io.Writer) {
 	c.out = w
+	if c.isColorAuto {
+		c.isColor = isColorTerminal(w)
+	}
+}
+
+// SetErrorWriter method sets the given writer into console receiver for
+// the entries written into os.Stderr on split mode.
+func (c *ConsoleReceiver) SetErrorWriter(w io.Writer) {
+	c.errOut = w
 }
 
 // IsCallerInfo method returns true if log receiver is configured with caller info
@@ -90,30 +127,75 @@ func (c *ConsoleReceiver) IsCallerInfo() bool {
 	return c.isCallerInfo
 }
 
-// Log method writes the log entry into os.Stderr.
+// Log method writes the log entry into os.Stderr, on split mode into
+// os.Stdout or os.Stderr based on entry level.
 func (c *ConsoleReceiver) Log(entry *Entry) {
+	c.mu.Lock()
+	err := c.write(entry)
+	fn := c.errorFn
+	c.mu.Unlock()
+	if err != nil && fn != nil {
+		fn(err, entry)
+	}
+}
+
+// OnError method sets the func, which is called when entry write fails.
+func (c *ConsoleReceiver) OnError(fn ErrorHandlerFunc) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.errorFn = fn
+}
+
+// Writer method returns the current log writer.
+func (c *ConsoleReceiver) Writer() io.Writer {
+	return c.out
+}
 
-	if c.isColor {
-		_, _ = c.out.Write(levelToColor[entry.Level])
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// write method writes the entry into output as per color mode, it returns
+// the first write error.
+func (c *ConsoleReceiver) write(entry *Entry) error {
+	out := c.out
+	if c.isSplit && entry.Level <= c.stderrLevel {
+		out = c.errOut
+	}
+
+	if !c.isColor {
+		_, err := out.Write(formatEntry(c.formatter, c.flags, entry))
+		return err
 	}
 
-	var msg []byte
 	if c.formatter == textFmt {
-		msg = textFormatter(c.flags, entry)
-	} else {
-		msg, _ = json.Marshal(entry)
-		msg = append(msg, '\n')
+		_, err := out.Write(colorTextFormatter(c.flags, entry, c.multiline))
+		return err
 	}
-	_, _ = c.out.Write(msg)
 
-	if c.isColor {
-		_, _ = c.out.Write(resetColor)
+	if _, err := out.Write(levelToColor[builtinLevel(entry.Level)]); err != nil {
+		return err
+	}
+	if _, err := out.Write(formatEntry(c.formatter, c.flags, entry)); err != nil {
+		return err
 	}
+	_, err := out.Write(resetColor)
+	return err
 }
 
-// Writer method returns the current log writer.
-func (c *ConsoleReceiver) Writer() io.Writer {
-	return c.out
+// isColorTerminal method returns true if given writer is a terminal and
+// `NO_COLOR` environment variable is not set.
+func isColorTerminal(w io.Writer) bool {
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	return enableConsoleColor(f)
 }
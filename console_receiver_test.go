@@ -5,6 +5,7 @@
 package log
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -87,6 +88,39 @@ func TestConsoleLoggerUnknownLevel(t *testing.T) {
 	assert.Equal(t, "log: unknown log level 'MYLEVEL'", err.Error())
 }
 
+func TestConsoleLoggerSplit(t *testing.T) {
+	configStr := `
+  log {
+    pattern = "%level:-5 %message"
+    color = false
+    console {
+      split = true
+      stderr_level = "warn"
+    }
+  }
+  `
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*ConsoleReceiver)
+	assert.Equal(t, os.Stdout, receiver.Writer())
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	receiver.SetWriter(stdout)
+	receiver.SetErrorWriter(stderr)
+
+	logger.Info("Yes, I would love to see")
+	logger.Warn("Yes, yes it's an warning")
+	logger.Error("Yes, yes, yes - finally an error")
+
+	assert.Equal(t, "INFO  Yes, I would love to see \n", stdout.String())
+	assert.Equal(t, "WARN  Yes, yes it's an warning \nERROR Yes, yes, yes - finally an error \n", stderr.String())
+
+	cfg, _ = config.ParseString(`log { console { split = true, stderr_level = "critical" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unknown console stderr level 'critical'", err.Error())
+}
+
 func TestConsoleLoggerDefaults(t *testing.T) {
 	configStr := `
   log {
@@ -135,3 +169,51 @@ func testConsoleLogger(t *testing.T, cfgStr string) {
 
 	assert.NotNil(t, logger.ToGoLogger())
 }
+
+func TestConsoleLoggerColor(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %shortfile %line %message"
+    color = true
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.Error("color me")
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("\033[0;31mERROR\033[0m \033[2m")))
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte("\033[0m color me")))
+
+	// json colors the whole line
+	cfg, _ = config.ParseString(`log { format = "json", color = true }`)
+	logger, _ = New(cfg)
+	buf.Reset()
+	logger.SetWriter(buf)
+	logger.Warn("color me")
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("\033[0;33m{")))
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), resetColor))
+}
+
+func TestConsoleLoggerColorDetection(t *testing.T) {
+	cfg, _ := config.ParseString(`log { pattern = "%level:-5 %message" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	// not a terminal
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.Info("no color")
+	assert.Equal(t, "INFO  no color \n", buf.String())
+
+	f, err := ioutil.TempFile("", "aah-log-console")
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	assert.False(t, isColorTerminal(f))
+
+	_ = os.Setenv("NO_COLOR", "1")
+	defer func() { _ = os.Unsetenv("NO_COLOR") }()
+	assert.False(t, isColorTerminal(os.Stderr))
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+	"sync"
+)
+
+type ctxKey struct{}
+
+// TraceContextFunc type is used to extract the trace and span ID of active
+// span from context, it returns empty values if context has no span.
+type TraceContextFunc func(ctx context.Context) (traceID, spanID string)
+
+var (
+	traceContextFn TraceContextFunc
+	traceMu        = &sync.RWMutex{}
+)
+
+// SetTraceContextFunc method sets the func to extract the trace and span ID
+// from context. If context carries an active span, fields `trace_id` and
+// `span_id` are attached by `FromContext` and `WithContext`, also `reqid`
+// is populated with trace ID if it's empty. For e.g. with OpenTelemetry:
+//
+//	log.SetTraceContextFunc(func(ctx context.Context) (string, string) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return "", ""
+//		}
+//		return sc.TraceID().String(), sc.SpanID().String()
+//	})
+//
+// Nil func disables it.
+func SetTraceContextFunc(fn TraceContextFunc) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	traceContextFn = fn
+}
+
+// NewContext method returns the copy of given context which carries the
+// logger, so request-scoped logger flows through handler and service
+// layers. For e.g.:
+//
+//	ctx = log.NewContext(ctx, logger.WithFields(log.Fields{
+//		"reqid":     reqID,
+//		"principal": userID,
+//	}))
+//
+//	log.FromContext(ctx).Info("order placed")
+func NewContext(ctx context.Context, logger Loggerer) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext method returns the logger from given context otherwise
+// default logger, with trace fields of active span and lifted context
+// fields if any, see `AddContextField` and `SetBaggageFunc`.
+func FromContext(ctx context.Context) Loggerer {
+	var logger Loggerer = dl
+	if ctx == nil {
+		return logger
+	}
+	if l, ok := ctx.Value(ctxKey{}).(Loggerer); ok {
+		logger = l
+	}
+	fields := traceFields(ctx, logger)
+	if lifted := baggageFields(ctx, logger); len(lifted) > 0 {
+		if fields == nil {
+			fields = make(Fields)
+		}
+		fields.addAll(lifted)
+	}
+	if len(fields) > 0 {
+		return logger.WithFields(fields)
+	}
+	return logger
+}
+
+// WithContext method returns the entry which has the fields of logger,
+// trace fields of active span and lifted context fields from given context,
+// if any.
+func (l *Logger) WithContext(ctx context.Context) Loggerer {
+	e := acquireEntry(l)
+	defer releaseEntry(e)
+	return e.WithContext(ctx)
+}
+
+// WithContext method returns the entry which has the fields of entry, logger,
+// trace fields of active span and lifted context fields from given context,
+// if any.
+func (e *Entry) WithContext(ctx context.Context) Loggerer {
+	if ctx == nil {
+		return e.WithFields(nil)
+	}
+	ne := acquireEntry(e.logger)
+	ne.isChild = true
+	ne.namespace = e.namespace
+	ne.addFields(e.Fields)
+	if ce, ok := ctx.Value(ctxKey{}).(*Entry); ok {
+		ne.addFields(ce.Fields)
+	}
+	ne.addFields(traceFields(ctx, ne))
+	ne.addFields(baggageFields(ctx, ne))
+	return ne
+}
+
+// traceFields method returns the fields `trace_id`, `span_id` and `reqid`
+// if it's not set on the logger, for the active span of context.
+func traceFields(ctx context.Context, logger Loggerer) Fields {
+	traceMu.RLock()
+	fn := traceContextFn
+	traceMu.RUnlock()
+	if fn == nil {
+		return nil
+	}
+
+	traceID, spanID := fn(ctx)
+	if len(traceID) == 0 {
+		return nil
+	}
+	fields := Fields{"trace_id": traceID, "span_id": spanID}
+	if e, ok := logger.(*Entry); !ok || len(e.Fields.str("reqid")) == 0 {
+		fields["reqid"] = traceID
+	}
+	return fields
+}
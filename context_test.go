@@ -0,0 +1,86 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogContext(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %reqid %principal %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	// no logger in context
+	assert.Equal(t, dl, FromContext(context.Background()))
+	assert.Equal(t, dl, FromContext(nil))
+	logger.WithContext(context.Background()).Info("no context logger")
+	assert.Equal(t, "INFO  no context logger \n", buf.String())
+
+	ctx := NewContext(context.Background(), logger.WithFields(Fields{
+		"reqid":     "req-1",
+		"principal": "jeeva",
+	}))
+	buf.Reset()
+	FromContext(ctx).Info("order placed")
+	assert.Equal(t, "INFO  req-1 jeeva order placed \n", buf.String())
+
+	buf.Reset()
+	logger.WithContext(ctx).WithField("order", 101).Info("order shipped")
+	assert.Equal(t, "INFO  req-1 jeeva order shipped fields[order: 101] \n", buf.String())
+
+	// logger in context, it has no fields
+	ctx = NewContext(context.Background(), logger)
+	assert.Equal(t, logger, FromContext(ctx))
+	buf.Reset()
+	logger.WithContext(ctx).Info("plain entry")
+	assert.Equal(t, "INFO  plain entry \n", buf.String())
+}
+
+func TestLogContextTraceFields(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %reqid %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	type spanKey struct{}
+	SetTraceContextFunc(func(ctx context.Context) (string, string) {
+		if span, ok := ctx.Value(spanKey{}).([2]string); ok {
+			return span[0], span[1]
+		}
+		return "", ""
+	})
+	defer SetTraceContextFunc(nil)
+
+	ctx := context.WithValue(context.Background(), spanKey{}, [2]string{"4bf92f3577b34da6", "00f067aa0ba902b7"})
+	logger.WithContext(ctx).Info("traced")
+	assert.True(t, strings.HasPrefix(buf.String(), "INFO  4bf92f3577b34da6 traced fields["))
+	assert.True(t, strings.Contains(buf.String(), "trace_id: 4bf92f3577b34da6"))
+	assert.True(t, strings.Contains(buf.String(), "span_id: 00f067aa0ba902b7"))
+
+	// reqid is kept as-is
+	buf.Reset()
+	FromContext(NewContext(ctx, logger.WithField("reqid", "req-1"))).Info("traced request")
+	assert.True(t, strings.HasPrefix(buf.String(), "INFO  req-1 traced request fields["))
+	assert.True(t, strings.Contains(buf.String(), "trace_id: 4bf92f3577b34da6"))
+
+	// no active span
+	buf.Reset()
+	logger.WithContext(context.Background()).Info("not traced")
+	assert.Equal(t, "INFO  not traced \n", buf.String())
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HeaderRequestID is the default header name of the request ID.
+	HeaderRequestID = "X-Request-Id"
+
+	// HeaderTraceparent is the W3C trace context header name.
+	HeaderTraceparent = "traceparent"
+)
+
+// IDGenerator type is used to generate the request ID.
+type IDGenerator func() string
+
+var (
+	idGenerator IDGenerator = UUIDv7
+	idMu                    = &sync.RWMutex{}
+
+	crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// SetIDGenerator method sets the request ID generator, it's used by the
+// `Middleware` when request has no request ID. Default is `UUIDv7`, nil
+// resets to default. For e.g.:
+//
+//	log.SetIDGenerator(log.ULID)
+func SetIDGenerator(fn IDGenerator) {
+	if fn == nil {
+		fn = UUIDv7
+	}
+	idMu.Lock()
+	defer idMu.Unlock()
+	idGenerator = fn
+}
+
+// NewID method returns the new request ID from the ID generator.
+func NewID() string {
+	idMu.RLock()
+	fn := idGenerator
+	idMu.RUnlock()
+	return fn()
+}
+
+// UUIDv7 method returns the time-ordered UUID version 7 (RFC 9562), for
+// e.g.: `01890a5d-ac96-774b-bcce-b302099a8057`.
+func UUIDv7() string {
+	var b [16]byte
+	_, _ = rand.Read(b[6:])
+	putTimestamp(b[:6])
+	b[6] = (b[6] & 0x0f) | 0x70 // version 7
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// ULID method returns the lexicographically sortable ID in Crockford's
+// base32, for e.g.: `01H4557B4PEXNZGRY7M3K6DT0B`.
+func ULID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[6:])
+	putTimestamp(b[:6])
+
+	// 128 bits are encoded into 26 characters of 5 bits, most significant
+	// character has 3 bits
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var s [26]byte
+	for i := 25; i >= 0; i-- {
+		s[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:])
+}
+
+// RequestIDFromHeader method returns the request ID from the given header
+// name otherwise trace ID of `traceparent` header, if any.
+func RequestIDFromHeader(hdr http.Header, name string) string {
+	if reqID := strings.TrimSpace(hdr.Get(name)); len(reqID) > 0 {
+		return reqID
+	}
+	traceID, _, _ := ParseTraceparent(hdr.Get(HeaderTraceparent))
+	return traceID
+}
+
+// ParseTraceparent method returns the trace ID and parent span ID of given
+// W3C `traceparent` header value, for e.g.:
+// `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`.
+func ParseTraceparent(value string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		!isHexID(parts[1], 32) || !isHexID(parts[2], 16) {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// FormatTraceparent method returns the W3C `traceparent` header value of
+// given trace ID and span ID with sampled flag.
+func FormatTraceparent(traceID, spanID string) string {
+	return "00-" + traceID + "-" + spanID + "-01"
+}
+
+// RequestIDFromContext method returns the request ID of the logger from
+// given context, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	switch l := ctx.Value(ctxKey{}).(type) {
+	case *Entry:
+		return l.Fields.str("reqid")
+	case *Logger:
+		return l.ctx.str("reqid")
+	}
+	return ""
+}
+
+// InjectHeaders method sets the request ID and `traceparent` headers from
+// the logger of given context, so request ID propagates to the downstream
+// services. Trace ID is taken from field `trace_id` and span ID from field
+// `span_id` otherwise new span ID is generated. For e.g.:
+//
+//	req, _ := http.NewRequest(http.MethodGet, url, nil)
+//	log.InjectHeaders(r.Context(), req.Header)
+func InjectHeaders(ctx context.Context, hdr http.Header) {
+	if reqID := RequestIDFromContext(ctx); len(reqID) > 0 {
+		hdr.Set(HeaderRequestID, reqID)
+	}
+
+	e, ok := FromContext(ctx).(*Entry)
+	if !ok {
+		return
+	}
+	traceID, spanID := e.Fields.str("trace_id"), e.Fields.str("span_id")
+	if !isHexID(traceID, 32) {
+		return
+	}
+	if !isHexID(spanID, 16) {
+		spanID = randomID(8)
+	}
+	hdr.Set(HeaderTraceparent, FormatTraceparent(traceID, spanID))
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// putTimestamp method writes the current Unix time in milliseconds as
+// 48-bit big-endian.
+func putTimestamp(b []byte) {
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+}
+
+// isHexID method returns true if given value is lowercase hex of given
+// length and not all zeros.
+func isHexID(v string, n int) bool {
+	if len(v) != n {
+		return false
+	}
+	isZero := true
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+		if c != '0' {
+			isZero = false
+		}
+	}
+	return !isZero
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogIDGenerator(t *testing.T) {
+	uuidRe := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulidRe := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+
+	id1, id2 := UUIDv7(), UUIDv7()
+	assert.True(t, uuidRe.MatchString(id1))
+	assert.NotEqual(t, id1, id2)
+	assert.True(t, id1[:8] <= id2[:8])
+
+	u1, u2 := ULID(), ULID()
+	assert.True(t, ulidRe.MatchString(u1))
+	assert.NotEqual(t, u1, u2)
+	assert.True(t, u1[:10] <= u2[:10])
+
+	assert.True(t, uuidRe.MatchString(NewID()))
+	SetIDGenerator(ULID)
+	assert.True(t, ulidRe.MatchString(NewID()))
+	SetIDGenerator(func() string { return "custom" })
+	assert.Equal(t, "custom", NewID())
+	SetIDGenerator(nil)
+	assert.True(t, uuidRe.MatchString(NewID()))
+}
+
+func TestLogTraceparent(t *testing.T) {
+	traceID, spanID, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.Equal(t, "00f067aa0ba902b7", spanID)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", FormatTraceparent(traceID, spanID))
+
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01",
+	} {
+		_, _, ok = ParseTraceparent(v)
+		assert.False(t, ok)
+	}
+
+	hdr := http.Header{}
+	assert.Equal(t, "", RequestIDFromHeader(hdr, HeaderRequestID))
+	hdr.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", RequestIDFromHeader(hdr, HeaderRequestID))
+	hdr.Set(HeaderRequestID, "req-1")
+	assert.Equal(t, "req-1", RequestIDFromHeader(hdr, HeaderRequestID))
+}
+
+func TestLogInjectHeaders(t *testing.T) {
+	cfg, _ := config.ParseString(`log { pattern = "%reqid %message %fields" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	// no logger in context
+	hdr := http.Header{}
+	InjectHeaders(context.Background(), hdr)
+	assert.Equal(t, 0, len(hdr))
+	assert.Equal(t, "", RequestIDFromContext(nil))
+
+	// logger with context fields
+	ctxLogger, _ := NewWithContext(cfg, Fields{"reqid": "req-0"})
+	ctx := NewContext(context.Background(), ctxLogger)
+	assert.Equal(t, "req-0", RequestIDFromContext(ctx))
+
+	// propagated from incoming request
+	var outgoing http.Header
+	handler := logger.Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outgoing = http.Header{}
+		InjectHeaders(r.Context(), outgoing)
+		FromContext(r.Context()).Info("calling downstream")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", outgoing.Get(HeaderRequestID))
+	traceID, spanID, ok := ParseTraceparent(outgoing.Get(HeaderTraceparent))
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.NotEqual(t, "00f067aa0ba902b7", spanID)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("4bf92f3577b34da6a3ce929d0e0e4736 calling downstream")))
+}
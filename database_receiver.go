@@ -0,0 +1,230 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var _ Receiver = (*DatabaseReceiver)(nil)
+
+// DatabaseReceiver inserts the log entry into database table using
+// `database/sql`, application has to import the driver. Entries are batched
+// and inserted within transaction using prepared statement. On database
+// failure entries are written into console receiver.
+//
+// Default table columns are `level`, `time`, `message` and `fields` (JSON),
+// column names are configurable via `log.database.columns`.
+type DatabaseReceiver struct {
+	errorReporter
+	driver      string
+	dsn         string
+	table       string
+	columns     []string
+	placeholder string
+	insertSQL   string
+	db          *sql.DB
+	stmt        *sql.Stmt
+	fallback    *ConsoleReceiver
+	batcher     *batcher
+	out         io.Writer
+	mu          sync.Mutex
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// DatabaseReceiver methods
+//___________________________________
+
+// Init method initializes the database receiver instance.
+func (d *DatabaseReceiver) Init(cfg *config.Config) error {
+	return d.init(cfg, "database", cfg.StringDefault("log.database.driver", ""),
+		cfg.StringDefault("log.database.dsn", ""), false)
+}
+
+// SetPattern method initializes the logger format pattern, it's used by
+// console fallback.
+func (d *DatabaseReceiver) SetPattern(pattern string) error {
+	return d.fallback.SetPattern(pattern)
+}
+
+// SetWriter method sets the given writer into database receiver.
+func (d *DatabaseReceiver) SetWriter(w io.Writer) {
+	d.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (d *DatabaseReceiver) IsCallerInfo() bool {
+	return d.fallback.IsCallerInfo()
+}
+
+// Log method queues the log entry to be inserted into database.
+func (d *DatabaseReceiver) Log(entry *Entry) {
+	e := *entry
+	e.Fields = make(Fields, len(entry.Fields))
+	for k, v := range entry.Fields {
+		e.Fields[k] = v
+	}
+	if !d.batcher.add(&e) {
+		d.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (d *DatabaseReceiver) Writer() io.Writer {
+	return d.out
+}
+
+// Close method inserts the queued entries and closes the database.
+func (d *DatabaseReceiver) Close() {
+	d.batcher.close()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stmt != nil {
+		_ = d.stmt.Close()
+	}
+	_ = d.db.Close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// DatabaseReceiver Unexported methods
+//___________________________________
+
+func (d *DatabaseReceiver) init(cfg *config.Config, section, driver, dsn string, createTable bool) error {
+	keyPrefix := "log." + section + "."
+	d.driver, d.dsn = driver, dsn
+	if ess.IsStrEmpty(d.driver) || ess.IsStrEmpty(d.dsn) {
+		return fmt.Errorf("log: %s driver and dsn is required", section)
+	}
+
+	d.table = cfg.StringDefault(keyPrefix+"table", "aah_logs")
+	d.columns = []string{
+		cfg.StringDefault(keyPrefix+"columns.level", "level"),
+		cfg.StringDefault(keyPrefix+"columns.time", "time"),
+		cfg.StringDefault(keyPrefix+"columns.message", "message"),
+		cfg.StringDefault(keyPrefix+"columns.fields", "fields"),
+	}
+
+	defaultPlaceholder := "?"
+	if d.driver == "postgres" || d.driver == "pgx" {
+		defaultPlaceholder = "$"
+	}
+	d.placeholder = cfg.StringDefault(keyPrefix+"placeholder", defaultPlaceholder)
+	if !(d.placeholder == "?" || d.placeholder == "$") {
+		return fmt.Errorf("log: unsupported %s placeholder '%s'", section, d.placeholder)
+	}
+	d.insertSQL = d.buildInsertSQL()
+
+	var err error
+	if d.db, err = sql.Open(d.driver, d.dsn); err != nil {
+		return err
+	}
+	d.db.SetMaxOpenConns(cfg.IntDefault(keyPrefix+"max_open_conns", 2))
+
+	d.fallback = &ConsoleReceiver{}
+	if err = d.fallback.Init(cfg); err != nil {
+		return err
+	}
+
+	if cfg.BoolDefault(keyPrefix+"create_table", createTable) {
+		if _, err = d.db.Exec(d.buildCreateTableSQL()); err != nil {
+			return err
+		}
+	}
+
+	flushInterval, err := parseInterval(cfg, keyPrefix+"flush_interval", "1s")
+	if err != nil {
+		return err
+	}
+	d.batcher = newBatcher(cfg.IntDefault(keyPrefix+"batch_size", 100),
+		cfg.IntDefault(keyPrefix+"queue_size", 1000), flushInterval, d.insert)
+
+	d.SetWriter(writerFunc(func(p []byte) (int, error) {
+		d.batcher.add(&Entry{
+			Level:   LevelInfo,
+			Time:    time.Now(),
+			Message: string(bytes.TrimRight(p, "\n")),
+		})
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+func (d *DatabaseReceiver) insert(items []interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.insertTx(items); err != nil {
+		// statement is prepared again on next insert, connection might
+		// have been changed
+		if d.stmt != nil {
+			_ = d.stmt.Close()
+			d.stmt = nil
+		}
+		for _, item := range items {
+			d.reportError(err, item.(*Entry))
+			d.fallback.Log(item.(*Entry))
+		}
+	}
+}
+
+func (d *DatabaseReceiver) insertTx(items []interface{}) error {
+	if d.stmt == nil {
+		stmt, err := d.db.Prepare(d.insertSQL)
+		if err != nil {
+			return err
+		}
+		d.stmt = stmt
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt := tx.Stmt(d.stmt)
+	for _, item := range items {
+		e := item.(*Entry)
+		fields := []byte("{}")
+		if len(e.Fields) > 0 {
+			fields, _ = json.Marshal(e.Fields)
+		}
+		// time is stored in UTC, so that time range query is consistent
+		if _, err = stmt.Exec(e.Level.String(), e.Time.UTC(), e.Message, string(fields)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (d *DatabaseReceiver) buildInsertSQL() string {
+	values := make([]string, len(d.columns))
+	for i := range d.columns {
+		if d.placeholder == "$" {
+			values[i] = fmt.Sprintf("$%d", i+1)
+		} else {
+			values[i] = "?"
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", d.table,
+		strings.Join(d.columns, ", "), strings.Join(values, ", "))
+}
+
+func (d *DatabaseReceiver) buildCreateTableSQL() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s VARCHAR(8) NOT NULL, %s TIMESTAMP NOT NULL, %s TEXT, %s TEXT)",
+		d.table, d.columns[0], d.columns[1], d.columns[2], d.columns[3])
+}
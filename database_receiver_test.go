@@ -0,0 +1,205 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestDatabaseLogger(t *testing.T) {
+	testDB.reset()
+	configStr := `
+  log {
+    receiver = "database"
+    database {
+      driver = "aahlogtest"
+      dsn = "test"
+      table = "app_logs"
+      create_table = true
+      flush_interval = "1h"
+      columns {
+        time = "created_at"
+      }
+    }
+  }
+  `
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("key1", "value1").Info("Yes, I would love to see")
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.receiver.(*DatabaseReceiver).Close()
+
+	testDB.mu.Lock()
+	defer testDB.mu.Unlock()
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS app_logs (level VARCHAR(8) NOT NULL, created_at TIMESTAMP NOT NULL, message TEXT, fields TEXT)", testDB.queries[0])
+	assert.Equal(t, "INSERT INTO app_logs (level, created_at, message, fields) VALUES (?, ?, ?, ?)", testDB.queries[1])
+	assert.Equal(t, 2, len(testDB.rows))
+	assert.Equal(t, "INFO", testDB.rows[0][0])
+	assert.Equal(t, "Yes, I would love to see", testDB.rows[0][2])
+	assert.Equal(t, `{"key1":"value1"}`, testDB.rows[0][3])
+	assert.Equal(t, "{}", testDB.rows[1][3])
+	assert.Equal(t, 1, testDB.commits)
+}
+
+func TestDatabaseLoggerFallback(t *testing.T) {
+	testDB.reset()
+	testDB.failExec = true
+
+	cfg, _ := config.ParseString(`log { receiver = "database", pattern = "%level:-5 %message", color = false, database { driver = "aahlogtest", dsn = "test", placeholder = "$", flush_interval = "1h" } }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*DatabaseReceiver)
+	buf := &bytes.Buffer{}
+	receiver.fallback.SetWriter(buf)
+
+	logger.Error("Yes, yes, yes - finally an error")
+	receiver.Close()
+
+	assert.Equal(t, "ERROR Yes, yes, yes - finally an error \n", buf.String())
+	testDB.mu.Lock()
+	defer testDB.mu.Unlock()
+	assert.Equal(t, "INSERT INTO aah_logs (level, time, message, fields) VALUES ($1, $2, $3, $4)", testDB.queries[0])
+	assert.Equal(t, 1, testDB.rollbacks)
+}
+
+func TestDatabaseLoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "database" }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: database driver and dsn is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "database", database { driver = "aahlogtest", dsn = "test", placeholder = ":" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unsupported database placeholder ':'", err.Error())
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Fake database/sql driver
+//___________________________________
+
+var testDB = &testDatabase{}
+
+func init() {
+	sql.Register("aahlogtest", testDB)
+}
+
+type testDatabase struct {
+	queries   []string
+	rows      [][]interface{}
+	args      []driver.Value
+	result    [][]driver.Value
+	commits   int
+	rollbacks int
+	failExec  bool
+	mu        sync.Mutex
+}
+
+func (db *testDatabase) reset() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.queries, db.rows, db.args, db.result = nil, nil, nil, nil
+	db.commits, db.rollbacks, db.failExec = 0, 0, false
+}
+
+func (db *testDatabase) Open(name string) (driver.Conn, error) {
+	return &testConn{db: db}, nil
+}
+
+type testConn struct {
+	db *testDatabase
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if !strings.HasPrefix(query, "CREATE") {
+		c.db.queries = append(c.db.queries, query)
+	}
+	return &testStmt{db: c.db, query: query}, nil
+}
+
+func (c *testConn) Close() error { return nil }
+
+func (c *testConn) Begin() (driver.Tx, error) { return &testTx{db: c.db}, nil }
+
+type testTx struct {
+	db *testDatabase
+}
+
+func (tx *testTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+
+func (tx *testTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rollbacks++
+	return nil
+}
+
+type testStmt struct {
+	db    *testDatabase
+	query string
+}
+
+func (s *testStmt) Close() error { return nil }
+
+func (s *testStmt) NumInput() int { return -1 }
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if strings.HasPrefix(s.query, "CREATE") {
+		s.db.queries = append(s.db.queries, s.query)
+		return driver.RowsAffected(0), nil
+	}
+	if s.db.failExec {
+		return nil, errors.New("connection refused")
+	}
+	row := make([]interface{}, len(args))
+	for i, a := range args {
+		row[i] = a
+	}
+	s.db.rows = append(s.db.rows, row)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.args = args
+	return &testRows{result: s.db.result}, nil
+}
+
+type testRows struct {
+	result [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return []string{"level", "time", "message", "fields"} }
+
+func (r *testRows) Close() error { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.result) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.result[0])
+	r.result = r.result[1:]
+	return nil
+}
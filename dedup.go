@@ -0,0 +1,122 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+)
+
+// deduper collapses the identical consecutive entries, it's configured
+// under `log.dedup`. First entry is logged and its duplicates within the
+// `window` are counted, then last duplicate is logged once with field
+// `repeat_count` when the next different entry or the next entry after the
+// window comes in, or on `Logger.Flush` and `Logger.Close`. There is no
+// timer, so a trailing run of duplicates waits until then.
+//
+//	log {
+//	  dedup {
+//	    window = "10s"
+//	    # message - level and message text (default)
+//	    # message_fields - level, message text and fields
+//	    key = "message"
+//	  }
+//	}
+type deduper struct {
+	mu       sync.Mutex
+	window   time.Duration
+	isFields bool
+	last     string
+	start    time.Time
+	count    int64
+	repeated *Entry
+}
+
+func newDeduper(cfg *config.Config) (*deduper, error) {
+	window, err := parseDuration(cfg, "log.dedup.window", "10s")
+	if err != nil {
+		return nil, err
+	}
+
+	d := &deduper{window: window}
+	switch key := cfg.StringDefault("log.dedup.key", "message"); key {
+	case "message":
+	case "message_fields":
+		d.isFields = true
+	default:
+		return nil, fmt.Errorf("log: unsupported dedup key '%s'", key)
+	}
+	return d, nil
+}
+
+// check method returns true if the entry is duplicate of previous entry and
+// the repeated entry which has to be logged before the given entry.
+func (d *deduper) check(e *Entry) (bool, *Entry) {
+	key := d.key(e)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if key == d.last && e.Time.Sub(d.start) < d.window {
+		d.count++
+		d.repeated = copyEntry(e)
+		return true, nil
+	}
+
+	repeated := d.flush()
+	d.last, d.start = key, e.Time
+	return false, repeated
+}
+
+// drain method returns the repeated entry if any and resets the count.
+func (d *deduper) drain() *Entry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.flush()
+}
+
+func (d *deduper) flush() *Entry {
+	if d.count == 0 {
+		return nil
+	}
+	repeated := d.repeated
+	repeated.Fields["repeat_count"] = d.count
+	d.count, d.repeated = 0, nil
+	return repeated
+}
+
+func (d *deduper) key(e *Entry) string {
+	if !d.isFields || len(e.Fields) == 0 {
+		return e.Level.String() + " " + e.Message
+	}
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString(e.Level.String() + " " + e.Message)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, " %s=%v", k, e.Fields[k])
+	}
+	return buf.String()
+}
+
+// copyEntry method returns the copy of entry with its own fields, since
+// entry is reused after logging.
+func copyEntry(e *Entry) *Entry {
+	ce := *e
+	ce.Fields = make(Fields, len(e.Fields)+1)
+	for k, v := range e.Fields {
+		ce.Fields[k] = v
+	}
+	return &ce
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogDedup(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %fields"
+    dedup {
+      window = "1m"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.Info("connection refused")
+	logger.Info("connection refused")
+	logger.WithField("attempt", 3).Info("connection refused")
+	logger.Error("connection refused")
+	logger.Error("connection refused")
+	logger.Flush()
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, 5, len(lines))
+	assert.Equal(t, "INFO  connection refused ", lines[0])
+	assert.True(t, strings.Contains(lines[1], "attempt: 3"))
+	assert.True(t, strings.Contains(lines[1], "repeat_count: 2"))
+	assert.Equal(t, "ERROR connection refused ", lines[2])
+	assert.Equal(t, "ERROR connection refused fields[repeat_count: 1] ", lines[3])
+
+	// window elapsed
+	buf.Reset()
+	logger.Warn("disk slow")
+	logger.deduper.start = logger.deduper.start.Add(-time.Minute)
+	logger.Warn("disk slow")
+	logger.Close()
+	assert.Equal(t, "WARN  disk slow \nWARN  disk slow \n", buf.String())
+}
+
+func TestLogDedupRepeatedEntry(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %shortfile %message %fields"
+    key_map = ["repeat_count=repeats"]
+    dedup { }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	hook := &testHook{}
+	assert.Nil(t, logger.AddEntryHook(hook))
+
+	logger.Info("connection refused")
+	logger.Info("connection refused")
+	logger.Info("connected")
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, 4, len(lines))
+	// repeated entry carries the caller info of last duplicate
+	assert.True(t, strings.HasSuffix(lines[0], " connection refused fields[hooked: true] "))
+	assert.True(t, strings.HasPrefix(lines[1], strings.TrimSuffix(lines[0], "fields[hooked: true] ")+"fields["))
+	assert.False(t, strings.HasPrefix(lines[1], "INFO   connection"))
+	assert.True(t, strings.Contains(lines[1], "repeats: 1"))
+	assert.True(t, strings.Contains(lines[1], "hooked: true"))
+	assert.Equal(t, 3, len(hook.entries))
+}
+
+func TestLogDedupRedact(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %fields"
+    redact = ["password"]
+    dedup { }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.WithField("password", "secret").Info("login failed")
+	logger.WithField("password", "secret").Info("login failed")
+	logger.WithField("password", "secret").Info("login failed")
+
+	// stored repeated entry is already redacted
+	assert.Equal(t, "[REDACTED]", logger.deduper.repeated.Fields["password"])
+	logger.Close()
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Equal(t, "INFO  login failed fields[password: [REDACTED]] ", lines[0])
+	assert.True(t, strings.Contains(lines[1], "password: [REDACTED]"))
+	assert.True(t, strings.Contains(lines[1], "repeat_count: 2"))
+	assert.False(t, strings.Contains(buf.String(), "secret"))
+}
+
+func TestLogDedupFields(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %fields"
+    dedup {
+      key = "message_fields"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.WithField("user", "a").Info("login")
+	logger.WithField("user", "b").Info("login")
+	logger.WithField("user", "b").Info("login")
+	logger.Info("done")
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, 5, len(lines))
+	assert.Equal(t, "INFO  login fields[user: a] ", lines[0])
+	assert.Equal(t, "INFO  login fields[user: b] ", lines[1])
+	assert.True(t, strings.Contains(lines[2], "repeat_count: 1"))
+	assert.Equal(t, "INFO  done ", lines[3])
+
+	cfg, _ = config.ParseString(`log { dedup { key = "fields" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unsupported dedup key 'fields'", err.Error())
+
+	cfg, _ = config.ParseString(`log { dedup { window = "often" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid duration 'often' for 'log.dedup.window'", err.Error())
+}
@@ -5,8 +5,10 @@
 package log
 
 import (
+	"context"
 	"io"
 	slog "log"
+	"net/http"
 
 	"aahframework.org/config.v0"
 )
@@ -57,6 +59,11 @@ func Debugf(format string, v ...interface{}) {
 	dl.Debugf(format, v...)
 }
 
+// Debugl logs message as `DEBUG`, message func is called only if level is enabled.
+func Debugl(fn func() string) {
+	dl.Debugl(fn)
+}
+
 // Trace logs message as `TRACE`. Arguments handled in the mananer of `fmt.Print`.
 func Trace(v ...interface{}) {
 	dl.Trace(v...)
@@ -67,6 +74,48 @@ func Tracef(format string, v ...interface{}) {
 	dl.Tracef(format, v...)
 }
 
+// Tracel logs message as `TRACE`, message func is called only if level is enabled.
+func Tracel(fn func() string) {
+	dl.Tracel(fn)
+}
+
+// Errorw logs message as `ERROR` with typed fields.
+func Errorw(msg string, fields ...Field) {
+	dl.Errorw(msg, fields...)
+}
+
+// Warnw logs message as `WARN` with typed fields.
+func Warnw(msg string, fields ...Field) {
+	dl.Warnw(msg, fields...)
+}
+
+// Infow logs message as `INFO` with typed fields.
+func Infow(msg string, fields ...Field) {
+	dl.Infow(msg, fields...)
+}
+
+// Debugw logs message as `DEBUG` with typed fields.
+func Debugw(msg string, fields ...Field) {
+	dl.Debugw(msg, fields...)
+}
+
+// Tracew logs message as `TRACE` with typed fields.
+func Tracew(msg string, fields ...Field) {
+	dl.Tracew(msg, fields...)
+}
+
+// Log logs message as given level name, it's for custom levels added using
+// `AddLevel`. Arguments handled in the mananer of `fmt.Print`.
+func Log(levelName string, v ...interface{}) {
+	dl.Log(levelName, v...)
+}
+
+// Logf logs message as given level name, it's for custom levels added using
+// `AddLevel`. Arguments handled in the mananer of `fmt.Printf`.
+func Logf(levelName, format string, v ...interface{}) {
+	dl.Logf(levelName, format, v...)
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Logger methods - Drop-in replacement
 // for Go standard logger
@@ -128,6 +177,55 @@ func AddHook(name string, hook HookFunc) error {
 	return dl.AddHook(name, hook)
 }
 
+// SetErrorHandler method sets the func, which is called when receiver write
+// or hook of default logger fails.
+func SetErrorHandler(fn ErrorHandlerFunc) {
+	dl.SetErrorHandler(fn)
+}
+
+// AddEntryHook method adds the hook into default logger.
+func AddEntryHook(h Hook) error {
+	return dl.AddEntryHook(h)
+}
+
+// AddFilter method is to add logger filter function into filter chain.
+func AddFilter(name string, filter FilterFunc) error {
+	return dl.AddFilter(name, filter)
+}
+
+// RemoveFilter method removes the given filter function from filter chain.
+func RemoveFilter(name string) {
+	dl.RemoveFilter(name)
+}
+
+// ForceTrace method marks the given request ID to be logged at TRACE
+// regardless of level.
+func ForceTrace(requestID string) {
+	dl.ForceTrace(requestID)
+}
+
+// UnforceTrace method removes the given request ID marked by `ForceTrace`.
+func UnforceTrace(requestID string) {
+	dl.UnforceTrace(requestID)
+}
+
+// SetDefaultFields method sets the fields which are merged into every
+// entry of default logger.
+func SetDefaultFields(fields Fields) {
+	dl.SetDefaultFields(fields)
+}
+
+// AddContextField method adds the mapping of context value key to field
+// name for default logger.
+func AddContextField(name string, key interface{}) error {
+	return dl.AddContextField(name, key)
+}
+
+// SetFieldMerge method sets the field merge mode of default logger.
+func SetFieldMerge(mode string) error {
+	return dl.SetFieldMerge(mode)
+}
+
 // WithFields method to add multiple key-value pairs into log.
 func WithFields(fields Fields) Loggerer {
 	return dl.WithFields(fields)
@@ -138,6 +236,34 @@ func WithField(key string, value interface{}) Loggerer {
 	return dl.WithField(key, value)
 }
 
+// ForWorker method returns the child logger with field `worker` of given ID.
+func ForWorker(id interface{}) Loggerer {
+	return dl.ForWorker(id)
+}
+
+// Middleware method returns the `net/http` middleware which creates the
+// request-scoped child logger of default logger.
+func Middleware(principalFn PrincipalFunc) func(http.Handler) http.Handler {
+	return dl.Middleware(principalFn)
+}
+
+// WithError method returns the entry with field `error` of given error.
+func WithError(err error) Loggerer {
+	return dl.WithError(err)
+}
+
+// WithNamespace method returns the entry, fields added to it are grouped
+// under the namespace.
+func WithNamespace(name string) Loggerer {
+	return dl.WithNamespace(name)
+}
+
+// WithContext method returns the entry which has the fields of logger from
+// given context, if any.
+func WithContext(ctx context.Context) Loggerer {
+	return dl.WithContext(ctx)
+}
+
 // Writer method returns the writer of default logger.
 func Writer() io.Writer {
 	return dl.receiver.Writer()
@@ -148,6 +274,22 @@ func SetWriter(w io.Writer) {
 	dl.SetWriter(w)
 }
 
+// Flush method writes the buffered log entries of default logger.
+func Flush() {
+	dl.Flush()
+}
+
+// Reopen method reopens the log file of default logger receiver.
+func Reopen() error {
+	return dl.Reopen()
+}
+
+// Close method writes the buffered log entries and stops the receiver of
+// default logger.
+func Close() {
+	dl.Close()
+}
+
 // ToGoLogger method wraps the current log writer into Go Logger instance.
 func ToGoLogger() *slog.Logger {
 	return dl.ToGoLogger()
@@ -163,6 +305,22 @@ func SetLevel(level string) error {
 	return dl.SetLevel(level)
 }
 
+// SetPackageLevel method sets log level of the package path for default
+// logger.
+func SetPackageLevel(pkg, level string) error {
+	return dl.SetPackageLevel(pkg, level)
+}
+
+// SetVerbosity method sets the verbosity of `V` for default logger.
+func SetVerbosity(verbosity int) {
+	dl.SetVerbosity(verbosity)
+}
+
+// V method returns the `Verbose` of given verbosity from default logger.
+func V(verbosity int) Verbose {
+	return dl.V(verbosity)
+}
+
 // SetPattern method sets the log format pattern for default logger.
 func SetPattern(pattern string) error {
 	return dl.SetPattern(pattern)
@@ -0,0 +1,78 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var _ Receiver = (*DiscardReceiver)(nil)
+
+// DiscardReceiver drops the log entry, it can be used to disable the logging
+// for e.g.: in benchmarks. Enable `log.discard.format` to format the entry
+// before dropping it, to measure the formatting cost in isolation.
+type DiscardReceiver struct {
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isFormat     bool
+	isCallerInfo bool
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// DiscardReceiver methods
+//___________________________________
+
+// Init method initializes the discard receiver instance.
+func (d *DiscardReceiver) Init(cfg *config.Config) error {
+	d.out = ioutil.Discard
+	d.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(d.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", d.formatter)
+	}
+	d.isFormat = cfg.BoolDefault("log.discard.format", false)
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (d *DiscardReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	d.flags = flags
+	if d.isFormat && d.formatter != jsonFmt {
+		d.isCallerInfo = isCallerInfo(d.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into discard receiver.
+func (d *DiscardReceiver) SetWriter(w io.Writer) {
+	d.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// and format is enabled otherwise false.
+func (d *DiscardReceiver) IsCallerInfo() bool {
+	return d.isCallerInfo
+}
+
+// Log method drops the log entry.
+func (d *DiscardReceiver) Log(entry *Entry) {
+	if d.isFormat {
+		_ = formatEntry(d.formatter, d.flags, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (d *DiscardReceiver) Writer() io.Writer {
+	return d.out
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestDiscardLogger(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "discard", pattern = "%time %level %shortfile %line %message" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, ioutil.Discard, logger.receiver.Writer())
+	assert.False(t, logger.receiver.IsCallerInfo())
+	logger.WithField("key1", "value1").Info("Yes, I would love to see")
+
+	cfg, _ = config.ParseString(`log { receiver = "discard", pattern = "%time %level %shortfile %line %message", discard { format = true } }`)
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.True(t, logger.receiver.IsCallerInfo())
+	logger.Error("Yes, yes, yes - finally an error")
+}
+
+func BenchmarkDiscardLogger(b *testing.B) {
+	cfg, _ := config.ParseString(`log { receiver = "discard", level = "info" }`)
+	logger, _ := New(cfg)
+	child := logger.WithField("key1", "value1")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		child.Info("Yes, I would love to see")
+	}
+}
+
+func BenchmarkDiscardLoggerFormat(b *testing.B) {
+	cfg, _ := config.ParseString(`log { receiver = "discard", level = "info", discard { format = true } }`)
+	logger, _ := New(cfg)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.WithField("key1", "value1").Info("Yes, I would love to see")
+	}
+}
+
+func TestDiscardLoggerAllocs(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "discard", level = "info" }`)
+	logger, _ := New(cfg)
+
+	// pooled entry and its fields map are reused
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Info("Yes, I would love to see")
+	})
+	assert.True(t, allocs <= 2)
+
+	child := logger.WithField("key1", "value1")
+	allocs = testing.AllocsPerRun(100, func() {
+		child.Info("Yes, I would love to see")
+	})
+	assert.True(t, allocs <= 3)
+}
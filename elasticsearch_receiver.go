@@ -0,0 +1,182 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var _ Receiver = (*ElasticsearchReceiver)(nil)
+
+// ElasticsearchReceiver indexes the log entry as JSON document into
+// Elasticsearch using bulk API. Index name is time layout template
+// applied on entry time in UTC, for e.g.: `aah-logs-2006.01.02`.
+type ElasticsearchReceiver struct {
+	errorReporter
+	url          string
+	index        string
+	header       http.Header
+	sender       *httpSender
+	batcher      *batcher
+	out          io.Writer
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+}
+
+type esDocument struct {
+	index  string
+	source []byte
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// ElasticsearchReceiver methods
+//___________________________________
+
+// Init method initializes the Elasticsearch receiver instance.
+func (es *ElasticsearchReceiver) Init(cfg *config.Config) error {
+	es.url = strings.TrimRight(cfg.StringDefault("log.elasticsearch.url", "http://127.0.0.1:9200"), "/") + "/_bulk"
+	es.index = cfg.StringDefault("log.elasticsearch.index", "aah-logs-2006.01.02")
+	if ess.IsStrEmpty(es.index) {
+		return errors.New("log: elasticsearch index is required")
+	}
+
+	es.header = http.Header{}
+	es.header.Set("Content-Type", "application/x-ndjson")
+	if apiKey := cfg.StringDefault("log.elasticsearch.api_key", ""); len(apiKey) > 0 {
+		es.header.Set("Authorization", "ApiKey "+apiKey)
+	} else if username := cfg.StringDefault("log.elasticsearch.username", ""); len(username) > 0 {
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(username, cfg.StringDefault("log.elasticsearch.password", ""))
+		es.header.Set("Authorization", req.Header.Get("Authorization"))
+	}
+
+	var err error
+	if es.sender, err = newHTTPSender(cfg, "elasticsearch"); err != nil {
+		return err
+	}
+
+	flushInterval, err := parseInterval(cfg, "log.elasticsearch.flush_interval", "1s")
+	if err != nil {
+		return err
+	}
+	es.batcher = newBatcher(cfg.IntDefault("log.elasticsearch.batch_size", 500),
+		cfg.IntDefault("log.elasticsearch.queue_size", 5000), flushInterval, func(items []interface{}) {
+			if err := es.bulk(items); err != nil {
+				es.reportError(err, nil)
+			}
+		})
+
+	es.SetWriter(writerFunc(func(p []byte) (int, error) {
+		now := time.Now()
+		source, _ := json.Marshal(map[string]string{
+			"level":     LevelInfo.String(),
+			"timestamp": formatTime(now),
+			"message":   string(bytes.TrimRight(p, "\n")),
+		})
+		es.batcher.add(&esDocument{index: now.UTC().Format(es.index), source: source})
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (es *ElasticsearchReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	es.flags = flags
+	return nil
+}
+
+// SetWriter method sets the given writer into Elasticsearch receiver.
+func (es *ElasticsearchReceiver) SetWriter(w io.Writer) {
+	es.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (es *ElasticsearchReceiver) IsCallerInfo() bool {
+	return es.isCallerInfo
+}
+
+// Log method queues the log entry to be indexed, entry is always
+// formatted as JSON.
+func (es *ElasticsearchReceiver) Log(entry *Entry) {
+	if !es.batcher.add(&esDocument{
+		index:  entry.Time.UTC().Format(es.index),
+		source: bytes.TrimRight(formatEntry(jsonFmt, es.flags, entry), "\n"),
+	}) {
+		es.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (es *ElasticsearchReceiver) Writer() io.Writer {
+	return es.out
+}
+
+// Close method indexes the queued entries and stops the receiver.
+func (es *ElasticsearchReceiver) Close() {
+	es.batcher.close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// ElasticsearchReceiver Unexported methods
+//___________________________________
+
+// bulk method sends the documents to bulk API in NDJSON format.
+func (es *ElasticsearchReceiver) bulk(items []interface{}) error {
+	buf := acquireBuffer()
+	defer releaseBuffer(buf)
+	for _, item := range items {
+		doc := item.(*esDocument)
+		action, _ := json.Marshal(map[string]interface{}{"index": map[string]string{"_index": doc.index}})
+		_, _ = buf.Write(action)
+		_ = buf.WriteByte('\n')
+		_, _ = buf.Write(doc.source)
+		_ = buf.WriteByte('\n')
+	}
+
+	body, err := es.sender.send(http.MethodPost, es.url, buf.Bytes(), es.header)
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err = json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if res.Errors {
+		for _, item := range res.Items {
+			for _, r := range item {
+				if r.Status > 299 {
+					return fmt.Errorf("log: elasticsearch %s: %s", r.Error.Type, r.Error.Reason)
+				}
+			}
+		}
+	}
+	return nil
+}
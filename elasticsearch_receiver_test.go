@@ -0,0 +1,90 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestElasticsearchLogger(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		path  string
+		auth  string
+		lines []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		_, _ = w.Write([]byte(`{"took":3,"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "elasticsearch"
+    elasticsearch {
+      url = "%s/"
+      index = "app-logs-2006.01.02"
+      api_key = "a2V5OnNlY3JldA=="
+      flush_interval = "1h"
+    }
+  }
+  `, server.URL)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("key1", "value1").Info("Yes, I would love to see")
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.receiver.(*ElasticsearchReceiver).Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "/_bulk", path)
+	assert.Equal(t, "ApiKey a2V5OnNlY3JldA==", auth)
+	assert.Equal(t, 4, len(lines))
+	assert.Equal(t, fmt.Sprintf(`{"index":{"_index":"app-logs-%s"}}`, time.Now().UTC().Format("2006.01.02")), lines[0])
+
+	var doc map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &doc))
+	assert.Equal(t, "INFO", doc["level"])
+	assert.Equal(t, "Yes, I would love to see", doc["message"])
+	assert.Equal(t, map[string]interface{}{"key1": "value1"}, doc["fields"])
+	assert.True(t, strings.Contains(lines[3], `"level":"ERROR"`))
+}
+
+func TestElasticsearchLoggerBulkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":true,"items":[{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+	}))
+	defer server.Close()
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log { receiver = "elasticsearch", elasticsearch { url = "%s", username = "elastic", password = "changeme" } }`, server.URL))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*ElasticsearchReceiver)
+	assert.Equal(t, "Basic ZWxhc3RpYzpjaGFuZ2VtZQ==", receiver.header.Get("Authorization"))
+
+	err = receiver.bulk([]interface{}{&esDocument{index: "aah-logs", source: []byte(`{"message":"test"}`)}})
+	assert.Equal(t, "log: elasticsearch mapper_parsing_exception: failed to parse", err.Error())
+	receiver.Close()
+}
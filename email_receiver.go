@@ -0,0 +1,343 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const (
+	emailDefaultSubject = `[{{.Level}}] {{.Hostname}} - {{.Count}} log entries`
+	emailDefaultBody    = `{{range .Entries}}{{.Formatted}}
+{{end}}{{if .Dropped}}
+{{.Dropped}} log entries were dropped due to rate limit.
+{{end}}`
+)
+
+var _ Receiver = (*EmailReceiver)(nil)
+
+// EmailReceiver emails the log entry of configured level and above (default
+// is `PANIC`, which includes `FATAL`) via SMTP. Entries are batched and
+// emails are rate limited to one per `log.email.min_interval`, entries
+// beyond `log.email.max_entries` are dropped until next email.
+type EmailReceiver struct {
+	errorReporter
+	addr         string
+	host         string
+	implicitTLS  bool
+	auth         smtp.Auth
+	from         string
+	to           []string
+	level        level
+	subject      *template.Template
+	body         *template.Template
+	minInterval  time.Duration
+	maxEntries   int
+	hostname     string
+	lastSent     time.Time
+	pending      []*emailEntry
+	dropped      int
+	timer        *time.Timer
+	batcher      *batcher
+	out          io.Writer
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.Mutex
+}
+
+type emailEntry struct {
+	Level     string
+	Time      time.Time
+	Message   string
+	Formatted string
+	Fields    Fields
+	level     level
+}
+
+type emailData struct {
+	Level    string
+	Count    int
+	Dropped  int
+	Hostname string
+	Entries  []*emailEntry
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// EmailReceiver methods
+//___________________________________
+
+// Init method initializes the email receiver instance.
+func (s *EmailReceiver) Init(cfg *config.Config) error {
+	s.host = cfg.StringDefault("log.email.host", "")
+	if ess.IsStrEmpty(s.host) {
+		return errors.New("log: email host is required")
+	}
+	s.addr = net.JoinHostPort(s.host, strconv.Itoa(cfg.IntDefault("log.email.port", 587)))
+	s.implicitTLS = cfg.BoolDefault("log.email.tls", false)
+	if username := cfg.StringDefault("log.email.username", ""); len(username) > 0 {
+		s.auth = smtp.PlainAuth("", username, cfg.StringDefault("log.email.password", ""), s.host)
+	}
+
+	s.from = cfg.StringDefault("log.email.from", "")
+	if to, found := cfg.StringList("log.email.to"); found {
+		s.to = to
+	} else if to := cfg.StringDefault("log.email.to", ""); len(to) > 0 {
+		for _, v := range strings.Split(to, ",") {
+			s.to = append(s.to, strings.TrimSpace(v))
+		}
+	}
+	if ess.IsStrEmpty(s.from) || len(s.to) == 0 {
+		return errors.New("log: email from and to is required")
+	}
+
+	levelName := cfg.StringDefault("log.email.level", "panic")
+	if s.level = levelByName(levelName); s.level == LevelUnknown {
+		return fmt.Errorf("log: unknown email level '%s'", levelName)
+	}
+
+	var err error
+	if s.subject, err = template.New("subject").Parse(cfg.StringDefault("log.email.subject", emailDefaultSubject)); err != nil {
+		return fmt.Errorf("log: email subject template: %s", err)
+	}
+	if s.body, err = template.New("body").Parse(cfg.StringDefault("log.email.body", emailDefaultBody)); err != nil {
+		return fmt.Errorf("log: email body template: %s", err)
+	}
+
+	if s.minInterval, err = parseDuration(cfg, "log.email.min_interval", "5m"); err != nil {
+		return err
+	}
+	s.maxEntries = cfg.IntDefault("log.email.max_entries", 100)
+	s.hostname, _ = os.Hostname()
+
+	flushInterval, err := parseInterval(cfg, "log.email.flush_interval", "30s")
+	if err != nil {
+		return err
+	}
+	s.batcher = newBatcher(s.maxEntries, s.maxEntries, flushInterval, s.flush)
+
+	// standard logger writes are not emailed
+	s.SetWriter(ioutil.Discard)
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (s *EmailReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	s.flags = flags
+	s.isCallerInfo = isCallerInfo(s.flags)
+	return nil
+}
+
+// SetWriter method sets the given writer into email receiver.
+func (s *EmailReceiver) SetWriter(w io.Writer) {
+	s.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (s *EmailReceiver) IsCallerInfo() bool {
+	return s.isCallerInfo
+}
+
+// Log method queues the log entry to be emailed if entry level is configured
+// level or above.
+func (s *EmailReceiver) Log(entry *Entry) {
+	if entry.Level > s.level {
+		return
+	}
+
+	fields := make(Fields, len(entry.Fields))
+	for k, v := range entry.Fields {
+		fields[k] = v
+	}
+	e := &emailEntry{
+		Level:     entry.Level.String(),
+		Time:      entry.Time,
+		Message:   entry.Message,
+		Formatted: string(bytes.TrimRight(textFormatter(s.flags, entry), " \n")),
+		Fields:    fields,
+		level:     entry.Level,
+	}
+
+	// fatal exits and panic unwinds the process, so it's emailed right
+	// away along with pending entries regardless of rate limit
+	if entry.Level == LevelFatal || entry.Level == LevelPanic {
+		s.mu.Lock()
+		s.pending = append(s.pending, e)
+		err := s.send()
+		s.mu.Unlock()
+		if err != nil {
+			s.reportError(err, entry)
+		}
+		return
+	}
+
+	if !s.batcher.add(e) {
+		s.mu.Lock()
+		s.dropped++
+		s.mu.Unlock()
+		s.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (s *EmailReceiver) Writer() io.Writer {
+	return s.out
+}
+
+// Close method emails the pending entries regardless of rate limit and
+// stops the receiver.
+func (s *EmailReceiver) Close() {
+	s.batcher.close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if len(s.pending) > 0 {
+		if err := s.send(); err != nil {
+			s.reportError(err, nil)
+		}
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// EmailReceiver Unexported methods
+//___________________________________
+
+func (s *EmailReceiver) flush(items []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keep(items)
+
+	wait := s.minInterval - time.Since(s.lastSent)
+	if wait <= 0 {
+		if err := s.send(); err != nil {
+			s.reportError(err, nil)
+		}
+		return
+	}
+
+	// rate limited, email is scheduled after min interval
+	if s.timer == nil {
+		s.timer = time.AfterFunc(wait, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.timer = nil
+			if len(s.pending) > 0 {
+				if err := s.send(); err != nil {
+					s.reportError(err, nil)
+				}
+			}
+		})
+	}
+}
+
+func (s *EmailReceiver) keep(items []interface{}) {
+	for _, item := range items {
+		if len(s.pending) >= s.maxEntries {
+			s.dropped++
+			continue
+		}
+		s.pending = append(s.pending, item.(*emailEntry))
+	}
+}
+
+func (s *EmailReceiver) send() error {
+	// subject level is the highest severity of the entries
+	lvl := s.pending[0].level
+	for _, e := range s.pending {
+		if e.level < lvl {
+			lvl = e.level
+		}
+	}
+	data := &emailData{
+		Level:    lvl.String(),
+		Count:    len(s.pending),
+		Dropped:  s.dropped,
+		Hostname: s.hostname,
+		Entries:  s.pending,
+	}
+	s.pending, s.dropped, s.lastSent = nil, 0, time.Now()
+
+	subject, body := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := s.subject.Execute(subject, data); err != nil {
+		return err
+	}
+	if err := s.body.Execute(body, data); err != nil {
+		return err
+	}
+
+	msg := &bytes.Buffer{}
+	_, _ = fmt.Fprintf(msg, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n", s.from,
+		strings.Join(s.to, ", "), mime.QEncoding.Encode("utf-8", subject.String()), time.Now().Format(time.RFC1123Z))
+	_, _ = msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	_, _ = msg.WriteString(strings.Replace(body.String(), "\n", "\r\n", -1))
+
+	if !s.implicitTLS {
+		return smtp.SendMail(s.addr, s.auth, s.from, s.to, msg.Bytes())
+	}
+	return s.sendTLS(msg.Bytes())
+}
+
+// sendTLS method sends the email over implicit TLS connection, usually
+// port 465.
+func (s *EmailReceiver) sendTLS(msg []byte) error {
+	conn, err := tls.Dial("tcp", s.addr, &tls.Config{ServerName: s.host})
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if s.auth != nil {
+		if err = c.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+	if err = c.Mail(s.from); err != nil {
+		return err
+	}
+	for _, to := range s.to {
+		if err = c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
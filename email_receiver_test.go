@@ -0,0 +1,186 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestEmailLogger(t *testing.T) {
+	server := newTestSMTPServer(t)
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(server.addr)
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "email"
+    pattern = "%%level:-5 %%message"
+    email {
+      host = "%s"
+      port = %s
+      from = "alerts@example.com"
+      to = "ops@example.com, dev@example.com"
+      level = "error"
+      flush_interval = "20ms"
+      min_interval = "1h"
+      max_entries = 2
+    }
+  }
+  `, host, port)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*EmailReceiver)
+
+	logger.Warn("Yes, yes it's an warning")
+	logger.Error("Yes, yes, yes - finally an error")
+	time.Sleep(100 * time.Millisecond)
+
+	// rate limited, kept till min interval or close
+	logger.Error("Second error")
+	logger.Error("Third error")
+	logger.Error("Fourth error, dropped")
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, len(server.Mails()))
+
+	receiver.Close()
+	mails := server.Mails()
+	assert.Equal(t, 2, len(mails))
+
+	assert.Equal(t, "alerts@example.com", mails[0].from)
+	assert.Equal(t, []string{"ops@example.com", "dev@example.com"}, mails[0].to)
+	assert.True(t, strings.Contains(mails[0].data, "Subject: [ERROR] "))
+	assert.True(t, strings.Contains(mails[0].data, " - 1 log entries\r\n"))
+	assert.True(t, strings.Contains(mails[0].data, "\r\n\r\nERROR Yes, yes, yes - finally an error\r\n"))
+	assert.False(t, strings.Contains(mails[0].data, "warning"))
+
+	assert.True(t, strings.Contains(mails[1].data, " - 2 log entries\r\n"))
+	assert.True(t, strings.Contains(mails[1].data, "ERROR Second error\r\nERROR Third error\r\n"))
+	assert.True(t, strings.Contains(mails[1].data, "1 log entries were dropped due to rate limit."))
+}
+
+func TestEmailLoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "email" }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: email host is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "email", email { host = "localhost" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: email from and to is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "email", email { host = "localhost", from = "a@b.c", to = "d@e.f", level = "critical" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unknown email level 'critical'", err.Error())
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Fake SMTP server
+//___________________________________
+
+type testMail struct {
+	from string
+	to   []string
+	data string
+}
+
+type testSMTPServer struct {
+	addr  string
+	ln    net.Listener
+	mails []testMail
+	mu    sync.Mutex
+	wg    sync.WaitGroup
+}
+
+func newTestSMTPServer(t *testing.T) *testSMTPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen")
+	s := &testSMTPServer{addr: ln.Addr().String(), ln: ln}
+	s.wg.Add(1)
+	go s.accept()
+	return s
+}
+
+func (s *testSMTPServer) Mails() []testMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]testMail{}, s.mails...)
+}
+
+func (s *testSMTPServer) Close() {
+	_ = s.ln.Close()
+	s.wg.Wait()
+}
+
+func (s *testSMTPServer) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+func (s *testSMTPServer) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	mail := testMail{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			mail.from = strings.Trim(strings.TrimSpace(line)[10:], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			mail.to = append(mail.to, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data []string
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data = append(data, l)
+			}
+			mail.data = strings.Join(data, "")
+			s.mu.Lock()
+			s.mails = append(s.mails, mail)
+			s.mu.Unlock()
+			mail = testMail{}
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
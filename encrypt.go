@@ -0,0 +1,176 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+// Encrypted log file is sequence of chunks, one per log entry, so file can
+// be read while it's being written.
+//
+//	[4 bytes big endian length][12 bytes nonce][ciphertext with 16 bytes tag]
+//
+// Length covers the nonce and ciphertext.
+const (
+	chunkHeaderSize = 4
+	maxChunkSize    = 64 << 20
+)
+
+// chunkCipher seals the log entry into chunk using AES-GCM with random nonce.
+type chunkCipher struct {
+	aead cipher.AEAD
+}
+
+func newChunkCipher(key []byte) (*chunkCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("log: invalid encrypt key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkCipher{aead: aead}, nil
+}
+
+// seal method returns the encrypted chunk of given bytes.
+func (c *chunkCipher) seal(p []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	chunk := make([]byte, chunkHeaderSize+nonceSize, chunkHeaderSize+nonceSize+len(p)+c.aead.Overhead())
+	nonce := chunk[chunkHeaderSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	chunk = c.aead.Seal(chunk, nonce, p, nil)
+	binary.BigEndian.PutUint32(chunk, uint32(len(chunk)-chunkHeaderSize))
+	return chunk, nil
+}
+
+// NewDecryptReader method returns the reader which decrypts the log file
+// written by file receiver with `log.encrypt` config. Key is the raw AES
+// key bytes.
+//
+//	f, _ := os.Open("logs/app.log")
+//	r, err := log.NewDecryptReader(f, key)
+//	if err != nil {
+//		return err
+//	}
+//	_, err = io.Copy(os.Stdout, r)
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	c, err := newChunkCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: bufio.NewReader(r), cipher: c}, nil
+}
+
+// decryptReader reads the chunks and returns the decrypted log entries.
+type decryptReader struct {
+	r      *bufio.Reader
+	cipher *chunkCipher
+	buf    []byte
+}
+
+// Read method implements `io.Reader`.
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		header := make([]byte, chunkHeaderSize)
+		if _, err := io.ReadFull(d.r, header); err != nil {
+			return 0, err
+		}
+		size := binary.BigEndian.Uint32(header)
+		if size < uint32(d.cipher.aead.NonceSize()) || size > maxChunkSize {
+			return 0, fmt.Errorf("log: invalid encrypted chunk size %d", size)
+		}
+
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(d.r, chunk); err != nil {
+			return 0, err
+		}
+		nonceSize := d.cipher.aead.NonceSize()
+		b, err := d.cipher.aead.Open(chunk[nonceSize:nonceSize], chunk[:nonceSize], chunk[nonceSize:], nil)
+		if err != nil {
+			return 0, fmt.Errorf("log: unable to decrypt chunk: %v", err)
+		}
+		d.buf = b
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// encryptKey method resolves the encryption key from config, in the order of
+// `key_provider`, `key_env` and `key`. Key in env and config is hex encoded.
+func encryptKey(cfg *config.Config) ([]byte, bool, error) {
+	if name := cfg.StringDefault("log.encrypt.key_provider", ""); len(name) > 0 {
+		fn := getKeyProviderByName(name)
+		if fn == nil {
+			return nil, true, fmt.Errorf("log: unknown encrypt key_provider '%s'", name)
+		}
+		key, err := fn()
+		return key, true, err
+	}
+
+	var value string
+	if env := cfg.StringDefault("log.encrypt.key_env", ""); len(env) > 0 {
+		if value = os.Getenv(env); ess.IsStrEmpty(value) {
+			return nil, true, fmt.Errorf("log: encrypt key env '%s' is empty", env)
+		}
+	} else if value = cfg.StringDefault("log.encrypt.key", ""); len(value) == 0 {
+		return nil, false, nil
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, true, fmt.Errorf("log: invalid encrypt key: %v", err)
+	}
+	return key, true, nil
+}
+
+// repairLastChunk method truncates the partially written last chunk of the
+// encrypted file and returns the count of chunks.
+func repairLastChunk(name string) (int64, error) {
+	file, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer ess.CloseQuietly(file)
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	var pos, count int64
+	header := make([]byte, chunkHeaderSize)
+	for size := info.Size(); pos < size; count++ {
+		if pos+chunkHeaderSize > size {
+			return count, file.Truncate(pos)
+		}
+		if _, err = file.ReadAt(header, pos); err != nil {
+			return count, err
+		}
+		next := pos + chunkHeaderSize + int64(binary.BigEndian.Uint32(header))
+		if next > size {
+			return count, file.Truncate(pos)
+		}
+		pos = next
+	}
+	return count, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestFileLoggerEncrypt(t *testing.T) {
+	defer cleaupFiles("encrypt-aah*")
+	key := bytes.Repeat([]byte{7}, 32)
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level:-5 %message"
+    file = "encrypt-aah.log"
+    encrypt {
+      key = "` + hex.EncodeToString(key) + `"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.Info("card 4111-1111-1111-1111")
+	logger.Warn("second entry")
+	logger.Close()
+
+	b, _ := ioutil.ReadFile("encrypt-aah.log")
+	assert.False(t, bytes.Contains(b, []byte("4111")))
+
+	// torn last chunk is truncated on open, entries are appended
+	assert.Nil(t, ioutil.WriteFile("encrypt-aah.log", append(b, 0, 0, 1), 0644))
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, int64(2), logger.receiver.(*FileReceiver).stats.lines)
+	logger.Error("third entry")
+	logger.Close()
+
+	f, err := os.Open("encrypt-aah.log")
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer func() { _ = f.Close() }()
+	r, err := NewDecryptReader(f, key)
+	assert.FailNowOnError(t, err, "unexpected error")
+	b, err = ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "INFO  card 4111-1111-1111-1111 \nWARN  second entry \nERROR third entry \n", string(b))
+
+	// wrong key
+	_, _ = f.Seek(0, 0)
+	r, _ = NewDecryptReader(f, bytes.Repeat([]byte{8}, 32))
+	_, err = ioutil.ReadAll(r)
+	assert.Equal(t, "log: unable to decrypt chunk: cipher: message authentication failed", err.Error())
+}
+
+func TestFileLoggerEncryptKey(t *testing.T) {
+	defer cleaupFiles("encrypt-key-aah*")
+	key := bytes.Repeat([]byte{9}, 16)
+	assert.Nil(t, AddKeyProvider("encrypt-test", func() ([]byte, error) { return key, nil }))
+
+	cfg, _ := config.ParseString(`log { receiver = "file", file = "encrypt-key-aah.log", encrypt { key_provider = "encrypt-test" } }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.NotNil(t, logger.receiver.(*FileReceiver).cipher)
+	logger.Close()
+
+	_ = os.Setenv("AAH_LOG_TEST_KEY", hex.EncodeToString(key))
+	defer func() { _ = os.Unsetenv("AAH_LOG_TEST_KEY") }()
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "encrypt-key-aah.log", encrypt { key_env = "AAH_LOG_TEST_KEY" } }`)
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.NotNil(t, logger.receiver.(*FileReceiver).cipher)
+	logger.Close()
+
+	for cfgStr, msg := range map[string]string{
+		`encrypt { key_provider = "vault" }`:   "log: unknown encrypt key_provider 'vault'",
+		`encrypt { key_env = "AAH_LOG_NONE" }`: "log: encrypt key env 'AAH_LOG_NONE' is empty",
+		`encrypt { key = "zz" }`:               "log: invalid encrypt key: encoding/hex: invalid byte: U+007A 'z'",
+		`encrypt { key = "0102" }`:             "log: invalid encrypt key: crypto/aes: invalid key size 2",
+	} {
+		cfg, _ = config.ParseString(`log { receiver = "file", file = "encrypt-key-aah.log", ` + cfgStr + ` }`)
+		_, err = New(cfg)
+		assert.Equal(t, msg, err.Error())
+	}
+}
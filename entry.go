@@ -14,6 +14,10 @@ import (
 	"time"
 )
 
+// maxPooledFields is the count of fields up to which the fields map of
+// pooled entry is reused, larger map is released to GC.
+const maxPooledFields = 64
+
 var (
 	entryPool *sync.Pool
 	bufPool   *sync.Pool
@@ -36,7 +40,12 @@ type Entry struct {
 	File         string    `json:"file,omitempty"`
 	Fields       Fields    `json:"fields,omitempty"`
 	Time         time.Time `json:"-"`
+	GoroutineID  uint64    `json:"-"`
+	Env          string    `json:"-"`
 	logger       *Logger
+	isForced     bool
+	isChild      bool
+	namespace    string
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -46,6 +55,7 @@ type Entry struct {
 // MarshalJSON method for formating entry to JSON.
 func (e *Entry) MarshalJSON() ([]byte, error) {
 	type alias Entry
+	ce := *e
 	ne := struct {
 		Level string `json:"level,omitempty"`
 		Time  string `json:"timestamp,omitempty"`
@@ -53,12 +63,16 @@ func (e *Entry) MarshalJSON() ([]byte, error) {
 	}{
 		Level: e.Level.String(),
 		Time:  formatTime(e.Time),
-		alias: (*alias)(e),
+		alias: (*alias)(&ce),
 	}
 
-	// delete skip fields
+	// skip fields are excluded on a copy, entry is shared across receivers
+	ce.Fields = make(Fields, len(e.Fields))
+	for k, v := range e.Fields {
+		ce.Fields[k] = v
+	}
 	for _, v := range strings.Fields("appname insname reqid principal") {
-		delete(ne.Fields, v)
+		delete(ce.Fields, v)
 	}
 
 	return json.Marshal(ne)
@@ -70,74 +84,104 @@ func (e *Entry) MarshalJSON() ([]byte, error) {
 
 // Error logs message as `ERROR`. Arguments handled in the mananer of `fmt.Print`.
 func (e *Entry) Error(v ...interface{}) {
-	if e.logger.level >= LevelError {
+	if e.isEnabled(LevelError) {
 		e.output(LevelError, fmt.Sprint(v...))
 	}
 }
 
 // Errorf logs message as `ERROR`. Arguments handled in the mananer of `fmt.Printf`.
 func (e *Entry) Errorf(format string, v ...interface{}) {
-	if e.logger.level >= LevelError {
+	if e.isEnabled(LevelError) {
 		e.output(LevelError, fmt.Sprintf(format, v...))
 	}
 }
 
 // Warn logs message as `WARN`. Arguments handled in the mananer of `fmt.Print`.
 func (e *Entry) Warn(v ...interface{}) {
-	if e.logger.level >= LevelWarn {
+	if e.isEnabled(LevelWarn) {
 		e.output(LevelWarn, fmt.Sprint(v...))
 	}
 }
 
 // Warnf logs message as `WARN`. Arguments handled in the mananer of `fmt.Printf`.
 func (e *Entry) Warnf(format string, v ...interface{}) {
-	if e.logger.level >= LevelWarn {
+	if e.isEnabled(LevelWarn) {
 		e.output(LevelWarn, fmt.Sprintf(format, v...))
 	}
 }
 
 // Info logs message as `INFO`. Arguments handled in the mananer of `fmt.Print`.
 func (e *Entry) Info(v ...interface{}) {
-	if e.logger.level >= LevelInfo {
+	if e.isEnabled(LevelInfo) {
 		e.output(LevelInfo, fmt.Sprint(v...))
 	}
 }
 
 // Infof logs message as `INFO`. Arguments handled in the mananer of `fmt.Printf`.
 func (e *Entry) Infof(format string, v ...interface{}) {
-	if e.logger.level >= LevelInfo {
+	if e.isEnabled(LevelInfo) {
 		e.output(LevelInfo, fmt.Sprintf(format, v...))
 	}
 }
 
 // Debug logs message as `DEBUG`. Arguments handled in the mananer of `fmt.Print`.
 func (e *Entry) Debug(v ...interface{}) {
-	if e.logger.level >= LevelDebug {
+	if e.isEnabled(LevelDebug) {
 		e.output(LevelDebug, fmt.Sprint(v...))
 	}
 }
 
 // Debugf logs message as `DEBUG`. Arguments handled in the mananer of `fmt.Printf`.
 func (e *Entry) Debugf(format string, v ...interface{}) {
-	if e.logger.level >= LevelDebug {
+	if e.isEnabled(LevelDebug) {
 		e.output(LevelDebug, fmt.Sprintf(format, v...))
 	}
 }
 
+// Debugl logs message as `DEBUG`, message func is called only if level is enabled.
+func (e *Entry) Debugl(fn func() string) {
+	if e.isEnabled(LevelDebug) {
+		e.output(LevelDebug, fn())
+	}
+}
+
 // Trace logs message as `TRACE`. Arguments handled in the mananer of `fmt.Print`.
 func (e *Entry) Trace(v ...interface{}) {
-	if e.logger.level >= LevelTrace {
+	if e.isEnabled(LevelTrace) {
 		e.output(LevelTrace, fmt.Sprint(v...))
 	}
 }
 
 // Tracef logs message as `TRACE`. Arguments handled in the mananer of `fmt.Printf`.
 func (e *Entry) Tracef(format string, v ...interface{}) {
-	if e.logger.level >= LevelTrace {
+	if e.isEnabled(LevelTrace) {
 		e.output(LevelTrace, fmt.Sprintf(format, v...))
 	}
 }
 
+// Tracel logs message as `TRACE`, message func is called only if level is enabled.
+func (e *Entry) Tracel(fn func() string) {
+	if e.isEnabled(LevelTrace) {
+		e.output(LevelTrace, fn())
+	}
+}
+
+// Log logs message as given level name, it's for custom levels added using
+// `AddLevel`. Arguments handled in the mananer of `fmt.Print`.
+func (e *Entry) Log(levelName string, v ...interface{}) {
+	if lvl := levelByName(levelName); lvl != LevelUnknown && e.isEnabled(lvl) {
+		e.output(lvl, fmt.Sprint(v...))
+	}
+}
+
+// Logf logs message as given level name, it's for custom levels added using
+// `AddLevel`. Arguments handled in the mananer of `fmt.Printf`.
+func (e *Entry) Logf(levelName, format string, v ...interface{}) {
+	if lvl := levelByName(levelName); lvl != LevelUnknown && e.isEnabled(lvl) {
+		e.output(lvl, fmt.Sprintf(format, v...))
+	}
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Entry methods - Drop-in replacement
 // for Go standard logger
@@ -242,11 +286,43 @@ func (e *Entry) ToGoLogger() *slog.Logger {
 // Entry context/field methods
 //_______________________________________
 
-// WithFields method to add multiple key-value pairs into log.
+// WithFields method to add multiple key-value pairs into log. It returns
+// the child entry which is immutable, so it can be used as per-request
+// logger across goroutines. Child shares the fields of parent if nothing is
+// added, otherwise fields are copied.
 func (e *Entry) WithFields(fields Fields) Loggerer {
 	ne := acquireEntry(e.logger)
+	ne.isChild = true
+	ne.namespace = e.namespace
+	if len(fields) == 0 && e.isChild {
+		ne.Fields = e.Fields
+		return ne
+	}
 	ne.addFields(e.Fields)
-	ne.addFields(fields)
+	ne.namespaceFields().addAll(fields)
+	return ne
+}
+
+// WithNamespace method returns the entry, fields added to it are grouped
+// under the namespace. Namespaces can be nested. Fields are rendered as
+// nested object in JSON and `<namespace>.<key>` in text formats. For e.g.:
+//
+//	logger.WithNamespace("db").WithField("query", q).Debug("executed")
+func (e *Entry) WithNamespace(name string) Loggerer {
+	ne := acquireEntry(e.logger)
+	ne.isChild = true
+	if e.isChild {
+		ne.Fields = e.Fields
+	} else {
+		ne.addFields(e.Fields)
+	}
+	ne.namespace = e.namespace
+	if len(name) > 0 {
+		if len(ne.namespace) > 0 {
+			ne.namespace += "."
+		}
+		ne.namespace += name
+	}
 	return ne
 }
 
@@ -255,7 +331,8 @@ func (e *Entry) WithField(key string, value interface{}) Loggerer {
 	return e.WithFields(Fields{key: value})
 }
 
-// Reset method resets the `Entry` values for reuse.
+// Reset method resets the `Entry` values for reuse. Fields map is cleared
+// for reuse, except the child entry which shares it.
 func (e *Entry) Reset() {
 	e.AppName = ""
 	e.RequestID = ""
@@ -265,8 +342,19 @@ func (e *Entry) Reset() {
 	e.Message = ""
 	e.File = ""
 	e.Line = 0
-	e.Fields = make(Fields)
+	e.GoroutineID = 0
+	e.Env = ""
+	if e.isChild || len(e.Fields) > maxPooledFields {
+		e.Fields = make(Fields)
+	} else {
+		for k := range e.Fields {
+			delete(e.Fields, k)
+		}
+	}
 	e.logger = nil
+	e.isForced = false
+	e.isChild = false
+	e.namespace = ""
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -277,16 +365,64 @@ func (f Fields) str(key string) string {
 	if v, found := f[key]; found {
 		return fmt.Sprint(v)
 	}
+	if idx := strings.IndexByte(key, '.'); idx > 0 {
+		if nf, ok := f[key[:idx]].(Fields); ok {
+			return nf.str(key[idx+1:])
+		}
+	}
 	return ""
 }
 
+func (f Fields) addAll(fields Fields) {
+	for k, v := range fields {
+		f[k] = v
+	}
+}
+
+// flatten method returns the fields with nested fields as dotted keys,
+// for e.g.: `db.query`. Same fields is returned if nothing is nested.
+func (f Fields) flatten() Fields {
+	isNested := false
+	for _, v := range f {
+		if _, ok := v.(Fields); ok {
+			isNested = true
+			break
+		}
+	}
+	if !isNested {
+		return f
+	}
+
+	flat := make(Fields, len(f))
+	for k, v := range f {
+		if nf, ok := v.(Fields); ok {
+			for nk, nv := range nf.flatten() {
+				flat[k+"."+nk] = nv
+			}
+			continue
+		}
+		flat[k] = v
+	}
+	return flat
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Unexported methods
 //___________________________________
 
 func (e *Entry) output(lvl level, msg string) {
+	if e.isChild {
+		// child entry is immutable, it's logged via pooled copy
+		ne := acquireEntry(e.logger)
+		ne.addFields(e.Fields)
+		ne.output(lvl, msg)
+		releaseEntry(ne)
+		return
+	}
+
 	e.Time = time.Now()
 	e.Level = lvl
+	e.isForced = lvl <= LevelTrace && e.logger.isForced(e)
 	e.Message = msg
 	e.processFields()
 	e.logger.output(e)
@@ -298,12 +434,34 @@ func (e *Entry) addFields(fields Fields) {
 	}
 }
 
+// namespaceFields method returns the fields of entry namespace, nested
+// fields are copied on the way, since those are shared with parent entry.
+func (e *Entry) namespaceFields() Fields {
+	fields := e.Fields
+	if len(e.namespace) == 0 {
+		return fields
+	}
+	for _, name := range strings.Split(e.namespace, ".") {
+		nf := make(Fields)
+		if existing, ok := fields[name].(Fields); ok {
+			nf.addAll(existing)
+		}
+		fields[name] = nf
+		fields = nf
+	}
+	return fields
+}
+
 func (e *Entry) processFields() {
-	e.addFields(e.logger.ctx)
+	e.logger.m.RLock()
+	defaults, fm := e.logger.defaults, e.logger.fieldMerge
+	e.logger.m.RUnlock()
+	e.mergeFields(fm, defaults, e.logger.ctx)
 	e.AppName = e.Fields.str("appname")
 	e.InstanceName = e.Fields.str("insname")
 	e.RequestID = e.Fields.str("reqid")
 	e.Principal = e.Fields.str("principal")
+	e.Env = e.logger.env
 }
 
 func (e *Entry) isSkipField(key string) bool {
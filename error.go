@@ -0,0 +1,81 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// maxStackDepth is the maximum count of frames captured for error stack.
+const maxStackDepth = 32
+
+// ErrorInfo is the value of entry field `error` added by `WithError`. It has
+// the error message, messages of the unwrap chain and stack trace of the
+// `WithError` call if `log.error_stack = true`. Text format renders the
+// chain after message and stack in the following lines, JSON format renders
+// it as object.
+type ErrorInfo struct {
+	Message string   `json:"message"`
+	Chain   []string `json:"chain,omitempty"`
+	Stack   string   `json:"stack,omitempty"`
+}
+
+// String method implements `fmt.Stringer`.
+func (ei *ErrorInfo) String() string {
+	if len(ei.Chain) == 0 {
+		return ei.Message
+	}
+	return ei.Message + " (caused by: " + strings.Join(ei.Chain, " <- ") + ")"
+}
+
+// WithError method returns the entry with field `error` of given error.
+// For e.g.:
+//
+//	logger.WithError(err).Error("unable to place order")
+func (l *Logger) WithError(err error) Loggerer {
+	e := acquireEntry(l)
+	defer releaseEntry(e)
+	return e.WithError(err)
+}
+
+// WithError method returns the entry with field `error` of given error.
+func (e *Entry) WithError(err error) Loggerer {
+	if err == nil {
+		return e.WithFields(nil)
+	}
+
+	ei := &ErrorInfo{Message: err.Error()}
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		ei.Chain = append(ei.Chain, cause.Error())
+	}
+	if e.logger.isErrorStack {
+		ei.Stack = callerStack()
+	}
+	return e.WithField("error", ei)
+}
+
+// callerStack method returns the stack trace outside of log pkg, each frame
+// is written as function name and tab indented file:line.
+func callerStack() string {
+	pc := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	buf := new(bytes.Buffer)
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.File, "aahframework.org/log") {
+			_, _ = fmt.Fprintf(buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
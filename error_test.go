@@ -0,0 +1,68 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogWithError(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	cause := errors.New("connection refused")
+	err = fmt.Errorf("query failed: %w", fmt.Errorf("dial db: %w", cause))
+	logger.WithError(err).Error("unable to place order")
+	assert.Equal(t, "ERROR unable to place order fields[error: query failed: dial db: connection refused "+
+		"(caused by: dial db: connection refused <- connection refused)] \n", buf.String())
+
+	buf.Reset()
+	logger.WithError(cause).Error("plain error")
+	logger.WithError(nil).Warn("no error")
+	assert.Equal(t, "ERROR plain error fields[error: connection refused] \nWARN  no error \n", buf.String())
+
+	// json with stack trace
+	cfg, _ = config.ParseString(`log {
+    format = "json"
+    error_stack = true
+  }`)
+	logger, _ = New(cfg)
+	buf.Reset()
+	logger.SetWriter(buf)
+	logger.WithError(err).Error("unable to place order")
+	assert.True(t, strings.Contains(buf.String(), `"error":{"message":"query failed: dial db: connection refused",`+
+		`"chain":["dial db: connection refused","connection refused"],"stack":"testing.tRunner\n\t`))
+}
+
+func TestLogWithErrorStackText(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %fields"
+    error_stack = true
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.WithError(errors.New("boom")).Error("failed")
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "ERROR failed fields[error: boom] ", lines[0])
+	assert.Equal(t, "\ttesting.tRunner", lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "\t\t"))
+	assert.True(t, strings.Contains(lines[2], "testing.go:"))
+	assert.False(t, strings.HasSuffix(buf.String(), "\n\n"))
+}
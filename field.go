@@ -0,0 +1,390 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"aahframework.org/config.v0"
+)
+
+type fieldType uint8
+
+const (
+	anyField fieldType = iota
+	stringField
+	int64Field
+	boolField
+	float64Field
+	durationField
+)
+
+// Field is the strongly-typed key-value of log entry, it's created by
+// `String`, `Int64`, `Duration`, `Err`, etc. and logged with `Infow`,
+// `Debugw`, etc. Values other than `Any` are not boxed until the level is
+// enabled. For e.g.:
+//
+//	logger.Infow("request completed",
+//		log.String("path", r.URL.Path),
+//		log.Int("status", 200),
+//		log.Duration("elapsed", time.Since(start)),
+//	)
+type Field struct {
+	Key   string
+	typ   fieldType
+	str   string
+	num   int64
+	value interface{}
+}
+
+// String method returns the field of string value.
+func String(key, value string) Field {
+	return Field{Key: key, typ: stringField, str: value}
+}
+
+// Int method returns the field of int value.
+func Int(key string, value int) Field {
+	return Field{Key: key, typ: int64Field, num: int64(value)}
+}
+
+// Int64 method returns the field of int64 value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, typ: int64Field, num: value}
+}
+
+// Bool method returns the field of bool value.
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, typ: boolField}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Float64 method returns the field of float64 value.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, typ: float64Field, num: int64(math.Float64bits(value))}
+}
+
+// Duration method returns the field of duration value.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, typ: durationField, num: int64(value)}
+}
+
+// Err method returns the field `error` of error message, field is skipped if
+// error is nil.
+func Err(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: "error", typ: stringField, str: err.Error()}
+}
+
+// Any method returns the field of any value.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, typ: anyField, value: value}
+}
+
+// Value method returns the value of field.
+func (f Field) Value() interface{} {
+	switch f.typ {
+	case stringField:
+		return f.str
+	case int64Field:
+		return f.num
+	case boolField:
+		return f.num == 1
+	case float64Field:
+		return math.Float64frombits(uint64(f.num))
+	case durationField:
+		return time.Duration(f.num)
+	default:
+		return f.value
+	}
+}
+
+// FieldsFromStruct method returns the fields of exported struct fields of
+// given struct or pointer to struct, so request DTOs and config structs can
+// be logged without map literals. Field name is taken from tag `log`,
+// `omitempty` skips the zero value and `-` skips the field. Embedded struct
+// fields are promoted and nested struct is added as nested fields. For e.g.:
+//
+//	type Order struct {
+//		ID     int    `log:"order_id"`
+//		Coupon string `log:"coupon,omitempty"`
+//		Card   string `log:"-"`
+//	}
+//
+//	logger.WithFields(log.FieldsFromStruct(order)).Info("order placed")
+func FieldsFromStruct(v interface{}) Fields {
+	fields := make(Fields)
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return fields
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		addStructFields(fields, rv)
+	}
+	return fields
+}
+
+func addStructFields(fields Fields, rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if len(sf.PkgPath) > 0 && !sf.Anonymous {
+			continue // unexported
+		}
+
+		tag := sf.Tag.Get("log")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.IndexByte(tag, ','); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		fv := rv.Field(i)
+		if opts == "omitempty" && isZeroValue(fv) {
+			continue
+		}
+		sv, isStruct := structValue(fv)
+		if sf.Anonymous && len(name) == 0 {
+			if isStruct {
+				addStructFields(fields, sv)
+			}
+			continue
+		}
+		if len(sf.PkgPath) > 0 {
+			continue // unexported embedded non-struct
+		}
+		if len(name) == 0 {
+			name = sf.Name
+		}
+		if isStruct {
+			nested := make(Fields)
+			addStructFields(nested, sv)
+			fields[name] = nested
+			continue
+		}
+		fields[name] = fv.Interface()
+	}
+}
+
+// structValue method returns the struct value of given value or pointer,
+// struct which renders itself, for e.g.: `time.Time`, is not a struct value.
+func structValue(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return v, false
+	}
+	if !v.CanInterface() {
+		return v, true // unexported embedded struct
+	}
+	switch v.Interface().(type) {
+	case fmt.Stringer, error, json.Marshaler:
+		return v, false
+	}
+	if v.CanAddr() {
+		switch v.Addr().Interface().(type) {
+		case fmt.Stringer, error, json.Marshaler:
+			return v, false
+		}
+	}
+	return v, true
+}
+
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Logger typed field methods
+//_______________________________________
+
+// Errorw logs message as `ERROR` with typed fields.
+func (l *Logger) Errorw(msg string, fields ...Field) {
+	if l.isMaxLevel(LevelError) {
+		l.outputw(LevelError, msg, fields)
+	}
+}
+
+// Warnw logs message as `WARN` with typed fields.
+func (l *Logger) Warnw(msg string, fields ...Field) {
+	if l.isMaxLevel(LevelWarn) {
+		l.outputw(LevelWarn, msg, fields)
+	}
+}
+
+// Infow logs message as `INFO` with typed fields.
+func (l *Logger) Infow(msg string, fields ...Field) {
+	if l.isMaxLevel(LevelInfo) {
+		l.outputw(LevelInfo, msg, fields)
+	}
+}
+
+// Debugw logs message as `DEBUG` with typed fields.
+func (l *Logger) Debugw(msg string, fields ...Field) {
+	if l.isMaxLevel(LevelDebug) {
+		l.outputw(LevelDebug, msg, fields)
+	}
+}
+
+// Tracew logs message as `TRACE` with typed fields.
+func (l *Logger) Tracew(msg string, fields ...Field) {
+	if l.isMaxLevel(LevelTrace) {
+		l.outputw(LevelTrace, msg, fields)
+	}
+}
+
+func (l *Logger) outputw(lvl level, msg string, fields []Field) {
+	e := acquireEntry(l)
+	if e.isEnabled(lvl) {
+		addTypedFields(e.Fields, fields)
+		e.output(lvl, msg)
+	}
+	releaseEntry(e)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Entry typed field methods
+//_______________________________________
+
+// Errorw logs message as `ERROR` with typed fields.
+func (e *Entry) Errorw(msg string, fields ...Field) {
+	if e.isEnabled(LevelError) {
+		e.outputw(LevelError, msg, fields)
+	}
+}
+
+// Warnw logs message as `WARN` with typed fields.
+func (e *Entry) Warnw(msg string, fields ...Field) {
+	if e.isEnabled(LevelWarn) {
+		e.outputw(LevelWarn, msg, fields)
+	}
+}
+
+// Infow logs message as `INFO` with typed fields.
+func (e *Entry) Infow(msg string, fields ...Field) {
+	if e.isEnabled(LevelInfo) {
+		e.outputw(LevelInfo, msg, fields)
+	}
+}
+
+// Debugw logs message as `DEBUG` with typed fields.
+func (e *Entry) Debugw(msg string, fields ...Field) {
+	if e.isEnabled(LevelDebug) {
+		e.outputw(LevelDebug, msg, fields)
+	}
+}
+
+// Tracew logs message as `TRACE` with typed fields.
+func (e *Entry) Tracew(msg string, fields ...Field) {
+	if e.isEnabled(LevelTrace) {
+		e.outputw(LevelTrace, msg, fields)
+	}
+}
+
+// outputw method logs the copy of entry with typed fields, so the fields
+// are not retained in the entry.
+func (e *Entry) outputw(lvl level, msg string, fields []Field) {
+	ne := acquireEntry(e.logger)
+	ne.addFields(e.Fields)
+	ne.namespace = e.namespace
+	addTypedFields(ne.namespaceFields(), fields)
+	ne.output(lvl, msg)
+	releaseEntry(ne)
+}
+
+func addTypedFields(dst Fields, fields []Field) {
+	for _, f := range fields {
+		if len(f.Key) > 0 {
+			dst[f.Key] = f.Value()
+		}
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Field value rendering
+//_______________________________________
+
+// fieldFormat renders the `time.Duration` and `time.Time` field values
+// consistently for all the formats, it's configured under
+// `log.field_format`.
+//
+//	log {
+//	  field_format {
+//	    # ns, ms and s - number in the unit, for e.g.: 1.5s is 1500 in ms
+//	    # human - for e.g.: 1.5s
+//	    duration = "ms"
+//	    time = "2006-01-02T15:04:05.000Z07:00"
+//	  }
+//	}
+type fieldFormat struct {
+	durationUnit time.Duration
+	isHuman      bool
+	timeLayout   string
+}
+
+func newFieldFormat(cfg *config.Config) (*fieldFormat, error) {
+	ff := &fieldFormat{timeLayout: cfg.StringDefault("log.field_format.time", "")}
+	switch unit := cfg.StringDefault("log.field_format.duration", ""); unit {
+	case "":
+	case "ns":
+		ff.durationUnit = time.Nanosecond
+	case "ms":
+		ff.durationUnit = time.Millisecond
+	case "s":
+		ff.durationUnit = time.Second
+	case "human":
+		ff.isHuman = true
+	default:
+		return nil, fmt.Errorf("log: unsupported field_format duration '%s'", unit)
+	}
+	return ff, nil
+}
+
+// apply method replaces the duration and time values of fields including
+// nested fields as per the format.
+func (ff *fieldFormat) apply(fields Fields) {
+	for k, v := range fields {
+		switch value := v.(type) {
+		case time.Duration:
+			if ff.isHuman {
+				fields[k] = value.String()
+			} else if ff.durationUnit > 0 {
+				fields[k] = float64(value) / float64(ff.durationUnit)
+			}
+		case time.Time:
+			if len(ff.timeLayout) > 0 {
+				fields[k] = value.Format(ff.timeLayout)
+			}
+		case Fields:
+			// nested fields are shared with parent entry
+			nf := make(Fields, len(value))
+			nf.addAll(value)
+			ff.apply(nf)
+			fields[k] = nf
+		}
+	}
+}
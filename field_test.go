@@ -0,0 +1,161 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogTypedFields(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "info"
+    pattern = "%level:-5 %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.Infow("request completed", String("path", "/orders"), Int("status", 200))
+	assert.True(t, strings.HasPrefix(buf.String(), "INFO  request completed fields["))
+	assert.True(t, strings.Contains(buf.String(), "path: /orders"))
+	assert.True(t, strings.Contains(buf.String(), "status: 200"))
+
+	buf.Reset()
+	logger.Debugw("not logged", String("path", "/orders"))
+	logger.Tracew("not logged")
+	logger.Errorw("failed", Err(nil))
+	logger.Warnw("retry", Duration("backoff", 2*time.Second))
+	assert.Equal(t, "ERROR failed \nWARN  retry fields[backoff: 2s] \n", buf.String())
+
+	// typed fields are not retained in entry
+	buf.Reset()
+	e := logger.WithField("reqid", "req-1")
+	e.Errorw("failed", Err(errors.New("connection refused")))
+	e.Warnw("degraded")
+	e.Debugw("not logged", Bool("cached", true))
+	e.Tracew("not logged")
+	e.Infow("done")
+	assert.Equal(t, "ERROR failed fields[error: connection refused] \nWARN  degraded \nINFO  done \n", buf.String())
+}
+
+func TestFieldValue(t *testing.T) {
+	for _, tc := range []struct {
+		field Field
+		value interface{}
+	}{
+		{String("s", "v"), "v"},
+		{Int("i", 10), int64(10)},
+		{Int64("i64", -20), int64(-20)},
+		{Bool("b", true), true},
+		{Bool("b", false), false},
+		{Float64("f", 1.5), 1.5},
+		{Duration("d", time.Minute), time.Minute},
+		{Err(errors.New("boom")), "boom"},
+		{Any("a", []int{1}), []int{1}},
+	} {
+		assert.Equal(t, tc.value, tc.field.Value())
+	}
+	assert.Equal(t, "", Err(nil).Key)
+}
+
+func TestLogFieldFormat(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    format = "json"
+    field_format {
+      duration = "ms"
+      time = "2006-01-02T15:04:05Z07:00"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	at := time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC)
+	logger.WithNamespace("db").Infow("request completed",
+		Duration("elapsed", 1500*time.Microsecond), Any("at", at))
+	assert.True(t, strings.Contains(buf.String(), `"db":{"at":"2026-10-15T08:30:00Z","elapsed":1.5}`))
+
+	for unit, expected := range map[string]interface{}{
+		"ns":    float64(1500000),
+		"s":     0.0015,
+		"human": "1.5ms",
+	} {
+		cfg, _ = config.ParseString(`log { field_format { duration = "` + unit + `" } }`)
+		ff, err := newFieldFormat(cfg)
+		assert.Nil(t, err)
+		fields := Fields{"elapsed": 1500 * time.Microsecond, "at": at}
+		ff.apply(fields)
+		assert.Equal(t, expected, fields["elapsed"])
+		assert.Equal(t, at, fields["at"])
+	}
+
+	cfg, _ = config.ParseString(`log { field_format { duration = "minutes" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unsupported field_format duration 'minutes'", err.Error())
+}
+
+type testAudit struct {
+	CreatedBy string `log:"created_by"`
+	internal  string
+}
+
+type testAddress struct {
+	City string `log:"city"`
+	Zip  string `log:"zip,omitempty"`
+}
+
+type testOrder struct {
+	testAudit
+	ID       int          `log:"order_id"`
+	Coupon   string       `log:"coupon,omitempty"`
+	Card     string       `log:"-"`
+	Items    []string     `log:"items,omitempty"`
+	Address  *testAddress `log:"address"`
+	Billing  *testAddress `log:"billing,omitempty"`
+	PlacedAt time.Time    `log:"placed_at"`
+	Total    float64
+	secret   string
+}
+
+func TestFieldsFromStruct(t *testing.T) {
+	at := time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC)
+	order := &testOrder{
+		testAudit: testAudit{CreatedBy: "jeeva", internal: "x"},
+		ID:        101,
+		Card:      "4111111111111111",
+		Address:   &testAddress{City: "Chennai"},
+		PlacedAt:  at,
+		Total:     99.5,
+		secret:    "s",
+	}
+	assert.Equal(t, Fields{
+		"created_by": "jeeva",
+		"order_id":   101,
+		"address":    Fields{"city": "Chennai"},
+		"placed_at":  at,
+		"Total":      99.5,
+	}, FieldsFromStruct(order))
+	assert.Equal(t, Fields{}, FieldsFromStruct((*testOrder)(nil)))
+	assert.Equal(t, Fields{}, FieldsFromStruct("not a struct"))
+
+	cfg, _ := config.ParseString(`log {
+    pattern = "%message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.WithFields(FieldsFromStruct(testAddress{City: "Chennai", Zip: "600001"})).Info("shipped")
+	assert.Equal(t, "shipped fields[city: Chennai, zip: 600001] \n", buf.String())
+}
@@ -5,11 +5,17 @@
 package log
 
 import (
-	"encoding/json"
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,15 +26,71 @@ import (
 const defaultRotatePolicy = "daily"
 
 var (
-	// backupTimeFormat is used for timestamp with filename on rotation
-	backupTimeFormat = "2006-01-02-15-04-05.000"
+	// abstract it, can be unit tested
+	freeDiskSpace = diskFreeSpace
 
 	_ Receiver = (*FileReceiver)(nil)
 )
 
-// FileReceiver writes the log entry into file.
+// FileReceiver writes the log entry into file. Rotated file is compressed
+// in the background if `log.rotate.compress` is configured, `gzip` is
+// supported out of the box and others can be added using `AddCompressor`.
+//
+// NOTE: By default logger registers SIGHUP handler to reopen the file, so it
+// works with external logrotate. If application handles SIGHUP by itself,
+// disable it with `log.reopen_on_sighup = false` and call `Logger.Reopen`.
+//
+// Writes are buffered if `log.file_buffer.size` is configured, buffer is
+// flushed on every `log.file_buffer.flush_interval` (default 1s), on
+// `Flush` and for FATAL and PANIC entries.
+//
+// Entry is written as whole with single write. On startup, partially
+// written last line from the previous crash is truncated and after a failed
+// partial write the next entry starts in new line, so newline delimited
+// records are never torn.
+//
+// Free disk space is checked every `log.disk_guard.check_interval` if
+// `log.disk_guard.min_free` is configured, below the threshold receiver
+// applies `log.disk_guard.action`
+//
+//	rotate     - rotates the file early, compresses and prunes the backups
+//	drop_debug - drops DEBUG and TRACE entries
+//	console    - writes the entries into stderr instead of file
+//
+// Application is notified via `Logger.OnLowDiskSpace`.
+//
+// Log file and its directory are created with `log.file_mode` and
+// `log.dir_mode` irrespective of process umask, owner is changed to
+// `log.file_uid` and `log.file_gid` if configured.
+//
+// For durability, file is synced to disk as per `log.fsync` config: entries
+// at or above `level`, every `entries` count or every `interval`.
+//
+// If `log.rotate.symlink = true`, entries are written into timestamped file
+// and `log.file` is maintained as symlink to it, for e.g.:
+// `app.log -> app-2018-03-05-13-20-00.000.log`.
+//
+// Output is encrypted with AES-GCM if `log.encrypt` is configured, key is
+// resolved from `key_provider` registered using `AddKeyProvider`, env
+// variable `key_env` or `key` in hex. Each entry is written as separate
+// chunk, so file can be read while it's being written, see
+// `NewDecryptReader`.
+//
+// Entries can be split into separate files by level with `log.split` config,
+// key is the level and entries at or above the level are written into its
+// file, entry goes to the most severe level it meets and rest goes into
+// `log.file`. Split files uses the same config as `log.file`, for e.g.:
+//
+//	log {
+//	  file = "logs/app.log"
+//	  split {
+//	    error = "logs/error.log"
+//	  }
+//	}
 type FileReceiver struct {
 	filename     string
+	current      string
+	isSymlink    bool
 	out          io.Writer
 	formatter    string
 	flags        []ess.FmtFlagPart
@@ -37,10 +99,48 @@ type FileReceiver struct {
 	mu           sync.Mutex
 	isClosed     bool
 	rotatePolicy string
-	openDay      int
+	interval     time.Duration
+	location     *time.Location
+	nextRotate   time.Time
 	isUTC        bool
 	maxSize      int64
 	maxLines     int64
+	maxBackups   int
+	maxAge       time.Duration
+	maxTotalSize int64
+	compressor   *compressor
+	backupMu     sync.Mutex
+	wg           sync.WaitGroup
+	file         *os.File
+	buf          *bufio.Writer
+	bufferSize   int
+	done         chan struct{}
+	syncLevel    level
+	syncEntries  int64
+	unsynced     int64
+	fileMode     os.FileMode
+	dirMode      os.FileMode
+	uid          int
+	gid          int
+	backupName   *rotateFilename
+	isTorn       bool
+	minFree      uint64
+	diskAction   string
+	isLowDisk    bool
+	freeSpace    uint64
+	diskSpaceFn  DiskSpaceFunc
+	rotateFn     RotateFunc
+	errorFn      ErrorHandlerFunc
+	backupDone   chan struct{}
+	splits       []*fileSplit
+	cipher       *chunkCipher
+	isSplit      bool
+}
+
+// fileSplit holds the file receiver of entries at or above the level.
+type fileSplit struct {
+	level    level
+	receiver *FileReceiver
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -49,17 +149,59 @@ type FileReceiver struct {
 
 // Init method initializes the file receiver instance.
 func (f *FileReceiver) Init(cfg *config.Config) error {
-	// File
-	f.filename = cfg.StringDefault("log.file", "")
-	if err := f.openFile(); err != nil {
+	// Buffer
+	flushInterval, err := parseDuration(cfg, "log.file_buffer.flush_interval", "1s")
+	if err != nil {
 		return err
 	}
+	if bufferSize := cfg.StringDefault("log.file_buffer.size", ""); len(bufferSize) > 0 {
+		size, err := ess.StrToBytes(bufferSize)
+		if err != nil {
+			return err
+		}
+		f.bufferSize = int(size)
+	}
+
+	// Permission and ownership
+	if f.fileMode, err = parseFileMode(cfg, "log.file_mode"); err != nil {
+		return err
+	}
+	if f.dirMode, err = parseFileMode(cfg, "log.dir_mode"); err != nil {
+		return err
+	}
+	f.uid = cfg.IntDefault("log.file_uid", -1)
+	f.gid = cfg.IntDefault("log.file_gid", -1)
 
 	f.formatter = cfg.StringDefault("log.format", "text")
-	if !(f.formatter == textFmt || f.formatter == jsonFmt) {
+	if !isFormatSupported(f.formatter) {
 		return fmt.Errorf("log: unsupported format '%s'", f.formatter)
 	}
 
+	// Encryption
+	key, isEncrypt, err := encryptKey(cfg)
+	if err != nil {
+		return err
+	}
+	if isEncrypt {
+		if f.cipher, err = newChunkCipher(key); err != nil {
+			return err
+		}
+	}
+
+	// File
+	f.filename = cfg.StringDefault("log.file", "")
+	f.current = f.filename
+	if f.backupName, err = newRotateFilename(f.filename,
+		cfg.StringDefault("log.rotate.filename", ""),
+		cfg.StringDefault("instance_name", hostname)); err != nil {
+		return err
+	}
+	if f.isSymlink = cfg.BoolDefault("log.rotate.symlink", false); !f.isSymlink {
+		if err := f.openFile(); err != nil {
+			return err
+		}
+	}
+
 	if policy, found := cfg.String("log.rotate.mode"); found {
 		f.rotatePolicy = policy
 		if ess.IsStrEmpty(f.rotatePolicy) {
@@ -74,7 +216,18 @@ func (f *FileReceiver) Init(cfg *config.Config) error {
 
 	switch f.rotatePolicy {
 	case defaultRotatePolicy:
-		f.openDay = f.getDay()
+		f.interval = 24 * time.Hour
+	case "hourly":
+		f.interval = time.Hour
+	case "interval":
+		interval, err := parseDuration(cfg, "log.rotate.interval", "24h")
+		if err != nil {
+			return err
+		}
+		if interval < time.Minute {
+			return fmt.Errorf("log: rotate interval '%s' is less than a minute", interval)
+		}
+		f.interval = interval
 	case "lines":
 		f.maxLines = int64(cfg.IntDefault("log.rotate.lines", 0))
 	case "size":
@@ -85,8 +238,93 @@ func (f *FileReceiver) Init(cfg *config.Config) error {
 		f.maxSize = maxSize
 	}
 
+	// Time boundary is computed in the timezone, default is local time or UTC
+	// if pattern has `utctime` flag.
+	if tz := cfg.StringDefault("log.rotate.timezone", ""); len(tz) > 0 {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return fmt.Errorf("log: invalid rotate timezone '%s': %v", tz, err)
+		}
+		f.location = loc
+	}
+	f.setNextRotate()
+
+	if f.isSymlink {
+		if err := f.openCurrent(); err != nil {
+			return err
+		}
+	}
+
+	// Backup files retention by count, age and total size, zero keeps all
+	f.maxBackups = cfg.IntDefault("log.rotate.backups", 0)
+	if maxAge := cfg.StringDefault("log.rotate.max_age", ""); len(maxAge) > 0 {
+		d, err := parseAge(maxAge)
+		if err != nil {
+			return fmt.Errorf("log: invalid rotate max_age '%s'", maxAge)
+		}
+		f.maxAge = d
+	}
+	if maxTotalSize := cfg.StringDefault("log.rotate.max_total_size", ""); len(maxTotalSize) > 0 {
+		size, err := ess.StrToBytes(maxTotalSize)
+		if err != nil {
+			return err
+		}
+		f.maxTotalSize = size
+	}
+
+	if name := cfg.StringDefault("log.rotate.compress", ""); len(name) > 0 {
+		if f.compressor = getCompressorByName(name); f.compressor == nil {
+			return fmt.Errorf("log: unsupported rotate compress '%s'", name)
+		}
+	}
+
 	f.mu = sync.Mutex{}
 
+	// Durability
+	f.syncLevel = LevelUnknown
+	if syncLevel := cfg.StringDefault("log.fsync.level", ""); len(syncLevel) > 0 {
+		if f.syncLevel = levelByName(syncLevel); f.syncLevel == LevelUnknown {
+			return fmt.Errorf("log: unknown fsync level '%s'", syncLevel)
+		}
+	}
+	f.syncEntries = int64(cfg.IntDefault("log.fsync.entries", 0))
+	syncInterval, err := parseDuration(cfg, "log.fsync.interval", "0s")
+	if err != nil {
+		return err
+	}
+
+	// Disk guard
+	var checkInterval time.Duration
+	if minFree := cfg.StringDefault("log.disk_guard.min_free", ""); len(minFree) > 0 {
+		size, err := ess.StrToBytes(minFree)
+		if err != nil {
+			return err
+		}
+		f.minFree = uint64(size)
+		f.diskAction = cfg.StringDefault("log.disk_guard.action", "rotate")
+		if !ess.IsSliceContainsString([]string{"rotate", "drop_debug", "console"}, f.diskAction) {
+			return fmt.Errorf("log: unsupported disk_guard action '%s'", f.diskAction)
+		}
+		if checkInterval, err = parseDuration(cfg, "log.disk_guard.check_interval", "10s"); err != nil {
+			return err
+		}
+		f.checkDiskSpace()
+	}
+
+	f.done = make(chan struct{})
+	if checkInterval > 0 {
+		go f.runPeriodically(checkInterval, f.checkDiskSpace, f.done)
+	}
+	if f.bufferSize > 0 && flushInterval > 0 {
+		go f.runPeriodically(flushInterval, f.Flush, f.done)
+	}
+	if syncInterval > 0 {
+		go f.runPeriodically(syncInterval, f.Sync, f.done)
+	}
+
+	if !f.isSplit {
+		return f.initSplits(cfg)
+	}
 	return nil
 }
 
@@ -97,15 +335,21 @@ func (f *FileReceiver) SetPattern(pattern string) error {
 		return err
 	}
 	f.flags = flags
-	if f.formatter == textFmt {
+	if f.formatter != jsonFmt {
 		f.isCallerInfo = isCallerInfo(f.flags)
 	}
 	f.isUTC = isFmtFlagExists(f.flags, FmtFlagUTCTime)
-	f.openDay = f.getDay()
+	f.setNextRotate()
+	for _, s := range f.splits {
+		if err := s.receiver.SetPattern(pattern); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// SetWriter method sets the given writer into file receiver.
+// SetWriter method sets the given writer into file receiver, split files
+// are not affected.
 func (f *FileReceiver) SetWriter(w io.Writer) {
 	f.out = w
 }
@@ -118,31 +362,89 @@ func (f *FileReceiver) IsCallerInfo() bool {
 
 // Log method logs the given entry values into file.
 func (f *FileReceiver) Log(entry *Entry) {
+	if r := f.splitReceiver(entry.Level); r != nil {
+		r.Log(entry)
+		return
+	}
+
+	if err := f.write(entry); err != nil {
+		f.mu.Lock()
+		fn := f.errorFn
+		f.mu.Unlock()
+		if fn != nil {
+			fn(err, entry)
+		}
+	}
+}
+
+// write method writes the entry into file, it returns the error of
+// encryption or write.
+func (f *FileReceiver) write(entry *Entry) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
 	if f.isRotate() {
-		_ = f.rotateFile()
+		f.rotate()
+	}
 
-		// reset rotation values
-		f.openDay = f.getDay()
-		f.stats.lines = 0
-		f.stats.bytes = 0
+	if f.isLowDisk {
+		switch f.diskAction {
+		case "drop_debug":
+			if entry.Level >= LevelDebug {
+				return nil
+			}
+		case "console":
+			_, err := os.Stderr.Write(formatEntry(f.formatter, f.flags, entry))
+			return err
+		}
 	}
 
-	var msg []byte
-	if f.formatter == textFmt {
-		msg = textFormatter(f.flags, entry)
-	} else {
-		msg, _ = json.Marshal(entry)
-		msg = append(msg, '\n')
+	msg := formatEntry(f.formatter, f.flags, entry)
+	if f.isTorn {
+		msg = append([]byte{'\n'}, msg...)
+	}
+	if f.cipher != nil {
+		var err error
+		if msg, err = f.cipher.seal(msg); err != nil {
+			return err
+		}
 	}
 
-	size, _ := f.out.Write(msg)
+	// buffer is flushed beforehand, so entry is not split across writes
+	if f.buf != nil && len(msg) > f.buf.Available() && f.buf.Buffered() > 0 {
+		_ = f.buf.Flush()
+	}
+	size, err := f.out.Write(msg)
+	f.isTorn = err != nil && size > 0 && !isBinaryFormat(f.formatter) && f.cipher == nil
+	if err != nil && f.minFree > 0 && !f.isLowDisk {
+		// write may have failed due to disk full
+		go f.checkDiskSpace()
+	}
 
 	// calculate receiver stats
 	f.stats.bytes += int64(size)
 	f.stats.lines++
+
+	if err != nil {
+		return err
+	}
+
+	if f.syncLevel != LevelUnknown && entry.Level <= f.syncLevel {
+		f.sync()
+		return nil
+	}
+	if f.syncEntries > 0 {
+		if f.unsynced++; f.unsynced >= f.syncEntries {
+			f.sync()
+			return nil
+		}
+	}
+
+	// process may exit after FATAL and PANIC
+	if f.buf != nil && entry.Level <= LevelPanic {
+		return f.buf.Flush()
+	}
+	return nil
 }
 
 // Writer method returns the current log writer.
@@ -150,14 +452,155 @@ func (f *FileReceiver) Writer() io.Writer {
 	return f.out
 }
 
+// Flush method writes the buffered entries into file.
+func (f *FileReceiver) Flush() {
+	for _, s := range f.splits {
+		s.receiver.Flush()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.buf != nil && !f.isClosed {
+		_ = f.buf.Flush()
+	}
+}
+
+// OnLowDiskSpace method sets the func, which is called when free disk space
+// goes below `log.disk_guard.min_free`.
+func (f *FileReceiver) OnLowDiskSpace(fn DiskSpaceFunc) {
+	for _, s := range f.splits {
+		s.receiver.OnLowDiskSpace(fn)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.diskSpaceFn = fn
+	if f.isLowDisk && fn != nil {
+		go fn(f.filename, f.freeSpace, f.minFree)
+	}
+}
+
+// OnError method sets the func, which is called when entry write into file
+// fails.
+func (f *FileReceiver) OnError(fn ErrorHandlerFunc) {
+	for _, s := range f.splits {
+		s.receiver.OnError(fn)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errorFn = fn
+}
+
+// OnRotate method sets the func, which is called with completed file path
+// and new file path after each rotation. Completed file is compressed one
+// if compression is configured, func is called before backups are pruned.
+func (f *FileReceiver) OnRotate(fn RotateFunc) {
+	for _, s := range f.splits {
+		s.receiver.OnRotate(fn)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rotateFn = fn
+}
+
+// Sync method writes the buffered entries and commits the file to disk.
+func (f *FileReceiver) Sync() {
+	for _, s := range f.splits {
+		s.receiver.Sync()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sync()
+}
+
+// Reopen method closes the log file and opens the file path again.
+func (f *FileReceiver) Reopen() error {
+	var err error
+	for _, s := range f.splits {
+		if serr := s.receiver.Reopen(); serr != nil && err == nil {
+			err = serr
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.close()
+	if oerr := f.openFile(); oerr != nil {
+		return oerr
+	}
+	return err
+}
+
+// Close method waits for the in-progress compression of rotated files and
+// closes the log file.
+func (f *FileReceiver) Close() {
+	for _, s := range f.splits {
+		s.receiver.Close()
+	}
+	f.mu.Lock()
+	if f.done != nil {
+		close(f.done)
+		f.done = nil
+	}
+	f.mu.Unlock()
+
+	// waits without lock, rotate func may log into this receiver
+	f.wg.Wait()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.close()
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // FileReceiver Unexported methods
 //___________________________________
 
+// initSplits method creates the file receiver for each level of
+// `log.split`, sorted from most severe level.
+func (f *FileReceiver) initSplits(cfg *config.Config) error {
+	for _, name := range cfg.KeysByPath("log.split") {
+		lvl := levelByName(name)
+		if lvl == LevelUnknown {
+			return fmt.Errorf("log: unknown split level '%s'", name)
+		}
+		filename := cfg.StringDefault("log.split."+name, "")
+		if ess.IsStrEmpty(filename) {
+			return fmt.Errorf("log: split file is empty for level '%s'", name)
+		}
+
+		scfg := config.NewEmpty()
+		if err := scfg.Merge(cfg); err != nil {
+			return err
+		}
+		scfg.SetString("log.file", filename)
+
+		r := &FileReceiver{isSplit: true}
+		if err := r.Init(scfg); err != nil {
+			return err
+		}
+		f.splits = append(f.splits, &fileSplit{level: lvl, receiver: r})
+	}
+
+	sort.Slice(f.splits, func(i, j int) bool {
+		return f.splits[i].level < f.splits[j].level
+	})
+	return nil
+}
+
+// splitReceiver method returns the split file receiver of the most severe
+// level the given level meets, otherwise nil.
+func (f *FileReceiver) splitReceiver(lvl level) *FileReceiver {
+	for _, s := range f.splits {
+		if lvl <= s.level {
+			return s.receiver
+		}
+	}
+	return nil
+}
+
 func (f *FileReceiver) isRotate() bool {
 	switch f.rotatePolicy {
-	case "daily":
-		return f.openDay != f.getDay()
+	case "daily", "hourly", "interval":
+		return !time.Now().Before(f.nextRotate)
 	case "lines":
 		return f.maxLines != 0 && f.stats.lines >= f.maxLines
 	case "size":
@@ -167,62 +610,649 @@ func (f *FileReceiver) isRotate() bool {
 	}
 }
 
+func (f *FileReceiver) rotate() {
+	_ = f.rotateFile()
+
+	// reset rotation values
+	f.setNextRotate()
+	f.stats.lines = 0
+	f.stats.bytes = 0
+}
+
+// checkDiskSpace method checks the free space of log directory and applies
+// the disk guard action on low disk space.
+func (f *FileReceiver) checkDiskSpace() {
+	free, err := freeDiskSpace(filepath.Dir(f.filename))
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.freeSpace = free
+	isLowDisk := free < f.minFree
+	if isLowDisk == f.isLowDisk {
+		return
+	}
+	f.isLowDisk = isLowDisk
+	if !isLowDisk {
+		return
+	}
+
+	if f.diskAction == "rotate" {
+		f.rotate()
+	}
+	if f.diskSpaceFn != nil {
+		go f.diskSpaceFn(f.filename, free, f.minFree)
+	}
+}
+
 func (f *FileReceiver) rotateFile() error {
+	if f.isSymlink {
+		f.close()
+		previous := f.current
+		f.setCurrent(f.backupFileName())
+		if err := f.openFile(); err != nil {
+			return err
+		}
+		f.processBackup(previous)
+		return f.updateSymlink()
+	}
+
 	if _, err := os.Lstat(f.filename); err == nil {
 		f.close()
-		if err = os.Rename(f.filename, f.backupFileName()); err != nil {
+		backup := f.backupFileName()
+		if err = os.Rename(f.filename, backup); err != nil {
 			return err
 		}
+		f.processBackup(backup)
 	}
 
 	return f.openFile()
 }
 
-func (f *FileReceiver) openFile() error {
+// processBackup method compresses the backup file and calls the rotate
+// func in the background if configured, then prunes the backups. Backups
+// are processed in the rotation order, so pruning does not remove the
+// backup which is not processed yet.
+func (f *FileReceiver) processBackup(backup string) {
+	if f.compressor == nil && f.rotateFn == nil {
+		f.pruneBackups()
+		return
+	}
+
+	previous, done := f.backupDone, make(chan struct{})
+	f.backupDone = done
+	f.wg.Add(1)
+	go func(fn RotateFunc, current string) {
+		defer f.wg.Done()
+		defer close(done)
+		if previous != nil {
+			<-previous
+		}
+		if f.compressor != nil {
+			backup = f.compressBackup(backup)
+		}
+		if fn != nil {
+			fn(backup, current)
+		}
+		f.pruneBackups()
+	}(f.rotateFn, f.current)
+}
+
+// openCurrent method opens the timestamped file in symlink mode, existing
+// target of the symlink is continued.
+func (f *FileReceiver) openCurrent() error {
+	if target, err := os.Readlink(f.filename); err == nil {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(f.filename), target)
+		}
+		if ess.IsFileExists(target) {
+			f.setCurrent(target)
+			return f.openFile()
+		}
+	} else if _, err := os.Lstat(f.filename); err == nil {
+		// regular file of previous run, it becomes backup
+		if err = os.Rename(f.filename, f.backupFileName()); err != nil {
+			return err
+		}
+	}
+
+	f.setCurrent(f.backupFileName())
+	if err := f.openFile(); err != nil {
+		return err
+	}
+	return f.updateSymlink()
+}
+
+// updateSymlink method points the `log.file` symlink to current file, it's
+// replaced atomically.
+func (f *FileReceiver) updateSymlink() error {
+	tmp := f.filename + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(f.current), tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.filename)
+}
+
+func (f *FileReceiver) setCurrent(name string) {
+	f.backupMu.Lock()
+	f.current = name
+	f.backupMu.Unlock()
+}
+
+// pruneBackups method removes the backup files as per configured retention
+// `log.rotate.backups`, `log.rotate.max_age` and `log.rotate.max_total_size`.
+// Newest backups are retained.
+func (f *FileReceiver) pruneBackups() {
+	if f.maxBackups <= 0 && f.maxAge <= 0 && f.maxTotalSize <= 0 {
+		return
+	}
+
+	f.backupMu.Lock()
+	defer f.backupMu.Unlock()
+	backups := f.backupFiles()
+	var totalSize int64
+	for i := len(backups) - 1; i >= 0; i-- {
+		info, err := os.Stat(backups[i])
+		if err != nil && f.compressor != nil {
+			info, err = os.Stat(backups[i] + f.compressor.ext)
+		}
+		if err != nil {
+			continue
+		}
+		totalSize += info.Size()
+
+		retain := len(backups) - i
+		if (f.maxBackups > 0 && retain > f.maxBackups) ||
+			(f.maxAge > 0 && time.Since(info.ModTime()) > f.maxAge) ||
+			(f.maxTotalSize > 0 && totalSize > f.maxTotalSize) {
+			_ = os.Remove(backups[i])
+			if f.compressor != nil {
+				_ = os.Remove(backups[i] + f.compressor.ext)
+			}
+		}
+	}
+}
+
+// compressBackup method compresses the rotated file into `<file><ext>` and
+// removes the rotated file. It returns the compressed file path.
+func (f *FileReceiver) compressBackup(backup string) string {
+	compressed := backup + f.compressor.ext
+	if err := compressFile(backup, compressed, f.compressor.fn); err != nil {
+		return backup
+	}
+	_ = f.applyPermission(compressed, f.fileMode)
+	_ = os.Remove(backup)
+	return compressed
+}
+
+func compressFile(src, dst string, fn CompressorFunc) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer ess.CloseQuietly(in)
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, filePermission)
+	if err != nil {
+		return err
+	}
+
+	w, err := fn(out)
+	if err == nil {
+		if _, err = io.Copy(w, in); err == nil {
+			err = w.Close()
+		} else {
+			_ = w.Close()
+		}
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
+
+// backupFiles method returns the backup files of the log file in the order
+// of oldest to newest, current file is excluded. Compressed backup is
+// returned without compressor extension.
+func (f *FileReceiver) backupFiles() []string {
 	dir := filepath.Dir(f.filename)
-	_ = ess.MkDirAll(dir, filePermission)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
 
-	file, err := os.OpenFile(f.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePermission)
+	type backup struct {
+		name string
+		key  string
+	}
+	var backups []backup
+	found := make(map[string]bool)
+	current := filepath.Base(f.current)
+	for _, info := range infos {
+		name := info.Name()
+		if f.compressor != nil {
+			name = strings.TrimSuffix(name, f.compressor.ext)
+		}
+		if info.IsDir() || found[name] || name == current {
+			continue
+		}
+		if key, ok := f.backupName.sortKey(name); ok {
+			found[name] = true
+			backups = append(backups, backup{name: name, key: key})
+		}
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].key < backups[j].key
+	})
+
+	files := make([]string, 0, len(backups))
+	for _, b := range backups {
+		files = append(files, filepath.Join(dir, b.name))
+	}
+	return files
+}
+
+func (f *FileReceiver) openFile() error {
+	dir := filepath.Dir(f.current)
+	if !ess.IsFileExists(dir) {
+		if err := os.MkdirAll(dir, modeOrDefault(f.dirMode)); err != nil {
+			return fmt.Errorf("log: unable to create log directory '%s': %v", dir, err)
+		}
+		if err := f.applyPermission(dir, f.dirMode); err != nil {
+			return err
+		}
+	}
+
+	var chunks int64
+	_, statErr := os.Stat(f.current)
+	if statErr == nil {
+		var err error
+		if f.cipher != nil {
+			chunks, err = repairLastChunk(f.current)
+		} else if !isBinaryFormat(f.formatter) {
+			err = repairLastLine(f.current)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(f.current, os.O_CREATE|os.O_APPEND|os.O_WRONLY, modeOrDefault(f.fileMode))
 	if err != nil {
 		return err
 	}
+	if os.IsNotExist(statErr) {
+		if err = f.applyPermission(f.current, f.fileMode); err != nil {
+			ess.CloseQuietly(file)
+			return err
+		}
+	}
 
 	fileStat, err := file.Stat()
 	if err != nil {
 		return err
 	}
 
-	f.SetWriter(file)
+	f.file = file
+	if f.bufferSize > 0 {
+		f.buf = bufio.NewWriterSize(file, f.bufferSize)
+		f.SetWriter(f.buf)
+	} else {
+		f.SetWriter(file)
+	}
 	f.isClosed = false
 	f.stats = &receiverStats{}
 	f.stats.bytes = fileStat.Size()
-	f.stats.lines = int64(ess.LineCntr(file))
+	if f.cipher != nil {
+		f.stats.lines = chunks
+	} else {
+		f.stats.lines = int64(ess.LineCntr(file))
+	}
+
+	return nil
+}
+
+// repairLastLine method truncates the partially written last line of the
+// file, i.e. bytes after the last newline.
+func repairLastLine(name string) error {
+	file, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer ess.CloseQuietly(file)
+
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	// scan backwards in chunks for the last newline
+	size := info.Size()
+	chunk := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := end - int64(len(chunk))
+		if start < 0 {
+			start = 0
+		}
+		b := chunk[:end-start]
+		if _, err = file.ReadAt(b, start); err != nil {
+			return err
+		}
+		if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+			if pos := start + int64(i) + 1; pos < size {
+				return file.Truncate(pos)
+			}
+			return nil
+		}
+		end = start
+	}
+	return file.Truncate(0)
+}
 
+// applyPermission method sets the mode, since file creation is subject to
+// umask, and changes the owner if configured.
+func (f *FileReceiver) applyPermission(name string, mode os.FileMode) error {
+	if mode != 0 {
+		if err := os.Chmod(name, mode); err != nil {
+			return fmt.Errorf("log: unable to set mode on '%s': %v", name, err)
+		}
+	}
+	if f.uid != -1 || f.gid != -1 {
+		if err := os.Chown(name, f.uid, f.gid); err != nil {
+			return fmt.Errorf("log: unable to set owner on '%s': %v", name, err)
+		}
+	}
 	return nil
 }
 
 func (f *FileReceiver) close() {
 	if !f.isClosed {
-		ess.CloseQuietly(f.out)
+		if f.buf != nil {
+			_ = f.buf.Flush()
+			ess.CloseQuietly(f.file)
+		} else {
+			ess.CloseQuietly(f.out)
+		}
 		f.isClosed = true
 	}
 }
 
+func (f *FileReceiver) sync() {
+	if f.isClosed {
+		return
+	}
+	if f.buf != nil {
+		_ = f.buf.Flush()
+	}
+	_ = f.file.Sync()
+	f.unsynced = 0
+}
+
+func (f *FileReceiver) runPeriodically(interval time.Duration, fn func(), done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			fn()
+		case <-done:
+			return
+		}
+	}
+}
+
 func (f *FileReceiver) backupFileName() string {
 	dir := filepath.Dir(f.filename)
-	fileName := filepath.Base(f.filename)
-	ext := filepath.Ext(fileName)
-	baseName := ess.StripExt(fileName)
-	t := time.Now()
-	if f.isUTC {
-		t = t.UTC()
+	t := time.Now().In(f.getLocation())
+
+	// rotated within same time, sequence continues from the latest backup
+	seq := 0
+	timeKey, _, _ := f.backupName.parse(f.backupName.format(t, 0))
+	for _, b := range f.backupFiles() {
+		if bTimeKey, bSeq, _ := f.backupName.parse(filepath.Base(b)); bTimeKey == timeKey && bSeq >= seq {
+			seq = bSeq + 1
+		}
+	}
+
+	name := filepath.Join(dir, f.backupName.format(t, seq))
+	for f.isBackupExists(name) {
+		seq++
+		name = filepath.Join(dir, f.backupName.format(t, seq))
+	}
+	return name
+}
+
+// parseFileMode method parses the octal file mode value, for e.g.: `0640`.
+// Zero is returned if it's not configured.
+func parseFileMode(cfg *config.Config, key string) (os.FileMode, error) {
+	value := cfg.StringDefault(key, "")
+	if len(value) == 0 {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("log: invalid file mode '%s' for '%s'", value, key)
 	}
-	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", baseName, t.Format(backupTimeFormat), ext))
+	return os.FileMode(mode), nil
 }
 
-func (f *FileReceiver) getDay() int {
+func modeOrDefault(mode os.FileMode) os.FileMode {
+	if mode == 0 {
+		return filePermission
+	}
+	return mode
+}
+
+// parseAge method parses the duration value, in addition to Go duration it
+// supports days, for e.g.: `7d`.
+func parseAge(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func (f *FileReceiver) isBackupExists(name string) bool {
+	return ess.IsFileExists(name) || (f.compressor != nil && ess.IsFileExists(name+f.compressor.ext))
+}
+
+// setNextRotate method computes the next rotation time for time-based
+// policies. Boundary is aligned to midnight of the timezone, so `daily`
+// rotates at midnight and `hourly` at every hour.
+func (f *FileReceiver) setNextRotate() {
+	if f.interval == 0 {
+		return
+	}
+	f.nextRotate = nextRotateTime(time.Now().In(f.getLocation()), f.interval)
+}
+
+func (f *FileReceiver) getLocation() *time.Location {
+	if f.location != nil {
+		return f.location
+	}
 	if f.isUTC {
-		return time.Now().UTC().Day()
+		return time.UTC
+	}
+	return time.Local
+}
+
+// nextRotateTime method returns the next interval boundary after given time,
+// intervals are counted from midnight of the time's location. Interval more
+// than a day is counted from given time.
+func nextRotateTime(t time.Time, interval time.Duration) time.Time {
+	if interval > 24*time.Hour {
+		return t.Add(interval)
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	nextMidnight := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	if interval == 24*time.Hour {
+		return nextMidnight
+	}
+
+	// interval doesn't divide the day evenly, next day starts from midnight
+	if next := midnight.Add((t.Sub(midnight)/interval + 1) * interval); next.Before(nextMidnight) {
+		return next
+	}
+	return nextMidnight
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// rotateFilename
+//___________________________________
+
+// rotateFilename is the file name template of rotated file. Supported
+// placeholders are
+//
+//	%Y - year, %m - month, %d - day
+//	%H - hour, %M - minute, %S - second, %L - millisecond
+//	%n - instance name, config `instance_name` default is hostname
+//	%i - sequence number, starts from 0 within same time
+//	%% - literal %
+//
+// If template doesn't have `%i`, sequence is added before extension from
+// second file onwards, for e.g.: `app-20180305.1.log`. Default template is
+// `<name>-%Y-%m-%d-%H-%M-%S.%L<ext>`.
+type rotateFilename struct {
+	template string
+	instance string
+	ext      string
+	hasSeq   bool
+	pattern  *regexp.Regexp
+	groups   []byte
+}
+
+// rotateTimeWidth is the digits width of time placeholders.
+var rotateTimeWidth = map[byte]int{'Y': 4, 'm': 2, 'd': 2, 'H': 2, 'M': 2, 'S': 2, 'L': 3}
+
+func newRotateFilename(filename, template, instance string) (*rotateFilename, error) {
+	if len(template) == 0 {
+		fileName := filepath.Base(filename)
+		template = ess.StripExt(fileName) + "-%Y-%m-%d-%H-%M-%S.%L" + filepath.Ext(fileName)
+	}
+	if strings.ContainsAny(template, `/\`) {
+		return nil, fmt.Errorf("log: rotate filename '%s' must not have directory", template)
+	}
+
+	r := &rotateFilename{template: template, instance: instance, ext: filepath.Ext(template)}
+	if strings.Contains(r.ext, "%") {
+		r.ext = ""
+	}
+	for i := 0; i < len(template)-1; i++ {
+		if template[i] == '%' {
+			i++
+			r.hasSeq = r.hasSeq || template[i] == 'i'
+		}
+	}
+
+	expr := new(bytes.Buffer)
+	expr.WriteByte('^')
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			// extension is matched at the end, after optional sequence
+			if !r.hasSeq && i == len(template)-len(r.ext) {
+				break
+			}
+			expr.WriteString(regexp.QuoteMeta(template[i : i+1]))
+			continue
+		}
+		if i++; i == len(template) {
+			return nil, fmt.Errorf("log: rotate filename '%s' ends with '%%'", template)
+		}
+		switch c := template[i]; c {
+		case '%':
+			expr.WriteString("%")
+		case 'n':
+			expr.WriteString(regexp.QuoteMeta(instance))
+		case 'i':
+			r.groups = append(r.groups, c)
+			expr.WriteString(`(\d+)`)
+		default:
+			width, found := rotateTimeWidth[c]
+			if !found {
+				return nil, fmt.Errorf("log: rotate filename '%s' has unknown placeholder '%%%c'", template, c)
+			}
+			r.groups = append(r.groups, c)
+			expr.WriteString(fmt.Sprintf(`(\d{%d})`, width))
+		}
+	}
+	if !r.hasSeq {
+		r.groups = append(r.groups, 'i')
+		expr.WriteString(`(?:\.(\d+))?` + regexp.QuoteMeta(r.ext))
+	}
+	expr.WriteByte('$')
+
+	var err error
+	r.pattern, err = regexp.Compile(expr.String())
+	return r, err
+}
+
+// format method returns the file name for given time and sequence.
+func (r *rotateFilename) format(t time.Time, seq int) string {
+	values := map[byte]int{
+		'Y': t.Year(), 'm': int(t.Month()), 'd': t.Day(),
+		'H': t.Hour(), 'M': t.Minute(), 'S': t.Second(), 'L': t.Nanosecond() / int(time.Millisecond),
+	}
+
+	buf := new(bytes.Buffer)
+	for i := 0; i < len(r.template); i++ {
+		if r.template[i] != '%' {
+			if !r.hasSeq && seq > 0 && i == len(r.template)-len(r.ext) {
+				buf.WriteString("." + strconv.Itoa(seq))
+			}
+			buf.WriteByte(r.template[i])
+			continue
+		}
+		i++
+		switch c := r.template[i]; c {
+		case '%':
+			buf.WriteByte('%')
+		case 'n':
+			buf.WriteString(r.instance)
+		case 'i':
+			buf.WriteString(strconv.Itoa(seq))
+		default:
+			buf.WriteString(fmt.Sprintf("%0*d", rotateTimeWidth[c], values[c]))
+		}
+	}
+	if !r.hasSeq && seq > 0 && len(r.ext) == 0 {
+		buf.WriteString("." + strconv.Itoa(seq))
+	}
+	return buf.String()
+}
+
+// sortKey method returns the chronological sort key of the file name, false
+// is returned if name doesn't match the template.
+func (r *rotateFilename) sortKey(name string) (string, bool) {
+	timeKey, seq, ok := r.parse(name)
+	return fmt.Sprintf("%s%09d", timeKey, seq), ok
+}
+
+// parse method returns the time values in order of year to millisecond
+// and sequence of the file name.
+func (r *rotateFilename) parse(name string) (string, int, bool) {
+	m := r.pattern.FindStringSubmatch(name)
+	if m == nil {
+		return "", 0, false
+	}
+
+	values := make(map[byte]string, len(r.groups))
+	for i, c := range r.groups {
+		values[c] = m[i+1]
+	}
+	timeKey := new(bytes.Buffer)
+	for _, c := range []byte("YmdHMSL") {
+		timeKey.WriteString(fmt.Sprintf("%0*s", rotateTimeWidth[c], values[c]))
 	}
-	return time.Now().Day()
+	seq, _ := strconv.Atoi(values['i'])
+	return timeKey.String(), seq, true
 }
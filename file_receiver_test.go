@@ -5,10 +5,23 @@
 package log
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
 	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
 	"aahframework.org/test.v0/assert"
 )
 
@@ -87,6 +100,708 @@ func TestFileLoggerRotation(t *testing.T) {
 	cleaupFiles("*.log")
 }
 
+func TestFileLoggerRotationBackups(t *testing.T) {
+	defer cleaupFiles("backups-aah*.log")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level %message"
+    file = "backups-aah.log"
+    rotate {
+      policy = "size"
+      size = "1kb"
+      backups = 3
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	// unrelated file is not pruned
+	assert.Nil(t, ioutil.WriteFile("backups-aah-error.log", []byte("error"), 0644))
+
+	for i := 0; i < 300; i++ {
+		logger.Infof("rotate me %03d", i)
+	}
+	logger.Close()
+
+	fr := logger.receiver.(*FileReceiver)
+	backups := fr.backupFiles()
+	assert.Equal(t, 3, len(backups))
+	assert.True(t, ess.IsFileExists("backups-aah-error.log"))
+
+	// newest backup has the latest entries
+	b, _ := ioutil.ReadFile(backups[2])
+	c, _ := ioutil.ReadFile(backups[1])
+	assert.True(t, string(b) > string(c))
+}
+
+func TestFileLoggerTimeRotation(t *testing.T) {
+	defer cleaupFiles("hourly-aah*.log")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level %message"
+    file = "hourly-aah.log"
+    rotate {
+      policy = "hourly"
+      timezone = "Asia/Kolkata"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.Info("first hour")
+
+	fr := logger.receiver.(*FileReceiver)
+	assert.Equal(t, time.Hour, fr.interval)
+	assert.Equal(t, "Asia/Kolkata", fr.nextRotate.Location().String())
+	assert.Equal(t, 0, fr.nextRotate.Minute())
+
+	// crossed the boundary
+	fr.nextRotate = time.Now().Add(-time.Second)
+	logger.Info("next hour")
+	assert.Equal(t, 1, len(fr.backupFiles()))
+	assert.True(t, fr.nextRotate.After(time.Now()))
+
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "hourly-aah.log", rotate { policy = "daily", timezone = "Mars/Base" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid rotate timezone 'Mars/Base': unknown time zone Mars/Base", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "hourly-aah.log", rotate { policy = "interval", interval = "10s" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: rotate interval '10s' is less than a minute", err.Error())
+}
+
+func TestFileNextRotateTime(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	testcases := []struct {
+		now      time.Time
+		interval time.Duration
+		expect   time.Time
+	}{
+		{time.Date(2018, 3, 5, 13, 20, 0, 0, time.UTC), time.Hour, time.Date(2018, 3, 5, 14, 0, 0, 0, time.UTC)},
+		{time.Date(2018, 3, 5, 13, 20, 0, 0, time.UTC), 24 * time.Hour, time.Date(2018, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2018, 3, 5, 13, 20, 0, 0, time.UTC), 6 * time.Hour, time.Date(2018, 3, 5, 18, 0, 0, 0, time.UTC)},
+		{time.Date(2018, 3, 5, 22, 20, 0, 0, time.UTC), 7 * time.Hour, time.Date(2018, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2018, 3, 5, 13, 20, 0, 0, time.UTC), 48 * time.Hour, time.Date(2018, 3, 7, 13, 20, 0, 0, time.UTC)},
+		// daylight saving day has 23 hours
+		{time.Date(2018, 3, 11, 12, 0, 0, 0, loc), 24 * time.Hour, time.Date(2018, 3, 12, 0, 0, 0, 0, loc)},
+	}
+	for _, tc := range testcases {
+		assert.True(t, tc.expect.Equal(nextRotateTime(tc.now, tc.interval)))
+	}
+}
+
+func TestFileLoggerRotationCompress(t *testing.T) {
+	defer cleaupFiles("compress-aah*")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level %message"
+    file = "compress-aah.log"
+    rotate {
+      policy = "size"
+      size = "1kb"
+      backups = 2
+      compress = "gzip"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	for i := 0; i < 200; i++ {
+		logger.Infof("compress me %03d", i)
+	}
+	logger.Close()
+
+	fr := logger.receiver.(*FileReceiver)
+	backups := fr.backupFiles()
+	assert.Equal(t, 2, len(backups))
+	for _, b := range backups {
+		assert.False(t, ess.IsFileExists(b))
+
+		gf, err := os.Open(b + ".gz")
+		assert.FailNowOnError(t, err, "compressed backup")
+		r, err := gzip.NewReader(gf)
+		assert.FailNowOnError(t, err, "gzip reader")
+		content, _ := ioutil.ReadAll(r)
+		assert.True(t, strings.HasPrefix(string(content), "INFO compress me "))
+		_ = gf.Close()
+	}
+
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "compress-aah.log", rotate { compress = "lz4" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unsupported rotate compress 'lz4'", err.Error())
+}
+
+func TestFileLoggerRetention(t *testing.T) {
+	defer cleaupFiles("retention-aah*.log")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level %message"
+    file = "retention-aah.log"
+    rotate {
+      policy = "size"
+      size = "1kb"
+      max_age = "7d"
+      max_total_size = "3kb"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	fr := logger.receiver.(*FileReceiver)
+	assert.Equal(t, 7*24*time.Hour, fr.maxAge)
+	assert.Equal(t, int64(3*1024), fr.maxTotalSize)
+
+	// expired backup
+	old := "retention-aah-2016-07-02-22-26-01.530.log"
+	assert.Nil(t, ioutil.WriteFile(old, []byte("old"), 0644))
+	oldTime := time.Now().Add(-8 * 24 * time.Hour)
+	assert.Nil(t, os.Chtimes(old, oldTime, oldTime))
+
+	for i := 0; i < 300; i++ {
+		logger.Infof("retain me %03d", i)
+	}
+	logger.Close()
+
+	assert.False(t, ess.IsFileExists(old))
+	var totalSize int64
+	for _, b := range fr.backupFiles() {
+		info, _ := os.Stat(b)
+		totalSize += info.Size()
+	}
+	assert.True(t, totalSize > 0 && totalSize <= 3*1024)
+
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "retention-aah.log", rotate { max_age = "week" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid rotate max_age 'week'", err.Error())
+}
+
+func TestFileLoggerReopen(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("open file cannot be moved on windows")
+	}
+	defer cleaupFiles("reopen-aah*.log")
+	cfg, _ := config.ParseString(`log { receiver = "file", pattern = "%message", file = "reopen-aah.log" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer logger.Close()
+
+	logger.Info("before move")
+	assert.Nil(t, os.Rename("reopen-aah.log", "reopen-aah-moved.log"))
+	logger.Info("still into moved file")
+	assert.Nil(t, logger.Reopen())
+	logger.Info("after reopen")
+
+	b, _ := ioutil.ReadFile("reopen-aah-moved.log")
+	assert.Equal(t, "before move \nstill into moved file \n", string(b))
+	b, _ = ioutil.ReadFile("reopen-aah.log")
+	assert.Equal(t, "after reopen \n", string(b))
+
+	// SIGHUP
+	assert.Nil(t, os.Rename("reopen-aah.log", "reopen-aah-moved.log"))
+	p, _ := os.FindProcess(os.Getpid())
+	assert.Nil(t, p.Signal(syscall.SIGHUP))
+	for i := 0; i < 100 && !ess.IsFileExists("reopen-aah.log"); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	logger.Info("after sighup")
+	b, _ = ioutil.ReadFile("reopen-aah.log")
+	assert.Equal(t, "after sighup \n", string(b))
+}
+
+func TestLogReopenSignal(t *testing.T) {
+	defer cleaupFiles("sighup-aah*.log")
+	testcases := []struct {
+		cfg      string
+		expected bool
+	}{
+		{cfg: `log { receiver = "console" }`},
+		{cfg: `log { receiver = "file", file = "sighup-aah.log", reopen_on_sighup = false }`},
+		{cfg: `log { receiver = "file", file = "sighup-aah.log" }`, expected: true},
+		{cfg: `log { file = "sighup-aah.log", receivers { console { }
+      file { } } }`, expected: true},
+	}
+	for _, tc := range testcases {
+		cfg, _ := config.ParseString(tc.cfg)
+		logger, err := New(cfg)
+		assert.FailNowOnError(t, err, "unexpected error")
+		assert.Equal(t, tc.expected, logger.reopenSig != nil)
+		logger.Close()
+	}
+}
+
+func TestFileLoggerSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink requires privilege on windows")
+	}
+	defer cleaupFiles("symlink-aah*")
+	assert.Nil(t, ioutil.WriteFile("symlink-aah.log", []byte("previous run\n"), 0644))
+
+	cfgStr := `
+  log {
+    receiver = "file"
+    pattern = "%message"
+    file = "symlink-aah.log"
+    rotate {
+      policy = "lines"
+      lines = 2
+      symlink = true
+    }
+  }`
+	cfg, _ := config.ParseString(cfgStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	fr := logger.receiver.(*FileReceiver)
+	target, err := os.Readlink("symlink-aah.log")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Base(fr.current), target)
+	assert.True(t, strings.HasPrefix(target, "symlink-aah-"))
+
+	// previous regular file is kept as backup
+	assert.Equal(t, 1, len(fr.backupFiles()))
+
+	logger.Info("line 1")
+	logger.Info("line 2")
+	logger.Info("line 3")
+	logger.Close()
+
+	target2, _ := os.Readlink("symlink-aah.log")
+	assert.NotEqual(t, target, target2)
+	b, _ := ioutil.ReadFile("symlink-aah.log")
+	assert.Equal(t, "line 3 \n", string(b))
+	b, _ = ioutil.ReadFile(target)
+	assert.Equal(t, "line 1 \nline 2 \n", string(b))
+	assert.Equal(t, 2, len(fr.backupFiles()))
+
+	// restart continues the current file
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.Info("line 4")
+	logger.Close()
+	b, _ = ioutil.ReadFile("symlink-aah.log")
+	assert.Equal(t, "line 3 \nline 4 \n", string(b))
+}
+
+func TestFileLoggerBuffer(t *testing.T) {
+	defer cleaupFiles("buffer-aah*.log")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level %message"
+    file = "buffer-aah.log"
+    file_buffer {
+      size = "4kb"
+      flush_interval = "50ms"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.Info("buffered")
+	b, _ := ioutil.ReadFile("buffer-aah.log")
+	assert.Equal(t, "", string(b))
+
+	logger.Flush()
+	b, _ = ioutil.ReadFile("buffer-aah.log")
+	assert.Equal(t, "INFO buffered \n", string(b))
+
+	// periodic flush
+	logger.Warn("flushed by interval")
+	time.Sleep(200 * time.Millisecond)
+	b, _ = ioutil.ReadFile("buffer-aah.log")
+	assert.Equal(t, "INFO buffered \nWARN flushed by interval \n", string(b))
+
+	// flushed on close
+	logger.Error("flushed on close")
+	logger.Close()
+	b, _ = ioutil.ReadFile("buffer-aah.log")
+	assert.True(t, strings.HasSuffix(string(b), "ERROR flushed on close \n"))
+
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "buffer-aah.log", file_buffer { size = "4kbs" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "format: invalid input '4kbs'", err.Error())
+}
+
+func TestFileLoggerFsync(t *testing.T) {
+	defer cleaupFiles("fsync-aah*.log")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level %message"
+    file = "fsync-aah.log"
+    file_buffer {
+      size = "4kb"
+      flush_interval = "1h"
+    }
+    fsync {
+      level = "error"
+      entries = 3
+      interval = "1h"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer logger.Close()
+
+	fr := logger.receiver.(*FileReceiver)
+	assert.Equal(t, LevelError, fr.syncLevel)
+
+	logger.Info("one")
+	b, _ := ioutil.ReadFile("fsync-aah.log")
+	assert.Equal(t, "", string(b))
+
+	// error entry is synced
+	logger.Error("two")
+	b, _ = ioutil.ReadFile("fsync-aah.log")
+	assert.Equal(t, "INFO one \nERROR two \n", string(b))
+
+	// every 3 entries
+	logger.Info("three")
+	logger.Info("four")
+	assert.Equal(t, int64(2), fr.unsynced)
+	logger.Info("five")
+	assert.Equal(t, int64(0), fr.unsynced)
+	b, _ = ioutil.ReadFile("fsync-aah.log")
+	assert.True(t, strings.HasSuffix(string(b), "INFO five \n"))
+
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "fsync-aah.log", fsync { level = "critical" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unknown fsync level 'critical'", err.Error())
+}
+
+func TestFileLoggerPermission(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix file mode")
+	}
+	defer func() { _ = os.RemoveAll("perm-logs") }()
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    file = "perm-logs/app/aah.log"
+    file_mode = "0640"
+    dir_mode = "0750"
+    file_uid = ` + strconv.Itoa(os.Getuid()) + `
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.Info("hardened")
+	logger.Close()
+
+	info, err := os.Stat("perm-logs/app/aah.log")
+	assert.FailNowOnError(t, err, "log file")
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	info, _ = os.Stat("perm-logs/app")
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "perm-logs/aah.log", file_mode = "0999" }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid file mode '0999' for 'log.file_mode'", err.Error())
+}
+
+func TestFileRotateFilename(t *testing.T) {
+	ts := time.Date(2018, 3, 5, 13, 20, 7, 45000000, time.UTC)
+	r, err := newRotateFilename("logs/aah.log", "", "node1")
+	assert.Nil(t, err)
+	assert.Equal(t, "aah-2018-03-05-13-20-07.045.log", r.format(ts, 0))
+	assert.Equal(t, "aah-2018-03-05-13-20-07.045.2.log", r.format(ts, 2))
+	key1, ok := r.sortKey("aah-2018-03-05-13-20-07.045.log")
+	assert.True(t, ok)
+	key2, _ := r.sortKey("aah-2018-03-05-13-20-07.045.2.log")
+	key3, _ := r.sortKey("aah-2018-03-05-13-20-07.045.10.log")
+	assert.True(t, key1 < key2 && key2 < key3)
+	_, ok = r.sortKey("aah-error.log")
+	assert.False(t, ok)
+
+	r, err = newRotateFilename("logs/aah.log", "app-%n-%d%m%Y-%H%M-%i.log", "node1")
+	assert.Nil(t, err)
+	assert.Equal(t, "app-node1-05032018-1320-0.log", r.format(ts, 0))
+	assert.Equal(t, "app-node1-05032018-1320-3.log", r.format(ts, 3))
+	key1, ok = r.sortKey("app-node1-31122017-2359-0.log")
+	assert.True(t, ok)
+	key2, _ = r.sortKey("app-node1-05032018-1320-3.log")
+	assert.True(t, key1 < key2)
+	_, ok = r.sortKey("app-node2-05032018-1320-3.log")
+	assert.False(t, ok)
+
+	r, _ = newRotateFilename("aah", "aah-%Y%%", "")
+	assert.Equal(t, "aah-2018%.1", r.format(ts, 1))
+	_, ok = r.sortKey("aah-2018%.1")
+	assert.True(t, ok)
+
+	_, err = newRotateFilename("aah.log", "aah-%Q.log", "")
+	assert.Equal(t, "log: rotate filename 'aah-%Q.log' has unknown placeholder '%Q'", err.Error())
+	_, err = newRotateFilename("aah.log", "archive/aah-%Y.log", "")
+	assert.Equal(t, "log: rotate filename 'archive/aah-%Y.log' must not have directory", err.Error())
+	_, err = newRotateFilename("aah.log", "aah-%", "")
+	assert.Equal(t, "log: rotate filename 'aah-%' ends with '%'", err.Error())
+}
+
+func TestFileLoggerRotateFilename(t *testing.T) {
+	defer cleaupFiles("tmpl-*.log")
+	cfg, _ := config.ParseString(`
+  instance_name = "node1"
+  log {
+    receiver = "file"
+    pattern = "%message"
+    file = "tmpl-aah.log"
+    rotate {
+      policy = "lines"
+      lines = 1
+      filename = "tmpl-%n-%Y%m%d-%i.log"
+      backups = 2
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	for i := 0; i < 5; i++ {
+		logger.Infof("line %d", i)
+	}
+	logger.Close()
+
+	backups := logger.receiver.(*FileReceiver).backupFiles()
+	assert.Equal(t, 2, len(backups))
+	day := time.Now().Format("20060102")
+	assert.Equal(t, "tmpl-node1-"+day+"-2.log", backups[0])
+	assert.Equal(t, "tmpl-node1-"+day+"-3.log", backups[1])
+	b, _ := ioutil.ReadFile(backups[1])
+	assert.Equal(t, "line 3 \n", string(b))
+}
+
+func TestFileLoggerRecovery(t *testing.T) {
+	defer cleaupFiles("recovery-aah*.log")
+	assert.Nil(t, ioutil.WriteFile("recovery-aah.log", []byte(`{"message":"ok"}`+"\n"+`{"message":"to`), 0644))
+
+	cfg, _ := config.ParseString(`log { receiver = "file", format = "json", file = "recovery-aah.log" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	b, _ := ioutil.ReadFile("recovery-aah.log")
+	assert.Equal(t, `{"message":"ok"}`+"\n", string(b))
+
+	// partial write, next entry starts in new line
+	fr := logger.receiver.(*FileReceiver)
+	w := &tornWriter{}
+	fr.SetWriter(w)
+	logger.Info("torn")
+	assert.True(t, fr.isTorn)
+	logger.Info("whole")
+	assert.False(t, fr.isTorn)
+	assert.True(t, strings.HasPrefix(w.String(), "{"))
+	assert.True(t, strings.Contains(w.String(), "\n{"))
+	logger.Close()
+
+	// file without newline and large file
+	assert.Nil(t, ioutil.WriteFile("recovery-aah.log", []byte("torn"), 0644))
+	assert.Nil(t, repairLastLine("recovery-aah.log"))
+	b, _ = ioutil.ReadFile("recovery-aah.log")
+	assert.Equal(t, "", string(b))
+
+	content := strings.Repeat("0123456789\n", 1000) + strings.Repeat("x", 5000)
+	assert.Nil(t, ioutil.WriteFile("recovery-aah.log", []byte(content), 0644))
+	assert.Nil(t, repairLastLine("recovery-aah.log"))
+	b, _ = ioutil.ReadFile("recovery-aah.log")
+	assert.Equal(t, 11000, len(b))
+}
+
+// tornWriter writes the half of the first entry and fails.
+type tornWriter struct {
+	bytes.Buffer
+	failed bool
+}
+
+func (w *tornWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		w.failed = true
+		_, _ = w.Buffer.Write(p[:len(p)/2])
+		return len(p) / 2, errors.New("no space left on device")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestFileLoggerDiskGuard(t *testing.T) {
+	defer cleaupFiles("diskguard-aah*.log")
+	actual, err := diskFreeSpace(".")
+	assert.Nil(t, err)
+	assert.True(t, actual > 0)
+
+	free := uint64(1 << 30)
+	freeDiskSpace = func(path string) (uint64, error) { return free, nil }
+	defer func() { freeDiskSpace = diskFreeSpace }()
+
+	newLogger := func(action string) (*Logger, *FileReceiver) {
+		cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level %message"
+    file = "diskguard-aah.log"
+    disk_guard {
+      min_free = "100mb"
+      action = "` + action + `"
+      check_interval = "1h"
+    }
+  }`)
+		logger, err := New(cfg)
+		assert.FailNowOnError(t, err, "unexpected error")
+		return logger, logger.receiver.(*FileReceiver)
+	}
+
+	// drop debug, app is notified
+	logger, fr := newLogger("drop_debug")
+	notified := make(chan uint64, 1)
+	logger.OnLowDiskSpace(func(filename string, free, threshold uint64) {
+		assert.Equal(t, "diskguard-aah.log", filename)
+		assert.Equal(t, uint64(100*1024*1024), threshold)
+		notified <- free
+	})
+	free = 1 << 20
+	fr.checkDiskSpace()
+	assert.Equal(t, uint64(1<<20), <-notified)
+	logger.Debug("dropped")
+	logger.Error("written")
+	logger.Close()
+	b, _ := ioutil.ReadFile("diskguard-aah.log")
+	assert.Equal(t, "ERROR written \n", string(b))
+
+	// rotate, notified on registration since disk is already low
+	logger, fr = newLogger("rotate")
+	logger.OnLowDiskSpace(func(filename string, free, threshold uint64) { notified <- free })
+	assert.Equal(t, uint64(1<<20), <-notified)
+	assert.True(t, fr.isLowDisk)
+	assert.Equal(t, 1, len(fr.backupFiles()))
+	logger.Close()
+
+	// console
+	free = 1 << 30
+	logger, fr = newLogger("console")
+	assert.False(t, fr.isLowDisk)
+	free = 0
+	fr.checkDiskSpace()
+	logger.Info("into stderr")
+	logger.Close()
+	b, _ = ioutil.ReadFile("diskguard-aah.log")
+	assert.Equal(t, "", string(b))
+
+	cfg, _ := config.ParseString(`log { receiver = "file", file = "diskguard-aah.log", disk_guard { min_free = "1gb", action = "panic" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unsupported disk_guard action 'panic'", err.Error())
+}
+
+func TestFileLoggerOnRotate(t *testing.T) {
+	defer cleaupFiles("onrotate-aah*")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%message"
+    file = "onrotate-aah.log"
+    rotate {
+      policy = "lines"
+      lines = 1
+      compress = "gzip"
+      backups = 1
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	var mu sync.Mutex
+	rotated := make(map[string]bool)
+	logger.OnRotate(func(oldPath, newPath string) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "onrotate-aah.log", newPath)
+		assert.True(t, strings.HasSuffix(oldPath, ".log.gz"))
+
+		// completed file is available before pruning
+		assert.True(t, ess.IsFileExists(oldPath))
+		rotated[oldPath] = true
+	})
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+	logger.Close()
+
+	assert.Equal(t, 2, len(rotated))
+	assert.Equal(t, 1, len(logger.receiver.(*FileReceiver).backupFiles()))
+}
+
+func TestFileLoggerOnRotateLog(t *testing.T) {
+	defer cleaupFiles("onrotatelog-aah*")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%message"
+    file = "onrotatelog-aah.log"
+    rotate {
+      policy = "lines"
+      lines = 1
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	var cnt int32
+	logger.OnRotate(func(oldPath, newPath string) {
+		if atomic.AddInt32(&cnt, 1) == 1 {
+			time.Sleep(50 * time.Millisecond)
+			logger.Infof("rotated %s", oldPath)
+		}
+	})
+	logger.Info("one")
+	logger.Info("two")
+
+	done := make(chan struct{})
+	go func() {
+		logger.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("file receiver close deadlocked")
+	}
+	assert.True(t, atomic.LoadInt32(&cnt) >= 1)
+}
+
+func TestFileLoggerSplit(t *testing.T) {
+	defer cleaupFiles("split-aah*")
+	cfg, _ := config.ParseString(`
+  log {
+    receiver = "file"
+    pattern = "%level:-5 %message"
+    file = "split-aah.log"
+    split {
+      error = "split-aah-error.log"
+      warn = "split-aah-warn.log"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.NotNil(t, logger.reopenSig)
+
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	exit = func(code int) {}
+	logger.Fatal("fatal")
+	exit = os.Exit
+	logger.Close()
+	assert.Nil(t, logger.reopenSig)
+
+	b, _ := ioutil.ReadFile("split-aah.log")
+	assert.Equal(t, "INFO  info \n", string(b))
+	b, _ = ioutil.ReadFile("split-aah-warn.log")
+	assert.Equal(t, "WARN  warn \n", string(b))
+	b, _ = ioutil.ReadFile("split-aah-error.log")
+	assert.Equal(t, "ERROR error \nFATAL fatal \n", string(b))
+
+	cfg, _ = config.ParseString(`log { receiver = "file", file = "split-aah.log", split { critical = "split-aah-critical.log" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unknown split level 'critical'", err.Error())
+}
+
 func TestFileLoggerFileOpenError(t *testing.T) {
 	fileConfigStr := `
   log {
@@ -0,0 +1,114 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"aahframework.org/config.v0"
+)
+
+// filterOps are the supported operators of filter rule, order matters
+// for parsing.
+var filterOps = []string{"==", "!=", "=~", "!~"}
+
+// filterRule drops the log entry which matches the rule, it's configured
+// as list `log.filters` and entry is dropped if any of the rule matches.
+// Rule is `<target> <operator> <value>`, target is `message`, `level` or
+// `fields.<name>` and operator is `==`, `!=`, `=~` (regex match) or `!~`.
+// Value can be quoted with single or double quotes. For e.g.:
+//
+//	log {
+//	  filters = [
+//	    "message =~ 'health check'",
+//	    "fields.path == '/ping'"
+//	  ]
+//	}
+type filterRule struct {
+	field string
+	op    string
+	value string
+	regex *regexp.Regexp
+}
+
+func newFilterRules(cfg *config.Config) ([]*filterRule, error) {
+	exprs, _ := cfg.StringList("log.filters")
+	rules := make([]*filterRule, 0, len(exprs))
+	for _, expr := range exprs {
+		rule, err := parseFilterRule(expr)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseFilterRule(expr string) (*filterRule, error) {
+	idx, op := -1, ""
+	for _, o := range filterOps {
+		if i := strings.Index(expr, o); i > 0 && (idx == -1 || i < idx) {
+			idx, op = i, o
+		}
+	}
+	if idx == -1 {
+		return nil, fmt.Errorf("log: invalid filter rule '%s'", expr)
+	}
+
+	rule := &filterRule{
+		field: strings.TrimSpace(expr[:idx]),
+		op:    op,
+		value: unquote(strings.TrimSpace(expr[idx+len(op):])),
+	}
+	if rule.field != "message" && rule.field != "level" &&
+		(!strings.HasPrefix(rule.field, "fields.") || len(rule.field) == len("fields.")) {
+		return nil, fmt.Errorf("log: unknown filter rule target '%s' in '%s'", rule.field, expr)
+	}
+	if op == "=~" || op == "!~" {
+		regex, err := regexp.Compile(rule.value)
+		if err != nil {
+			return nil, fmt.Errorf("log: invalid filter rule regex '%s': %v", rule.value, err)
+		}
+		rule.regex = regex
+	}
+	return rule, nil
+}
+
+// match method returns true if entry matches the rule.
+func (r *filterRule) match(e *Entry) bool {
+	var value string
+	switch r.field {
+	case "message":
+		value = e.Message
+	case "level":
+		value = e.Level.String()
+		if r.regex == nil {
+			return strings.EqualFold(value, r.value) == (r.op == "==")
+		}
+	default:
+		value = e.Fields.str(r.field[len("fields."):])
+	}
+
+	switch r.op {
+	case "==":
+		return value == r.value
+	case "!=":
+		return value != r.value
+	case "=~":
+		return r.regex.MatchString(value)
+	default:
+		return !r.regex.MatchString(value)
+	}
+}
+
+// unquote method removes the surrounding single or double quotes.
+func unquote(s string) string {
+	if len(s) > 1 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
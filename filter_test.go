@@ -0,0 +1,105 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogFilters(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message"
+    filters = [
+      "message =~ 'health check'",
+      "fields.path == \"/ping\"",
+      "level == 'debug'",
+      "fields.user !~ ^admin"
+    ]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.WithField("user", "admin").Info("GET health check ok")
+	logger.WithField("user", "admin").WithField("path", "/ping").Info("request")
+	logger.WithField("user", "admin").Debug("debug entry")
+	logger.WithField("user", "guest").Info("guest entry")
+	logger.WithField("user", "admin1").WithField("path", "/pingx").Info("kept entry")
+	assert.Equal(t, "INFO  kept entry \n", buf.String())
+}
+
+func TestLogAddFilter(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	var calls []string
+	err = logger.AddFilter("tenant", func(e *Entry) bool {
+		calls = append(calls, "tenant")
+		return e.Fields["tenant"] != "sandbox"
+	})
+	assert.Nil(t, err)
+	err = logger.AddFilter("feature", func(e *Entry) bool {
+		calls = append(calls, "feature")
+		return e.Message != "beta feature"
+	})
+	assert.Nil(t, err)
+
+	logger.WithField("tenant", "sandbox").Info("sandbox entry")
+	assert.Equal(t, []string{"tenant"}, calls)
+	logger.Info("beta feature")
+	logger.WithField("tenant", "acme").Info("acme entry")
+	assert.Equal(t, "INFO  acme entry \n", buf.String())
+	assert.Equal(t, []string{"tenant", "tenant", "feature", "tenant", "feature"}, calls)
+
+	// Already added, empty name and nil func
+	err = logger.AddFilter("tenant", func(e *Entry) bool { return true })
+	assert.Equal(t, "log: filter name 'tenant' is already added, skip it", err.Error())
+	err = logger.AddFilter("", func(e *Entry) bool { return true })
+	assert.Equal(t, "log: filter name is empty", err.Error())
+	assert.Equal(t, ErrFilterFuncIsNil, logger.AddFilter("nilfilter", nil))
+
+	logger.RemoveFilter("feature")
+	buf.Reset()
+	logger.Info("beta feature")
+	assert.Equal(t, "INFO  beta feature \n", buf.String())
+}
+
+func TestFilterRule(t *testing.T) {
+	rule, err := parseFilterRule(`level != WARN`)
+	assert.Nil(t, err)
+	assert.Equal(t, "level", rule.field)
+	assert.Equal(t, "!=", rule.op)
+	assert.True(t, rule.match(&Entry{Level: LevelError}))
+	assert.False(t, rule.match(&Entry{Level: LevelWarn}))
+
+	rule, _ = parseFilterRule(`message != "a == b"`)
+	assert.Equal(t, "a == b", rule.value)
+	assert.False(t, rule.match(&Entry{Message: "a == b"}))
+
+	for expr, msg := range map[string]string{
+		"message":              "log: invalid filter rule 'message'",
+		"== 'x'":               "log: invalid filter rule '== 'x''",
+		"file == 'x.go'":       "log: unknown filter rule target 'file' in 'file == 'x.go''",
+		"fields. == 'x'":       "log: unknown filter rule target 'fields.' in 'fields. == 'x''",
+		"message =~ '(health'": "log: invalid filter rule regex '(health': error parsing regexp: missing closing ): `(health`",
+	} {
+		_, err = parseFilterRule(expr)
+		assert.Equal(t, msg, err.Error())
+	}
+
+	cfg, _ := config.ParseString(`log { filters = ["level"] }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid filter rule 'level'", err.Error())
+}
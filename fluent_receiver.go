@@ -0,0 +1,355 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var (
+	// ErrFluentAckMismatch returned when Fluentd acknowledgement does not
+	// match with sent chunk.
+	ErrFluentAckMismatch = errors.New("log: fluent ack mismatch")
+
+	_ Receiver = (*FluentReceiver)(nil)
+)
+
+// FluentReceiver forwards the log entry to Fluentd or Fluent Bit using
+// forward protocol (MessagePack over TCP). Entries are batched per tag in
+// forward mode, optionally acknowledged and buffered while aggregator is
+// unreachable.
+type FluentReceiver struct {
+	errorReporter
+	protocol      string
+	address       string
+	tag           string
+	requireAck    bool
+	timeout       time.Duration
+	batchSize     int
+	flushInterval time.Duration
+	bufferSize    int
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	backoff       time.Duration
+	nextDial      time.Time
+	conn          net.Conn
+	reader        *bufio.Reader
+	queue         chan *fluentEvent
+	pending       []*fluentEvent
+	done          chan struct{}
+	out           io.Writer
+	formatter     string
+	flags         []ess.FmtFlagPart
+	isCallerInfo  bool
+	mu            sync.Mutex
+	wg            sync.WaitGroup
+}
+
+type fluentEvent struct {
+	tag    string
+	time   time.Time
+	record map[string]interface{}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// FluentReceiver methods
+//___________________________________
+
+// Init method initializes the Fluentd forward receiver instance.
+func (f *FluentReceiver) Init(cfg *config.Config) error {
+	f.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(f.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", f.formatter)
+	}
+
+	f.protocol = strings.ToLower(cfg.StringDefault("log.fluent.protocol", "tcp"))
+	if !(f.protocol == "tcp" || f.protocol == "unix") {
+		return fmt.Errorf("log: unsupported fluent protocol '%s'", f.protocol)
+	}
+	f.address = cfg.StringDefault("log.fluent.address", "127.0.0.1:24224")
+	f.tag = cfg.StringDefault("log.fluent.tag", "aah.{level}")
+	if ess.IsStrEmpty(f.tag) {
+		return errors.New("log: fluent tag is required")
+	}
+
+	f.requireAck = cfg.BoolDefault("log.fluent.require_ack", false)
+	f.batchSize = cfg.IntDefault("log.fluent.batch_size", 100)
+	if f.batchSize <= 0 {
+		f.batchSize = 1
+	}
+	f.bufferSize = cfg.IntDefault("log.fluent.buffer_size", 10000)
+	queueSize := cfg.IntDefault("log.fluent.queue_size", 1000)
+
+	var err error
+	if f.timeout, err = parseDuration(cfg, "log.fluent.timeout", "5s"); err != nil {
+		return err
+	}
+	if f.flushInterval, err = parseInterval(cfg, "log.fluent.flush_interval", "1s"); err != nil {
+		return err
+	}
+	if f.minBackoff, err = parseDuration(cfg, "log.fluent.reconnect.min_backoff", "500ms"); err != nil {
+		return err
+	}
+	if f.maxBackoff, err = parseDuration(cfg, "log.fluent.reconnect.max_backoff", "30s"); err != nil {
+		return err
+	}
+
+	f.mu = sync.Mutex{}
+	f.queue = make(chan *fluentEvent, queueSize)
+	f.done = make(chan struct{})
+	f.SetWriter(writerFunc(func(p []byte) (int, error) {
+		f.enqueue(&fluentEvent{
+			tag:    expandEntryTemplate(f.tag, &Entry{Level: LevelInfo}),
+			time:   time.Now(),
+			record: map[string]interface{}{"message": string(bytes.TrimRight(p, "\n"))},
+		})
+		return len(p), nil
+	}))
+
+	// initial connection failure is not fatal, entries are buffered till
+	// aggregator becomes reachable
+	_ = f.connect()
+
+	f.wg.Add(1)
+	go f.run()
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (f *FluentReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	f.flags = flags
+	if f.formatter != jsonFmt {
+		f.isCallerInfo = isCallerInfo(f.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into Fluentd receiver.
+func (f *FluentReceiver) SetWriter(w io.Writer) {
+	f.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (f *FluentReceiver) IsCallerInfo() bool {
+	return f.isCallerInfo
+}
+
+// Log method queues the log entry to be forwarded. Record has `level`,
+// `message` and entry fields.
+func (f *FluentReceiver) Log(entry *Entry) {
+	record := make(map[string]interface{}, len(entry.Fields)+4)
+	for k, v := range entry.Fields {
+		record[k] = v
+	}
+	record["level"] = entry.Level.String()
+	record["message"] = string(bytes.TrimRight(formatEntry(f.formatter, f.flags, entry), " \n"))
+	if len(entry.File) > 0 {
+		record["file"], record["line"] = entry.File, entry.Line
+	}
+
+	if !f.enqueue(&fluentEvent{tag: expandEntryTemplate(f.tag, entry), time: entry.Time, record: record}) {
+		f.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (f *FluentReceiver) Writer() io.Writer {
+	return f.out
+}
+
+// Close method forwards the queued entries and closes the connection.
+func (f *FluentReceiver) Close() {
+	close(f.done)
+	f.wg.Wait()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn != nil {
+		ess.CloseQuietly(f.conn)
+		f.conn = nil
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// FluentReceiver Unexported methods
+//___________________________________
+
+// enqueue method queues the event, returns false if queue is full and
+// event is dropped.
+func (f *FluentReceiver) enqueue(e *fluentEvent) bool {
+	select {
+	case f.queue <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f *FluentReceiver) run() {
+	defer f.wg.Done()
+	ticker := time.NewTicker(f.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*fluentEvent, 0, f.batchSize)
+	for {
+		select {
+		case e := <-f.queue:
+			if batch = append(batch, e); len(batch) >= f.batchSize {
+				f.flush(batch)
+				batch = make([]*fluentEvent, 0, f.batchSize)
+			}
+		case <-ticker.C:
+			// pending events are retried on every tick
+			f.flush(batch)
+			batch = make([]*fluentEvent, 0, f.batchSize)
+		case <-f.done:
+			for {
+				select {
+				case e := <-f.queue:
+					batch = append(batch, e)
+				default:
+					f.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush method forwards the pending and given events grouped by tag, on
+// failure undelivered events are kept for next flush.
+func (f *FluentReceiver) flush(batch []*fluentEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	events := append(f.pending, batch...)
+	f.pending = nil
+	if len(events) == 0 {
+		return
+	}
+
+	if f.conn == nil {
+		if time.Now().Before(f.nextDial) {
+			f.keepPending(events)
+			return
+		}
+		if err := f.connect(); err != nil {
+			f.reportError(err, nil)
+			f.keepPending(events)
+			return
+		}
+	}
+
+	var tags []string
+	byTag := make(map[string][]*fluentEvent)
+	for _, e := range events {
+		if _, found := byTag[e.tag]; !found {
+			tags = append(tags, e.tag)
+		}
+		byTag[e.tag] = append(byTag[e.tag], e)
+	}
+
+	for i, tag := range tags {
+		if err := f.forward(tag, byTag[tag]); err != nil {
+			f.reportError(err, nil)
+			ess.CloseQuietly(f.conn)
+			f.conn = nil
+			f.scheduleDial()
+			for _, t := range tags[i:] {
+				f.keepPending(byTag[t])
+			}
+			return
+		}
+	}
+}
+
+// forward method sends the events in forward mode
+// 	[tag, [[time, record], ...], {"chunk": "<id>"}]
+func (f *FluentReceiver) forward(tag string, events []*fluentEvent) error {
+	e := &msgpackEncoder{}
+	e.arrayHeader(3)
+	e.string(tag)
+	e.arrayHeader(len(events))
+	for _, ev := range events {
+		e.arrayHeader(2)
+		e.eventTime(ev.time)
+		e.encode(ev.record)
+	}
+
+	var chunk string
+	if f.requireAck {
+		b := make([]byte, 16)
+		_, _ = rand.Read(b)
+		chunk = base64.StdEncoding.EncodeToString(b)
+		e.encode(map[string]interface{}{"chunk": chunk, "size": len(events)})
+	} else {
+		e.encode(map[string]interface{}{"size": len(events)})
+	}
+
+	_ = f.conn.SetDeadline(time.Now().Add(f.timeout))
+	if _, err := f.conn.Write(e.Bytes()); err != nil {
+		return err
+	}
+
+	if f.requireAck {
+		resp, err := decodeMsgpackStringMap(f.reader)
+		if err != nil {
+			return err
+		}
+		if resp["ack"] != chunk {
+			return ErrFluentAckMismatch
+		}
+	}
+	return nil
+}
+
+// keepPending method keeps the events for retry, oldest events are dropped
+// beyond buffer size.
+func (f *FluentReceiver) keepPending(events []*fluentEvent) {
+	f.pending = append(f.pending, events...)
+	if over := len(f.pending) - f.bufferSize; over > 0 {
+		f.pending = f.pending[over:]
+	}
+}
+
+func (f *FluentReceiver) connect() error {
+	conn, err := net.DialTimeout(f.protocol, f.address, f.timeout)
+	if err != nil {
+		f.scheduleDial()
+		return err
+	}
+	f.conn = conn
+	f.reader = bufio.NewReader(conn)
+	f.backoff = 0
+	return nil
+}
+
+func (f *FluentReceiver) scheduleDial() {
+	if f.backoff == 0 {
+		f.backoff = f.minBackoff
+	} else if f.backoff *= 2; f.backoff > f.maxBackoff {
+		f.backoff = f.maxBackoff
+	}
+	f.nextDial = time.Now().Add(f.backoff)
+}
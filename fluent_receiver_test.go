@@ -0,0 +1,227 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestFluentLogger(t *testing.T) {
+	server := newTestFluentServer(t)
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "fluent"
+    pattern = "%%level:-5 %%message"
+    fluent {
+      address = "%s"
+      tag = "aah.{appname}.{level}"
+      require_ack = true
+      flush_interval = "50ms"
+    }
+  }
+  `, server.addr)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("appname", "myapp").Info("Yes, I would love to see")
+	logger.WithField("appname", "myapp").Info("Yes, info again")
+	logger.WithFields(Fields{"appname": "myapp", "key1": "value1"}).Error("Yes, yes, yes - finally an error")
+	logger.Debug("I would like to see this message, debug is useful for dev")
+	logger.receiver.(*FluentReceiver).Close()
+
+	msgs := server.Messages()
+	assert.Equal(t, 3, len(msgs))
+
+	assert.Equal(t, "aah.myapp.info", msgs[0].tag)
+	assert.Equal(t, 2, len(msgs[0].records))
+	assert.Equal(t, "INFO  Yes, I would love to see", msgs[0].records[0]["message"])
+	assert.Equal(t, "INFO", msgs[0].records[1]["level"])
+	assert.True(t, len(msgs[0].chunk) > 0)
+
+	assert.Equal(t, "aah.myapp.error", msgs[1].tag)
+	assert.Equal(t, "value1", msgs[1].records[0]["key1"])
+	assert.Equal(t, "myapp", msgs[1].records[0]["appname"])
+
+	assert.Equal(t, "aah.-.debug", msgs[2].tag)
+}
+
+func TestFluentLoggerBuffering(t *testing.T) {
+	server := newTestFluentServer(t)
+	addr := server.addr
+	server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "fluent"
+    fluent {
+      address = "%s"
+      tag = "aah.logs"
+      flush_interval = "20ms"
+      buffer_size = 2
+      reconnect {
+        min_backoff = "10ms"
+        max_backoff = "20ms"
+      }
+    }
+  }
+  `, addr)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*FluentReceiver)
+
+	logger.Info("first entry, dropped")
+	logger.Info("second entry")
+	logger.Info("third entry")
+	time.Sleep(100 * time.Millisecond)
+
+	receiver.mu.Lock()
+	assert.Equal(t, 2, len(receiver.pending))
+	receiver.mu.Unlock()
+
+	server = newTestFluentServerAt(t, addr)
+	defer server.Close()
+	time.Sleep(150 * time.Millisecond)
+	receiver.Close()
+
+	msgs := server.Messages()
+	assert.Equal(t, 1, len(msgs))
+	assert.Equal(t, 2, len(msgs[0].records))
+	assert.True(t, strings.HasSuffix(msgs[0].records[0]["message"].(string), "second entry"))
+	assert.True(t, strings.HasSuffix(msgs[0].records[1]["message"].(string), "third entry"))
+}
+
+func TestFluentLoggerAckMismatch(t *testing.T) {
+	server := newTestFluentServer(t)
+	defer server.Close()
+	server.badAck = true
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log { receiver = "fluent", fluent { address = "%s", require_ack = true, flush_interval = "1h" } }`, server.addr))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*FluentReceiver)
+
+	receiver.mu.Lock()
+	err = receiver.forward("aah.logs", []*fluentEvent{
+		{tag: "aah.logs", time: time.Now(), record: map[string]interface{}{"message": "not acked"}},
+	})
+	receiver.mu.Unlock()
+	assert.Equal(t, ErrFluentAckMismatch, err)
+	receiver.Close()
+}
+
+func TestFluentLoggerUnsupported(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "fluent", fluent { protocol = "udp" } }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: unsupported fluent protocol 'udp'", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "fluent", fluent { tag = "" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: fluent tag is required", err.Error())
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Fake Fluentd forward server
+//___________________________________
+
+type testFluentMessage struct {
+	tag     string
+	records []map[string]interface{}
+	chunk   string
+}
+
+type testFluentServer struct {
+	t      *testing.T
+	addr   string
+	ln     net.Listener
+	badAck bool
+	msgs   []testFluentMessage
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+}
+
+func newTestFluentServer(t *testing.T) *testFluentServer {
+	return newTestFluentServerAt(t, "127.0.0.1:0")
+}
+
+func newTestFluentServerAt(t *testing.T, addr string) *testFluentServer {
+	ln, err := net.Listen("tcp", addr)
+	assert.FailNowOnError(t, err, "unable to listen")
+	s := &testFluentServer{t: t, addr: ln.Addr().String(), ln: ln}
+	s.wg.Add(1)
+	go s.accept()
+	return s
+}
+
+func (s *testFluentServer) Messages() []testFluentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]testFluentMessage{}, s.msgs...)
+}
+
+func (s *testFluentServer) Close() {
+	_ = s.ln.Close()
+	s.wg.Wait()
+}
+
+func (s *testFluentServer) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+func (s *testFluentServer) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		v, err := decodeTestMsgpack(r)
+		if err != nil {
+			return
+		}
+
+		// [tag, [[time, record], ...], option]
+		msg := v.([]interface{})
+		m := testFluentMessage{tag: msg[0].(string)}
+		for _, entry := range msg[1].([]interface{}) {
+			m.records = append(m.records, entry.([]interface{})[1].(map[string]interface{}))
+		}
+		if opt, ok := msg[2].(map[string]interface{}); ok {
+			m.chunk, _ = opt["chunk"].(string)
+		}
+
+		s.mu.Lock()
+		s.msgs = append(s.msgs, m)
+		s.mu.Unlock()
+
+		if len(m.chunk) > 0 {
+			ack := m.chunk
+			if s.badAck {
+				ack = "unknown"
+			}
+			e := &msgpackEncoder{}
+			e.encode(map[string]string{"ack": ack})
+			_, _ = conn.Write(e.Bytes())
+		}
+	}
+}
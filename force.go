@@ -0,0 +1,123 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"aahframework.org/essentials.v0"
+)
+
+// ForceTrace method marks the given request ID to be logged at TRACE
+// regardless of logger, package and receiver level, so the requests of
+// single user can be traced in production. Request ID is matched against
+// the entry field `reqid`, it's applied to the entries logged via
+// `WithField` and `WithFields`. For e.g.:
+//
+//	logger.ForceTrace("5a6ab4d0-7b4f-4fb6-9b5c-d1b37c8b5f36")
+//	defer logger.UnforceTrace("5a6ab4d0-7b4f-4fb6-9b5c-d1b37c8b5f36")
+func (l *Logger) ForceTrace(requestID string) {
+	if ess.IsStrEmpty(requestID) {
+		return
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.forcedIDs == nil {
+		l.forcedIDs = make(map[string]bool)
+	}
+	l.forcedIDs[requestID] = true
+	l.updateForced()
+}
+
+// UnforceTrace method removes the given request ID marked by `ForceTrace`.
+func (l *Logger) UnforceTrace(requestID string) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	delete(l.forcedIDs, requestID)
+	l.updateForced()
+}
+
+// ForceTraceIf method adds the named predicate, entry which the predicate
+// returns true is logged at TRACE regardless of logger, package and receiver
+// level. Predicate is called before the entry is formatted. For e.g.:
+//
+//	_ = logger.ForceTraceIf("tenant", func(e *log.Entry) bool {
+//		return e.Fields["tenant"] == "acme"
+//	})
+func (l *Logger) ForceTraceIf(name string, predicate FilterFunc) error {
+	if ess.IsStrEmpty(name) {
+		return errors.New("log: force trace name is empty")
+	}
+	if predicate == nil {
+		return ErrFilterFuncIsNil
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	for _, f := range l.forcedFns {
+		if f.name == name {
+			return fmt.Errorf("log: force trace name '%v' is already added, skip it", name)
+		}
+	}
+
+	forcedFns := make([]entryFilter, len(l.forcedFns), len(l.forcedFns)+1)
+	copy(forcedFns, l.forcedFns)
+	l.forcedFns = append(forcedFns, entryFilter{name: name, fn: predicate})
+	l.updateForced()
+	return nil
+}
+
+// UnforceTraceIf method removes the named predicate added by `ForceTraceIf`.
+func (l *Logger) UnforceTraceIf(name string) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	forcedFns := make([]entryFilter, 0, len(l.forcedFns))
+	for _, f := range l.forcedFns {
+		if f.name != name {
+			forcedFns = append(forcedFns, f)
+		}
+	}
+	l.forcedFns = forcedFns
+	l.updateForced()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// updateForced method updates the count of force trace request IDs and
+// predicates, it's checked first to skip the lookup. Caller holds the lock.
+func (l *Logger) updateForced() {
+	atomic.StoreInt32(&l.forced, int32(len(l.forcedIDs)+len(l.forcedFns)))
+}
+
+// isForced method returns true if the entry is marked to log at TRACE.
+func (l *Logger) isForced(e *Entry) bool {
+	if atomic.LoadInt32(&l.forced) == 0 {
+		return false
+	}
+
+	l.m.RLock()
+	defer l.m.RUnlock()
+	if len(l.forcedIDs) > 0 && l.forcedIDs[e.Fields.str("reqid")] {
+		return true
+	}
+	for _, f := range l.forcedFns {
+		if f.fn(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnabled method returns true if given level is enabled for the caller
+// package or the entry is marked to log at TRACE. Marked entry is
+// dispatched to all the receivers regardless of its level.
+func (e *Entry) isEnabled(lvl level) bool {
+	return e.logger.isEnabled(lvl) || (lvl <= LevelTrace && e.logger.isForced(e))
+}
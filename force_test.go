@@ -0,0 +1,74 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogForceTrace(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "warn"
+    pattern = "%level:-5 %message"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.ForceTrace("req-1")
+	logger.ForceTrace("")
+	logger.WithField("reqid", "req-2").Debug("other request")
+	logger.WithField("reqid", "req-1").Trace("forced trace")
+	logger.WithField("reqid", "req-1").Debugf("forced %s", "debug")
+	logger.Trace("no request")
+	assert.Equal(t, "TRACE forced trace \nDEBUG forced debug \n", buf.String())
+
+	logger.UnforceTrace("req-1")
+	buf.Reset()
+	logger.WithField("reqid", "req-1").Info("unforced")
+	assert.Equal(t, "", buf.String())
+
+	err = logger.ForceTraceIf("tenant", func(e *Entry) bool {
+		return e.Fields["tenant"] == "acme"
+	})
+	assert.Nil(t, err)
+	logger.WithField("tenant", "acme").Info("acme entry")
+	logger.WithField("tenant", "other").Info("other entry")
+	assert.Equal(t, "INFO  acme entry \n", buf.String())
+
+	err = logger.ForceTraceIf("tenant", func(e *Entry) bool { return true })
+	assert.Equal(t, "log: force trace name 'tenant' is already added, skip it", err.Error())
+	err = logger.ForceTraceIf("", func(e *Entry) bool { return true })
+	assert.Equal(t, "log: force trace name is empty", err.Error())
+	assert.Equal(t, ErrFilterFuncIsNil, logger.ForceTraceIf("nil", nil))
+
+	logger.UnforceTraceIf("tenant")
+	assert.Equal(t, int32(0), logger.forced)
+}
+
+func TestMultiLoggerForceTrace(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message"
+    receivers {
+      console { level = "error" }
+      discard { }
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.receiver.(*MultiReceiver).Receiver("console").SetWriter(buf)
+
+	// forced entry is dispatched regardless of receiver level
+	logger.ForceTrace("req-1")
+	logger.WithField("reqid", "req-1").Info("forced info")
+	logger.WithField("reqid", "req-2").Info("not forced")
+	assert.Equal(t, "INFO  forced info \n", buf.String())
+}
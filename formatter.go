@@ -6,9 +6,17 @@ package log
 
 import (
 	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"aahframework.org/essentials.v0"
 )
@@ -28,13 +36,39 @@ const (
 	FmtFlagMessage
 	FmtFlagFields
 	FmtFlagCustom
+	FmtFlagHostname
+	FmtFlagPID
+	FmtFlagGoroutineID
+	FmtFlagEnv
 	FmtFlagUnknown
 )
 
 const (
-	textFmt = "text"
-	jsonFmt = "json"
-	space   = " "
+	textFmt     = "text"
+	jsonFmt     = "json"
+	logfmtFmt   = "logfmt"
+	prettyFmt   = "prettyjson"
+	cefFmt      = "cef"
+	ecsFmt      = "ecs"
+	gelfFmt     = "gelf"
+	rfc5424Fmt  = "rfc5424"
+	msgpackFmt  = "msgpack"
+	protoFmt    = "protobuf"
+	cborFmt     = "cbor"
+	csvFmt      = "csv"
+	gcpFmt      = "gcp"
+	otelFmt     = "otel"
+	clfFmt      = "clf"
+	combinedFmt = "combined"
+	space       = " "
+
+	// text formatter multi-line message modes
+	multilineEscape = "escape"
+	multilineIndent = "indent"
+
+	// templateFmtPrefix is the prefix of template format, rest of the
+	// format value is Go template text.
+	templateFmtPrefix = "template:"
 )
 
 type (
@@ -59,6 +93,34 @@ var (
 	//    2006-01-02 15:04:05.000 INFO  This is my message
 	DefaultPattern = "%time:2006-01-02 15:04:05.000 %level:-5 %message"
 
+	// RFC5424MsgIDField is the entry field name used as MSGID by rfc5424
+	// formatter.
+	RFC5424MsgIDField = "msgid"
+
+	// hostname is used by formatters, if entry doesn't have instance name
+	hostname, _ = os.Hostname()
+
+	// pid is the process ID used by `pid` format flag
+	pid = os.Getpid()
+
+	// gcpProjectID is used to compose trace resource name by gcp formatter
+	gcpProjectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+
+	// templates is the compiled template formatters by template text
+	templates  = make(map[string]*template.Template)
+	templateMu = &sync.RWMutex{}
+
+	// templateFuncs is the functions available in template formatter
+	templateFuncs = template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"base":  filepath.Base,
+		"json": func(v interface{}) string {
+			b, _ := json.Marshal(v)
+			return string(b)
+		},
+	}
+
 	// FmtFlags is the list of log format flags supported by aah log library
 	// Usage of flag order is up to format composition.
 	//    level     - outputs ERROR, WARN, INFO, DEBUG, TRACE
@@ -72,8 +134,12 @@ var (
 	//    shortfile - outputs final file name element: d.go
 	//    line      - outputs file line number: L23
 	//    message   - outputs given message along supplied arguments if they present
-	//    fields    - outputs field values into log entry
+	//    fields    - outputs field values into log entry, sorted by key
 	//    custom    - outputs string as-is into log entry
+	//    hostname  - outputs host name of the machine
+	//    pid       - outputs process ID
+	//    goid      - outputs goroutine ID of the log call
+	//    env       - outputs application environment name, i.e. `env.active`
 	FmtFlags = map[string]ess.FmtFlag{
 		"level":     FmtFlagLevel,
 		"appname":   FmtFlagAppName,
@@ -88,9 +154,145 @@ var (
 		"message":   FmtFlagMessage,
 		"fields":    FmtFlagFields,
 		"custom":    FmtFlagCustom,
+		"hostname":  FmtFlagHostname,
+		"pid":       FmtFlagPID,
+		"goid":      FmtFlagGoroutineID,
+		"env":       FmtFlagEnv,
 	}
 )
 
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// formatEntry
+//___________________________________
+
+// formatEntry method formats the `Entry` object as per given formatter name
+// and flags, the text result is terminated with newline.
+func formatEntry(formatter string, flags []ess.FmtFlagPart, entry *Entry) []byte {
+	if l := entry.logger; l != nil {
+		switch {
+		case formatter == jsonFmt && len(l.jsonKeys) > 0:
+			return jsonKeyOrderFormatter(flags, entry, l.jsonKeys)
+		case formatter == textFmt && len(l.multiline) > 0:
+			return multilineTextFormatter(flags, entry, l.multiline)
+		}
+	}
+
+	formatterMu.RLock()
+	f, found := formatters[formatter]
+	formatterMu.RUnlock()
+	if found {
+		return f.Format(flags, entry)
+	}
+
+	if strings.HasPrefix(formatter, templateFmtPrefix) {
+		return templateFormatter(formatter, entry)
+	}
+
+	return jsonFormatter(flags, entry)
+}
+
+// isFormatSupported method returns true if given formatter name is supported
+// otherwise false.
+func isFormatSupported(formatter string) bool {
+	formatterMu.RLock()
+	_, found := formatters[formatter]
+	formatterMu.RUnlock()
+	return found || (strings.HasPrefix(formatter, templateFmtPrefix) && parseTemplateFormat(formatter) == nil)
+}
+
+// isBinaryFormat method returns true if formatter output is binary, which
+// is not newline delimited.
+func isBinaryFormat(formatter string) bool {
+	return formatter == msgpackFmt || formatter == protoFmt || formatter == cborFmt
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// FormatterFunc methods
+//___________________________________
+
+// Format method calls f(flags, entry).
+func (f FormatterFunc) Format(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	return f(flags, entry)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// jsonFormatter
+//___________________________________
+
+// jsonFormatter formats the `Entry` object as JSON.
+func jsonFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	msg, _ := json.Marshal(entry)
+	return append(renameJSONKeys(appendProcessInfo(msg, flags, entry), entry), '\n')
+}
+
+// appendProcessInfo method adds the `hostname`, `pid`, `goroutine_id` and
+// `env` keys into JSON object, if its flag exists in the pattern.
+func appendProcessInfo(msg []byte, flags []ess.FmtFlagPart, entry *Entry) []byte {
+	buf := new(bytes.Buffer)
+	for _, part := range flags {
+		switch part.Flag {
+		case FmtFlagHostname:
+			jsonPair(buf, "hostname", hostname)
+		case FmtFlagPID:
+			jsonPair(buf, "pid", pid)
+		case FmtFlagGoroutineID:
+			jsonPair(buf, "goroutine_id", entry.GoroutineID)
+		case FmtFlagEnv:
+			if len(entry.Env) > 0 {
+				jsonPair(buf, "env", entry.Env)
+			}
+		}
+	}
+	if buf.Len() == 0 || len(msg) < 2 {
+		return msg
+	}
+
+	b := make([]byte, 0, len(msg)+buf.Len())
+	b = append(b, msg[:len(msg)-1]...)
+	if len(msg) > 2 {
+		b = append(b, ',')
+	}
+	b = append(b, buf.Bytes()[:buf.Len()-1]...)
+	return append(b, '}')
+}
+
+// jsonKeyOrderFormatter formats the `Entry` object as JSON with keys in the
+// given order, rest of the keys follow in alphabetical order. Fields are
+// always in alphabetical order. Order is configured per logger via
+// `log.json.key_order`.
+func jsonKeyOrderFormatter(flags []ess.FmtFlagPart, entry *Entry, order []string) []byte {
+	msg, _ := json.Marshal(entry)
+	msg = appendProcessInfo(msg, flags, entry)
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &values); err != nil {
+		return append(msg, '\n')
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for _, k := range order {
+		if v, found := values[k]; found {
+			jsonPair(buf, entry.outputKey(k), v)
+			delete(values, k)
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		jsonPair(buf, entry.outputKey(k), values[k])
+	}
+
+	if buf.Len() > 1 {
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // textFormatter
 //___________________________________
@@ -99,11 +301,39 @@ var (
 // 	For e.g.:
 // 		2016-07-02 22:26:01.530 INFO formatter_test.go L29 - Yes, I would love to see
 func textFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	return formatText(flags, entry, false, "")
+}
+
+// colorTextFormatter formats the `Entry` object same as `textFormatter` with
+// ANSI colors, level is colored as per level and caller info is dimmed.
+func colorTextFormatter(flags []ess.FmtFlagPart, entry *Entry, multiline string) []byte {
+	return formatText(flags, entry, true, multiline)
+}
+
+// multilineTextFormatter formats the `Entry` object same as `textFormatter`
+// and multi-line message is written as per mode, it's configured per logger
+// via `log.text.multiline`.
+//
+//	escape - newlines are escaped as `\n`, entry is written in single line
+//	indent - continuation lines are indented under the message column
+func multilineTextFormatter(flags []ess.FmtFlagPart, entry *Entry, mode string) []byte {
+	return formatText(flags, entry, false, mode)
+}
+
+func formatText(flags []ess.FmtFlagPart, entry *Entry, color bool, multiline string) []byte {
 	buf := new(bytes.Buffer)
+	var stack string
 
 	for _, part := range flags {
 		switch part.Flag {
 		case FmtFlagLevel:
+			if color {
+				buf.Write(levelToColor[builtinLevel(entry.Level)])
+				buf.WriteString(fmt.Sprintf(part.Format, entry.Level))
+				buf.Write(resetColor)
+				buf.WriteString(space)
+				continue
+			}
 			buf.WriteString(fmt.Sprintf(part.Format, entry.Level) + space)
 		case FmtFlagAppName:
 			if len(entry.AppName) > 0 {
@@ -126,30 +356,1123 @@ func textFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
 		case FmtFlagUTCTime:
 			buf.WriteString(entry.Time.UTC().Format(part.Format) + space)
 		case FmtFlagLongfile, FmtFlagShortfile:
+			file := entry.File
 			if part.Flag == FmtFlagShortfile {
-				entry.File = filepath.Base(entry.File)
+				file = filepath.Base(file)
 			}
-			buf.WriteString(fmt.Sprintf(part.Format, entry.File) + space)
+			writeDimmed(buf, fmt.Sprintf(part.Format, file), color)
 		case FmtFlagLine:
-			buf.WriteString("L" + fmt.Sprintf(part.Format, entry.Line) + space)
+			writeDimmed(buf, "L"+fmt.Sprintf(part.Format, entry.Line), color)
 		case FmtFlagMessage:
-			buf.WriteString(entry.Message + space)
+			writeMessage(buf, entry.Message, multiline)
 		case FmtFlagCustom:
 			buf.WriteString(part.Format + space)
+		case FmtFlagHostname:
+			buf.WriteString(fmt.Sprintf(part.Format, hostname) + space)
+		case FmtFlagPID:
+			buf.WriteString(fmt.Sprintf(part.Format, pid) + space)
+		case FmtFlagGoroutineID:
+			buf.WriteString(fmt.Sprintf(part.Format, entry.GoroutineID) + space)
+		case FmtFlagEnv:
+			if len(entry.Env) > 0 {
+				buf.WriteString(fmt.Sprintf(part.Format, entry.Env) + space)
+			}
 		case FmtFlagFields:
-			fs := make([]string, 0)
-			for k, v := range entry.Fields {
+			fields := entry.Fields.flatten()
+			keys := make([]string, 0, len(fields))
+			for k := range fields {
 				if !entry.isSkipField(k) {
-					fs = append(fs, fmt.Sprintf("%v: %v", k, v))
+					keys = append(keys, k)
 				}
 			}
+			sort.Strings(keys)
+			fs := make([]string, 0, len(keys))
+			for _, k := range keys {
+				fs = append(fs, fmt.Sprintf("%v: %v", k, fields[k]))
+			}
 
 			if len(fs) > 0 {
 				buf.WriteString("fields[" + strings.Join(fs, ", ") + "] ")
 			}
+			if ei, ok := entry.Fields["error"].(*ErrorInfo); ok {
+				stack = ei.Stack
+			}
 		}
 	}
 
 	buf.WriteByte('\n')
+
+	// error stack trace follows the entry, indented by tab
+	if len(stack) > 0 {
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(stack, "\n"), "\n") {
+			buf.WriteString("\t" + line)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// writeMessage method writes the message as per multi-line mode.
+func writeMessage(buf *bytes.Buffer, msg, multiline string) {
+	if len(multiline) == 0 || !strings.ContainsAny(msg, "\r\n") {
+		buf.WriteString(msg + space)
+		return
+	}
+
+	msg = strings.TrimRight(msg, "\r\n")
+	switch multiline {
+	case multilineEscape:
+		msg = strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(msg)
+	case multilineIndent:
+		// message column of the current line, ANSI color codes are not visible
+		line := buf.Bytes()[bytes.LastIndexByte(buf.Bytes(), '\n')+1:]
+		width := 0
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\033' {
+				for i < len(line) && line[i] != 'm' {
+					i++
+				}
+				continue
+			}
+			width++
+		}
+		msg = strings.Replace(strings.Replace(msg, "\r\n", "\n", -1), "\n",
+			"\n"+strings.Repeat(space, width), -1)
+	}
+	buf.WriteString(msg + space)
+}
+
+func writeDimmed(buf *bytes.Buffer, value string, color bool) {
+	if color {
+		buf.Write(dimColor)
+		buf.WriteString(value)
+		buf.Write(resetColor)
+	} else {
+		buf.WriteString(value)
+	}
+	buf.WriteString(space)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// logfmtFormatter
+//___________________________________
+
+// logfmtFormatter formats the `Entry` object as logfmt `key=value` pairs,
+// keys are `time`, `level`, `msg`, `app_name`, `instance_name`,
+// `request_id`, `principal`, `caller` and entry fields in sorted order.
+// Caller is added if pattern has `shortfile`, `longfile` or `line` flag.
+//
+//	For e.g.:
+//		time=2016-07-02T22:26:01.530+05:30 level=info msg="Yes, I would love to see" key1=value1
+func logfmtFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	buf := new(bytes.Buffer)
+	logfmtPair(buf, "time", entry.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	logfmtPair(buf, "level", strings.ToLower(entry.Level.String()))
+	logfmtPair(buf, "msg", entry.Message)
+
+	for _, kv := range [][2]string{
+		{"app_name", entry.AppName},
+		{"instance_name", entry.InstanceName},
+		{"request_id", entry.RequestID},
+		{"principal", entry.Principal},
+	} {
+		if len(kv[1]) > 0 {
+			logfmtPair(buf, kv[0], kv[1])
+		}
+	}
+
+	if len(entry.File) > 0 {
+		file := entry.File
+		if !isFmtFlagExists(flags, FmtFlagLongfile) {
+			file = filepath.Base(file)
+		}
+		logfmtPair(buf, "caller", fmt.Sprintf("%s:%d", file, entry.Line))
+	}
+
+	fields := entry.Fields.flatten()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !entry.isSkipField(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		logfmtPair(buf, k, fmt.Sprint(fields[k]))
+	}
+
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// logfmtPair method writes the key=value pair followed by space, value is
+// quoted if it's empty or has space, quote, equal sign or control characters.
+func logfmtPair(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key))
+	buf.WriteByte('=')
+
+	if len(value) == 0 || strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f
+	}) != -1 {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+	buf.WriteByte(' ')
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// cefFormatter
+//___________________________________
+
+var (
+	// levelToCEFSeverity is mapping of aah log level to CEF severity (0-10)
+	levelToCEFSeverity = map[level]int{
+		LevelFatal: 10,
+		LevelPanic: 10,
+		LevelError: 7,
+		LevelWarn:  5,
+		LevelInfo:  3,
+		LevelDebug: 1,
+		LevelTrace: 0,
+	}
+
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r", " ", "\n", " ")
+	cefValueEscaper  = strings.NewReplacer(`\`, `\\`, "=", `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+// cefFormatter formats the `Entry` object as ArcSight Common Event Format.
+// Device product is app name (default `aah`), signature ID is the level and
+// name is first line of the message. Entry details are mapped into CEF
+// extensions `rt`, `msg`, `dproc`, `dvchost`, `externalId`, `suser` and
+// caller into `cs1` labelled as `caller`; entry fields follow as custom
+// extensions in sorted order. Caller is added if pattern has `shortfile`,
+// `longfile` or `line` flag.
+//
+//	For e.g.:
+//		CEF:0|aahframework.org|myapp|0.7.1|WARN|Login failed|5|rt=1467523561530 msg=Login failed suser=jeeva
+func cefFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	product := entry.AppName
+	if len(product) == 0 {
+		product = "aah"
+	}
+	name := entry.Message
+	if idx := strings.IndexAny(name, "\r\n"); idx != -1 {
+		name = name[:idx]
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("CEF:0|aahframework.org|")
+	buf.WriteString(cefHeaderEscaper.Replace(product))
+	buf.WriteString("|" + Version + "|")
+	buf.WriteString(cefHeaderEscaper.Replace(entry.Level.String()))
+	buf.WriteByte('|')
+	buf.WriteString(cefHeaderEscaper.Replace(name))
+	buf.WriteString("|" + strconv.Itoa(levelToCEFSeverity[builtinLevel(entry.Level)]) + "|")
+
+	cefExtension(buf, "rt", strconv.FormatInt(entry.Time.UnixNano()/int64(time.Millisecond), 10))
+	cefExtension(buf, "msg", entry.Message)
+	for _, kv := range [][2]string{
+		{"dproc", entry.AppName},
+		{"dvchost", entry.InstanceName},
+		{"externalId", entry.RequestID},
+		{"suser", entry.Principal},
+	} {
+		if len(kv[1]) > 0 {
+			cefExtension(buf, kv[0], kv[1])
+		}
+	}
+
+	if len(entry.File) > 0 {
+		file := entry.File
+		if !isFmtFlagExists(flags, FmtFlagLongfile) {
+			file = filepath.Base(file)
+		}
+		cefExtension(buf, "cs1Label", "caller")
+		cefExtension(buf, "cs1", fmt.Sprintf("%s:%d", file, entry.Line))
+	}
+
+	fields := entry.Fields.flatten()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !entry.isSkipField(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cefExtension(buf, k, fmt.Sprint(fields[k]))
+	}
+
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// cefExtension method writes the key=value extension followed by space, key
+// can have only alphanumeric characters and value is escaped as per CEF.
+func cefExtension(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, key))
+	buf.WriteByte('=')
+	buf.WriteString(cefValueEscaper.Replace(value))
+	buf.WriteByte(' ')
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// ecsFormatter
+//___________________________________
+
+// ecsVersion is the Elastic Common Schema version of the ecs formatter.
+const ecsVersion = "1.6.0"
+
+// ecsFormatter formats the `Entry` object as Elastic Common Schema JSON.
+// Keys are `@timestamp`, `log.level`, `message`, `ecs.version`,
+// `service.name`, `service.node.name`, `trace.id` (request ID), `user.name`,
+// `log.origin.file.name`, `log.origin.file.line` and entry fields, a field
+// doesn't override ECS key. Caller is added if pattern has `shortfile`,
+// `longfile` or `line` flag.
+//
+//	For e.g.:
+//		{"@timestamp":"2016-07-02T22:26:01.530Z","log.level":"info","message":"Yes, I would love to see","ecs.version":"1.6.0"}
+func ecsFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	jsonPair(buf, "@timestamp", entry.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	jsonPair(buf, "log.level", strings.ToLower(entry.Level.String()))
+	jsonPair(buf, "message", entry.Message)
+	jsonPair(buf, "ecs.version", ecsVersion)
+
+	keys := map[string]bool{"@timestamp": true, "log.level": true, "message": true, "ecs.version": true}
+	for _, kv := range [][2]string{
+		{"service.name", entry.AppName},
+		{"service.node.name", entry.InstanceName},
+		{"trace.id", entry.RequestID},
+		{"user.name", entry.Principal},
+	} {
+		if len(kv[1]) > 0 {
+			jsonPair(buf, kv[0], kv[1])
+			keys[kv[0]] = true
+		}
+	}
+
+	if len(entry.File) > 0 {
+		file := entry.File
+		if !isFmtFlagExists(flags, FmtFlagLongfile) {
+			file = filepath.Base(file)
+		}
+		jsonPair(buf, "log.origin.file.name", file)
+		jsonPair(buf, "log.origin.file.line", entry.Line)
+		keys["log.origin.file.name"], keys["log.origin.file.line"] = true, true
+	}
+
+	fieldKeys := make([]string, 0, len(entry.Fields))
+	for k := range entry.Fields {
+		if !entry.isSkipField(k) && !keys[k] {
+			fieldKeys = append(fieldKeys, k)
+		}
+	}
+	sort.Strings(fieldKeys)
+	for _, k := range fieldKeys {
+		jsonPair(buf, k, entry.Fields[k])
+	}
+
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// jsonPair method writes the JSON key and value followed by comma, value
+// is written as string if it's not JSON marshalable.
+func jsonPair(buf *bytes.Buffer, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(v)
+	buf.WriteByte(',')
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// gcpFormatter
+//___________________________________
+
+// gcpFormatter formats the `Entry` object as Google Cloud structured logging
+// JSON, so GKE, Cloud Run, etc. pick up the severity, source location and
+// trace from stdout. Keys are `severity`, `message`, `timestamp`,
+// `logging.googleapis.com/sourceLocation`, `logging.googleapis.com/trace`,
+// `logging.googleapis.com/spanId`, entry details and fields.
+//
+// Trace is taken from entry field `trace_id`, it's prefixed with
+// `projects/<project>/traces/` if it's not a resource name and project is
+// known from environment variable `GOOGLE_CLOUD_PROJECT`. Span is taken from
+// entry field `span_id`. Caller is added if pattern has `shortfile`,
+// `longfile` or `line` flag.
+//
+//	For e.g.:
+//		{"severity":"INFO","message":"Yes, I would love to see","timestamp":"2016-07-02T22:26:01.53Z"}
+func gcpFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	jsonPair(buf, "severity", levelToCloudSeverity[builtinLevel(entry.Level)])
+	jsonPair(buf, "message", entry.Message)
+	jsonPair(buf, "timestamp", entry.Time.UTC().Format(time.RFC3339Nano))
+
+	if len(entry.File) > 0 {
+		file := entry.File
+		if !isFmtFlagExists(flags, FmtFlagLongfile) {
+			file = filepath.Base(file)
+		}
+		jsonPair(buf, "logging.googleapis.com/sourceLocation",
+			&cloudSourceLocation{File: file, Line: strconv.Itoa(entry.Line)})
+	}
+
+	if v, found := entry.Fields["trace_id"]; found {
+		trace := fmt.Sprint(v)
+		if !strings.HasPrefix(trace, "projects/") && len(gcpProjectID) > 0 {
+			trace = "projects/" + gcpProjectID + "/traces/" + trace
+		}
+		jsonPair(buf, "logging.googleapis.com/trace", trace)
+	}
+	if v, found := entry.Fields["span_id"]; found {
+		jsonPair(buf, "logging.googleapis.com/spanId", fmt.Sprint(v))
+	}
+
+	keys := map[string]bool{"severity": true, "message": true, "timestamp": true, "trace_id": true, "span_id": true}
+	for _, kv := range [][2]string{
+		{"app_name", entry.AppName},
+		{"instance_name", entry.InstanceName},
+		{"request_id", entry.RequestID},
+		{"principal", entry.Principal},
+	} {
+		if len(kv[1]) > 0 {
+			jsonPair(buf, kv[0], kv[1])
+			keys[kv[0]] = true
+		}
+	}
+
+	fieldKeys := make([]string, 0, len(entry.Fields))
+	for k := range entry.Fields {
+		if !entry.isSkipField(k) && !keys[k] {
+			fieldKeys = append(fieldKeys, k)
+		}
+	}
+	sort.Strings(fieldKeys)
+	for _, k := range fieldKeys {
+		jsonPair(buf, k, entry.Fields[k])
+	}
+
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// otelFormatter
+//___________________________________
+
+// levelToOTelSeverity is mapping of aah log level to OpenTelemetry severity
+// number
+var levelToOTelSeverity = map[level]int{
+	LevelFatal: 21,
+	LevelPanic: 21,
+	LevelError: 17,
+	LevelWarn:  13,
+	LevelInfo:  9,
+	LevelDebug: 5,
+	LevelTrace: 1,
+}
+
+// otelLogRecord is the log record as per OpenTelemetry Logs data model.
+type otelLogRecord struct {
+	Timestamp      string                 `json:"Timestamp"`
+	SeverityText   string                 `json:"SeverityText"`
+	SeverityNumber int                    `json:"SeverityNumber"`
+	Body           string                 `json:"Body"`
+	Attributes     map[string]interface{} `json:"Attributes,omitempty"`
+	Resource       map[string]string      `json:"Resource,omitempty"`
+	TraceID        string                 `json:"TraceId,omitempty"`
+	SpanID         string                 `json:"SpanId,omitempty"`
+}
+
+// otelFormatter formats the `Entry` object as JSON log record as per
+// OpenTelemetry Logs data model. Timestamp is Unix time in nanoseconds as
+// string, app name and instance name are `service.name` and
+// `service.instance.id` resource attributes. Request ID, principal
+// (`enduser.id`), caller (`code.filepath`, `code.lineno`) and entry fields
+// are attributes; entry fields `trace_id` and `span_id` are mapped to
+// `TraceId` and `SpanId`. Caller is added if pattern has `shortfile`,
+// `longfile` or `line` flag.
+//
+//	For e.g.:
+//		{"Timestamp":"1467498361530000000","SeverityText":"INFO","SeverityNumber":9,"Body":"Yes, I would love to see","Resource":{"service.name":"myapp"}}
+func otelFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	r := &otelLogRecord{
+		Timestamp:      strconv.FormatInt(entry.Time.UnixNano(), 10),
+		SeverityText:   entry.Level.String(),
+		SeverityNumber: levelToOTelSeverity[builtinLevel(entry.Level)],
+		Body:           entry.Message,
+		Attributes:     make(map[string]interface{}),
+		Resource:       make(map[string]string),
+	}
+
+	if len(entry.AppName) > 0 {
+		r.Resource["service.name"] = entry.AppName
+	}
+	if len(entry.InstanceName) > 0 {
+		r.Resource["service.instance.id"] = entry.InstanceName
+	}
+	if len(entry.RequestID) > 0 {
+		r.Attributes["request_id"] = entry.RequestID
+	}
+	if len(entry.Principal) > 0 {
+		r.Attributes["enduser.id"] = entry.Principal
+	}
+	if len(entry.File) > 0 {
+		file := entry.File
+		if !isFmtFlagExists(flags, FmtFlagLongfile) {
+			file = filepath.Base(file)
+		}
+		r.Attributes["code.filepath"] = file
+		r.Attributes["code.lineno"] = entry.Line
+	}
+
+	for k, v := range entry.Fields {
+		switch {
+		case entry.isSkipField(k):
+		case k == "trace_id":
+			r.TraceID = fmt.Sprint(v)
+		case k == "span_id":
+			r.SpanID = fmt.Sprint(v)
+		default:
+			r.Attributes[k] = v
+		}
+	}
+
+	msg, _ := json.Marshal(r)
+	return append(msg, '\n')
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// clfFormatter, combinedFormatter
+//___________________________________
+
+// clfFormatter formats the `Entry` object as HTTP access log in Apache
+// Common Log Format from the entry fields `remote_addr`, `method`, `path`,
+// `proto`, `status` and `bytes`; user is the entry principal. Entry field
+// `latency` is appended if present.
+//
+//	For e.g.:
+//		127.0.0.1 - jeeva [02/Jul/2016:22:26:01 +0000] "GET /index.html HTTP/1.1" 200 2326
+func clfFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	return accessLogFormatter(entry, false)
+}
+
+// combinedFormatter formats the `Entry` object as HTTP access log in Apache
+// Combined Log Format, it's `clf` with entry fields `referer` and
+// `user_agent`.
+//
+//	For e.g.:
+//		127.0.0.1 - - [02/Jul/2016:22:26:01 +0000] "GET / HTTP/1.1" 200 2326 "https://aahframework.org" "Mozilla/5.0"
+func combinedFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	return accessLogFormatter(entry, true)
+}
+
+func accessLogFormatter(entry *Entry, isCombined bool) []byte {
+	field := func(name string) string {
+		if v, found := entry.Fields[name]; found {
+			if s := fmt.Sprint(v); len(s) > 0 {
+				return s
+			}
+		}
+		return "-"
+	}
+
+	request := field("method") + space + field("path") + space
+	if proto := field("proto"); proto != "-" {
+		request += proto
+	} else {
+		request += "HTTP/1.1"
+	}
+	bytesSent := field("bytes")
+	if bytesSent == "0" {
+		bytesSent = "-"
+	}
+	user := entry.Principal
+	if len(user) == 0 {
+		user = "-"
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(accessLogEscape(field("remote_addr")) + " - " + accessLogEscape(user) + " [" +
+		entry.Time.Format("02/Jan/2006:15:04:05 -0700") + `] "` + accessLogEscape(request) + `" ` +
+		accessLogEscape(field("status")) + space + accessLogEscape(bytesSent))
+	if isCombined {
+		buf.WriteString(` "` + accessLogEscape(field("referer")) + `" "` + accessLogEscape(field("user_agent")) + `"`)
+	}
+	if latency := field("latency"); latency != "-" {
+		buf.WriteString(space + accessLogEscape(latency))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// accessLogEscape method escapes the quote, backslash and control
+// characters as Apache httpd does.
+func accessLogEscape(v string) string {
+	buf := new(bytes.Buffer)
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c < ' ' || c == 0x7f:
+			_, _ = fmt.Fprintf(buf, "\\x%02x", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// gelfFormatter
+//___________________________________
+
+// gelfFormatter formats the `Entry` object as GELF 1.1 JSON message. Host is
+// instance name or hostname, short message is first line of the message and
+// full message is added for multi-line message; level is syslog severity.
+// Entry details and fields are added as additional fields with `_` prefix,
+// field name `id` is written as `_field_id` since `_id` is reserved.
+// Caller is added if pattern has `shortfile`, `longfile` or `line` flag.
+//
+//	For e.g.:
+//		{"version":"1.1","host":"app-sfo-cn-01","short_message":"Yes, I would love to see","timestamp":1467498361.530,"level":6}
+func gelfFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	host := entry.InstanceName
+	if len(host) == 0 {
+		host = hostname
+	}
+	short := strings.TrimRight(entry.Message, "\r\n")
+	if idx := strings.IndexAny(short, "\r\n"); idx != -1 {
+		short = short[:idx]
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	jsonPair(buf, "version", "1.1")
+	jsonPair(buf, "host", host)
+	jsonPair(buf, "short_message", short)
+	if short != entry.Message {
+		jsonPair(buf, "full_message", entry.Message)
+	}
+	buf.WriteString(`"timestamp":` + strconv.FormatFloat(float64(entry.Time.UnixNano()/int64(time.Millisecond))/1000, 'f', 3, 64) + ",")
+	jsonPair(buf, "level", syslogSeverities[levelToSyslogSeverity[builtinLevel(entry.Level)]])
+
+	for _, kv := range [][2]string{
+		{"_app_name", entry.AppName},
+		{"_request_id", entry.RequestID},
+		{"_principal", entry.Principal},
+	} {
+		if len(kv[1]) > 0 {
+			jsonPair(buf, kv[0], kv[1])
+		}
+	}
+
+	if len(entry.File) > 0 {
+		file := entry.File
+		if !isFmtFlagExists(flags, FmtFlagLongfile) {
+			file = filepath.Base(file)
+		}
+		jsonPair(buf, "_file", file)
+		jsonPair(buf, "_line", entry.Line)
+	}
+
+	fields := entry.Fields.flatten()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !entry.isSkipField(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		jsonPair(buf, gelfFieldName(k), fields[k])
+	}
+
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// gelfFieldName method returns GELF additional field name, it can contain
+// only letters, digits, underscore, dash and dot.
+func gelfFieldName(key string) string {
+	if key == "id" {
+		key = "field_id"
+	}
+	return "_" + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// rfc5424Formatter
+//___________________________________
+
+// rfc5424SDID is the SD-ID of entry details, 32473 is the IANA reserved
+// enterprise number for documentation use.
+const rfc5424SDID = "aah@32473"
+
+var rfc5424ValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "]", `\]`)
+
+// rfc5424Formatter formats the `Entry` object as RFC 5424 syslog message
+// with facility local0, app name is entry app name or program name and MSGID
+// is the value of entry field `RFC5424MsgIDField`. Entry details and fields
+// are written as SD-PARAMs of single SD-ELEMENT `aah@32473`. Caller is added
+// if pattern has `shortfile`, `longfile` or `line` flag.
+//
+//	For e.g.:
+//		<134>1 2016-07-02T22:26:01.530000Z myhost myapp 1234 login [aah@32473 user="jeeva"] Yes, I would love to see
+func rfc5424Formatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	appName := entry.AppName
+	if len(appName) == 0 {
+		appName = filepath.Base(os.Args[0])
+	}
+	host := entry.InstanceName
+	if len(host) == 0 {
+		host = hostname
+	}
+
+	msgID := "-"
+	if v, found := entry.Fields[RFC5424MsgIDField]; found {
+		msgID = rfc5424Name(fmt.Sprint(v), 32)
+	}
+
+	buf := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(buf, "<%d>1 %s %s %s %d %s ",
+		syslogFacilities["local0"]*8+syslogSeverities[levelToSyslogSeverity[builtinLevel(entry.Level)]],
+		entry.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		rfc5424Name(host, 255), rfc5424Name(appName, 48), os.Getpid(), msgID)
+
+	params := [][2]string{
+		{"request_id", entry.RequestID},
+		{"principal", entry.Principal},
+	}
+	if len(entry.File) > 0 {
+		file := entry.File
+		if !isFmtFlagExists(flags, FmtFlagLongfile) {
+			file = filepath.Base(file)
+		}
+		params = append(params, [2]string{"file", file}, [2]string{"line", strconv.Itoa(entry.Line)})
+	}
+
+	fields := entry.Fields.flatten()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !entry.isSkipField(k) && k != RFC5424MsgIDField {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		params = append(params, [2]string{k, fmt.Sprint(fields[k])})
+	}
+
+	sd := new(bytes.Buffer)
+	for _, p := range params {
+		if len(p[1]) > 0 {
+			sd.WriteString(space + rfc5424Name(strings.Replace(p[0], "=", "_", -1), 32) +
+				`="` + rfc5424ValueEscaper.Replace(p[1]) + `"`)
+		}
+	}
+	if sd.Len() > 0 {
+		buf.WriteString("[" + rfc5424SDID)
+		buf.Write(sd.Bytes())
+		buf.WriteString("]")
+	} else {
+		buf.WriteString("-")
+	}
+
+	if len(entry.Message) > 0 {
+		buf.WriteString(space + entry.Message)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// rfc5424Name method returns the header field or SD-NAME as per RFC 5424,
+// only printable US-ASCII characters except space, `=`, `]` and `"` upto
+// given max length.
+func rfc5424Name(v string, max int) string {
+	v = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, v)
+	if len(v) == 0 {
+		return "-"
+	}
+	if len(v) > max {
+		v = v[:max]
+	}
+	return v
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// msgpackFormatter
+//___________________________________
+
+// msgpackFormatter formats the `Entry` object as MessagePack map, entries are
+// written back to back without delimiter since MessagePack is self-delimiting.
+// Schema of the map, keys with empty value are omitted:
+//
+//	time          timestamp ext (-1)
+//	level         string, e.g.: INFO
+//	message       string
+//	app_name      string
+//	instance_name string
+//	request_id    string
+//	principal     string
+//	file          string, if pattern has caller info flag
+//	line          int, if pattern has caller info flag
+//	fields        map of string to value
+func msgpackFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	kvs := make([][2]string, 0, 6)
+	for _, kv := range [][2]string{
+		{"message", entry.Message},
+		{"app_name", entry.AppName},
+		{"instance_name", entry.InstanceName},
+		{"request_id", entry.RequestID},
+		{"principal", entry.Principal},
+	} {
+		if len(kv[1]) > 0 {
+			kvs = append(kvs, kv)
+		}
+	}
+
+	file := entry.File
+	if len(file) > 0 && !isFmtFlagExists(flags, FmtFlagLongfile) {
+		file = filepath.Base(file)
+	}
+
+	fields := make(map[string]interface{}, len(entry.Fields))
+	for k, v := range entry.Fields {
+		if !entry.isSkipField(k) {
+			fields[k] = v
+		}
+	}
+
+	size := 2 + len(kvs)
+	if len(file) > 0 {
+		size += 2
+	}
+	if len(fields) > 0 {
+		size++
+	}
+
+	e := &msgpackEncoder{}
+	e.mapHeader(size)
+	e.string("time")
+	e.timestamp(entry.Time)
+	e.string("level")
+	e.string(entry.Level.String())
+	for _, kv := range kvs {
+		e.string(kv[0])
+		e.string(kv[1])
+	}
+	if len(file) > 0 {
+		e.string("file")
+		e.string(file)
+		e.string("line")
+		e.int(int64(entry.Line))
+	}
+	if len(fields) > 0 {
+		e.string("fields")
+		e.encode(fields)
+	}
+	return e.Bytes()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// templateFormatter
+//___________________________________
+
+// templateFormatter formats the `Entry` object using Go template, format
+// value is `template:` followed by template text. Template is executed with
+// `Entry` object and functions `upper`, `lower`, `base` and `json` are
+// available. Newline is appended if template output doesn't end with it.
+// `.File` and `.Line` are populated if pattern has caller info flag.
+//
+//	For e.g.:
+//		format = "template:{{.Time.Format \"15:04:05\"}} [{{.Level}}] {{.Message}}{{range $k, $v := .Fields}} {{$k}}={{$v}}{{end}}"
+func templateFormatter(formatter string, entry *Entry) []byte {
+	templateMu.RLock()
+	tmpl := templates[formatter]
+	templateMu.RUnlock()
+	if tmpl == nil {
+		if err := parseTemplateFormat(formatter); err != nil {
+			return []byte(err.Error() + "\n")
+		}
+		return templateFormatter(formatter, entry)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, entry); err != nil {
+		buf.WriteString("log: template error: " + err.Error())
+	}
+	if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// parseTemplateFormat method compiles the template format and caches it
+// for `templateFormatter`.
+func parseTemplateFormat(formatter string) error {
+	templateMu.Lock()
+	defer templateMu.Unlock()
+	if _, found := templates[formatter]; found {
+		return nil
+	}
+
+	tmpl, err := template.New("log").Funcs(templateFuncs).
+		Parse(strings.TrimPrefix(formatter, templateFmtPrefix))
+	if err != nil {
+		return fmt.Errorf("log: invalid format template: %v", err)
+	}
+	templates[formatter] = tmpl
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// csvFormatter
+//___________________________________
+
+// csvFormatter formats the `Entry` object as CSV record, columns and its
+// order are derived from log `pattern` flags. Time columns use the flag
+// format, `custom` flag is written as-is and `fields` column is JSON object
+// of entry fields. Values are quoted as per RFC 4180.
+//
+//	For e.g.: pattern `%utctime:2006-01-02T15:04:05Z07:00 %level %reqid %message %fields`
+//		2016-07-02T22:26:01Z,INFO,40139CA6368607085BF6,"Yes, I would love to see","{""user"":""jeeva""}"
+func csvFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	record := make([]string, 0, len(flags))
+	for _, part := range flags {
+		var value string
+		switch part.Flag {
+		case FmtFlagLevel:
+			value = strings.TrimSpace(fmt.Sprintf(part.Format, entry.Level))
+		case FmtFlagAppName:
+			value = entry.AppName
+		case FmtFlagInstanceName:
+			value = entry.InstanceName
+		case FmtFlagRequestID:
+			value = entry.RequestID
+		case FmtFlagPrincipal:
+			value = entry.Principal
+		case FmtFlagTime:
+			value = entry.Time.Format(part.Format)
+		case FmtFlagUTCTime:
+			value = entry.Time.UTC().Format(part.Format)
+		case FmtFlagLongfile:
+			value = entry.File
+		case FmtFlagShortfile:
+			value = filepath.Base(entry.File)
+		case FmtFlagLine:
+			value = strconv.Itoa(entry.Line)
+		case FmtFlagMessage:
+			value = entry.Message
+		case FmtFlagCustom:
+			value = part.Format
+		case FmtFlagHostname:
+			value = hostname
+		case FmtFlagPID:
+			value = strconv.Itoa(pid)
+		case FmtFlagGoroutineID:
+			value = strconv.FormatUint(entry.GoroutineID, 10)
+		case FmtFlagEnv:
+			value = entry.Env
+		case FmtFlagFields:
+			fields := make(Fields, len(entry.Fields))
+			for k, v := range entry.Fields {
+				if !entry.isSkipField(k) {
+					fields[k] = v
+				}
+			}
+			if len(fields) > 0 {
+				b, _ := json.Marshal(fields)
+				value = string(b)
+			}
+		}
+		record = append(record, value)
+	}
+
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	_ = w.Write(record)
+	w.Flush()
+	return buf.Bytes()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// cborFormatter
+//___________________________________
+
+// cborFormatter formats the `Entry` object as CBOR map with same keys as
+// `msgpack` formatter, time is encoded as epoch-based date/time (tag 1).
+// Entries are written back to back since CBOR is self-delimiting.
+func cborFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	m := map[string]interface{}{
+		"time":  entry.Time,
+		"level": entry.Level.String(),
+	}
+	for _, kv := range [][2]string{
+		{"message", entry.Message},
+		{"app_name", entry.AppName},
+		{"instance_name", entry.InstanceName},
+		{"request_id", entry.RequestID},
+		{"principal", entry.Principal},
+	} {
+		if len(kv[1]) > 0 {
+			m[kv[0]] = kv[1]
+		}
+	}
+
+	if len(entry.File) > 0 {
+		file := entry.File
+		if !isFmtFlagExists(flags, FmtFlagLongfile) {
+			file = filepath.Base(file)
+		}
+		m["file"], m["line"] = file, entry.Line
+	}
+
+	fields := make(map[string]interface{}, len(entry.Fields))
+	for k, v := range entry.Fields {
+		if !entry.isSkipField(k) {
+			fields[k] = v
+		}
+	}
+	if len(fields) > 0 {
+		m["fields"] = fields
+	}
+
+	e := &cborEncoder{}
+	e.encode(m)
+	return e.Bytes()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// protobufFormatter
+//___________________________________
+
+// protobufFormatter formats the `Entry` object as `aah.log.v1.LogEntry`
+// message, see `log_entry.proto`. Each message is prefixed with its length
+// as varint, so stream can be read with delimited message readers, for
+// e.g.: Java `parseDelimitedFrom`. File and line are added if pattern has
+// caller info flag.
+func protobufFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	pe := *entry
+	if len(pe.File) > 0 && !isFmtFlagExists(flags, FmtFlagLongfile) {
+		pe.File = filepath.Base(pe.File)
+	}
+	pe.Fields = make(Fields, len(entry.Fields))
+	for k, v := range entry.Fields {
+		if !entry.isSkipField(k) {
+			pe.Fields[k] = v
+		}
+	}
+
+	msg := encodeProtoEntry(&pe)
+	e := &protoEncoder{}
+	e.varint(uint64(len(msg)))
+	_, _ = e.Write(msg)
+	return e.Bytes()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// prettyJSONFormatter
+//___________________________________
+
+// prettyJSONFormatter formats the `Entry` object as indented JSON for
+// development use, set `log.format = "prettyjson"`. Keys are ordered as
+// timestamp, level, message, entry details and fields; multi-line message
+// is rendered as array of lines.
+//
+//	For e.g.:
+//		{
+//		  "timestamp": "2016-07-02T22:26:01-07:00",
+//		  "level": "INFO",
+//		  "message": "Yes, I would love to see",
+//		  "fields": {
+//		    "user": "jeeva"
+//		  }
+//		}
+func prettyJSONFormatter(flags []ess.FmtFlagPart, entry *Entry) []byte {
+	var message interface{} = entry.Message
+	if strings.ContainsRune(entry.Message, '\n') {
+		message = strings.Split(strings.TrimRight(entry.Message, "\n"), "\n")
+	}
+
+	var fields Fields
+	for k, v := range entry.Fields {
+		switch k {
+		case "appname", "insname", "reqid", "principal":
+			continue
+		}
+		if fields == nil {
+			fields = make(Fields)
+		}
+		fields[k] = v
+	}
+
+	ne := struct {
+		Time         string      `json:"timestamp,omitempty"`
+		Level        string      `json:"level,omitempty"`
+		Message      interface{} `json:"message"`
+		AppName      string      `json:"app_name,omitempty"`
+		InstanceName string      `json:"instance_name,omitempty"`
+		RequestID    string      `json:"request_id,omitempty"`
+		Principal    string      `json:"principal,omitempty"`
+		File         string      `json:"file,omitempty"`
+		Line         int         `json:"line,omitempty"`
+		Fields       Fields      `json:"fields,omitempty"`
+	}{
+		Time:         formatTime(entry.Time),
+		Level:        entry.Level.String(),
+		Message:      message,
+		AppName:      entry.AppName,
+		InstanceName: entry.InstanceName,
+		RequestID:    entry.RequestID,
+		Principal:    entry.Principal,
+		File:         entry.File,
+		Line:         entry.Line,
+		Fields:       fields,
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(ne)
 	return buf.Bytes()
 }
@@ -0,0 +1,505 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:     LevelInfo,
+		Time:      time.Date(2016, 7, 2, 22, 26, 1, 530000000, time.UTC),
+		Message:   `Yes, I would love to "see"`,
+		RequestID: "40139CA6368607085BF6",
+		File:      "/a/b/c/formatter_test.go",
+		Line:      29,
+		Fields:    Fields{"reqid": "40139CA6368607085BF6", "user": "jeeva", "empty": "", "path": "a=b"},
+	}
+	assert.Equal(t, `time=2016-07-02T22:26:01.530Z level=info msg="Yes, I would love to \"see\"" `+
+		`request_id=40139CA6368607085BF6 caller=formatter_test.go:29 empty="" path="a=b" user=jeeva`+"\n",
+		string(formatEntry(logfmtFmt, flags, entry)))
+
+	flags, _ = ess.ParseFmtFlag("%level %longfile %message", FmtFlags)
+	entry = &Entry{Level: LevelError, Time: entry.Time, Message: "multi\nline", File: "/a/b/c.go", Line: 2}
+	assert.Equal(t, `time=2016-07-02T22:26:01.530Z level=error msg="multi\nline" caller=/a/b/c.go:2`+"\n",
+		string(formatEntry(logfmtFmt, flags, entry)))
+}
+
+func TestLogfmtConsoleLogger(t *testing.T) {
+	cfg, _ := config.ParseString(`log { format = "logfmt", color = false, pattern = "%level %message" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.False(t, logger.receiver.IsCallerInfo())
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.WithField("key1", "value1").Warn("Yes, yes it's an warning")
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte(` level=warn msg="Yes, yes it's an warning" key1=value1`+"\n")))
+}
+
+func TestPrettyJSONFormatter(t *testing.T) {
+	entry := &Entry{
+		Level:     LevelWarn,
+		Time:      time.Date(2016, 7, 2, 22, 26, 1, 0, time.UTC),
+		Message:   "first line\nsecond <line>\n",
+		RequestID: "40139CA6368607085BF6",
+		Fields:    Fields{"reqid": "40139CA6368607085BF6", "user": "jeeva", "attempt": 2},
+	}
+	assert.Equal(t, `{
+  "timestamp": "2016-07-02T22:26:01Z",
+  "level": "WARN",
+  "message": [
+    "first line",
+    "second <line>"
+  ],
+  "request_id": "40139CA6368607085BF6",
+  "fields": {
+    "attempt": 2,
+    "user": "jeeva"
+  }
+}
+`, string(formatEntry(prettyFmt, nil, entry)))
+	assert.Equal(t, 3, len(entry.Fields))
+
+	entry = &Entry{Level: LevelInfo, Time: entry.Time, Message: "single line"}
+	assert.Equal(t, "{\n  \"timestamp\": \"2016-07-02T22:26:01Z\",\n  \"level\": \"INFO\",\n"+
+		"  \"message\": \"single line\"\n}\n", string(formatEntry(prettyFmt, nil, entry)))
+}
+
+func TestPrettyJSONConsoleLogger(t *testing.T) {
+	cfg, _ := config.ParseString(`log { format = "prettyjson", color = false }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.WithField("user", "jeeva").Info("pretty")
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`  "message": "pretty",`)))
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte("  \"fields\": {\n    \"user\": \"jeeva\"\n  }")))
+}
+
+func TestCEFFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:     LevelWarn,
+		Time:      time.Date(2016, 7, 2, 22, 26, 1, 530000000, time.UTC),
+		Message:   "Login failed|retry\nuser=jeeva path=C:\\temp",
+		AppName:   "my|app",
+		Principal: "jeeva",
+		File:      "/a/b/c/formatter_test.go",
+		Line:      29,
+		Fields:    Fields{"principal": "jeeva", "src.ip": "10.0.0.1", "attempt": 3},
+	}
+	assert.Equal(t, `CEF:0|aahframework.org|my\|app|`+Version+`|WARN|Login failed\|retry|5|`+
+		`rt=1467498361530 msg=Login failed|retry\nuser\=jeeva path\=C:\\temp dproc=my|app suser=jeeva `+
+		`cs1Label=caller cs1=formatter_test.go:29 attempt=3 srcip=10.0.0.1`+"\n",
+		string(formatEntry(cefFmt, flags, entry)))
+
+	entry = &Entry{Level: LevelError, Time: entry.Time, Message: "failed"}
+	assert.Equal(t, "CEF:0|aahframework.org|aah|"+Version+"|ERROR|failed|7|rt=1467498361530 msg=failed\n",
+		string(formatEntry(cefFmt, nil, entry)))
+}
+
+func TestECSFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %longfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:     LevelError,
+		Time:      time.Date(2016, 7, 2, 22, 26, 1, 530000000, time.FixedZone("IST", 19800)),
+		Message:   "payment failed",
+		AppName:   "myapp",
+		RequestID: "40139CA6368607085BF6",
+		File:      "/a/b/c.go",
+		Line:      12,
+		Fields:    Fields{"reqid": "40139CA6368607085BF6", "message": "override", "order.id": 42, "ch": make(chan int)},
+	}
+	assert.Equal(t, `{"@timestamp":"2016-07-02T16:56:01.530Z","log.level":"error","message":"payment failed",`+
+		`"ecs.version":"1.6.0","service.name":"myapp","trace.id":"40139CA6368607085BF6",`+
+		`"log.origin.file.name":"/a/b/c.go","log.origin.file.line":12,"ch":"`+fmt.Sprint(entry.Fields["ch"])+`","order.id":42}`+"\n",
+		string(formatEntry(ecsFmt, flags, entry)))
+}
+
+func TestGELFFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:        LevelWarn,
+		Time:         time.Date(2016, 7, 2, 22, 26, 1, 530000000, time.UTC),
+		Message:      "disk is almost full\nused 95%\n",
+		InstanceName: "app-sfo-cn-01",
+		Principal:    "jeeva",
+		File:         "/a/b/c/formatter_test.go",
+		Line:         29,
+		Fields:       Fields{"insname": "app-sfo-cn-01", "id": 7, "disk path": "/data"},
+	}
+	assert.Equal(t, `{"version":"1.1","host":"app-sfo-cn-01","short_message":"disk is almost full",`+
+		`"full_message":"disk is almost full\nused 95%\n","timestamp":1467498361.530,"level":4,`+
+		`"_principal":"jeeva","_file":"formatter_test.go","_line":29,"_disk_path":"/data","_field_id":7}`+"\n",
+		string(formatEntry(gelfFmt, flags, entry)))
+
+	entry = &Entry{Level: LevelError, Time: entry.Time, Message: "failed"}
+	assert.Equal(t, `{"version":"1.1","host":"`+hostname+`","short_message":"failed",`+
+		`"timestamp":1467498361.530,"level":3}`+"\n", string(formatEntry(gelfFmt, nil, entry)))
+}
+
+func TestRFC5424Formatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:        LevelInfo,
+		Time:         time.Date(2016, 7, 2, 22, 26, 1, 530000000, time.UTC),
+		Message:      "Yes, I would love to see",
+		AppName:      "my app",
+		InstanceName: "app-sfo-cn-01",
+		File:         "/a/b/c/formatter_test.go",
+		Line:         29,
+		Fields:       Fields{"insname": "app-sfo-cn-01", "msgid": "user login", "user": `jee"va]`, "a=b": 1},
+	}
+	assert.Equal(t, fmt.Sprintf(`<134>1 2016-07-02T22:26:01.530000Z app-sfo-cn-01 my_app %d user_login `+
+		`[aah@32473 file="formatter_test.go" line="29" a_b="1" user="jee\"va\]"] Yes, I would love to see`+"\n", os.Getpid()),
+		string(formatEntry(rfc5424Fmt, flags, entry)))
+
+	defer func() { RFC5424MsgIDField = "msgid" }()
+	RFC5424MsgIDField = "event"
+	entry = &Entry{Level: LevelError, Time: entry.Time, AppName: "myapp", InstanceName: "host1",
+		Fields: Fields{"event": "payment"}}
+	assert.Equal(t, fmt.Sprintf("<131>1 2016-07-02T22:26:01.530000Z host1 myapp %d payment -\n", os.Getpid()),
+		string(formatEntry(rfc5424Fmt, nil, entry)))
+}
+
+func TestProtobufFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:     LevelWarn,
+		Time:      time.Unix(1467498361, 530000000),
+		Message:   "Yes, yes it's an warning",
+		RequestID: "40139CA6368607085BF6",
+		File:      "/a/b/c/formatter_test.go",
+		Line:      29,
+		Fields:    Fields{"reqid": "40139CA6368607085BF6", "attempt": 2},
+	}
+	b := formatEntry(protoFmt, flags, entry)
+	r := bytes.NewReader(append(b, b...))
+	for i := 0; i < 2; i++ {
+		size, err := binary.ReadUvarint(r)
+		assert.FailNowOnError(t, err, "")
+		msg := make([]byte, size)
+		_, _ = r.Read(msg)
+
+		m := testDecodeProto(msg)
+		assert.Equal(t, "WARN", m[1])
+		assert.Equal(t, uint64(entry.Time.UnixNano()), m[2])
+		assert.Equal(t, "Yes, yes it's an warning", m[3])
+		assert.Equal(t, "40139CA6368607085BF6", m[6])
+		assert.Equal(t, "formatter_test.go", m[8])
+		assert.Equal(t, uint64(29), m[9])
+		assert.Equal(t, map[string]string{"attempt": "2"}, m[10])
+	}
+	assert.Equal(t, 0, r.Len())
+	assert.Equal(t, "/a/b/c/formatter_test.go", entry.File)
+}
+
+func TestCBORFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:   LevelInfo,
+		Time:    time.Unix(1363896240, 0),
+		Message: "hi",
+		File:    "/a/b.go",
+		Line:    7,
+		Fields:  Fields{"insname": "app-1", "k": 1},
+	}
+	// {"fields": {"k": 1}, "file": "b.go", "level": "INFO", "line": 7, "message": "hi", "time": 1(1363896240)}
+	assert.Equal(t, "a6"+"666669656c6473"+"a1616b01"+"6466696c65"+"64622e676f"+"656c6576656c"+"64494e464f"+
+		"646c696e65"+"07"+"676d657373616765"+"626869"+"6474696d65"+"c11a514b67b0",
+		hex.EncodeToString(formatEntry(cborFmt, flags, entry)))
+}
+
+func TestCSVFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%utctime:2006-01-02T15:04:05Z07:00 %level:-5 %reqid %shortfile %line %custom:aah %message %fields", FmtFlags)
+	entry := &Entry{
+		Level:     LevelInfo,
+		Time:      time.Date(2016, 7, 2, 22, 26, 1, 0, time.UTC),
+		Message:   "Yes, I would \"love\" to see\nnext line",
+		RequestID: "40139CA6368607085BF6",
+		File:      "/a/b/c/formatter_test.go",
+		Line:      29,
+		Fields:    Fields{"reqid": "40139CA6368607085BF6", "user": "jeeva"},
+	}
+	assert.Equal(t, `2016-07-02T22:26:01Z,INFO,40139CA6368607085BF6,formatter_test.go,29,aah,`+
+		`"Yes, I would ""love"" to see`+"\n"+`next line","{""user"":""jeeva""}"`+"\n",
+		string(formatEntry(csvFmt, flags, entry)))
+
+	flags, _ = ess.ParseFmtFlag("%level %principal %fields %message", FmtFlags)
+	entry = &Entry{Level: LevelError, Message: "failed"}
+	assert.Equal(t, "ERROR,,,failed\n", string(formatEntry(csvFmt, flags, entry)))
+}
+
+func TestProcessInfoFlags(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %hostname %pid %goid %env %message", FmtFlags)
+	entry := &Entry{Level: LevelInfo, Message: "started", GoroutineID: 18, Env: "prod"}
+	assert.Equal(t, fmt.Sprintf("INFO %s %d 18 prod started \n", hostname, pid),
+		string(formatEntry(textFmt, flags, entry)))
+
+	b := formatEntry(jsonFmt, flags, entry)
+	assert.True(t, bytes.HasSuffix(b, []byte(fmt.Sprintf(`"hostname":%q,"pid":%d,"goroutine_id":18,"env":"prod"}`+"\n", hostname, pid))))
+	assert.Equal(t, fmt.Sprintf("INFO,%s,%d,18,prod,started\n", hostname, pid),
+		string(formatEntry(csvFmt, flags, entry)))
+
+	// env is omitted if not configured
+	flags, _ = ess.ParseFmtFlag("%env %message", FmtFlags)
+	assert.Equal(t, "started \n", string(formatEntry(textFmt, flags, &Entry{Message: "started"})))
+	assert.Equal(t, "{}", string(appendProcessInfo([]byte("{}"), flags, &Entry{})))
+	assert.Equal(t, `{"env":"dev"}`, string(appendProcessInfo([]byte("{}"), flags, &Entry{Env: "dev"})))
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	format := `template:{{.Time.Format "15:04:05"}} [{{.Level}}] {{.Message | upper}}` +
+		`{{range $k, $v := .Fields}} {{$k}}={{$v}}{{end}}{{if .File}} {{base .File}}:{{.Line}}{{end}}`
+	assert.True(t, isFormatSupported(format))
+
+	entry := &Entry{
+		Level:   LevelWarn,
+		Time:    time.Date(2016, 7, 2, 22, 26, 1, 0, time.UTC),
+		Message: "disk is full",
+		File:    "/a/b/c.go",
+		Line:    12,
+		Fields:  Fields{"disk": "/data", "used": 95},
+	}
+	assert.Equal(t, "22:26:01 [WARN] DISK IS FULL disk=/data used=95 c.go:12\n",
+		string(formatEntry(format, nil, entry)))
+
+	// template output with newline
+	assert.Equal(t, "WARN\n", string(formatEntry("template:{{.Level}}\n", nil, entry)))
+	assert.Equal(t, `{"disk":"/data","used":95}`+"\n", string(formatEntry("template:{{json .Fields}}", nil, entry)))
+
+	// execution error
+	assert.Equal(t, "log: template error: template: log:1:2: executing \"log\" at <.Unknown>: "+
+		"can't evaluate field Unknown in type *log.Entry\n", string(formatEntry("template:{{.Unknown}}", nil, entry)))
+
+	// invalid template
+	assert.False(t, isFormatSupported("template:{{.Level"))
+	cfg, _ := config.ParseString(`log { format = "template:{{.Level" }`)
+	_, err := New(cfg)
+	assert.True(t, strings.HasPrefix(err.Error(), "log: invalid format template: template: log:1: unclosed action"))
+
+	cfg, _ = config.ParseString(`log { format = "template:{{.Level}} {{.Message}}", color = false }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.Info("templated")
+	assert.Equal(t, "INFO templated\n", buf.String())
+}
+
+func TestAddFormatter(t *testing.T) {
+	err := AddFormatter("mycorp", FormatterFunc(func(flags []ess.FmtFlagPart, entry *Entry) []byte {
+		return []byte("mycorp|" + entry.Level.String() + "|" + entry.Message + "\n")
+	}))
+	assert.Nil(t, err)
+
+	err = AddFormatter("mycorp", FormatterFunc(jsonFormatter))
+	assert.Equal(t, "log: formatter name 'mycorp' is already added, skip it", err.Error())
+
+	err = AddFormatter("text", FormatterFunc(jsonFormatter))
+	assert.Equal(t, "log: formatter name 'text' is already added, skip it", err.Error())
+
+	err = AddFormatter("custom", nil)
+	assert.Equal(t, ErrFormatterIsNil, err)
+
+	err = AddFormatter(" ", FormatterFunc(jsonFormatter))
+	assert.Equal(t, "log: formatter name is empty", err.Error())
+
+	assert.True(t, isFormatSupported("mycorp"))
+	assert.False(t, isFormatSupported("MyCorp"))
+
+	cfg, _ := config.ParseString(`log { format = "mycorp", color = false }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.Warn("custom formatter")
+	assert.Equal(t, "mycorp|WARN|custom formatter\n", buf.String())
+}
+
+func TestTextFormatterKeepsEntry(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %message", FmtFlags)
+	entry := &Entry{Level: LevelInfo, Message: "hi", File: "/a/b/c.go"}
+	assert.Equal(t, "INFO c.go hi \n", string(formatEntry(textFmt, flags, entry)))
+	assert.Equal(t, "/a/b/c.go", entry.File)
+}
+
+func TestTextFormatterFieldOrder(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %message %fields", FmtFlags)
+	entry := &Entry{Level: LevelInfo, Message: "hi", Fields: Fields{
+		"zone": "b", "app": "orders", "reqid": "req-1", "db": Fields{"query": "select 1"}, "count": 2,
+	}}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "INFO hi fields[app: orders, count: 2, db.query: select 1, zone: b] \n",
+			string(formatEntry(textFmt, flags, entry)))
+	}
+}
+
+func TestGCPFormatter(t *testing.T) {
+	defer func(p string) { gcpProjectID = p }(gcpProjectID)
+	gcpProjectID = "my-project"
+
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:     LevelWarn,
+		Time:      time.Date(2016, 7, 2, 22, 26, 1, 530000000, time.UTC),
+		Message:   "Yes, yes it's an warning",
+		RequestID: "40139CA6368607085BF6",
+		File:      "/a/b/c/formatter_test.go",
+		Line:      29,
+		Fields: Fields{"reqid": "40139CA6368607085BF6", "trace_id": "105445aa7843bc8bf206b12000100000",
+			"span_id": "000000000000004a", "severity": "override", "user": "jeeva"},
+	}
+	assert.Equal(t, `{"severity":"WARNING","message":"Yes, yes it's an warning","timestamp":"2016-07-02T22:26:01.53Z",`+
+		`"logging.googleapis.com/sourceLocation":{"file":"formatter_test.go","line":"29"},`+
+		`"logging.googleapis.com/trace":"projects/my-project/traces/105445aa7843bc8bf206b12000100000",`+
+		`"logging.googleapis.com/spanId":"000000000000004a","request_id":"40139CA6368607085BF6","user":"jeeva"}`+"\n",
+		string(formatEntry(gcpFmt, flags, entry)))
+
+	gcpProjectID = ""
+	entry = &Entry{Level: LevelFatal, Time: entry.Time, Message: "down", Fields: Fields{"trace_id": "abc"}}
+	assert.Equal(t, `{"severity":"CRITICAL","message":"down","timestamp":"2016-07-02T22:26:01.53Z",`+
+		`"logging.googleapis.com/trace":"abc"}`+"\n", string(formatEntry(gcpFmt, nil, entry)))
+}
+
+func TestOTelFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:        LevelError,
+		Time:         time.Unix(1467498361, 530000000),
+		Message:      "payment failed",
+		AppName:      "myapp",
+		InstanceName: "app-sfo-cn-01",
+		Principal:    "jeeva",
+		File:         "/a/b/c/formatter_test.go",
+		Line:         29,
+		Fields: Fields{"principal": "jeeva", "trace_id": "5b8efff798038103d269b633813fc60c",
+			"span_id": "eee19b7ec3c1b174", "order_id": 42},
+	}
+	assert.Equal(t, `{"Timestamp":"1467498361530000000","SeverityText":"ERROR","SeverityNumber":17,`+
+		`"Body":"payment failed","Attributes":{"code.filepath":"formatter_test.go","code.lineno":29,`+
+		`"enduser.id":"jeeva","order_id":42},"Resource":{"service.instance.id":"app-sfo-cn-01","service.name":"myapp"},`+
+		`"TraceId":"5b8efff798038103d269b633813fc60c","SpanId":"eee19b7ec3c1b174"}`+"\n",
+		string(formatEntry(otelFmt, flags, entry)))
+
+	entry = &Entry{Level: LevelTrace, Time: entry.Time, Message: "trace"}
+	assert.Equal(t, `{"Timestamp":"1467498361530000000","SeverityText":"TRACE","SeverityNumber":1,"Body":"trace"}`+"\n",
+		string(formatEntry(otelFmt, nil, entry)))
+}
+
+func TestAccessLogFormatter(t *testing.T) {
+	entry := &Entry{
+		Level:     LevelInfo,
+		Time:      time.Date(2016, 7, 2, 22, 26, 1, 0, time.FixedZone("PDT", -7*3600)),
+		Principal: "jeeva",
+		Fields: Fields{"remote_addr": "127.0.0.1", "method": "GET", "path": `/search?q="aah"`,
+			"proto": "HTTP/2.0", "status": 200, "bytes": 2326, "referer": "https://aahframework.org",
+			"user_agent": "Mozilla/5.0\t(X11)", "latency": "1.2ms"},
+	}
+	assert.Equal(t, `127.0.0.1 - jeeva [02/Jul/2016:22:26:01 -0700] "GET /search?q=\"aah\" HTTP/2.0" 200 2326 1.2ms`+"\n",
+		string(formatEntry(clfFmt, nil, entry)))
+	assert.Equal(t, `127.0.0.1 - jeeva [02/Jul/2016:22:26:01 -0700] "GET /search?q=\"aah\" HTTP/2.0" 200 2326 `+
+		`"https://aahframework.org" "Mozilla/5.0\x09(X11)" 1.2ms`+"\n",
+		string(formatEntry(combinedFmt, nil, entry)))
+
+	entry = &Entry{Time: entry.Time, Fields: Fields{"method": "HEAD", "path": "/", "status": 304, "bytes": 0}}
+	assert.Equal(t, `- - - [02/Jul/2016:22:26:01 -0700] "HEAD / HTTP/1.1" 304 - "-" "-"`+"\n",
+		string(formatEntry(combinedFmt, nil, entry)))
+}
+
+func TestJSONKeyOrderFormatter(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    format = "json"
+    color = false
+    json {
+      key_order = ["timestamp", "level", "message", "unknown"]
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.WithFields(Fields{"zone": "z1", "attempt": 2}).Info("ordered")
+
+	line := buf.String()
+	assert.True(t, strings.HasPrefix(line, `{"timestamp":"`))
+	assert.True(t, strings.Contains(line, `","level":"INFO","message":"ordered","fields":{"attempt":2,"zone":"z1"}`))
+
+	entry := &Entry{Level: LevelWarn, Time: time.Date(2016, 7, 2, 22, 26, 1, 0, time.UTC), Message: "m",
+		RequestID: "r1", File: "c.go", Line: 2}
+	assert.Equal(t, `{"message":"m","level":"WARN","file":"c.go","line":2,"request_id":"r1",`+
+		`"timestamp":"2016-07-02T22:26:01Z"}`+"\n", string(jsonKeyOrderFormatter(nil, entry, []string{"message", "level"})))
+
+	// key order is applied only to the configured logger
+	cfg, _ = config.ParseString(`log { format = "json" }`)
+	other, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	otherBuf := &bytes.Buffer{}
+	other.SetWriter(otherBuf)
+	other.Info("default")
+	assert.False(t, strings.HasPrefix(otherBuf.String(), `{"timestamp":"`))
+	formatterMu.RLock()
+	_, ok := formatters[jsonFmt].(FormatterFunc)
+	formatterMu.RUnlock()
+	assert.True(t, ok)
+
+	buf.Reset()
+	logger.Info("ordered")
+	assert.True(t, strings.HasPrefix(buf.String(), `{"timestamp":"`))
+}
+
+func TestTextFormatterMultiline(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level:-5 %custom:- %message %fields", FmtFlags)
+	entry := &Entry{Level: LevelError, Message: "query failed\nSELECT *\r\n  FROM t\n", Fields: Fields{"k": 1}}
+
+	assert.Equal(t, "ERROR - query failed\nSELECT *\r\n  FROM t\n fields[k: 1] \n",
+		string(textFormatter(flags, entry)))
+	assert.Equal(t, `ERROR - query failed\nSELECT *\r\n  FROM t fields[k: 1] `+"\n",
+		string(multilineTextFormatter(flags, entry, multilineEscape)))
+	assert.Equal(t, "ERROR - query failed\n        SELECT *\n          FROM t fields[k: 1] \n",
+		string(multilineTextFormatter(flags, entry, multilineIndent)))
+	assert.Equal(t, "\033[0;31mERROR\033[0m - query failed\n        SELECT *\n          FROM t \n",
+		string(colorTextFormatter(flags[:3], entry, multilineIndent)))
+
+	cfg, _ := config.ParseString(`log { pattern = "%level:-5 %message", color = false, text { multiline = "escape" } }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.Info("line 1\nline 2")
+	assert.Equal(t, `INFO  line 1\nline 2 `+"\n", buf.String())
+
+	// multi-line mode is applied only to the configured logger
+	cfg, _ = config.ParseString(`log { pattern = "%level:-5 %message", color = false }`)
+	other, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	otherBuf := &bytes.Buffer{}
+	other.SetWriter(otherBuf)
+	other.Info("line 1\nline 2")
+	assert.Equal(t, "INFO  line 1\nline 2 \n", otherBuf.String())
+
+	buf.Reset()
+	logger.Info("line 1\nline 2")
+	assert.Equal(t, `INFO  line 1\nline 2 `+"\n", buf.String())
+
+	cfg, _ = config.ParseString(`log { text { multiline = "fold" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unknown text multiline mode 'fold'", err.Error())
+}
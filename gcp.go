@@ -0,0 +1,191 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const gcpLoggingScope = "https://www.googleapis.com/auth/logging.write"
+
+var gcpMetadataHost = "http://metadata.google.internal"
+
+// gcpServiceAccount is the subset of Google service account JSON key file.
+type gcpServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+	key         *rsa.PrivateKey
+}
+
+// gcpTokenSource provides the OAuth2 access token using service account key
+// if configured otherwise from GCE metadata server. Token is cached till
+// it's about to expire.
+type gcpTokenSource struct {
+	account *gcpServiceAccount
+	client  *http.Client
+	token   string
+	expiry  time.Time
+	mu      sync.Mutex
+}
+
+func newGCPTokenSource(credentialsFile string) (*gcpTokenSource, error) {
+	s := &gcpTokenSource{client: &http.Client{Timeout: 5 * time.Second}}
+	if len(credentialsFile) == 0 {
+		return s, nil
+	}
+
+	b, err := ioutil.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	account := &gcpServiceAccount{}
+	if err = json.Unmarshal(b, account); err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, errors.New("log: gcp invalid service account private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	}
+	var ok bool
+	if account.key, ok = key.(*rsa.PrivateKey); !ok {
+		return nil, errors.New("log: gcp service account private key is not RSA")
+	}
+	if len(account.TokenURI) == 0 {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	s.account = account
+	return s, nil
+}
+
+// Token method returns the valid access token.
+func (s *gcpTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.token) > 0 && time.Now().Add(time.Minute).Before(s.expiry) {
+		return s.token, nil
+	}
+
+	var (
+		req *http.Request
+		err error
+	)
+	if s.account == nil {
+		req, err = http.NewRequest(http.MethodGet, gcpMetadataURL("instance/service-accounts/default/token"), nil)
+		if err == nil {
+			req.Header.Set("Metadata-Flavor", "Google")
+		}
+	} else {
+		var assertion string
+		if assertion, err = s.account.jwt(time.Now()); err != nil {
+			return "", err
+		}
+		form := url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+		req, err = http.NewRequest(http.MethodPost, s.account.TokenURI, strings.NewReader(form.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("log: gcp token status %d", res.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	s.token = token.AccessToken
+	s.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// jwt method creates the signed JWT assertion for OAuth2 token exchange.
+func (a *gcpServiceAccount) jwt(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   a.ClientEmail,
+		"scope": gcpLoggingScope,
+		"aud":   a.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// gcpMetadata method returns the value from GCE metadata server for the
+// given path.
+func gcpMetadata(client *http.Client, path string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, gcpMetadataURL(path), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("log: gcp metadata status %d", res.StatusCode)
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	return strings.TrimSpace(string(b)), err
+}
+
+func gcpMetadataURL(path string) string {
+	host := gcpMetadataHost
+	if h := os.Getenv("GCE_METADATA_HOST"); len(h) > 0 {
+		host = "http://" + h
+	}
+	return host + "/computeMetadata/v1/" + path
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestGCPServiceAccountToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.FailNowOnError(t, err, "")
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		assert.Equal(t, 3, len(parts))
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		assert.Nil(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], sig))
+
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var c map[string]interface{}
+		_ = json.Unmarshal(claims, &c)
+		assert.Equal(t, "logger@project.iam.gserviceaccount.com", c["iss"])
+		assert.Equal(t, gcpLoggingScope, c["scope"])
+
+		_, _ = w.Write([]byte(`{"access_token":"sa-token","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	b, _ := json.Marshal(map[string]string{
+		"project_id":   "my-project",
+		"client_email": "logger@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    server.URL,
+	})
+	keyFile := filepath.Join(os.TempDir(), "aah-log-gcp-key.json")
+	_ = ioutil.WriteFile(keyFile, b, 0600)
+	defer func() { _ = os.Remove(keyFile) }()
+
+	ts, err := newGCPTokenSource(keyFile)
+	assert.FailNowOnError(t, err, "")
+	assert.Equal(t, "my-project", ts.account.ProjectID)
+
+	token, err := ts.Token()
+	assert.Nil(t, err)
+	assert.Equal(t, "sa-token", token)
+
+	_, err = newGCPTokenSource(filepath.Join(os.TempDir(), "not-exists.json"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,306 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const grpcPushMethod = "/aah.log.v1.LogCollector/Push"
+
+var _ Receiver = (*GRPCReceiver)(nil)
+
+// GRPCReceiver streams the log entries as `LogEntry` message (see
+// `log_entry.proto`) to the gRPC collector service `LogCollector.Push` using
+// client streaming call. Entries are buffered on the client side upto
+// `log.grpc.queue_size` and stream is reopened with backoff on failure.
+//
+// TLS is required since Go standard library speaks HTTP/2 only over TLS,
+// configure `log.grpc.tls.ca_file`, `cert_file` and `key_file` as needed.
+type GRPCReceiver struct {
+	errorReporter
+	url          string
+	header       http.Header
+	client       *http.Client
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	queue        chan []byte
+	done         chan struct{}
+	wg           sync.WaitGroup
+	out          io.Writer
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+}
+
+// grpcStream is the client streaming call, request body is streamed
+// through the pipe.
+type grpcStream struct {
+	pw     *io.PipeWriter
+	result chan error
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// GRPCReceiver methods
+//___________________________________
+
+// Init method initializes the gRPC receiver instance.
+func (g *GRPCReceiver) Init(cfg *config.Config) error {
+	address := cfg.StringDefault("log.grpc.address", "")
+	if ess.IsStrEmpty(address) {
+		return errors.New("log: grpc address is required")
+	}
+	g.url = (&url.URL{Scheme: "https", Host: address, Path: grpcPushMethod}).String()
+
+	tlsConfig, err := grpcTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+	g.client = &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   tlsConfig,
+		ForceAttemptHTTP2: true,
+	}}
+
+	g.header = http.Header{}
+	g.header.Set("Content-Type", "application/grpc+proto")
+	g.header.Set("TE", "trailers")
+	g.header.Set("User-Agent", "aah-log/"+Version)
+	for _, k := range cfg.KeysByPath("log.grpc.metadata") {
+		g.header.Set(k, cfg.StringDefault("log.grpc.metadata."+k, ""))
+	}
+
+	if g.minBackoff, err = parseDuration(cfg, "log.grpc.min_backoff", "500ms"); err != nil {
+		return err
+	}
+	if g.maxBackoff, err = parseDuration(cfg, "log.grpc.max_backoff", "30s"); err != nil {
+		return err
+	}
+
+	g.queue = make(chan []byte, cfg.IntDefault("log.grpc.queue_size", 1000))
+	g.done = make(chan struct{})
+	g.wg.Add(1)
+	go g.run()
+
+	g.SetWriter(writerFunc(func(p []byte) (int, error) {
+		g.enqueue(&Entry{Level: LevelInfo, Time: time.Now(), Message: string(bytes.TrimRight(p, "\n"))})
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern, entry is sent as
+// structured message, pattern is used only for caller info.
+func (g *GRPCReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	g.flags = flags
+	g.isCallerInfo = isCallerInfo(g.flags)
+	return nil
+}
+
+// SetWriter method sets the given writer into gRPC receiver.
+func (g *GRPCReceiver) SetWriter(w io.Writer) {
+	g.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (g *GRPCReceiver) IsCallerInfo() bool {
+	return g.isCallerInfo
+}
+
+// Log method queues the log entry to be streamed to the collector.
+func (g *GRPCReceiver) Log(entry *Entry) {
+	if !g.enqueue(entry) {
+		g.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (g *GRPCReceiver) Writer() io.Writer {
+	return g.out
+}
+
+// Close method sends the queued entries and closes the stream.
+func (g *GRPCReceiver) Close() {
+	close(g.done)
+	g.wg.Wait()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// GRPCReceiver Unexported methods
+//___________________________________
+
+// enqueue method queues the entry as gRPC message, returns false if queue
+// is full and entry is dropped.
+func (g *GRPCReceiver) enqueue(entry *Entry) bool {
+	// gRPC length-prefixed message, uncompressed
+	msg := encodeProtoEntry(entry)
+	frame := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(msg)))
+	copy(frame[5:], msg)
+
+	select {
+	case g.queue <- frame:
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *GRPCReceiver) run() {
+	defer g.wg.Done()
+
+	var (
+		stream  *grpcStream
+		backoff = g.minBackoff
+	)
+	send := func(frame []byte, retry bool) {
+		for {
+			if stream == nil {
+				stream = g.open()
+			}
+			_, err := stream.pw.Write(frame)
+			if err == nil {
+				backoff = g.minBackoff
+				return
+			}
+			if cerr := stream.close(); cerr != nil {
+				err = cerr
+			}
+			g.reportError(err, nil)
+			stream = nil
+			if !retry {
+				return
+			}
+
+			select {
+			case <-time.After(backoff):
+			case <-g.done:
+				retry = false
+			}
+			if backoff *= 2; backoff > g.maxBackoff {
+				backoff = g.maxBackoff
+			}
+		}
+	}
+
+	for {
+		select {
+		case frame := <-g.queue:
+			send(frame, true)
+		case <-g.done:
+			for {
+				select {
+				case frame := <-g.queue:
+					send(frame, false)
+				default:
+					if stream != nil {
+						if err := stream.close(); err != nil {
+							g.reportError(err, nil)
+						}
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// open method starts the client streaming call.
+func (g *GRPCReceiver) open() *grpcStream {
+	pr, pw := io.Pipe()
+	s := &grpcStream{pw: pw, result: make(chan error, 1)}
+
+	req, _ := http.NewRequest(http.MethodPost, g.url, pr)
+	for k, v := range g.header {
+		req.Header[k] = v
+	}
+
+	go func() {
+		res, err := g.client.Do(req)
+		if err != nil {
+			_ = pr.CloseWithError(err)
+			s.result <- err
+			return
+		}
+		defer func() { _ = res.Body.Close() }()
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+		_ = pr.CloseWithError(io.ErrClosedPipe)
+
+		err = grpcStatusError(res)
+		s.result <- err
+	}()
+
+	return s
+}
+
+// close method ends the request stream and returns the call status.
+func (s *grpcStream) close() error {
+	_ = s.pw.Close()
+	return <-s.result
+}
+
+// grpcStatusError method returns the error if call is not successful, status
+// is taken from trailers or headers for trailers-only response.
+func grpcStatusError(res *http.Response) error {
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("log: grpc http status %d", res.StatusCode)
+	}
+	status := res.Trailer.Get("Grpc-Status")
+	message := res.Trailer.Get("Grpc-Message")
+	if len(status) == 0 {
+		status, message = res.Header.Get("Grpc-Status"), res.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		return fmt.Errorf("log: grpc status %s: %s", status, message)
+	}
+	return nil
+}
+
+func grpcTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.BoolDefault("log.grpc.tls.insecure_skip_verify", false),
+		ServerName:         cfg.StringDefault("log.grpc.tls.server_name", ""),
+		NextProtos:         []string{"h2"},
+	}
+
+	if caFile := cfg.StringDefault("log.grpc.tls.ca_file", ""); len(caFile) > 0 {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("log: grpc invalid ca file '%s'", caFile)
+		}
+	}
+
+	if certFile := cfg.StringDefault("log.grpc.tls.cert_file", ""); len(certFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(certFile, cfg.StringDefault("log.grpc.tls.key_file", ""))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestGRPCLogger(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		entries []map[int]interface{}
+		header  http.Header
+		proto   string
+		path    string
+	)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		header, proto, path = r.Header, r.Proto, r.URL.Path
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/grpc+proto")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		br := bufio.NewReader(r.Body)
+		for {
+			var prefix [5]byte
+			if _, err := io.ReadFull(br, prefix[:]); err != nil {
+				break
+			}
+			msg := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+			_, _ = io.ReadFull(br, msg)
+			mu.Lock()
+			entries = append(entries, testDecodeProto(msg))
+			mu.Unlock()
+		}
+		_, _ = w.Write([]byte{0, 0, 0, 0, 0})
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "grpc"
+    grpc {
+      address = "%s"
+      tls {
+        insecure_skip_verify = true
+      }
+      metadata {
+        X-Tenant = "aah"
+      }
+    }
+  }
+  `, strings.TrimPrefix(server.URL, "https://"))
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("key1", "value1").Info("Yes, I would love to see")
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "HTTP/2.0", proto)
+	assert.Equal(t, "/aah.log.v1.LogCollector/Push", path)
+	assert.Equal(t, "trailers", header.Get("Te"))
+	assert.Equal(t, "aah", header.Get("X-Tenant"))
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "INFO", entries[0][1])
+	assert.Equal(t, "Yes, I would love to see", entries[0][3])
+	assert.Equal(t, "ERROR", entries[1][1])
+	assert.True(t, entries[1][2].(uint64) > 0)
+	assert.Equal(t, map[string]string{"key1": "value1"}, entries[0][10])
+}
+
+func TestGRPCLoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "grpc" }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: grpc address is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "grpc", grpc { address = "localhost:9000", tls { ca_file = "testdata/notexists.pem" } } }`)
+	_, err = New(cfg)
+	assert.NotNil(t, err)
+
+	res := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Trailer: http.Header{}}
+	res.Header.Set("Grpc-Status", "12")
+	res.Header.Set("Grpc-Message", "unknown service")
+	assert.Equal(t, "log: grpc status 12: unknown service", grpcStatusError(res).Error())
+}
+
+// testDecodeProto decodes the message fields used by `LogEntry`, varint as
+// uint64, bytes as string and map field 10 as map[string]string.
+func testDecodeProto(b []byte) map[int]interface{} {
+	m := make(map[int]interface{})
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		field := int(key >> 3)
+		if key&7 == protoVarint {
+			v, n := binary.Uvarint(b)
+			m[field], b = v, b[n:]
+			continue
+		}
+		l, n := binary.Uvarint(b)
+		v := b[n : n+int(l)]
+		b = b[n+int(l):]
+		if field == 10 {
+			entry := testDecodeProto(v)
+			fields, _ := m[field].(map[string]string)
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			fields[entry[1].(string)] = entry[2].(string)
+			m[field] = fields
+			continue
+		}
+		m[field] = string(v)
+	}
+	return m
+}
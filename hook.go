@@ -0,0 +1,131 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrHookIsNil is returned when hook is nil.
+var ErrHookIsNil = errors.New("log: hook is nil")
+
+// Hook interface is used to mirror the log entries into other systems, for
+// e.g.: metrics and alerting. Unlike `HookFunc`, it's called synchronously
+// with the final entry before it's dispatched to the receiver.
+type Hook interface {
+	// Levels method returns the level names hook fires for, for e.g.:
+	// `ERROR`, `WARN`. Empty means all levels.
+	Levels() []string
+
+	// Fire method is called with the final entry before dispatch. Entry is
+	// reused after `Fire` returns, so hook has to copy the values it keeps.
+	Fire(e *Entry)
+}
+
+// levelHook holds the hook and its levels, nil levels means all levels.
+type levelHook struct {
+	hook    Hook
+	levels  map[level]bool
+	isAsync bool
+}
+
+// AddEntryHook method adds the hook into logger, hooks are fired in the
+// order of added. It's not named `AddHook`, since existing method
+// `Logger.AddHook(name, HookFunc)` is kept as-is for compatibility and Go
+// does not support method overloading. For e.g.:
+//
+//	type alertHook struct{}
+//
+//	func (alertHook) Levels() []string { return []string{"ERROR", "FATAL"} }
+//
+//	func (alertHook) Fire(e *log.Entry) { alerts.Notify(e.Message, e.RequestID) }
+//
+//	logger.AddEntryHook(alertHook{})
+func (l *Logger) AddEntryHook(h Hook) error {
+	if h == nil {
+		return ErrHookIsNil
+	}
+
+	lh := levelHook{hook: h}
+	for _, name := range h.Levels() {
+		lvl := levelByName(name)
+		if lvl == LevelUnknown {
+			return fmt.Errorf("log: unknown hook level '%s'", name)
+		}
+		if lh.levels == nil {
+			lh.levels = make(map[level]bool)
+		}
+		lh.levels[lvl] = true
+	}
+
+	if ah, ok := h.(AsyncHook); ok && ah.IsAsync() {
+		lh.isAsync = true
+		l.hookPool.start()
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	if m, ok := h.(*MetricsHook); ok {
+		m.bind(l)
+		l.metrics = m
+	}
+	entryHooks := make([]levelHook, len(l.entryHooks), len(l.entryHooks)+1)
+	copy(entryHooks, l.entryHooks)
+	l.entryHooks = append(entryHooks, lh)
+	return nil
+}
+
+// dispatch method fires the hooks of entry level and logs the entry into
+// receiver, write latency is observed if metrics hook is added.
+func (l *Logger) dispatch(e *Entry) {
+	l.m.RLock()
+	entryHooks, metrics := l.entryHooks, l.metrics
+	l.m.RUnlock()
+
+	if len(entryHooks) > 0 {
+		l.fireHooks(entryHooks, e)
+	}
+	if metrics == nil {
+		l.receiver.Log(e)
+		return
+	}
+	start := time.Now()
+	l.receiver.Log(e)
+	metrics.observe(time.Since(start))
+}
+
+// fireHooks method fires the hooks of entry level, async hooks are queued
+// into hook pool with copy of entry.
+func (l *Logger) fireHooks(entryHooks []levelHook, e *Entry) {
+	var ce *Entry
+	for _, lh := range entryHooks {
+		if lh.levels != nil && !lh.levels[e.Level] {
+			continue
+		}
+		if lh.isAsync {
+			if ce == nil {
+				ce = copyEntry(e)
+			}
+			l.hookPool.submit(hookTask{hook: lh.hook, e: ce})
+			continue
+		}
+		if err := fireHook(lh.hook, e); err != nil {
+			l.handleError(err, e)
+		}
+	}
+}
+
+// fireHook method calls the hook, panic of hook is returned as error.
+func fireHook(h Hook, e *Entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("log: hook panic: %v", r)
+		}
+	}()
+	h.Fire(e)
+	return nil
+}
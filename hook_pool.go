@@ -0,0 +1,189 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"aahframework.org/config.v0"
+)
+
+// Hook pool overflow policies, it's applied when queue is full.
+const (
+	hookOverflowDrop       = "drop"
+	hookOverflowDropOldest = "drop_oldest"
+	hookOverflowBlock      = "block"
+)
+
+// AsyncHook interface is implemented by the hook which runs on the bounded
+// worker pool, so slow hook, for e.g.: HTTP calls, doesn't block the
+// logging. Entry given to `Fire` is a copy, it's shared by the async hooks
+// of the entry, so hook must not modify it.
+type AsyncHook interface {
+	Hook
+
+	// IsAsync method returns true if hook runs asynchronously.
+	IsAsync() bool
+}
+
+// HookStats holds the counters of async hook executions.
+type HookStats struct {
+	Queued   int64
+	Executed int64
+	Dropped  int64
+}
+
+// hookPool runs the async hooks on bounded workers with queue, it's
+// configured under `log.hook_pool`. Workers are started on first async
+// hook. For e.g.:
+//
+//	log {
+//	  hook_pool {
+//	    workers = 4
+//	    queue_size = 1024
+//	    # drop - new entry is dropped, drop_oldest - oldest queued entry is
+//	    # dropped, block - waits for the queue
+//	    overflow = "drop"
+//	  }
+//	}
+type hookPool struct {
+	// 64-bit atomic values are kept first for alignment on 32-bit platforms
+	queued   int64
+	executed int64
+	dropped  int64
+
+	workers  int
+	overflow string
+	queue    chan hookTask
+	once     sync.Once
+	done     chan struct{}
+	wg       sync.WaitGroup
+	closed   int32
+	errorFn  ErrorHandlerFunc
+}
+
+type hookTask struct {
+	hook Hook
+	e    *Entry
+}
+
+func newHookPool(cfg *config.Config) (*hookPool, error) {
+	p := &hookPool{
+		workers:  cfg.IntDefault("log.hook_pool.workers", 4),
+		overflow: cfg.StringDefault("log.hook_pool.overflow", hookOverflowDrop),
+		done:     make(chan struct{}),
+	}
+	switch p.overflow {
+	case hookOverflowDrop, hookOverflowDropOldest, hookOverflowBlock:
+	default:
+		return nil, fmt.Errorf("log: unsupported hook_pool overflow '%s'", p.overflow)
+	}
+	if p.workers <= 0 {
+		p.workers = 1
+	}
+	queueSize := cfg.IntDefault("log.hook_pool.queue_size", 1024)
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	p.queue = make(chan hookTask, queueSize)
+	return p, nil
+}
+
+// HookStats method returns the counters of async hook executions.
+func (l *Logger) HookStats() HookStats {
+	return HookStats{
+		Queued:   atomic.LoadInt64(&l.hookPool.queued),
+		Executed: atomic.LoadInt64(&l.hookPool.executed),
+		Dropped:  atomic.LoadInt64(&l.hookPool.dropped),
+	}
+}
+
+// start method starts the workers once.
+func (p *hookPool) start() {
+	p.once.Do(func() {
+		for i := 0; i < p.workers; i++ {
+			p.wg.Add(1)
+			go p.run()
+		}
+	})
+}
+
+// submit method queues the hook task as per overflow policy.
+func (p *hookPool) submit(t hookTask) {
+	if atomic.LoadInt32(&p.closed) == 1 {
+		atomic.AddInt64(&p.dropped, 1)
+		return
+	}
+
+	switch p.overflow {
+	case hookOverflowBlock:
+		select {
+		case p.queue <- t:
+		case <-p.done:
+			atomic.AddInt64(&p.dropped, 1)
+			return
+		}
+	case hookOverflowDropOldest:
+		for {
+			select {
+			case p.queue <- t:
+				atomic.AddInt64(&p.queued, 1)
+				return
+			default:
+			}
+			select {
+			case <-p.queue:
+				atomic.AddInt64(&p.dropped, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case p.queue <- t:
+		default:
+			atomic.AddInt64(&p.dropped, 1)
+			return
+		}
+	}
+	atomic.AddInt64(&p.queued, 1)
+}
+
+// close method executes the queued tasks and stops the workers.
+func (p *hookPool) close() {
+	if !atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
+		return
+	}
+	close(p.done)
+	p.wg.Wait()
+}
+
+func (p *hookPool) run() {
+	defer p.wg.Done()
+	for {
+		select {
+		case t := <-p.queue:
+			p.execute(t)
+		case <-p.done:
+			for {
+				select {
+				case t := <-p.queue:
+					p.execute(t)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *hookPool) execute(t hookTask) {
+	err := fireHook(t.hook, t.e)
+	atomic.AddInt64(&p.executed, 1)
+	if err != nil && p.errorFn != nil {
+		p.errorFn(err, t.e)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+type slowHook struct {
+	mu       sync.Mutex
+	release  chan struct{}
+	messages []string
+}
+
+func (h *slowHook) Levels() []string { return nil }
+
+func (h *slowHook) IsAsync() bool { return true }
+
+func (h *slowHook) Fire(e *Entry) {
+	<-h.release
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, e.Message+" "+e.Fields.str("order"))
+}
+
+func TestLogAsyncHook(t *testing.T) {
+	testcases := []struct {
+		overflow string
+		executed []string
+		dropped  int64
+	}{
+		{overflow: "drop", executed: []string{"m1 1", "m2 2", "m3 3"}, dropped: 2},
+		{overflow: "drop_oldest", executed: []string{"m1 1", "m4 4", "m5 5"}, dropped: 2},
+		{overflow: "block", executed: []string{"m1 1", "m2 2", "m3 3", "m4 4", "m5 5"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.overflow, func(t *testing.T) {
+			cfg, _ := config.ParseString(`log {
+        receiver = "discard"
+        hook_pool {
+          workers = 1
+          queue_size = 2
+          overflow = "` + tc.overflow + `"
+        }
+      }`)
+			logger, err := New(cfg)
+			assert.FailNowOnError(t, err, "unexpected error")
+
+			hook := &slowHook{release: make(chan struct{})}
+			assert.Nil(t, logger.AddEntryHook(hook))
+
+			// first entry occupies the worker
+			logger.WithField("order", 1).Info("m1")
+			for logger.HookStats().Queued == 1 && len(logger.hookPool.queue) > 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			if tc.overflow == "block" {
+				go func() {
+					time.Sleep(20 * time.Millisecond)
+					close(hook.release)
+				}()
+			}
+			for i := 2; i <= 5; i++ {
+				logger.WithField("order", i).Info("m", i)
+			}
+			if tc.overflow != "block" {
+				close(hook.release)
+			}
+			logger.Close()
+
+			assert.Equal(t, tc.executed, hook.messages)
+			stats := logger.HookStats()
+			assert.Equal(t, tc.dropped, stats.Dropped)
+			assert.Equal(t, int64(len(tc.executed)), stats.Executed)
+
+			// closed pool drops
+			logger.Info("after close")
+			assert.Equal(t, tc.dropped+1, logger.HookStats().Dropped)
+		})
+	}
+
+	cfg, _ := config.ParseString(`log { hook_pool { overflow = "spill" } }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: unsupported hook_pool overflow 'spill'", err.Error())
+}
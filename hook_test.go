@@ -5,6 +5,7 @@
 package log
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"testing"
@@ -66,3 +67,49 @@ func TestLogHook(t *testing.T) {
 
 	time.Sleep(1 * time.Millisecond)
 }
+
+type testHook struct {
+	levels  []string
+	entries []string
+}
+
+func (h *testHook) Levels() []string {
+	return h.levels
+}
+
+func (h *testHook) Fire(e *Entry) {
+	h.entries = append(h.entries, e.Level.String()+" "+e.Message+" "+e.Fields.str("reqid"))
+	e.Fields["hooked"] = true
+}
+
+func TestLogEntryHook(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	errHook := &testHook{levels: []string{"error", "WARN"}}
+	allHook := &testHook{}
+	assert.Nil(t, logger.AddEntryHook(errHook))
+	assert.Nil(t, logger.AddEntryHook(allHook))
+
+	logger.WithField("reqid", "req-1").Info("order placed")
+	logger.Warn("low stock")
+	logger.Error("payment failed")
+	logger.Trace("not logged")
+
+	assert.Equal(t, []string{"WARN low stock ", "ERROR payment failed "}, errHook.entries)
+	assert.Equal(t, []string{"INFO order placed req-1", "WARN low stock ", "ERROR payment failed "}, allHook.entries)
+
+	// hook receives the entry before dispatch
+	assert.Equal(t, "INFO  order placed fields[hooked: true] \n"+
+		"WARN  low stock fields[hooked: true] \n"+
+		"ERROR payment failed fields[hooked: true] \n", buf.String())
+
+	assert.Equal(t, ErrHookIsNil, logger.AddEntryHook(nil))
+	err = logger.AddEntryHook(&testHook{levels: []string{"unknown"}})
+	assert.Equal(t, "log: unknown hook level 'unknown'", err.Error())
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var _ Receiver = (*HTTPReceiver)(nil)
+
+// HTTPReceiver posts the log entries to the configured HTTP endpoint. Entries
+// are batched and posted as newline delimited body (NDJSON for json format),
+// set `log.http.batch_size = 1` to post each entry in its own request.
+// Request body can be gzip compressed and request is retried on network
+// error, 429 and 5xx responses.
+type HTTPReceiver struct {
+	errorReporter
+	url          string
+	method       string
+	isGzip       bool
+	header       http.Header
+	sender       *httpSender
+	batcher      *batcher
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// HTTPReceiver methods
+//___________________________________
+
+// Init method initializes the HTTP receiver instance.
+func (h *HTTPReceiver) Init(cfg *config.Config) error {
+	h.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(h.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", h.formatter)
+	}
+
+	h.url = cfg.StringDefault("log.http.url", "")
+	if ess.IsStrEmpty(h.url) {
+		return errors.New("log: http url is required")
+	}
+	h.method = strings.ToUpper(cfg.StringDefault("log.http.method", http.MethodPost))
+	h.isGzip = cfg.BoolDefault("log.http.gzip", false)
+
+	h.header = http.Header{}
+	if h.formatter == jsonFmt {
+		h.header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		h.header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	if h.isGzip {
+		h.header.Set("Content-Encoding", "gzip")
+	}
+	for _, k := range cfg.KeysByPath("log.http.headers") {
+		h.header.Set(k, cfg.StringDefault("log.http.headers."+k, ""))
+	}
+	if token := cfg.StringDefault("log.http.token", ""); len(token) > 0 {
+		h.header.Set("Authorization", "Bearer "+token)
+	} else if username := cfg.StringDefault("log.http.username", ""); len(username) > 0 {
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(username, cfg.StringDefault("log.http.password", ""))
+		h.header.Set("Authorization", req.Header.Get("Authorization"))
+	}
+
+	var err error
+	if h.sender, err = newHTTPSender(cfg, "http"); err != nil {
+		return err
+	}
+
+	flushInterval, err := parseInterval(cfg, "log.http.flush_interval", "1s")
+	if err != nil {
+		return err
+	}
+	h.batcher = newBatcher(cfg.IntDefault("log.http.batch_size", 100),
+		cfg.IntDefault("log.http.queue_size", 1000), flushInterval, h.post)
+
+	h.SetWriter(writerFunc(func(p []byte) (int, error) {
+		h.batcher.add(append([]byte{}, p...))
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (h *HTTPReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	h.flags = flags
+	if h.formatter != jsonFmt {
+		h.isCallerInfo = isCallerInfo(h.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into HTTP receiver.
+func (h *HTTPReceiver) SetWriter(w io.Writer) {
+	h.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (h *HTTPReceiver) IsCallerInfo() bool {
+	return h.isCallerInfo
+}
+
+// Log method queues the log entry to be posted to HTTP endpoint.
+func (h *HTTPReceiver) Log(entry *Entry) {
+	line := formatEntry(h.formatter, h.flags, entry)
+
+	// fatal exits and panic unwinds the process, so it's posted right away
+	if entry.Level == LevelFatal || entry.Level == LevelPanic {
+		h.post([]interface{}{line})
+		return
+	}
+	if !h.batcher.add(line) {
+		h.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (h *HTTPReceiver) Writer() io.Writer {
+	return h.out
+}
+
+// Flush method posts the queued entries.
+func (h *HTTPReceiver) Flush() {
+	h.batcher.flush()
+}
+
+// Close method posts the queued entries and stops the receiver.
+func (h *HTTPReceiver) Close() {
+	h.batcher.close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// HTTPReceiver Unexported methods
+//___________________________________
+
+func (h *HTTPReceiver) post(items []interface{}) {
+	buf := &bytes.Buffer{}
+	var w io.Writer = buf
+	var gw *gzip.Writer
+	if h.isGzip {
+		gw = gzip.NewWriter(buf)
+		w = gw
+	}
+
+	for _, item := range items {
+		line := bytes.TrimRight(item.([]byte), " \n")
+		_, _ = w.Write(line)
+		_, _ = w.Write([]byte("\n"))
+	}
+	if gw != nil {
+		_ = gw.Close()
+	}
+
+	if _, err := h.sender.send(h.method, h.url, buf.Bytes(), h.header); err != nil {
+		h.reportError(err, nil)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestHTTPLogger(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		bodies   []string
+		header   http.Header
+		attempts int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		header = r.Header
+		gr, err := gzip.NewReader(r.Body)
+		assert.FailNowOnError(t, err, "unexpected error")
+		body, _ := ioutil.ReadAll(gr)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "http"
+    format = "json"
+    http {
+      url = "%s/ingest"
+      token = "s3cr3t"
+      gzip = true
+      flush_interval = "1h"
+      headers {
+        X-Source = "aah"
+      }
+      retry {
+        min_backoff = "1ms"
+      }
+    }
+  }
+  `, server.URL)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.Info("Yes, I would love to see")
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.Flush()
+
+	mu.Lock()
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, "Bearer s3cr3t", header.Get("Authorization"))
+	assert.Equal(t, "aah", header.Get("X-Source"))
+	assert.Equal(t, "application/x-ndjson", header.Get("Content-Type"))
+	assert.Equal(t, 1, len(bodies))
+	lines := strings.Split(strings.TrimSuffix(bodies[0], "\n"), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.Contains(lines[0], `"message":"Yes, I would love to see"`))
+	assert.True(t, strings.Contains(lines[1], `"level":"ERROR"`))
+	mu.Unlock()
+
+	logger.Close()
+}
+
+func TestHTTPLoggerText(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, http.MethodPut, r.Method)
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log { receiver = "http", pattern = "%%level:-5 %%message", http { url = "%s", method = "put", batch_size = 1 } }`, server.URL))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.Info("Yes, I would love to see")
+	logger.Warn("Yes, yes it's an warning")
+	logger.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"INFO  Yes, I would love to see\n", "WARN  Yes, yes it's an warning\n"}, bodies)
+}
+
+func TestHTTPLoggerDeliveryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log { receiver = "http", http { url = "%s", batch_size = 1 } }`, server.URL))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	var (
+		mu       sync.Mutex
+		reported []error
+	)
+	logger.SetErrorHandler(func(err error, e *Entry) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	})
+
+	logger.Info("Yes, I would love to see")
+	logger.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, len(reported))
+	assert.Equal(t, "log: http status 400: bad request", reported[0].Error())
+}
+
+func TestHTTPLoggerRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	cfg, _ := config.ParseString(`log { http { retry { max = 1, min_backoff = "1ms", max_backoff = "10ms" } } }`)
+	sender, err := newHTTPSender(cfg, "http")
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	start := time.Now()
+	_, err = sender.send(http.MethodPost, server.URL, []byte("{}"), nil)
+	assert.Equal(t, "log: http status 429: slow down", err.Error())
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestHTTPLoggerCloseFlush(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log { receiver = "http", http { url = "%s" } }`, server.URL))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.Info("Yes, I would love to see")
+	logger.Close()
+
+	// flush and close after close are no-op
+	done := make(chan struct{})
+	go func() {
+		logger.Flush()
+		logger.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("flush after close is blocked")
+	}
+}
+
+func TestHTTPLoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "http" }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: http url is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "http", http { url = "http://localhost", flush_interval = "0s" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid interval '0s' for 'log.http.flush_interval'", err.Error())
+}
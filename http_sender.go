@@ -0,0 +1,131 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"aahframework.org/config.v0"
+)
+
+// httpStatusError represents the unsuccessful HTTP response.
+type httpStatusError struct {
+	code int
+	body string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("log: http status %d: %s", e.code, e.body)
+}
+
+// httpSender sends the HTTP request with retry, it's shared by the receivers
+// which pushes entries to HTTP APIs.
+type httpSender struct {
+	client     *http.Client
+	header     http.Header
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// newHTTPSender method creates the HTTP sender from config section
+// `log.<section>`, supported keys are `timeout`, `retry.max`,
+// `retry.min_backoff` and `retry.max_backoff`.
+func newHTTPSender(cfg *config.Config, section string) (*httpSender, error) {
+	keyPrefix := "log." + section + "."
+	s := &httpSender{
+		header:     http.Header{},
+		maxRetries: cfg.IntDefault(keyPrefix+"retry.max", 3),
+	}
+
+	timeout, err := parseDuration(cfg, keyPrefix+"timeout", "10s")
+	if err != nil {
+		return nil, err
+	}
+	if s.minBackoff, err = parseDuration(cfg, keyPrefix+"retry.min_backoff", "500ms"); err != nil {
+		return nil, err
+	}
+	if s.maxBackoff, err = parseDuration(cfg, keyPrefix+"retry.max_backoff", "10s"); err != nil {
+		return nil, err
+	}
+
+	s.client = &http.Client{Timeout: timeout}
+	s.header.Set("User-Agent", "aah-log/"+Version)
+	return s, nil
+}
+
+// send method sends the request and returns the response body. Request is
+// retried with exponential backoff on network error, 429 and 5xx responses,
+// `Retry-After` header value in seconds is honored up to max backoff.
+func (s *httpSender) send(method, url string, body []byte, header http.Header) ([]byte, error) {
+	var (
+		err     error
+		backoff = s.minBackoff
+	)
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > s.maxBackoff {
+				backoff = s.maxBackoff
+			}
+		}
+
+		var (
+			resBody    []byte
+			retryAfter time.Duration
+		)
+		resBody, retryAfter, err = s.do(method, url, body, header)
+		if err == nil {
+			return resBody, nil
+		}
+		if se, ok := err.(*httpStatusError); ok && !(se.code == http.StatusTooManyRequests || se.code >= 500) {
+			return nil, err
+		}
+		if retryAfter > backoff {
+			backoff = retryAfter
+			if backoff > s.maxBackoff {
+				backoff = s.maxBackoff
+			}
+		}
+	}
+	return nil, err
+}
+
+func (s *httpSender) do(method, url string, body []byte, header http.Header) ([]byte, time.Duration, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	for k, v := range s.header {
+		req.Header[k] = v
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return nil, retryAfter, &httpStatusError{code: res.StatusCode, body: string(bytes.TrimSpace(resBody))}
+	}
+	return resBody, 0, nil
+}
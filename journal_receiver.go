@@ -0,0 +1,159 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const defaultJournalSocket = "/run/systemd/journal/socket"
+
+var _ Receiver = (*JournalReceiver)(nil)
+
+// JournalReceiver writes the log entry into systemd journal via native
+// protocol. Entry fields are mapped into journal fields as uppercase keys.
+type JournalReceiver struct {
+	errorReporter
+	socket       string
+	identifier   string
+	conn         net.Conn
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.Mutex
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// JournalReceiver methods
+//___________________________________
+
+// Init method initializes the journal receiver instance.
+func (j *JournalReceiver) Init(cfg *config.Config) error {
+	j.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(j.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", j.formatter)
+	}
+
+	j.socket = cfg.StringDefault("log.journal.socket", defaultJournalSocket)
+	j.identifier = cfg.StringDefault("log.journal.identifier", filepath.Base(os.Args[0]))
+
+	conn, err := net.Dial("unixgram", j.socket)
+	if err != nil {
+		return err
+	}
+	j.conn = conn
+	j.SetWriter(conn)
+
+	j.mu = sync.Mutex{}
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (j *JournalReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	j.flags = flags
+	if j.formatter != jsonFmt {
+		j.isCallerInfo = isCallerInfo(j.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into journal receiver.
+func (j *JournalReceiver) SetWriter(w io.Writer) {
+	j.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (j *JournalReceiver) IsCallerInfo() bool {
+	return j.isCallerInfo
+}
+
+// Log method writes the log entry into systemd journal.
+func (j *JournalReceiver) Log(entry *Entry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	buf := acquireBuffer()
+	defer releaseBuffer(buf)
+
+	msg := bytes.TrimRight(formatEntry(j.formatter, j.flags, entry), " \n")
+	writeJournalField(buf, "MESSAGE", string(msg))
+	writeJournalField(buf, "PRIORITY", strconv.Itoa(syslogSeverities[levelToSyslogSeverity[builtinLevel(entry.Level)]]))
+	writeJournalField(buf, "SYSLOG_IDENTIFIER", j.identifier)
+	if len(entry.File) > 0 {
+		writeJournalField(buf, "CODE_FILE", entry.File)
+		writeJournalField(buf, "CODE_LINE", strconv.Itoa(entry.Line))
+	}
+
+	keys := make([]string, 0, len(entry.Fields))
+	for k := range entry.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if name := journalFieldName(k); len(name) > 0 {
+			writeJournalField(buf, name, fmt.Sprint(entry.Fields[k]))
+		}
+	}
+
+	if _, err := j.out.Write(buf.Bytes()); err != nil {
+		j.reportError(err, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (j *JournalReceiver) Writer() io.Writer {
+	return j.out
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// writeJournalField method writes the field as per journal native protocol,
+// multi-line values are written with binary safe serialization.
+func writeJournalField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if strings.ContainsRune(value, '\n') {
+		buf.WriteByte('\n')
+		_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	} else {
+		buf.WriteByte('=')
+	}
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName method returns journal compliant field name, it can
+// contain only uppercase letters, digits and underscore and can't start with
+// underscore or digit.
+func journalFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if !((c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			name[i] = '_'
+		}
+	}
+	return strings.TrimLeft(string(name), "_0123456789")
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestJournalLogger(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "aahlog")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	socket := filepath.Join(dir, "journal.socket")
+	pc, err := net.ListenPacket("unixgram", socket)
+	assert.FailNowOnError(t, err, "unable to listen unixgram")
+	defer func() { _ = pc.Close() }()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "journal"
+    level = "debug"
+    pattern = "%%message"
+    journal {
+      socket = "%s"
+      identifier = "aahapp"
+    }
+  }
+  `, socket)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("reqid", "40139CA6368607085BF6").
+		WithField("_private.key", "value").
+		Warn("Yes, yes it's an warning")
+
+	msg := readJournalPacket(t, pc)
+	assert.True(t, strings.Contains(msg, "MESSAGE=Yes, yes it's an warning\n"))
+	assert.True(t, strings.Contains(msg, "PRIORITY=4\n"))
+	assert.True(t, strings.Contains(msg, "SYSLOG_IDENTIFIER=aahapp\n"))
+	assert.True(t, strings.Contains(msg, "REQID=40139CA6368607085BF6\n"))
+	assert.True(t, strings.Contains(msg, "PRIVATE_KEY=value\n"))
+
+	logger.Error("multi-line\nerror message")
+	msg = readJournalPacket(t, pc)
+	assert.True(t, strings.HasPrefix(msg, "MESSAGE\n\x18\x00\x00\x00\x00\x00\x00\x00multi-line\nerror message\n"))
+	assert.True(t, strings.Contains(msg, "PRIORITY=3\n"))
+}
+
+func TestJournalLoggerSocketError(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "journal", journal { socket = "/not/exists/journal.socket" } }`)
+	logger, err := New(cfg)
+	assert.Nil(t, logger)
+	assert.NotNil(t, err)
+}
+
+func TestJournalFieldName(t *testing.T) {
+	assert.Equal(t, "APPNAME", journalFieldName("appname"))
+	assert.Equal(t, "HTTP_STATUS", journalFieldName("http-status"))
+	assert.Equal(t, "KEY", journalFieldName("_1key"))
+	assert.Equal(t, "", journalFieldName("__"))
+
+	buf := &bytes.Buffer{}
+	writeJournalField(buf, "KEY", "value")
+	assert.Equal(t, "KEY=value\n", buf.String())
+}
+
+func readJournalPacket(t *testing.T, pc net.PacketConn) string {
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.FailNowOnError(t, err, "unable to read journal packet")
+	return string(buf[:n])
+}
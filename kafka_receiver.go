@@ -0,0 +1,639 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const (
+	kafkaAPIProduce  int16 = 0
+	kafkaAPIMetadata int16 = 3
+
+	// kafkaMaxResponseSize is the upper bound of broker response size,
+	// same as broker default `socket.request.max.bytes`.
+	kafkaMaxResponseSize int32 = 100 << 20
+)
+
+var (
+	// ErrKafkaQueueFull returned when kafka receiver queue is full and entry
+	// is dropped.
+	ErrKafkaQueueFull = errors.New("log: kafka queue is full, entry dropped")
+
+	crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+	_ Receiver = (*KafkaReceiver)(nil)
+)
+
+type (
+	// KafkaErrorHandler type is called when kafka receiver is unable to deliver
+	// the messages to broker.
+	KafkaErrorHandler func(err error, messages []*KafkaMessage)
+
+	// KafkaMessage represents the message published into kafka topic.
+	KafkaMessage struct {
+		Key       []byte
+		Value     []byte
+		Timestamp time.Time
+		partition int32
+	}
+
+	// KafkaReceiver publishes the log entry into kafka topic. Messages are
+	// batched asynchronously and produced to partition leaders using kafka
+	// wire protocol (Produce v3, record batch v2).
+	KafkaReceiver struct {
+		errorReporter
+		brokers       []string
+		clientID      string
+		topic         string
+		keyField      string
+		acks          int16
+		timeout       time.Duration
+		batchSize     int
+		flushInterval time.Duration
+		leaders       map[int32]string
+		partitions    []int32
+		conns         map[string]net.Conn
+		correlationID int32
+		rr            uint32
+		queue         chan *KafkaMessage
+		done          chan struct{}
+		closeOnce     sync.Once
+		errHandler    KafkaErrorHandler
+		out           io.Writer
+		formatter     string
+		flags         []ess.FmtFlagPart
+		isCallerInfo  bool
+		mu            sync.Mutex
+		wg            sync.WaitGroup
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// KafkaReceiver methods
+//___________________________________
+
+// Init method initializes the kafka receiver instance.
+func (k *KafkaReceiver) Init(cfg *config.Config) error {
+	k.formatter = cfg.StringDefault("log.format", jsonFmt)
+	if !isFormatSupported(k.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", k.formatter)
+	}
+
+	if brokers, found := cfg.StringList("log.kafka.brokers"); found {
+		k.brokers = brokers
+	} else {
+		k.brokers = kafkaBrokerAddr(cfg.StringDefault("log.kafka.brokers", ""))
+	}
+	if len(k.brokers) == 0 {
+		return errors.New("log: kafka brokers is required")
+	}
+
+	k.topic = cfg.StringDefault("log.kafka.topic", "")
+	if ess.IsStrEmpty(k.topic) {
+		return errors.New("log: kafka topic is required")
+	}
+
+	k.clientID = cfg.StringDefault("log.kafka.client_id", "aah-log")
+	k.keyField = cfg.StringDefault("log.kafka.key", "")
+	k.acks = int16(cfg.IntDefault("log.kafka.acks", 1))
+	k.batchSize = cfg.IntDefault("log.kafka.batch_size", 100)
+	if k.batchSize <= 0 {
+		k.batchSize = 1
+	}
+
+	var err error
+	if k.timeout, err = parseDuration(cfg, "log.kafka.timeout", "10s"); err != nil {
+		return err
+	}
+	if k.flushInterval, err = parseInterval(cfg, "log.kafka.flush_interval", "1s"); err != nil {
+		return err
+	}
+
+	k.conns = make(map[string]net.Conn)
+	if err = k.refreshMetadata(); err != nil {
+		return err
+	}
+
+	k.mu = sync.Mutex{}
+	k.queue = make(chan *KafkaMessage, cfg.IntDefault("log.kafka.queue_size", 10000))
+	k.done = make(chan struct{})
+	k.SetWriter(writerFunc(func(p []byte) (int, error) {
+		return len(p), k.enqueue(&KafkaMessage{Value: bytes.TrimRight(p, "\n"), Timestamp: time.Now()})
+	}))
+
+	k.wg.Add(1)
+	go k.run()
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (k *KafkaReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	k.flags = flags
+	if k.formatter != jsonFmt {
+		k.isCallerInfo = isCallerInfo(k.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into kafka receiver.
+func (k *KafkaReceiver) SetWriter(w io.Writer) {
+	k.out = w
+}
+
+// SetErrorHandler method sets the callback which is called on message
+// delivery failure with the undelivered messages. Failure is reported to
+// the logger error handler too, see `Logger.SetErrorHandler`.
+func (k *KafkaReceiver) SetErrorHandler(h KafkaErrorHandler) {
+	k.errHandler = h
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (k *KafkaReceiver) IsCallerInfo() bool {
+	return k.isCallerInfo
+}
+
+// Log method queues the log entry to be published into kafka topic.
+func (k *KafkaReceiver) Log(entry *Entry) {
+	// key is resolved before formatting, formatter may exclude the field
+	msg := &KafkaMessage{Timestamp: entry.Time}
+	if key := entry.Fields.str(k.keyField); len(key) > 0 {
+		msg.Key = []byte(key)
+	}
+	msg.Value = bytes.TrimRight(formatEntry(k.formatter, k.flags, entry), " \n")
+
+	if err := k.enqueue(msg); err != nil {
+		k.handleError(err, []*KafkaMessage{msg}, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (k *KafkaReceiver) Writer() io.Writer {
+	return k.out
+}
+
+// Close method publishes the queued messages and closes the broker
+// connections.
+func (k *KafkaReceiver) Close() {
+	k.closeOnce.Do(func() { close(k.done) })
+	k.wg.Wait()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for addr, conn := range k.conns {
+		ess.CloseQuietly(conn)
+		delete(k.conns, addr)
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// KafkaReceiver Unexported methods
+//___________________________________
+
+func (k *KafkaReceiver) enqueue(msg *KafkaMessage) error {
+	select {
+	case k.queue <- msg:
+		return nil
+	default:
+		return ErrKafkaQueueFull
+	}
+}
+
+func (k *KafkaReceiver) run() {
+	defer k.wg.Done()
+	ticker := time.NewTicker(k.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*KafkaMessage, 0, k.batchSize)
+	for {
+		select {
+		case msg := <-k.queue:
+			if batch = append(batch, msg); len(batch) >= k.batchSize {
+				k.publish(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				k.publish(batch)
+				batch = batch[:0]
+			}
+		case <-k.done:
+			for {
+				select {
+				case msg := <-k.queue:
+					batch = append(batch, msg)
+				default:
+					if len(batch) > 0 {
+						k.publish(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// publish method produces the messages to its partition leaders, on failure
+// metadata is refreshed and retried once.
+func (k *KafkaReceiver) publish(batch []*KafkaMessage) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, msg := range batch {
+		msg.partition = k.partitionFor(msg.Key)
+	}
+
+	failed, err := k.produce(batch)
+	if err != nil && len(failed) > 0 {
+		if err = k.refreshMetadata(); err == nil {
+			failed, err = k.produce(failed)
+		}
+	}
+
+	if err != nil && len(failed) > 0 {
+		k.handleError(err, failed, nil)
+	}
+}
+
+// partitionFor method returns the partition for the message key, messages
+// without key are distributed in round-robin manner.
+func (k *KafkaReceiver) partitionFor(key []byte) int32 {
+	cnt := uint32(len(k.partitions))
+	if len(key) == 0 {
+		k.rr++
+		return k.partitions[k.rr%cnt]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return k.partitions[h.Sum32()%cnt]
+}
+
+func (k *KafkaReceiver) produce(batch []*KafkaMessage) ([]*KafkaMessage, error) {
+	byLeader := make(map[string]map[int32][]*KafkaMessage)
+	for _, msg := range batch {
+		leader := k.leaders[msg.partition]
+		if _, found := byLeader[leader]; !found {
+			byLeader[leader] = make(map[int32][]*KafkaMessage)
+		}
+		byLeader[leader][msg.partition] = append(byLeader[leader][msg.partition], msg)
+	}
+
+	var (
+		failed  []*KafkaMessage
+		lastErr error
+	)
+	for leader, partitions := range byLeader {
+		if err := k.produceTo(leader, partitions); err != nil {
+			lastErr = err
+			for _, msgs := range partitions {
+				failed = append(failed, msgs...)
+			}
+		}
+	}
+	return failed, lastErr
+}
+
+func (k *KafkaReceiver) produceTo(addr string, partitions map[int32][]*KafkaMessage) error {
+	e := &kafkaEncoder{}
+	e.int16(-1) // transactional_id
+	e.int16(k.acks)
+	e.int32(int32(k.timeout / time.Millisecond))
+	e.int32(1)
+	e.string(k.topic)
+	e.int32(int32(len(partitions)))
+	for partition, msgs := range partitions {
+		e.int32(partition)
+		e.bytes(encodeKafkaRecordBatch(msgs))
+	}
+
+	resp, err := k.request(addr, kafkaAPIProduce, 3, e.Bytes())
+	if err != nil || k.acks == 0 {
+		return err
+	}
+
+	d := &kafkaDecoder{b: resp}
+	for topics := d.arrayLen(6); topics > 0 && d.err == nil; topics-- {
+		_ = d.string()
+		for cnt := d.arrayLen(22); cnt > 0 && d.err == nil; cnt-- {
+			partition := d.int32()
+			if code := d.int16(); code != 0 {
+				err = fmt.Errorf("log: kafka produce error code %d on partition %d", code, partition)
+			}
+			_, _ = d.int64(), d.int64()
+		}
+	}
+	if err == nil {
+		err = d.err
+	}
+	return err
+}
+
+// refreshMetadata method fetches topic partition leaders from the brokers.
+func (k *KafkaReceiver) refreshMetadata() error {
+	e := &kafkaEncoder{}
+	e.int32(1)
+	e.string(k.topic)
+
+	var lastErr error
+	for _, broker := range k.brokers {
+		resp, err := k.request(broker, kafkaAPIMetadata, 1, e.Bytes())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		d := &kafkaDecoder{b: resp}
+		brokers := make(map[int32]string)
+		for cnt := d.arrayLen(12); cnt > 0 && d.err == nil; cnt-- {
+			id, host, port := d.int32(), d.string(), d.int32()
+			_ = d.string() // rack
+			brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+		}
+		_ = d.int32() // controller_id
+
+		leaders := make(map[int32]string)
+		var partitions []int32
+		for topics := d.arrayLen(9); topics > 0 && d.err == nil; topics-- {
+			if code := d.int16(); code != 0 {
+				lastErr = fmt.Errorf("log: kafka metadata error code %d for topic '%s'", code, k.topic)
+			}
+			_, _ = d.string(), d.int8()
+			for cnt := d.arrayLen(18); cnt > 0 && d.err == nil; cnt-- {
+				_ = d.int16()
+				partition, leader := d.int32(), d.int32()
+				d.int32Array() // replicas
+				d.int32Array() // isr
+				if addr, found := brokers[leader]; found {
+					leaders[partition] = addr
+					partitions = append(partitions, partition)
+				}
+			}
+		}
+		if d.err != nil {
+			lastErr = d.err
+			continue
+		}
+
+		if len(partitions) > 0 {
+			k.leaders = leaders
+			k.partitions = partitions
+			return nil
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("log: kafka topic '%s' has no available partitions", k.topic)
+	}
+	return lastErr
+}
+
+// request method sends the kafka request to broker and returns response
+// body without correlation id.
+func (k *KafkaReceiver) request(addr string, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	conn, found := k.conns[addr]
+	if !found {
+		var err error
+		if conn, err = net.DialTimeout("tcp", addr, k.timeout); err != nil {
+			return nil, err
+		}
+		k.conns[addr] = conn
+	}
+
+	k.correlationID++
+	e := &kafkaEncoder{}
+	e.int32(0) // size placeholder
+	e.int16(apiKey)
+	e.int16(apiVersion)
+	e.int32(k.correlationID)
+	e.string(k.clientID)
+	_, _ = e.Write(body)
+	req := e.Bytes()
+	binary.BigEndian.PutUint32(req, uint32(len(req)-4))
+
+	resp, err := k.roundTrip(conn, req, apiKey == kafkaAPIProduce && k.acks == 0)
+	if err != nil {
+		ess.CloseQuietly(conn)
+		delete(k.conns, addr)
+	}
+	return resp, err
+}
+
+func (k *KafkaReceiver) roundTrip(conn net.Conn, req []byte, noResponse bool) ([]byte, error) {
+	_ = conn.SetDeadline(time.Now().Add(k.timeout))
+	if _, err := conn.Write(req); err != nil || noResponse {
+		return nil, err
+	}
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	if id := int32(binary.BigEndian.Uint32(header[4:])); id != k.correlationID {
+		return nil, fmt.Errorf("log: kafka correlation id mismatch, expected %d got %d", k.correlationID, id)
+	}
+
+	size := int32(binary.BigEndian.Uint32(header))
+	if size < 4 || size > kafkaMaxResponseSize {
+		return nil, fmt.Errorf("log: kafka invalid response size %d", size)
+	}
+
+	resp := make([]byte, size-4)
+	_, err := io.ReadFull(conn, resp)
+	return resp, err
+}
+
+func (k *KafkaReceiver) handleError(err error, messages []*KafkaMessage, entry *Entry) {
+	if k.errHandler != nil {
+		k.errHandler(err, messages)
+	}
+	k.reportError(err, entry)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Kafka protocol encoding
+//___________________________________
+
+// encodeKafkaRecordBatch method encodes the messages as kafka record batch
+// v2 (magic byte 2) without compression.
+func encodeKafkaRecordBatch(msgs []*KafkaMessage) []byte {
+	first, max := msgs[0].Timestamp, msgs[0].Timestamp
+	for _, msg := range msgs {
+		if msg.Timestamp.After(max) {
+			max = msg.Timestamp
+		}
+	}
+
+	records := &kafkaEncoder{}
+	for i, msg := range msgs {
+		r := &kafkaEncoder{}
+		r.int8(0) // attributes
+		r.varint(int64(msg.Timestamp.Sub(first) / time.Millisecond))
+		r.varint(int64(i))
+		if msg.Key == nil {
+			r.varint(-1)
+		} else {
+			r.varint(int64(len(msg.Key)))
+			_, _ = r.Write(msg.Key)
+		}
+		r.varint(int64(len(msg.Value)))
+		_, _ = r.Write(msg.Value)
+		r.varint(0) // headers
+
+		records.varint(int64(r.Len()))
+		_, _ = records.Write(r.Bytes())
+	}
+
+	// fields covered by CRC, from attributes to end of records
+	crcPart := &kafkaEncoder{}
+	crcPart.int16(0) // attributes
+	crcPart.int32(int32(len(msgs) - 1))
+	crcPart.int64(toMillis(first))
+	crcPart.int64(toMillis(max))
+	crcPart.int64(-1) // producer id
+	crcPart.int16(-1) // producer epoch
+	crcPart.int32(-1) // base sequence
+	crcPart.int32(int32(len(msgs)))
+	_, _ = crcPart.Write(records.Bytes())
+
+	batch := &kafkaEncoder{}
+	batch.int64(0) // base offset
+	batch.int32(int32(4 + 1 + 4 + crcPart.Len()))
+	batch.int32(-1) // partition leader epoch
+	batch.int8(2)   // magic
+	batch.int32(int32(crc32.Checksum(crcPart.Bytes(), crc32cTable)))
+	_, _ = batch.Write(crcPart.Bytes())
+	return batch.Bytes()
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+type kafkaEncoder struct {
+	bytes.Buffer
+}
+
+func (e *kafkaEncoder) int8(v int8) {
+	_ = e.WriteByte(byte(v))
+}
+
+func (e *kafkaEncoder) int16(v int16) {
+	_ = binary.Write(e, binary.BigEndian, v)
+}
+
+func (e *kafkaEncoder) int32(v int32) {
+	_ = binary.Write(e, binary.BigEndian, v)
+}
+
+func (e *kafkaEncoder) int64(v int64) {
+	_ = binary.Write(e, binary.BigEndian, v)
+}
+
+func (e *kafkaEncoder) varint(v int64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	_, _ = e.Write(b[:binary.PutVarint(b, v)])
+}
+
+func (e *kafkaEncoder) string(v string) {
+	e.int16(int16(len(v)))
+	_, _ = e.WriteString(v)
+}
+
+func (e *kafkaEncoder) bytes(v []byte) {
+	e.int32(int32(len(v)))
+	_, _ = e.Write(v)
+}
+
+type kafkaDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *kafkaDecoder) next(n int) []byte {
+	if d.err != nil || n < 0 || len(d.b) < n {
+		if d.err == nil {
+			d.err = errors.New("log: kafka response is malformed")
+		}
+		return make([]byte, 8)
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *kafkaDecoder) int8() int8 {
+	return int8(d.next(1)[0])
+}
+
+func (d *kafkaDecoder) int16() int16 {
+	return int16(binary.BigEndian.Uint16(d.next(2)))
+}
+
+func (d *kafkaDecoder) int32() int32 {
+	return int32(binary.BigEndian.Uint32(d.next(4)))
+}
+
+func (d *kafkaDecoder) int64() int64 {
+	return int64(binary.BigEndian.Uint64(d.next(8)))
+}
+
+// arrayLen method reads the array length, it's malformed if remaining bytes
+// are less than the length of elements of given minimum size.
+func (d *kafkaDecoder) arrayLen(size int) int32 {
+	n := d.int32()
+	if d.err == nil && n > 0 && int64(n)*int64(size) > int64(len(d.b)) {
+		d.err = errors.New("log: kafka response is malformed")
+	}
+	if d.err != nil {
+		return 0
+	}
+	return n
+}
+
+func (d *kafkaDecoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(int(n)))
+}
+
+func (d *kafkaDecoder) int32Array() {
+	for cnt := d.arrayLen(4); cnt > 0 && d.err == nil; cnt-- {
+		_ = d.int32()
+	}
+}
+
+// kafkaBrokerAddr method is used to parse broker address list given as
+// comma separated value.
+func kafkaBrokerAddr(v string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(v, ",") {
+		if addr = strings.TrimSpace(addr); len(addr) > 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
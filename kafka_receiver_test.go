@@ -0,0 +1,338 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestKafkaLogger(t *testing.T) {
+	broker := newTestKafkaBroker(t, 3)
+	defer broker.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "kafka"
+    pattern = "%%level:-5 %%message"
+    format = "text"
+    kafka {
+      brokers = ["%s"]
+      topic = "aah-logs"
+      key = "reqid"
+      batch_size = 3
+      flush_interval = "50ms"
+    }
+  }
+  `, broker.addr)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	receiver := logger.receiver.(*KafkaReceiver)
+	assert.Equal(t, 3, len(receiver.partitions))
+
+	logger.WithField("reqid", "40139CA6368607085BF6").Info("Yes, I would love to see")
+	logger.WithField("reqid", "40139CA6368607085BF6").Warn("Yes, yes it's an warning")
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.Debug("I would like to see this message, debug is useful for dev")
+	receiver.Close()
+
+	records := broker.Records()
+	assert.Equal(t, 4, len(records))
+
+	keyed := make(map[string]int32)
+	for _, r := range records {
+		if r.key == "40139CA6368607085BF6" {
+			keyed[r.value] = r.partition
+		}
+	}
+	assert.Equal(t, 2, len(keyed))
+	assert.Equal(t, keyed["INFO  Yes, I would love to see"], keyed["WARN  Yes, yes it's an warning"])
+}
+
+func TestKafkaLoggerKeyJSON(t *testing.T) {
+	broker := newTestKafkaBroker(t, 3)
+	defer broker.Close()
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log { receiver = "kafka", kafka { brokers = "%s", topic = "aah-logs", key = "reqid" } }`, broker.addr))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	e := logger.WithField("reqid", "40139CA6368607085BF6").(*Entry)
+	e.Info("Yes, I would love to see")
+	e.Warn("Yes, yes it's an warning")
+	receiver := logger.receiver.(*KafkaReceiver)
+	receiver.Close()
+	receiver.Close()
+
+	records := broker.Records()
+	assert.Equal(t, 2, len(records))
+	for _, r := range records {
+		assert.Equal(t, "40139CA6368607085BF6", r.key)
+	}
+	assert.Equal(t, "40139CA6368607085BF6", e.Fields["reqid"])
+}
+
+func TestKafkaLoggerInvalidResponseSize(t *testing.T) {
+	for _, size := range []uint32{2, 0x80000000, uint32(kafkaMaxResponseSize) + 1} {
+		client, server := net.Pipe()
+		go func(size uint32) {
+			req := make([]byte, 8)
+			_, _ = io.ReadFull(server, req)
+			header := make([]byte, 8)
+			binary.BigEndian.PutUint32(header, size)
+			_, _ = server.Write(header)
+		}(size)
+
+		k := &KafkaReceiver{timeout: time.Second}
+		_, err := k.roundTrip(client, make([]byte, 8), false)
+		assert.Equal(t, fmt.Sprintf("log: kafka invalid response size %d", int32(size)), err.Error())
+		_ = client.Close()
+		_ = server.Close()
+	}
+}
+
+func TestKafkaDecoderArrayLen(t *testing.T) {
+	// huge count with truncated response
+	d := &kafkaDecoder{b: []byte{0x7f, 0xff, 0xff, 0xff, 0, 1}}
+	assert.Equal(t, int32(0), d.arrayLen(4))
+	assert.Equal(t, "log: kafka response is malformed", d.err.Error())
+
+	d = &kafkaDecoder{b: []byte{0, 0, 0, 2, 0, 0, 0, 7, 0, 0, 0, 9}}
+	assert.Equal(t, int32(2), d.arrayLen(4))
+	assert.Nil(t, d.err)
+
+	d = &kafkaDecoder{b: []byte{0, 0, 0, 3, 0, 0, 0, 7}}
+	d.int32Array()
+	assert.Equal(t, "log: kafka response is malformed", d.err.Error())
+}
+
+func TestKafkaLoggerDeliveryError(t *testing.T) {
+	broker := newTestKafkaBroker(t, 1)
+	defer broker.Close()
+	broker.produceErrCode = 6 // NOT_LEADER_FOR_PARTITION
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log { receiver = "kafka", kafka { brokers = "%s", topic = "aah-logs" } }`, broker.addr))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	var (
+		mu     sync.Mutex
+		failed []*KafkaMessage
+		lerr   error
+	)
+	receiver := logger.receiver.(*KafkaReceiver)
+	receiver.SetErrorHandler(func(err error, messages []*KafkaMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		lerr = err
+		failed = append(failed, messages...)
+	})
+
+	var reported []error
+	logger.SetErrorHandler(func(err error, e *Entry) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	})
+
+	logger.Info("Yes, I would love to see")
+	receiver.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, len(failed))
+	assert.Equal(t, "log: kafka produce error code 6 on partition 0", lerr.Error())
+	assert.Equal(t, 1, len(reported))
+	assert.Equal(t, lerr, reported[0])
+}
+
+func TestKafkaLoggerConfigErrors(t *testing.T) {
+	testcases := []struct {
+		cfg string
+		err string
+	}{
+		{cfg: `kafka { topic = "aah-logs" }`, err: "log: kafka brokers is required"},
+		{cfg: `kafka { brokers = "localhost:9092" }`, err: "log: kafka topic is required"},
+		{cfg: `kafka { brokers = "localhost:9092", topic = "aah-logs", timeout = "10" }`, err: "log: invalid duration '10' for 'log.kafka.timeout'"},
+		{cfg: `format = "xml"`, err: "log: unsupported format 'xml'"},
+	}
+
+	for _, tc := range testcases {
+		cfg, _ := config.ParseString(`log { receiver = "kafka"
+		` + tc.cfg + ` }`)
+		logger, err := New(cfg)
+		assert.Nil(t, logger)
+		assert.Equal(t, tc.err, err.Error())
+	}
+
+	assert.Equal(t, []string{"host1:9092", "host2:9092"}, kafkaBrokerAddr(" host1:9092, host2:9092,"))
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Test kafka broker
+//___________________________________
+
+type testKafkaRecord struct {
+	partition int32
+	key       string
+	value     string
+}
+
+type testKafkaBroker struct {
+	t              *testing.T
+	ln             net.Listener
+	addr           string
+	partitions     int32
+	produceErrCode int16
+	mu             sync.Mutex
+	records        []testKafkaRecord
+}
+
+func newTestKafkaBroker(t *testing.T, partitions int32) *testKafkaBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+	b := &testKafkaBroker{t: t, ln: ln, addr: ln.Addr().String(), partitions: partitions}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *testKafkaBroker) Close() {
+	_ = b.ln.Close()
+}
+
+func (b *testKafkaBroker) Records() []testKafkaRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]testKafkaRecord(nil), b.records...)
+}
+
+func (b *testKafkaBroker) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	for {
+		size := make([]byte, 4)
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(size))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+
+		d := &kafkaDecoder{b: req}
+		apiKey, _, correlationID, _ := d.int16(), d.int16(), d.int32(), d.string()
+
+		e := &kafkaEncoder{}
+		e.int32(0)
+		e.int32(correlationID)
+		switch apiKey {
+		case kafkaAPIMetadata:
+			b.metadata(e)
+		case kafkaAPIProduce:
+			b.produce(d, e)
+		}
+		resp := e.Bytes()
+		binary.BigEndian.PutUint32(resp, uint32(len(resp)-4))
+		_, _ = conn.Write(resp)
+	}
+}
+
+func (b *testKafkaBroker) metadata(e *kafkaEncoder) {
+	host, port, _ := net.SplitHostPort(b.addr)
+	p, _ := strconv.Atoi(port)
+	e.int32(1)
+	e.int32(1)
+	e.string(host)
+	e.int32(int32(p))
+	e.int16(-1)
+	e.int32(1)
+	e.int32(1)
+	e.int16(0)
+	e.string("aah-logs")
+	e.int8(0)
+	e.int32(b.partitions)
+	for i := int32(0); i < b.partitions; i++ {
+		e.int16(0)
+		e.int32(i)
+		e.int32(1)
+		e.int32(0)
+		e.int32(0)
+	}
+}
+
+func (b *testKafkaBroker) produce(d *kafkaDecoder, e *kafkaEncoder) {
+	_, _, _ = d.int16(), d.int16(), d.int32()
+	_ = d.int32()
+	topic := d.string()
+	cnt := d.int32()
+
+	e.int32(1)
+	e.string(topic)
+	e.int32(cnt)
+	for ; cnt > 0; cnt-- {
+		partition := d.int32()
+		batch := d.next(int(d.int32()))
+		b.decodeBatch(partition, batch)
+
+		e.int32(partition)
+		e.int16(b.produceErrCode)
+		e.int64(0)
+		e.int64(-1)
+	}
+	e.int32(0)
+}
+
+func (b *testKafkaBroker) decodeBatch(partition int32, batch []byte) {
+	bd := &kafkaDecoder{b: batch}
+	_, _, _, _ = bd.int64(), bd.int32(), bd.int32(), bd.int8()
+	crc := uint32(bd.int32())
+	assert.Equal(b.t, crc32.Checksum(bd.b, crc32cTable), crc)
+	_, _, _, _, _, _, _ = bd.int16(), bd.int32(), bd.int64(), bd.int64(), bd.int64(), bd.int16(), bd.int32()
+	cnt := bd.int32()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	rest := bd.b
+	for i := int32(0); i < cnt; i++ {
+		l, n := binary.Varint(rest)
+		rec := rest[n : n+int(l)]
+		rest = rest[n+int(l):]
+
+		rec = rec[1:]
+		_, n = binary.Varint(rec)
+		rec = rec[n:]
+		_, n = binary.Varint(rec)
+		rec = rec[n:]
+		r := testKafkaRecord{partition: partition}
+		kl, n := binary.Varint(rec)
+		rec = rec[n:]
+		if kl > 0 {
+			r.key, rec = string(rec[:kl]), rec[kl:]
+		}
+		vl, n := binary.Varint(rec)
+		rec = rec[n:]
+		r.value = string(rec[:vl])
+		b.records = append(b.records, r)
+	}
+}
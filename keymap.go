@@ -0,0 +1,98 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"aahframework.org/config.v0"
+)
+
+// newKeyMap method returns the output key mapping configured as list
+// `log.key_map`, so logs conform to an established log schema without
+// changing the call sites. Mapping is `<key>=<new key>`, it's applied to the
+// field keys and the JSON keys of entry, for e.g.: `message`, `timestamp`,
+// `request_id`. For e.g.:
+//
+//	log {
+//	  key_map = ["message=msg", "request_id=req_id", "user=user_name"]
+//	}
+func newKeyMap(cfg *config.Config) (map[string]string, error) {
+	mappings, _ := cfg.StringList("log.key_map")
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+
+	keyMap := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 ||
+			len(strings.TrimSpace(parts[1])) == 0 {
+			return nil, fmt.Errorf("log: invalid key mapping '%s'", m)
+		}
+		keyMap[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return keyMap, nil
+}
+
+// renameFields method renames the field keys as per the key map, entry
+// detail fields `appname`, `insname`, `reqid` and `principal` are kept.
+func (l *Logger) renameFields(e *Entry) {
+	var renamed Fields
+	for k, v := range e.Fields {
+		if nk, found := l.keyMap[k]; found && !e.isSkipField(k) {
+			if renamed == nil {
+				renamed = make(Fields)
+			}
+			renamed[nk] = v
+			delete(e.Fields, k)
+		}
+	}
+	e.addFields(renamed)
+}
+
+// outputKey method returns the mapped key of the given JSON key if any.
+func (e *Entry) outputKey(key string) string {
+	if e.logger != nil {
+		if nk, found := e.logger.keyMap[key]; found {
+			return nk
+		}
+	}
+	return key
+}
+
+// renameJSONKeys method renames the top-level keys of JSON object as per the
+// key map of entry logger, key order is kept.
+func renameJSONKeys(msg []byte, entry *Entry) []byte {
+	if entry.logger == nil || len(entry.logger.keyMap) == 0 {
+		return msg
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(msg))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return msg
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return msg
+		}
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return msg
+		}
+		jsonPair(buf, entry.outputKey(t.(string)), value)
+	}
+	if buf.Len() > 1 {
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogKeyMap(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    format = "json"
+    key_map = ["message=msg", "request_id=req_id", "user=user_name", "reqid=x"]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.WithFields(Fields{"reqid": "req-1", "user": "jeeva"}).Info("welcome")
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, `{"level":"INFO","timestamp":`))
+	assert.True(t, strings.Contains(out, `"req_id":"req-1","msg":"welcome"`))
+	assert.True(t, strings.Contains(out, `"fields":{"user_name":"jeeva"}`))
+	assert.False(t, strings.Contains(out, `"message"`))
+
+	// key order formatter
+	cfg, _ = config.ParseString(`log {
+    format = "json"
+    key_map = ["message=msg"]
+    json { key_order = ["message", "level"] }
+  }`)
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf.Reset()
+	logger.SetWriter(buf)
+	logger.Info("welcome")
+	assert.True(t, strings.HasPrefix(buf.String(), `{"msg":"welcome","level":"INFO",`))
+
+	// text format
+	cfg, _ = config.ParseString(`log {
+    pattern = "%message %fields"
+    key_map = ["user=user_name"]
+  }`)
+	logger, _ = New(cfg)
+	buf.Reset()
+	logger.SetWriter(buf)
+	logger.WithField("user", "jeeva").Info("welcome")
+	assert.Equal(t, "welcome fields[user_name: jeeva] \n", buf.String())
+
+	cfg, _ = config.ParseString(`log { key_map = ["message"] }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid key mapping 'message'", err.Error())
+}
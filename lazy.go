@@ -0,0 +1,30 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import "fmt"
+
+// Lazy type defers the evaluation of log argument or field value until the
+// level check is passed, so expensive value is not computed for disabled
+// level. Field value is evaluated once per entry. For e.g.:
+//
+//	logger.Debug("state: ", log.Lazy(func() interface{} { return dumpState() }))
+//	logger.WithField("state", log.Lazy(dumpState)).Trace("state changed")
+type Lazy func() interface{}
+
+// String method implements `fmt.Stringer`, it calls the func.
+func (fn Lazy) String() string {
+	return fmt.Sprint(fn())
+}
+
+// LazyField method returns the typed field of `Lazy` value, func is called
+// only if the entry is logged. For e.g.:
+//
+//	logger.Debugw("request received", log.LazyField("body", func() interface{} {
+//		return dumpBody(r)
+//	}))
+func LazyField(key string, fn func() interface{}) Field {
+	return Any(key, Lazy(fn))
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogLazy(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "debug"
+    pattern = "%level:-5 %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	calls := 0
+	expensive := func() string {
+		calls++
+		return "expensive"
+	}
+	logger.Tracel(expensive)
+	logger.WithField("key", "value").Tracel(expensive)
+	logger.Trace("state: ", Lazy(func() interface{} { return expensive() }))
+	logger.WithField("state", Lazy(func() interface{} { return expensive() })).Trace("state")
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, "", buf.String())
+
+	logger.Debugl(expensive)
+	logger.Debug("state: ", Lazy(func() interface{} { return expensive() }))
+	logger.WithField("state", Lazy(func() interface{} { return calls })).Debugl(expensive)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "DEBUG expensive \nDEBUG state: expensive \nDEBUG expensive fields[state: 3] \n", buf.String())
+
+	// field is evaluated once for the json format
+	cfg, _ = config.ParseString(`log { level = "trace", format = "json" }`)
+	logger, _ = New(cfg)
+	buf.Reset()
+	logger.SetWriter(buf)
+	logger.WithField("state", Lazy(func() interface{} { calls++; return []int{1, 2} })).Tracel(expensive)
+	assert.Equal(t, 5, calls)
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"state":[1,2]`)))
+}
+
+func TestLogLazyField(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "info"
+    pattern = "%level:-5 %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	calls := 0
+	body := func() interface{} {
+		calls++
+		return "{\"id\":1}"
+	}
+	logger.Debugw("request received", LazyField("body", body))
+	logger.WithField("reqid", "req-1").Debugw("request received", LazyField("body", body))
+	assert.Equal(t, 0, calls)
+
+	logger.Infow("request received", LazyField("body", body))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "INFO  request received fields[body: {\"id\":1}] \n", buf.String())
+}
@@ -18,33 +18,63 @@
 package log
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	slog "log"
+	"math"
 	"os"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
 )
 
 // Level type definition
 type level uint8
 
+// packageLevel holds the level override of package path.
+type packageLevel struct {
+	path  string
+	level level
+}
+
+// packageLevels holds the package levels, most specific path first, and the
+// base level. It's read-only once stored into `Logger.pkgLevels`.
+type packageLevels struct {
+	levels []packageLevel
+	base   level
+}
+
 // HookFunc type is aah framework logger custom hook.
 type HookFunc func(e Entry)
 
-// Log Level definition
+// FilterFunc type is aah framework logger entry filter, entry is dropped if
+// it returns false. It's called before the entry is formatted.
+type FilterFunc func(e *Entry) bool
+
+// entryFilter holds the named filter func of logger filter chain.
+type entryFilter struct {
+	name string
+	fn   FilterFunc
+}
+
+// Log Level definition, values are spaced by 10 so that custom levels can be
+// added in between using `AddLevel`. Lower value is more severe.
 const (
-	LevelFatal level = iota
+	LevelFatal level = iota * 10
 	LevelPanic
 	LevelError
 	LevelWarn
 	LevelInfo
 	LevelDebug
 	LevelTrace
-	LevelUnknown
+	LevelUnknown level = math.MaxUint8
 )
 
 var (
@@ -54,6 +84,25 @@ var (
 	// ErrHookFuncIsNil is returned when hook function is nil.
 	ErrHookFuncIsNil = errors.New("log: hook func is nil")
 
+	// ErrFilterFuncIsNil is returned when filter function is nil.
+	ErrFilterFuncIsNil = errors.New("log: filter func is nil")
+
+	// ErrReceiverFactoryIsNil is returned when receiver factory is nil.
+	ErrReceiverFactoryIsNil = errors.New("log: receiver factory is nil")
+
+	// ErrFormatterIsNil is returned when formatter is nil.
+	ErrFormatterIsNil = errors.New("log: formatter is nil")
+
+	// ErrCompressorFuncIsNil is returned when compressor func is nil.
+	ErrCompressorFuncIsNil = errors.New("log: compressor func is nil")
+
+	// ErrKeyFuncIsNil is returned when encryption key func is nil.
+	ErrKeyFuncIsNil = errors.New("log: key func is nil")
+
+	// ErrQueueFull is reported when receiver queue is full and entry is
+	// dropped.
+	ErrQueueFull = errors.New("log: receiver queue is full, entry dropped")
+
 	filePermission = os.FileMode(0755)
 
 	// abstract it, can be unit tested
@@ -67,25 +116,159 @@ type (
 	// format flags. Logger can be used simultaneously from multiple goroutines;
 	// it guarantees to serialize access to the Receivers.
 	Logger struct {
-		cfg      *config.Config
-		m        *sync.RWMutex
-		level    level
-		receiver Receiver
-		ctx      Fields
-		hooks    map[string]HookFunc
+		cfg       *config.Config
+		m         *sync.RWMutex
+		level     int32
+		receiver  Receiver
+		ctx       Fields
+		defaults  Fields
+		hooks     map[string]HookFunc
+		fchain    []entryFilter
+		levelSig  chan os.Signal
+		reopenSig chan os.Signal
+		schedule  *levelSchedule
+
+		forced    int32
+		forcedIDs map[string]bool
+		forcedFns []entryFilter
+
+		maxLevel  int32
+		pkgLevels atomic.Value
+		verbosity int32
+
+		maxMessageLen int
+		maxFieldLen   int
+		spill         *spillover
+		filters       []*filterRule
+		fieldFormat   *fieldFormat
+		fieldMerge    fieldMerge
+		redactor      *redactor
+		keyMap        map[string]string
+		jsonKeys      []string
+		multiline     string
+		ctxFields     []contextField
+		entryHooks    []levelHook
+		hookPool      *hookPool
+		errorFn       ErrorHandlerFunc
+		metrics       *MetricsHook
+		counters      *logCounters
+		sampler       *sampler
+		limiter       *rateLimiter
+		deduper       *deduper
+		env           string
+		isGoroutineID bool
+		isErrorStack  bool
 	}
 
 	// Receiver is the interface for pluggable log receiver.
 	// For e.g: Console, File
+	//
+	// Logger calls `Init` with application config, then `SetPattern` with
+	// `log.pattern`. `Log` is called for every log entry with in the logger
+	// level, entry is reused after `Log` returns, so receiver has to copy the
+	// values it needs to process later. Receiver which buffers the entries
+	// can implement `Flusher` and `Closer`. Custom receiver can be registered
+	// using `AddReceiver`.
 	Receiver interface {
+		// Init method initializes the receiver from `log.*` config.
 		Init(cfg *config.Config) error
+
+		// SetPattern method sets the log format pattern.
 		SetPattern(pattern string) error
+
+		// SetWriter method sets the writer of the receiver.
 		SetWriter(w io.Writer)
+
+		// IsCallerInfo method returns true if log entry requires caller info.
 		IsCallerInfo() bool
+
+		// Writer method returns the writer of the receiver.
 		Writer() io.Writer
+
+		// Log method logs the given entry.
 		Log(e *Entry)
 	}
 
+	// ReceiverFactory func creates new instance of the receiver.
+	ReceiverFactory func() Receiver
+
+	// Flusher interface is implemented by the receiver which buffers the
+	// log entries, `Flush` writes the buffered entries.
+	Flusher interface {
+		Flush()
+	}
+
+	// Closer interface is implemented by the receiver which needs to release
+	// the resources, `Close` writes the buffered entries and stops it.
+	Closer interface {
+		Close()
+	}
+
+	// Reopener interface is implemented by the receiver which writes into
+	// file, `Reopen` closes and opens the file again, for e.g.: after the
+	// file is moved by external logrotate.
+	Reopener interface {
+		Reopen() error
+	}
+
+	// diskSpaceNotifier interface is implemented by the receiver which
+	// monitors the disk space.
+	diskSpaceNotifier interface {
+		OnLowDiskSpace(fn DiskSpaceFunc)
+	}
+
+	// receiverLeveler interface is implemented by the receiver which applies
+	// its own level to the entries.
+	receiverLeveler interface {
+		maxLevel() level
+	}
+
+	// rotateNotifier interface is implemented by the receiver which rotates
+	// the log file.
+	rotateNotifier interface {
+		OnRotate(fn RotateFunc)
+	}
+
+	// errorNotifier interface is implemented by the receiver which reports
+	// the write errors.
+	errorNotifier interface {
+		OnError(fn ErrorHandlerFunc)
+	}
+
+	// Formatter interface is to encode the log entry, it's selected by name
+	// from config `log.format`. Flags are the parsed log `pattern` of the
+	// receiver. Formatter is called concurrently by the receivers, it must
+	// not modify the entry.
+	Formatter interface {
+		Format(flags []ess.FmtFlagPart, entry *Entry) []byte
+	}
+
+	// FormatterFunc type is an adapter to allow the use of ordinary function
+	// as `Formatter`.
+	FormatterFunc func(flags []ess.FmtFlagPart, entry *Entry) []byte
+
+	// DiskSpaceFunc type is called when free disk space of log file goes
+	// below the threshold, see `Logger.OnLowDiskSpace`.
+	DiskSpaceFunc func(filename string, free, threshold uint64)
+
+	// RotateFunc type is called after log file rotation with completed file
+	// path and new file path, see `Logger.OnRotate`.
+	RotateFunc func(oldPath, newPath string)
+
+	// ErrorHandlerFunc type is called when receiver write or hook fails,
+	// see `Logger.SetErrorHandler`. Entry is nil when the failure is not of
+	// a single entry, for e.g.: batch delivery of HTTP based receivers.
+	ErrorHandlerFunc func(err error, entry *Entry)
+
+	// CompressorFunc type is used to compress the rotated log file, it wraps
+	// the given writer, for e.g. `gzip.NewWriter`.
+	CompressorFunc func(w io.Writer) (io.WriteCloser, error)
+
+	// KeyFunc type is used to fetch the log file encryption key, for e.g.:
+	// from KMS. Key size must be 16, 24 or 32 bytes for AES-128, AES-192 or
+	// AES-256.
+	KeyFunc func() ([]byte, error)
+
 	// Loggerer interface is for Logger and Entry log method implementation.
 	Loggerer interface {
 		Error(v ...interface{})
@@ -98,10 +281,24 @@ type (
 		Debugf(format string, v ...interface{})
 		Trace(v ...interface{})
 		Tracef(format string, v ...interface{})
+		Debugl(fn func() string)
+		Tracel(fn func() string)
+		Log(levelName string, v ...interface{})
+		Logf(levelName, format string, v ...interface{})
+		Errorw(msg string, fields ...Field)
+		Warnw(msg string, fields ...Field)
+		Infow(msg string, fields ...Field)
+		Debugw(msg string, fields ...Field)
+		Tracew(msg string, fields ...Field)
 
 		// Context/Field methods
 		WithFields(fields Fields) Loggerer
 		WithField(key string, value interface{}) Loggerer
+		WithContext(ctx context.Context) Loggerer
+		WithNamespace(name string) Loggerer
+		WithError(err error) Loggerer
+		ForWorker(id interface{}) Loggerer
+		WorkerFunc(id interface{}, fn func(logger Loggerer) error) func() error
 
 		// Level Info
 		IsLevelInfo() bool
@@ -136,11 +333,37 @@ func New(cfg *config.Config) (*Logger, error) {
 		return nil, errors.New("log: config is nil")
 	}
 
-	logger := &Logger{m: &sync.RWMutex{}, cfg: cfg}
+	logger := &Logger{m: &sync.RWMutex{}, cfg: cfg, counters: &logCounters{}}
+
+	// JSON key order of json format
+	logger.jsonKeys, _ = cfg.StringList("log.json.key_order")
+
+	// Text multi-line message mode of text format
+	switch mode := cfg.StringDefault("log.text.multiline", ""); mode {
+	case "", multilineEscape, multilineIndent:
+		logger.multiline = mode
+	default:
+		return nil, fmt.Errorf("log: unknown text multiline mode '%s'", mode)
+	}
+
+	// Template format, to report the template error in detail
+	if format := cfg.StringDefault("log.format", ""); strings.HasPrefix(format, templateFmtPrefix) {
+		if err := parseTemplateFormat(format); err != nil {
+			return nil, err
+		}
+	}
 
 	// Receiver
-	receiverType := strings.ToUpper(cfg.StringDefault("log.receiver", "CONSOLE"))
-	if err := logger.SetReceiver(getReceiverByName(receiverType)); err != nil {
+	var receiver Receiver
+	if cfg.IsExists("log.receivers") {
+		if cfg.IsExists("log.level_schedule") {
+			return nil, errors.New("log: level schedule is not supported with receivers")
+		}
+		receiver = &MultiReceiver{}
+	} else {
+		receiver = getReceiverByName(strings.ToUpper(cfg.StringDefault("log.receiver", "CONSOLE")))
+	}
+	if err := logger.SetReceiver(receiver); err != nil {
 		return nil, err
 	}
 
@@ -149,17 +372,318 @@ func New(cfg *config.Config) (*Logger, error) {
 		return nil, err
 	}
 
-	// Level
-	if err := logger.SetLevel(cfg.StringDefault("log.level", "DEBUG")); err != nil {
+	// Level, env variable `AAH_LOG_LEVEL` overrides the config
+	levelName := cfg.StringDefault("log.level", "DEBUG")
+	if envLevel := levelFromEnv(""); len(envLevel) > 0 {
+		levelName = envLevel
+	}
+	if err := logger.SetLevel(levelName); err != nil {
 		return nil, err
 	}
 
+	// Package levels, for e.g.: `"aahframework.org/security=debug"`
+	pkgLevels, _ := cfg.StringList("log.package_levels")
+	for _, pl := range pkgLevels {
+		parts := strings.SplitN(pl, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("log: invalid package level '%s'", pl)
+		}
+		if err := logger.SetPackageLevel(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); err != nil {
+			return nil, err
+		}
+	}
+
+	// Verbosity of `Logger.V`
+	logger.verbosity = int32(cfg.IntDefault("log.verbosity", 0))
+
+	// Goroutine ID is captured only if any of the pattern uses it
+	for _, k := range cfg.KeysByPath("log.receivers") {
+		if isGoroutineIDPattern(cfg.StringDefault("log.receivers."+k+".pattern", "")) {
+			logger.isGoroutineID = true
+		}
+	}
+
+	// Stack trace of `WithError`
+	logger.isErrorStack = cfg.BoolDefault("log.error_stack", false)
+
+	// Application environment name for `env` format flag
+	logger.env = cfg.StringDefault("env.active", "")
+
+	// Truncation limits, zero means no limit
+	logger.maxMessageLen = cfg.IntDefault("log.truncate.message", 0)
+	logger.maxFieldLen = cfg.IntDefault("log.truncate.field", 0)
+	if logger.maxFieldLen > 0 && cfg.IsExists("log.truncate.spillover") {
+		s, err := newSpillover(cfg)
+		if err != nil {
+			return nil, err
+		}
+		setErrorHandler(s.receiver, logger.reportError)
+		logger.spill = s
+	}
+
+	// Filter rules, matched entries are dropped
+	filters, err := newFilterRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logger.filters = filters
+
+	// Rendering of duration and time field values
+	if cfg.IsExists("log.field_format") {
+		ff, err := newFieldFormat(cfg)
+		if err != nil {
+			return nil, err
+		}
+		logger.fieldFormat = ff
+	}
+
+	// Redaction of field values by key
+	if cfg.IsExists("log.redact") {
+		r, err := newRedactor(cfg)
+		if err != nil {
+			return nil, err
+		}
+		logger.redactor = r
+	}
+
+	// Output key mapping, for e.g.: `message=msg`
+	keyMap, err := newKeyMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logger.keyMap = keyMap
+
+	// Baggage members lifted into fields
+	ctxFields, err := newBaggageFields(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logger.ctxFields = ctxFields
+
+	// Worker pool of async hooks
+	hookPool, err := newHookPool(cfg)
+	if err != nil {
+		return nil, err
+	}
+	hookPool.errorFn = logger.handleError
+	logger.hookPool = hookPool
+
+	// Sampling, kept entries of sampled level has fields `sampled` and
+	// `sample_rate`
+	if cfg.IsExists("log.sampling") {
+		s, err := newSampler(cfg)
+		if err != nil {
+			return nil, err
+		}
+		logger.sampler = s
+	}
+
+	// Deduplication of identical consecutive entries
+	if cfg.IsExists("log.dedup") {
+		d, err := newDeduper(cfg)
+		if err != nil {
+			return nil, err
+		}
+		logger.deduper = d
+	}
+
+	// Rate limit, dropped entries are reported as summary entry
+	if cfg.IsExists("log.rate_limit") {
+		r, err := newRateLimiter(cfg)
+		if err != nil {
+			return nil, err
+		}
+		logger.limiter = r
+	}
+
 	logger.ctx = make(Fields)
 	logger.hooks = make(map[string]HookFunc)
 
+	// Default fields, for e.g.: `region = "us-east-1"`
+	defaults := make(Fields)
+	for _, k := range cfg.KeysByPath("log.default_fields") {
+		defaults[k] = cfg.StringDefault("log.default_fields."+k, "")
+	}
+	logger.defaults = defaults
+
+	// Merge mode of same key in default, context and call-site fields
+	fm, err := fieldMergeByName(cfg.StringDefault("log.field_merge", FieldMergeOverride))
+	if err != nil {
+		return nil, err
+	}
+	logger.fieldMerge = fm
+
+	// Level schedule, level is changed by time window
+	if cfg.IsExists("log.level_schedule") {
+		s, err := newLevelSchedule(cfg, logger.currentLevel())
+		if err != nil {
+			return nil, err
+		}
+		logger.schedule = s
+		logger.applySchedule(time.Now())
+		if len(s.windows) > 0 {
+			s.done = make(chan struct{})
+			go logger.runSchedule(s.done)
+		}
+	}
+
+	// Level signals, SIGUSR1 and SIGUSR2 steps the level at runtime
+	if cfg.BoolDefault("log.level_signals", false) {
+		logger.watchLevelSignals()
+	}
+
+	// File reopen on SIGHUP, it's enabled by default
+	if cfg.BoolDefault("log.reopen_on_sighup", true) {
+		logger.watchReopenSignal()
+	}
+
 	return logger, nil
 }
 
+// AddReceiver method registers the receiver factory by name, so that receiver
+// can be configured from config `log.receiver`. Name is case-insensitive.
+func AddReceiver(name string, factory ReceiverFactory) error {
+	if factory == nil {
+		return ErrReceiverFactoryIsNil
+	}
+
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if len(name) == 0 {
+		return errors.New("log: receiver name is empty")
+	}
+
+	receiverMu.Lock()
+	defer receiverMu.Unlock()
+	if _, found := receiverFactories[name]; found {
+		return fmt.Errorf("log: receiver name '%v' is already added, skip it", strings.ToLower(name))
+	}
+
+	receiverFactories[name] = factory
+	return nil
+}
+
+// AddFormatter method registers the formatter by name, so that formatter
+// can be configured from config `log.format`. Name is case-sensitive.
+func AddFormatter(name string, formatter Formatter) error {
+	if formatter == nil {
+		return ErrFormatterIsNil
+	}
+
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return errors.New("log: formatter name is empty")
+	}
+
+	formatterMu.Lock()
+	defer formatterMu.Unlock()
+	if _, found := formatters[name]; found {
+		return fmt.Errorf("log: formatter name '%v' is already added, skip it", name)
+	}
+
+	formatters[name] = formatter
+	return nil
+}
+
+// AddCompressor method registers the compressor by name, so that rotated
+// log file can be compressed using config `log.rotate.compress`. Given
+// extension is appended to the compressed file name. Name is case-sensitive.
+//
+//	log.AddCompressor("zstd", ".zst", func(w io.Writer) (io.WriteCloser, error) {
+//		return zstd.NewWriter(w)
+//	})
+func AddCompressor(name, ext string, fn CompressorFunc) error {
+	if fn == nil {
+		return ErrCompressorFuncIsNil
+	}
+
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return errors.New("log: compressor name is empty")
+	}
+
+	compressorMu.Lock()
+	defer compressorMu.Unlock()
+	if _, found := compressors[name]; found {
+		return fmt.Errorf("log: compressor name '%v' is already added, skip it", name)
+	}
+
+	compressors[name] = &compressor{ext: ext, fn: fn}
+	return nil
+}
+
+// AddKeyProvider method registers the encryption key provider by name, so
+// that log file can be encrypted with the key using config
+// `log.encrypt.key_provider`. Name is case-sensitive.
+//
+//	log.AddKeyProvider("kms", func() ([]byte, error) {
+//		return decryptDataKey(encryptedDataKey)
+//	})
+func AddKeyProvider(name string, fn KeyFunc) error {
+	if fn == nil {
+		return ErrKeyFuncIsNil
+	}
+
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return errors.New("log: key provider name is empty")
+	}
+
+	keyProviderMu.Lock()
+	defer keyProviderMu.Unlock()
+	if _, found := keyProviders[name]; found {
+		return fmt.Errorf("log: key provider name '%v' is already added, skip it", name)
+	}
+
+	keyProviders[name] = fn
+	return nil
+}
+
+// AddLevel method registers the custom level by name with its value, lower
+// value is more severe. Built-in levels are FATAL 0, PANIC 10, ERROR 20,
+// WARN 30, INFO 40, DEBUG 50 and TRACE 60. Custom level can be used in
+// pattern, config level and formatters, receivers map it to the next
+// less severe built-in level for their severity. Name is case-insensitive.
+// Register the levels before creating the logger, for e.g.:
+//
+//	log.AddLevel("AUDIT", 15)  // between PANIC and ERROR
+//	log.AddLevel("NOTICE", 35) // between WARN and INFO
+//
+//	logger.Log("NOTICE", "configuration reloaded")
+func AddLevel(name string, value uint8) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if len(name) == 0 {
+		return errors.New("log: level name is empty")
+	}
+	if level(value) == LevelUnknown {
+		return fmt.Errorf("log: level value '%d' is reserved", value)
+	}
+
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	t := loadLevels()
+	if _, found := t.byName[name]; found {
+		return fmt.Errorf("log: level name '%v' is already added, skip it", name)
+	}
+	if existing, found := t.byValue[level(value)]; found {
+		return fmt.Errorf("log: level value '%d' is already used by '%s'", value, existing)
+	}
+
+	nt := &levelTable{
+		byName:  make(map[string]level, len(t.byName)+1),
+		byValue: make(map[level]string, len(t.byValue)+1),
+	}
+	for k, v := range t.byName {
+		nt.byName[k] = v
+	}
+	for k, v := range t.byValue {
+		nt.byValue[k] = v
+	}
+	nt.byName[name] = level(value)
+	nt.byValue[level(value)] = name
+	levelTab.Store(nt)
+	return nil
+}
+
 // NewWithContext method creates the aah logger based on supplied `config.Config`.
 func NewWithContext(cfg *config.Config, ctx Fields) (*Logger, error) {
 	l, err := New(cfg)
@@ -195,6 +719,35 @@ func (l *Logger) AddContext(fields Fields) {
 	}
 }
 
+// SetDefaultFields method sets the fields which are merged into every
+// entry of the logger, field given per call overrides the default field.
+// Precedence can be changed by field merge mode, see `SetFieldMerge`.
+// It's for deployment-wide context, for e.g.: region, version and build
+// SHA. Default fields can be configured under `log.default_fields`.
+//
+//	logger.SetDefaultFields(log.Fields{"region": "us-east-1", "version": "1.4.2"})
+func (l *Logger) SetDefaultFields(fields Fields) {
+	defaults := make(Fields, len(fields))
+	for k, v := range fields {
+		defaults[k] = v
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.defaults = defaults
+}
+
+// DefaultFields method returns the copy of logger default fields.
+func (l *Logger) DefaultFields() Fields {
+	l.m.RLock()
+	defer l.m.RUnlock()
+	fields := make(Fields, len(l.defaults))
+	for k, v := range l.defaults {
+		fields[k] = v
+	}
+	return fields
+}
+
 // AddHook method is to add logger hook function.
 func (l *Logger) AddHook(name string, hook HookFunc) error {
 	if hook == nil {
@@ -211,9 +764,51 @@ func (l *Logger) AddHook(name string, hook HookFunc) error {
 	return nil
 }
 
+// AddFilter method is to add logger filter function into filter chain.
+// Filters are called in the order of added, entry is dropped by first
+// filter returns false. For e.g.:
+//
+//	_ = logger.AddFilter("tenant", func(e *log.Entry) bool {
+//		return e.Fields["tenant"] != "sandbox"
+//	})
+func (l *Logger) AddFilter(name string, filter FilterFunc) error {
+	if ess.IsStrEmpty(name) {
+		return errors.New("log: filter name is empty")
+	}
+	if filter == nil {
+		return ErrFilterFuncIsNil
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	for _, f := range l.fchain {
+		if f.name == name {
+			return fmt.Errorf("log: filter name '%v' is already added, skip it", name)
+		}
+	}
+
+	fchain := make([]entryFilter, len(l.fchain), len(l.fchain)+1)
+	copy(fchain, l.fchain)
+	l.fchain = append(fchain, entryFilter{name: name, fn: filter})
+	return nil
+}
+
+// RemoveFilter method removes the given filter function from filter chain.
+func (l *Logger) RemoveFilter(name string) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	fchain := make([]entryFilter, 0, len(l.fchain))
+	for _, f := range l.fchain {
+		if f.name != name {
+			fchain = append(fchain, f)
+		}
+	}
+	l.fchain = fchain
+}
+
 // Level method returns currently enabled logging level.
 func (l *Logger) Level() string {
-	return levelToLevelName[l.level]
+	return l.currentLevel().String()
 }
 
 // SetLevel method sets the given logging level for the logger.
@@ -225,7 +820,44 @@ func (l *Logger) SetLevel(level string) error {
 	if levelFlag == LevelUnknown {
 		return fmt.Errorf("log: unknown log level '%s'", level)
 	}
-	l.level = levelFlag
+	atomic.StoreInt32(&l.level, int32(levelFlag))
+	l.setMaxLevel()
+	return nil
+}
+
+// SetPackageLevel method sets the logging level for the given package path
+// and its sub packages, it overrides the logger level for the log calls made
+// from the package. Most specific package path is applied, for e.g.:
+//
+//	logger.SetPackageLevel("aahframework.org/security", "debug")
+//
+// Caller package is resolved from call stack only if any package level is
+// set.
+func (l *Logger) SetPackageLevel(pkg, level string) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	levelFlag := levelByName(level)
+	if levelFlag == LevelUnknown {
+		return fmt.Errorf("log: unknown log level '%s'", level)
+	}
+	pkg = strings.TrimSuffix(pkg, "/")
+	if len(pkg) == 0 {
+		return errors.New("log: package path is empty")
+	}
+
+	current := l.loadPackageLevels()
+	pkgLevels := make([]packageLevel, 0, len(current.levels)+1)
+	for _, pl := range current.levels {
+		if pl.path != pkg {
+			pkgLevels = append(pkgLevels, pl)
+		}
+	}
+	pkgLevels = append(pkgLevels, packageLevel{path: pkg, level: levelFlag})
+	sort.Slice(pkgLevels, func(i, j int) bool {
+		return len(pkgLevels[i].path) > len(pkgLevels[j].path)
+	})
+	l.pkgLevels.Store(&packageLevels{levels: pkgLevels, base: current.base})
+	l.setMaxLevel()
 	return nil
 }
 
@@ -236,7 +868,11 @@ func (l *Logger) SetPattern(pattern string) error {
 	if l.receiver == nil {
 		return ErrLogReceiverIsNil
 	}
-	return l.receiver.SetPattern(pattern)
+	if err := l.receiver.SetPattern(pattern); err != nil {
+		return err
+	}
+	l.isGoroutineID = isGoroutineIDPattern(pattern)
+	return nil
 }
 
 // SetReceiver method sets the given receiver into logger instance.
@@ -249,7 +885,28 @@ func (l *Logger) SetReceiver(receiver Receiver) error {
 	}
 
 	l.receiver = receiver
-	return l.receiver.Init(l.cfg)
+	if err := l.receiver.Init(l.cfg); err != nil {
+		return err
+	}
+	setErrorHandler(l.receiver, l.reportError)
+	l.setMaxLevel()
+	return nil
+}
+
+// SetReceiverLevel method sets the level of the receiver by name, it's
+// applicable to `log.receivers`.
+func (l *Logger) SetReceiverLevel(name, level string) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	m, ok := l.receiver.(*MultiReceiver)
+	if !ok {
+		return fmt.Errorf("log: unknown receiver '%s'", name)
+	}
+	if err := m.SetLevel(name, level); err != nil {
+		return err
+	}
+	l.setMaxLevel()
+	return nil
 }
 
 // SetWriter method sets the given writer into logger instance.
@@ -259,6 +916,79 @@ func (l *Logger) SetWriter(w io.Writer) {
 	l.receiver.SetWriter(w)
 }
 
+// Flush method writes the buffered log entries, if receiver implements
+// `Flusher`.
+func (l *Logger) Flush() {
+	l.logRepeated()
+	l.logSuppressed()
+	if f, ok := l.receiver.(Flusher); ok {
+		f.Flush()
+	}
+	if l.spill != nil {
+		l.spill.flush()
+	}
+}
+
+// OnLowDiskSpace method sets the func to notify low disk space, if receiver
+// monitors the disk space, i.e. file receiver with `log.disk_guard`.
+func (l *Logger) OnLowDiskSpace(fn DiskSpaceFunc) {
+	if n, ok := l.receiver.(diskSpaceNotifier); ok {
+		n.OnLowDiskSpace(fn)
+	}
+}
+
+// OnRotate method sets the func to notify completed log file after each
+// rotation, so that application can upload, checksum or index it. It's
+// applicable to file receiver.
+func (l *Logger) OnRotate(fn RotateFunc) {
+	if n, ok := l.receiver.(rotateNotifier); ok {
+		n.OnRotate(fn)
+	}
+}
+
+// SetErrorHandler method sets the func, which is called when receiver write
+// or hook fails, so application can count, alert on or fall back from log
+// delivery failures. Receiver reports the error if it implements
+// `OnError(fn ErrorHandlerFunc)`, built-in receivers do except discard and
+// ring receivers. Entry dropped due to full receiver queue is reported as
+// `ErrQueueFull`. Panic of hook is reported as error. Func must not log into
+// the failed receiver.
+//
+//	logger.SetErrorHandler(func(err error, e *log.Entry) {
+//		deliveryFailures.Inc()
+//	})
+func (l *Logger) SetErrorHandler(fn ErrorHandlerFunc) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.errorFn = fn
+}
+
+// Reopen method reopens the log file, if receiver implements `Reopener`.
+// It's called on SIGHUP unless `log.reopen_on_sighup = false`.
+func (l *Logger) Reopen() error {
+	if r, ok := l.receiver.(Reopener); ok {
+		return r.Reopen()
+	}
+	return nil
+}
+
+// Close method writes the buffered log entries and stops the receiver, if
+// receiver implements `Closer`.
+func (l *Logger) Close() {
+	l.stopLevelSignals()
+	l.stopReopenSignal()
+	l.stopSchedule()
+	l.hookPool.close()
+	l.logRepeated()
+	l.logSuppressed()
+	if c, ok := l.receiver.(Closer); ok {
+		c.Close()
+	}
+	if l.spill != nil {
+		l.spill.close()
+	}
+}
+
 // ToGoLogger method wraps the current log writer into Go Logger instance.
 func (l *Logger) ToGoLogger() *slog.Logger {
 	return slog.New(l.receiver.Writer(), "", slog.LstdFlags)
@@ -270,7 +1000,7 @@ func (l *Logger) ToGoLogger() *slog.Logger {
 
 // Error logs message as `ERROR`. Arguments handled in the mananer of `fmt.Print`.
 func (l *Logger) Error(v ...interface{}) {
-	if l.level >= LevelError {
+	if l.isMaxLevel(LevelError) {
 		e := acquireEntry(l)
 		e.Error(v...)
 		releaseEntry(e)
@@ -279,7 +1009,7 @@ func (l *Logger) Error(v ...interface{}) {
 
 // Errorf logs message as `ERROR`. Arguments handled in the mananer of `fmt.Printf`.
 func (l *Logger) Errorf(format string, v ...interface{}) {
-	if l.level >= LevelError {
+	if l.isMaxLevel(LevelError) {
 		e := acquireEntry(l)
 		e.Errorf(format, v...)
 		releaseEntry(e)
@@ -288,7 +1018,7 @@ func (l *Logger) Errorf(format string, v ...interface{}) {
 
 // Warn logs message as `WARN`. Arguments handled in the mananer of `fmt.Print`.
 func (l *Logger) Warn(v ...interface{}) {
-	if l.level >= LevelWarn {
+	if l.isMaxLevel(LevelWarn) {
 		e := acquireEntry(l)
 		e.Warn(v...)
 		releaseEntry(e)
@@ -297,7 +1027,7 @@ func (l *Logger) Warn(v ...interface{}) {
 
 // Warnf logs message as `WARN`. Arguments handled in the mananer of `fmt.Printf`.
 func (l *Logger) Warnf(format string, v ...interface{}) {
-	if l.level >= LevelWarn {
+	if l.isMaxLevel(LevelWarn) {
 		e := acquireEntry(l)
 		e.Warnf(format, v...)
 		releaseEntry(e)
@@ -306,7 +1036,7 @@ func (l *Logger) Warnf(format string, v ...interface{}) {
 
 // Info logs message as `INFO`. Arguments handled in the mananer of `fmt.Print`.
 func (l *Logger) Info(v ...interface{}) {
-	if l.level >= LevelInfo {
+	if l.isMaxLevel(LevelInfo) {
 		e := acquireEntry(l)
 		e.Info(v...)
 		releaseEntry(e)
@@ -315,7 +1045,7 @@ func (l *Logger) Info(v ...interface{}) {
 
 // Infof logs message as `INFO`. Arguments handled in the mananer of `fmt.Printf`.
 func (l *Logger) Infof(format string, v ...interface{}) {
-	if l.level >= LevelInfo {
+	if l.isMaxLevel(LevelInfo) {
 		e := acquireEntry(l)
 		e.Infof(format, v...)
 		releaseEntry(e)
@@ -324,7 +1054,7 @@ func (l *Logger) Infof(format string, v ...interface{}) {
 
 // Debug logs message as `DEBUG`. Arguments handled in the mananer of `fmt.Print`.
 func (l *Logger) Debug(v ...interface{}) {
-	if l.level >= LevelDebug {
+	if l.isMaxLevel(LevelDebug) {
 		e := acquireEntry(l)
 		e.Debug(v...)
 		releaseEntry(e)
@@ -333,16 +1063,30 @@ func (l *Logger) Debug(v ...interface{}) {
 
 // Debugf logs message as `DEBUG`. Arguments handled in the mananer of `fmt.Printf`.
 func (l *Logger) Debugf(format string, v ...interface{}) {
-	if l.level >= LevelDebug {
+	if l.isMaxLevel(LevelDebug) {
 		e := acquireEntry(l)
 		e.Debugf(format, v...)
 		releaseEntry(e)
 	}
 }
 
+// Debugl logs message as `DEBUG`, message func is called only if level is
+// enabled. It's for the message which is expensive to build.
+//
+//	logger.Debugl(func() string {
+//		return fmt.Sprintf("request: %s", dumpRequest(r))
+//	})
+func (l *Logger) Debugl(fn func() string) {
+	if l.isMaxLevel(LevelDebug) {
+		e := acquireEntry(l)
+		e.Debugl(fn)
+		releaseEntry(e)
+	}
+}
+
 // Trace logs message as `TRACE`. Arguments handled in the mananer of `fmt.Print`.
 func (l *Logger) Trace(v ...interface{}) {
-	if l.level >= LevelTrace {
+	if l.isMaxLevel(LevelTrace) {
 		e := acquireEntry(l)
 		e.Trace(v...)
 		releaseEntry(e)
@@ -351,13 +1095,45 @@ func (l *Logger) Trace(v ...interface{}) {
 
 // Tracef logs message as `TRACE`. Arguments handled in the mananer of `fmt.Printf`.
 func (l *Logger) Tracef(format string, v ...interface{}) {
-	if l.level >= LevelTrace {
+	if l.isMaxLevel(LevelTrace) {
 		e := acquireEntry(l)
 		e.Tracef(format, v...)
 		releaseEntry(e)
 	}
 }
 
+// Tracel logs message as `TRACE`, message func is called only if level is
+// enabled. It's for the message which is expensive to build.
+func (l *Logger) Tracel(fn func() string) {
+	if l.isMaxLevel(LevelTrace) {
+		e := acquireEntry(l)
+		e.Tracel(fn)
+		releaseEntry(e)
+	}
+}
+
+// Log logs message as given level name, it's for custom levels added using
+// `AddLevel`. Arguments handled in the mananer of `fmt.Print`. Entry of
+// unknown level is not logged.
+func (l *Logger) Log(levelName string, v ...interface{}) {
+	if lvl := levelByName(levelName); lvl != LevelUnknown && l.isMaxLevel(lvl) {
+		e := acquireEntry(l)
+		e.Log(levelName, v...)
+		releaseEntry(e)
+	}
+}
+
+// Logf logs message as given level name, it's for custom levels added using
+// `AddLevel`. Arguments handled in the mananer of `fmt.Printf`. Entry of
+// unknown level is not logged.
+func (l *Logger) Logf(levelName, format string, v ...interface{}) {
+	if lvl := levelByName(levelName); lvl != LevelUnknown && l.isMaxLevel(lvl) {
+		e := acquireEntry(l)
+		e.Logf(levelName, format, v...)
+		releaseEntry(e)
+	}
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Logger context/field methods
 //_______________________________________
@@ -376,6 +1152,14 @@ func (l *Logger) WithField(key string, value interface{}) Loggerer {
 	return e.WithField(key, value)
 }
 
+// WithNamespace method returns the entry, fields added to it are grouped
+// under the namespace.
+func (l *Logger) WithNamespace(name string) Loggerer {
+	e := acquireEntry(l)
+	defer releaseEntry(e)
+	return e.WithNamespace(name)
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Logger methods - Drop-in replacement
 // for Go standard logger
@@ -450,57 +1234,376 @@ func (l *Logger) Panicln(v ...interface{}) {
 
 // IsLevelInfo method returns true if log level is INFO otherwise false.
 func (l *Logger) IsLevelInfo() bool {
-	return l.level == LevelInfo
+	return l.currentLevel() == LevelInfo
 }
 
 // IsLevelError method returns true if log level is ERROR otherwise false.
 func (l *Logger) IsLevelError() bool {
-	return l.level == LevelError
+	return l.currentLevel() == LevelError
 }
 
 // IsLevelWarn method returns true if log level is WARN otherwise false.
 func (l *Logger) IsLevelWarn() bool {
-	return l.level == LevelWarn
+	return l.currentLevel() == LevelWarn
 }
 
 // IsLevelDebug method returns true if log level is DEBUG otherwise false.
 func (l *Logger) IsLevelDebug() bool {
-	return l.level == LevelDebug
+	return l.currentLevel() == LevelDebug
 }
 
 // IsLevelTrace method returns true if log level is TRACE otherwise false.
 func (l *Logger) IsLevelTrace() bool {
-	return l.level == LevelTrace
+	return l.currentLevel() == LevelTrace
 }
 
 // IsLevelFatal method returns true if log level is FATAL otherwise false.
 func (l *Logger) IsLevelFatal() bool {
-	return l.level == LevelFatal
+	return l.currentLevel() == LevelFatal
 }
 
 // IsLevelPanic method returns true if log level is PANIC otherwise false.
 func (l *Logger) IsLevelPanic() bool {
-	return l.level == LevelPanic
+	return l.currentLevel() == LevelPanic
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Unexported methods
 //___________________________________
 
+// setMaxLevel method computes the most verbose level of the logger,
+// receivers and package levels, it's used to skip the log calls quickly.
+// It's called with lock held.
+func (l *Logger) setMaxLevel() {
+	base := l.currentLevel()
+	if r, ok := l.receiver.(receiverLeveler); ok {
+		base = r.maxLevel()
+	}
+	levels := l.loadPackageLevels().levels
+	maxLevel := base
+	for _, pl := range levels {
+		if pl.level > maxLevel {
+			maxLevel = pl.level
+		}
+	}
+	l.pkgLevels.Store(&packageLevels{levels: levels, base: base})
+	atomic.StoreInt32(&l.maxLevel, int32(maxLevel))
+}
+
+// currentLevel method returns the logger level, it's stored atomically
+// since level signals and schedule change it in the background.
+func (l *Logger) currentLevel() level {
+	return level(atomic.LoadInt32(&l.level))
+}
+
+// isMaxLevel method returns true if given level is within the most verbose
+// level of the logger, receivers and package levels.
+func (l *Logger) isMaxLevel(lvl level) bool {
+	return lvl <= level(atomic.LoadInt32(&l.maxLevel))
+}
+
+// loadPackageLevels method returns the current package levels, it's copied
+// on write so lookup on each log call is lock free.
+func (l *Logger) loadPackageLevels() *packageLevels {
+	if p, ok := l.pkgLevels.Load().(*packageLevels); ok {
+		return p
+	}
+	return &packageLevels{base: l.currentLevel()}
+}
+
+// isEnabled method returns true if given level is enabled for the caller
+// package.
+func (l *Logger) isEnabled(lvl level) bool {
+	if !l.isMaxLevel(lvl) {
+		return false
+	}
+	p := l.loadPackageLevels()
+	if len(p.levels) == 0 {
+		return true
+	}
+	return lvl <= p.level(fetchCallerPackage())
+}
+
+// packageLevel method returns the level of most specific package path
+// matches the given package otherwise logger level, for multiple receivers
+// the most verbose receiver level.
+func (l *Logger) packageLevel(pkg string) level {
+	return l.loadPackageLevels().level(pkg)
+}
+
+func (p *packageLevels) level(pkg string) level {
+	for _, pl := range p.levels {
+		if pkg == pl.path || strings.HasPrefix(pkg, pl.path+"/") {
+			return pl.level
+		}
+	}
+	return p.base
+}
+
 func (l *Logger) output(e *Entry) {
+	for k, v := range e.Fields {
+		if fn, ok := v.(Lazy); ok {
+			e.Fields[k] = fn()
+		}
+	}
+	for _, rule := range l.filters {
+		if rule.match(e) {
+			l.countDrop(dropFilter)
+			return
+		}
+	}
+	if !l.allow(e) {
+		l.countDrop(dropFilter)
+		return
+	}
+	if l.sampler != nil {
+		rate, keep := l.sampler.sample(e.Level)
+		if !keep {
+			l.countDrop(dropSample)
+			return
+		}
+		if rate > 1 {
+			e.Fields["sampled"] = true
+			e.Fields["sample_rate"] = rate
+		}
+	}
 	if l.receiver.IsCallerInfo() {
 		e.File, e.Line = fetchCallerInfo()
 	}
-	l.receiver.Log(e)
+	if l.isGoroutineID {
+		e.GoroutineID = goroutineID()
+	}
+
+	// rate limit key is read before the key is renamed or redacted
+	var limitKey string
+	if l.limiter != nil && len(l.limiter.key) > 0 {
+		limitKey = e.Fields.str(l.limiter.key)
+	}
+
+	// entry is transformed before it's stored by deduper, so repeated entry
+	// does not carry the unredacted values
+	l.transform(e)
+	if l.deduper != nil {
+		isDuplicate, repeated := l.deduper.check(e)
+		if repeated != nil {
+			l.logRepeatedEntry(repeated)
+		}
+		if isDuplicate {
+			l.countDrop(dropDedup)
+			return
+		}
+	}
+	if l.limiter != nil && e.Level > LevelPanic {
+		allowed, suppressed := l.limiter.allow(limitKey, e.Time)
+		if !allowed {
+			l.countDrop(dropRateLimit)
+			return
+		}
+		if suppressed > 0 {
+			l.logSuppressedEntry(limitKey, suppressed, e)
+		}
+	}
+	l.emit(e)
+}
+
+// transform method applies the field format, redaction, key map and
+// truncation on the entry before it's stored or dispatched.
+func (l *Logger) transform(e *Entry) {
+	if l.fieldFormat != nil {
+		l.fieldFormat.apply(e.Fields)
+	}
+	if l.redactor != nil {
+		l.redactor.apply(e.Fields, "")
+	}
+	if len(l.keyMap) > 0 {
+		l.renameFields(e)
+	}
+	if l.maxMessageLen > 0 || l.maxFieldLen > 0 {
+		l.truncate(e)
+	}
+}
+
+// emit method dispatches the entry to the receiver and executes the logger
+// hooks. Logged, repeated and suppressed entries are emitted through it.
+func (l *Logger) emit(e *Entry) {
+	l.dispatch(e)
+
+	// Execute logger hooks, entry is copied since it's returned to pool
+	if l.hasHooks() {
+		go l.executeHooks(*copyEntry(e))
+	}
+}
+
+// allow method returns false if any of the filter in chain drops the entry.
+func (l *Logger) allow(e *Entry) bool {
+	l.m.RLock()
+	fchain := l.fchain
+	l.m.RUnlock()
+	for _, f := range fchain {
+		if !f.fn(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// logRepeated method logs the pending repeated entry of deduplication.
+func (l *Logger) logRepeated() {
+	if l.deduper == nil {
+		return
+	}
+	if repeated := l.deduper.drain(); repeated != nil {
+		l.logRepeatedEntry(repeated)
+	}
+}
 
-	// Execute logger hooks
-	go l.executeHooks(*e)
+// logRepeatedEntry method logs the repeated entry of deduplication, it
+// carries the caller info of the last duplicate. Entry is already
+// transformed, only the `repeat_count` key is renamed as per key map.
+func (l *Logger) logRepeatedEntry(e *Entry) {
+	if nk, found := l.keyMap["repeat_count"]; found {
+		e.Fields[nk] = e.Fields["repeat_count"]
+		delete(e.Fields, "repeat_count")
+	}
+	l.emit(e)
+}
+
+// logSuppressed method logs the summary entry of rate limited keys which
+// have suppressed entries.
+func (l *Logger) logSuppressed() {
+	if l.limiter == nil {
+		return
+	}
+	keys, counts := l.limiter.drain()
+	for _, key := range keys {
+		l.logSuppressedEntry(key, counts[key], nil)
+	}
+}
+
+// logSuppressedEntry method logs the summary entry of rate limited key as
+// `WARN` with fields `suppressed` and the key, it's not rate limited. Caller
+// info is taken from the given entry which triggered the summary, if any.
+func (l *Logger) logSuppressedEntry(key string, count int64, src *Entry) {
+	e := acquireEntry(l)
+	defer releaseEntry(e)
+	e.Time = time.Now()
+	e.Level = LevelWarn
+	e.Message = fmt.Sprintf("suppressed %d similar entries", count)
+	e.Fields["suppressed"] = count
+	if len(l.limiter.key) > 0 {
+		e.Fields[l.limiter.key] = key
+	}
+	e.processFields()
+	if l.receiver.IsCallerInfo() {
+		if src != nil {
+			e.File, e.Line = src.File, src.Line
+		} else {
+			e.File, e.Line = fetchCallerInfo()
+		}
+	}
+	if l.isGoroutineID {
+		e.GoroutineID = goroutineID()
+	}
+	l.transform(e)
+	l.emit(e)
+}
+
+// truncate method truncates the message and string field values which
+// exceeds the configured limits, truncated value ends with `...` and field
+// `truncated` is added into entry. Full field values are written into
+// spillover receiver if configured.
+func (l *Logger) truncate(e *Entry) {
+	truncated := false
+	if l.maxMessageLen > 0 && len(e.Message) > l.maxMessageLen {
+		e.Message = truncateString(e.Message, l.maxMessageLen)
+		truncated = true
+	}
+
+	if l.maxFieldLen > 0 {
+		var spilled Fields
+		for k, v := range e.Fields {
+			var value string
+			switch t := v.(type) {
+			case string:
+				value = t
+			case []byte:
+				if len(t) <= l.maxFieldLen {
+					continue
+				}
+				value = string(t)
+			default:
+				continue
+			}
+			if len(value) > l.maxFieldLen {
+				if l.spill != nil {
+					if spilled == nil {
+						spilled = make(Fields)
+					}
+					spilled[k] = value
+				}
+				e.Fields[k] = truncateString(value, l.maxFieldLen)
+				truncated = true
+			}
+		}
+
+		if len(spilled) > 0 {
+			ref := randomID(8)
+			l.spill.log(e, ref, spilled)
+			e.Fields["spillover_ref"] = ref
+		}
+	}
+
+	if truncated {
+		e.Fields["truncated"] = true
+	}
+}
+
+func (l *Logger) hasHooks() bool {
+	l.m.RLock()
+	defer l.m.RUnlock()
+	return len(l.hooks) > 0
 }
 
 func (l *Logger) executeHooks(e Entry) {
 	l.m.RLock()
 	defer l.m.RUnlock()
 	for _, fn := range l.hooks {
-		go fn(e)
+		go l.executeHook(fn, e)
+	}
+}
+
+// executeHook method calls the hook func, panic is reported to error
+// handler.
+func (l *Logger) executeHook(fn HookFunc, e Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.handleError(fmt.Errorf("log: hook panic: %v", r), &e)
+		}
+	}()
+	fn(e)
+}
+
+// handleError method calls the error handler, if it's set.
+func (l *Logger) handleError(err error, e *Entry) {
+	l.m.RLock()
+	fn := l.errorFn
+	l.m.RUnlock()
+	if fn != nil {
+		fn(err, e)
+	}
+}
+
+// reportError method counts the receiver write error and calls the error
+// handler.
+func (l *Logger) reportError(err error, e *Entry) {
+	atomic.AddInt64(&l.counters.writeErrors, 1)
+	l.handleError(err, e)
+}
+
+// setErrorHandler method sets the error handler into receiver, if receiver
+// reports the write errors.
+func setErrorHandler(r Receiver, fn ErrorHandlerFunc) {
+	if n, ok := r.(errorNotifier); ok {
+		n.OnError(fn)
 	}
 }
@@ -5,12 +5,20 @@
 package log
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
 	"aahframework.org/test.v0/assert"
 )
 
@@ -76,6 +84,435 @@ func TestMisc(t *testing.T) {
 	stdLogger.Print("This is aah logger binds go logger")
 }
 
+func TestAddReceiver(t *testing.T) {
+	err := AddReceiver("mydiscard", func() Receiver { return &DiscardReceiver{} })
+	assert.Nil(t, err)
+
+	err = AddReceiver("MyDiscard", func() Receiver { return &DiscardReceiver{} })
+	assert.Equal(t, "log: receiver name 'mydiscard' is already added, skip it", err.Error())
+
+	err = AddReceiver("custom", nil)
+	assert.Equal(t, ErrReceiverFactoryIsNil, err)
+
+	err = AddReceiver(" ", func() Receiver { return &DiscardReceiver{} })
+	assert.Equal(t, "log: receiver name is empty", err.Error())
+
+	cfg, _ := config.ParseString(`log { receiver = "mydiscard" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	_, ok := logger.receiver.(*DiscardReceiver)
+	assert.True(t, ok)
+
+	// discard receiver does not buffer
+	logger.Flush()
+	logger.Close()
+}
+
+func TestAddCompressor(t *testing.T) {
+	nop := func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil }
+	assert.Nil(t, AddCompressor("nop", ".nop", nop))
+	assert.Equal(t, ".nop", getCompressorByName("nop").ext)
+
+	err := AddCompressor("gzip", ".gz", nop)
+	assert.Equal(t, "log: compressor name 'gzip' is already added, skip it", err.Error())
+
+	assert.Equal(t, ErrCompressorFuncIsNil, AddCompressor("custom", ".c", nil))
+
+	err = AddCompressor(" ", ".c", nop)
+	assert.Equal(t, "log: compressor name is empty", err.Error())
+}
+
+func TestAddKeyProvider(t *testing.T) {
+	fn := func() ([]byte, error) { return make([]byte, 32), nil }
+	assert.Nil(t, AddKeyProvider("static", fn))
+	assert.NotNil(t, getKeyProviderByName("static"))
+
+	err := AddKeyProvider("static", fn)
+	assert.Equal(t, "log: key provider name 'static' is already added, skip it", err.Error())
+
+	assert.Equal(t, ErrKeyFuncIsNil, AddKeyProvider("custom", nil))
+
+	err = AddKeyProvider(" ", fn)
+	assert.Equal(t, "log: key provider name is empty", err.Error())
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestLogPackageLevels(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "error"
+    pattern = "%level:-5 %message"
+    package_levels = ["aahframework.org/security=debug", "aahframework.org/security/authc=warn"]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, "ERROR", logger.Level())
+	assert.Equal(t, LevelDebug, level(logger.maxLevel))
+
+	assert.Equal(t, LevelDebug, logger.packageLevel("aahframework.org/security"))
+	assert.Equal(t, LevelDebug, logger.packageLevel("aahframework.org/security/authz"))
+	assert.Equal(t, LevelWarn, logger.packageLevel("aahframework.org/security/authc"))
+	assert.Equal(t, LevelError, logger.packageLevel("aahframework.org/securityx"))
+	assert.Equal(t, LevelError, logger.packageLevel("main"))
+
+	// log calls from this test are resolved to package `testing`
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.Info("not logged")
+	assert.Nil(t, logger.SetPackageLevel("testing", "info"))
+	logger.Info("logged")
+	logger.WithField("key", "value").Debug("not logged")
+	logger.Print("logged")
+	assert.Equal(t, "INFO  logged \nINFO  logged \n", buf.String())
+
+	assert.Equal(t, "log: unknown log level 'verbose'", logger.SetPackageLevel("main", "verbose").Error())
+	assert.Equal(t, "log: package path is empty", logger.SetPackageLevel("", "info").Error())
+
+	cfg, _ = config.ParseString(`log { package_levels = ["aahframework.org/security"] }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid package level 'aahframework.org/security'", err.Error())
+}
+
+func TestLogPackageLevelsRace(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "error"
+    package_levels = ["aahframework.org/security=debug"]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.SetWriter(ioutil.Discard)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			_ = logger.SetPackageLevel("testing", []string{"info", "warn"}[i%2])
+			logger.Info("Yes, I would love to see")
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		logger.Info("Yes, I would love to see")
+	}
+	wg.Wait()
+	assert.Equal(t, LevelWarn, logger.packageLevel("testing"))
+}
+
+func TestLogLevelEnv(t *testing.T) {
+	cfg, _ := config.ParseString(`log { level = "warn" }`)
+	_ = os.Setenv("AAH_LOG_LEVEL", "trace")
+	defer func() { _ = os.Unsetenv("AAH_LOG_LEVEL") }()
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, "TRACE", logger.Level())
+
+	// unknown level is ignored, configured level is used
+	_ = os.Setenv("AAH_LOG_LEVEL", "verbose")
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, "WARN", logger.Level())
+	assert.Equal(t, "", levelFromEnv(""))
+
+	assert.Equal(t, "", levelFromEnv("file-audit"))
+	_ = os.Setenv("AAH_LOG_FILE_AUDIT_LEVEL", " debug ")
+	defer func() { _ = os.Unsetenv("AAH_LOG_FILE_AUDIT_LEVEL") }()
+	assert.Equal(t, "debug", levelFromEnv("file-audit"))
+}
+
+func TestAddLevel(t *testing.T) {
+	defer levelTab.Store(loadLevels())
+	assert.Nil(t, AddLevel("notice", 35))
+	assert.Nil(t, AddLevel("AUDIT", 15))
+
+	cfg, _ := config.ParseString(`log { level = "notice", pattern = "%level:-6 %message" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, "NOTICE", logger.Level())
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.Info("not logged")
+	logger.Log("notice", "config reloaded")
+	logger.WithField("user", "jeeva").Logf("AUDIT", "user %s logged in", "jeeva")
+	logger.Warn("warning")
+	logger.Log("VERBOSE", "unknown level")
+	assert.Equal(t, "NOTICE config reloaded \nAUDIT  user jeeva logged in \nWARN   warning \n", buf.String())
+
+	// formatters map custom level to next less severe built-in level
+	assert.Equal(t, LevelInfo, builtinLevel(level(35)))
+	assert.Equal(t, LevelError, builtinLevel(level(15)))
+	assert.Equal(t, LevelWarn, builtinLevel(LevelWarn))
+	assert.Equal(t, LevelTrace, builtinLevel(level(65)))
+	assert.True(t, bytes.Contains(formatEntry(jsonFmt, nil, &Entry{Level: level(35), Message: "m"}), []byte(`"level":"NOTICE"`)))
+
+	err = AddLevel("Notice", 36)
+	assert.Equal(t, "log: level name 'NOTICE' is already added, skip it", err.Error())
+	err = AddLevel("WARNING", 30)
+	assert.Equal(t, "log: level value '30' is already used by 'WARN'", err.Error())
+	err = AddLevel("NONE", 255)
+	assert.Equal(t, "log: level value '255' is reserved", err.Error())
+	err = AddLevel(" ", 36)
+	assert.Equal(t, "log: level name is empty", err.Error())
+}
+
+func TestPackageName(t *testing.T) {
+	assert.Equal(t, "aahframework.org/security", packageName("aahframework.org/security.(*Manager).Init"))
+	assert.Equal(t, "aahframework.org/log.v0", packageName("aahframework.org/log%2ev0.New"))
+	assert.Equal(t, "main", packageName("main.main"))
+	assert.Equal(t, "testing", packageName("testing.tRunner"))
+}
+
+func TestLogTruncate(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    format = "json"
+    truncate {
+      message = 10
+      field = 4
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.WithFields(Fields{"user": "jeevanandam", "id": 7}).Info("welcome to aah")
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"message":"welcome to...`)))
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"user":"jeev..."`)))
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"id":7`)))
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"truncated":true`)))
+
+	buf.Reset()
+	logger.WithField("user", "aah").Info("short")
+	assert.False(t, bytes.Contains(buf.Bytes(), []byte("truncated")))
+
+	// multi-byte character is not split
+	assert.Equal(t, "ab...", truncateString("abé", 3))
+	assert.Equal(t, "abé...", truncateString("abéd", 4))
+}
+
+func TestLogGoroutineID(t *testing.T) {
+	cfg, _ := config.ParseString(`env { active = "dev" }
+  log { pattern = "%goid %env %message" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.True(t, logger.isGoroutineID)
+
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.Info("welcome")
+	assert.Equal(t, fmt.Sprintf("%d dev welcome \n", goroutineID()), buf.String())
+	assert.True(t, goroutineID() > 0)
+
+	cfg, _ = config.ParseString(`log { receivers { console { pattern = "%goid %message" } } }`)
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.True(t, logger.isGoroutineID)
+}
+
+func TestLogDefaultFields(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    format = "json"
+    default_fields {
+      region = "us-east-1"
+      version = "1.4.2"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, Fields{"region": "us-east-1", "version": "1.4.2"}, logger.DefaultFields())
+
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.Info("welcome")
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"region":"us-east-1"`)))
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"version":"1.4.2"`)))
+
+	// overridden per call
+	buf.Reset()
+	logger.WithField("region", "eu-west-1").Info("welcome")
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"region":"eu-west-1"`)))
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"version":"1.4.2"`)))
+
+	fields := Fields{"sha": "a1b2c3d"}
+	logger.SetDefaultFields(fields)
+	fields["sha"] = "changed"
+	buf.Reset()
+	logger.Info("welcome")
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte(`"sha":"a1b2c3d"`)))
+	assert.False(t, bytes.Contains(buf.Bytes(), []byte("region")))
+}
+
+func TestLogNamespace(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	db := logger.WithField("reqid", "req-1").WithNamespace("db")
+	db.WithField("query", "select 1").Info("executed")
+	assert.Equal(t, "INFO  executed fields[db.query: select 1] \n", buf.String())
+
+	// nested namespace, parent entry is not affected
+	buf.Reset()
+	sql := db.WithField("rows", 2).WithNamespace("conn")
+	sql.WithField("id", 7).Info("nested")
+	db.Info("parent")
+	assert.True(t, strings.Contains(buf.String(), "db.conn.id: 7"))
+	assert.True(t, strings.Contains(buf.String(), "db.rows: 2"))
+	assert.True(t, strings.HasSuffix(buf.String(), "INFO  parent \n"))
+
+	// json is nested object
+	cfg, _ = config.ParseString(`log { format = "json" }`)
+	logger, _ = New(cfg)
+	buf.Reset()
+	logger.SetWriter(buf)
+	logger.WithNamespace("db").WithNamespace("").WithFields(Fields{"query": "select 1"}).Infow("executed", Int("rows", 1))
+	assert.True(t, strings.Contains(buf.String(), `"db":{"query":"select 1","rows":1}`))
+
+	// logfmt is dotted key
+	cfg, _ = config.ParseString(`log { format = "logfmt" }`)
+	logger, _ = New(cfg)
+	buf.Reset()
+	logger.SetWriter(buf)
+	logger.WithNamespace("http").WithField("status", 200).Info("served")
+	assert.True(t, strings.HasSuffix(buf.String(), "http.status=200\n"))
+
+	fields := Fields{"db": Fields{"query": "select 1"}, "id": 1}
+	assert.Equal(t, "select 1", fields.str("db.query"))
+	assert.Equal(t, "", fields.str("id.query"))
+	assert.Equal(t, Fields{"db.query": "select 1", "id": 1}, fields.flatten())
+}
+
+func TestLogChildEntry(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "trace"
+    pattern = "%level:-5 %reqid %message %fields"
+    redact = ["token"]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.SetWriter(ioutil.Discard)
+	logger.AddContext(Fields{"app": "orders"})
+
+	reqLogger := logger.WithFields(Fields{"reqid": "req-1", "token": "secret"})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reqLogger.Infof("entry %d", i)
+			reqLogger.WithField("i", i).Debugw("typed", Int("n", i))
+		}(i)
+	}
+	wg.Wait()
+
+	// child is not modified by logging
+	child := reqLogger.(*Entry)
+	assert.Equal(t, Fields{"reqid": "req-1", "token": "secret"}, child.Fields)
+	assert.Equal(t, "", child.Message)
+	assert.Equal(t, "", child.RequestID)
+
+	// fields are shared, if nothing is added
+	shared := child.WithFields(nil).(*Entry)
+	assert.Equal(t, fmt.Sprintf("%p", child.Fields), fmt.Sprintf("%p", shared.Fields))
+	added := shared.WithField("user", "jeeva").(*Entry)
+	assert.Equal(t, 3, len(added.Fields))
+	assert.Equal(t, 2, len(shared.Fields))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+type panicHook struct{ isAsync bool }
+
+func (panicHook) Levels() []string { return []string{"error"} }
+
+func (h panicHook) IsAsync() bool { return h.isAsync }
+
+func (panicHook) Fire(e *Entry) { panic("hook failed") }
+
+func TestLogErrorHandler(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		errs   []string
+		called = make(chan struct{}, 10)
+	)
+	handler := func(err error, e *Entry) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err.Error()+": "+e.Message)
+		called <- struct{}{}
+	}
+
+	// console receiver
+	cfg, _ := config.ParseString(`log { pattern = "%message" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.SetWriter(failingWriter{})
+	logger.Info("not delivered")
+	logger.SetErrorHandler(handler)
+	logger.Info("console entry")
+	assert.Equal(t, []string{"disk full: console entry"}, errs)
+
+	// file receiver
+	dir, err := ioutil.TempDir("", "errhandler")
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer os.RemoveAll(dir)
+	cfg, _ = config.ParseString(`log {
+    receiver = "file"
+    file = "` + filepath.ToSlash(filepath.Join(dir, "app.log")) + `"
+  }`)
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer logger.Close()
+	logger.SetErrorHandler(handler)
+	logger.SetWriter(failingWriter{})
+	logger.Warn("file entry")
+	assert.Equal(t, "disk full: file entry", errs[1])
+
+	// handler is set into new receiver
+	assert.Nil(t, logger.SetReceiver(&ConsoleReceiver{}))
+	logger.SetWriter(failingWriter{})
+	logger.Warn("new receiver entry")
+	assert.Equal(t, "disk full: new receiver entry", errs[2])
+
+	// hook panics
+	cfg, _ = config.ParseString(`log { receiver = "discard" }`)
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.SetErrorHandler(handler)
+	assert.Nil(t, logger.AddEntryHook(panicHook{}))
+	logger.Error("sync hook")
+	assert.Equal(t, "log: hook panic: hook failed: sync hook", errs[3])
+
+	assert.Nil(t, logger.AddEntryHook(panicHook{isAsync: true}))
+	assert.Nil(t, logger.AddHook("panic", func(e Entry) { panic("func failed") }))
+	for len(called) > 0 {
+		<-called
+	}
+	logger.Error("async hook")
+	<-called
+	<-called
+	<-called
+	logger.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 7, len(errs))
+	assert.True(t, ess.IsSliceContainsString(errs, "log: hook panic: hook failed: async hook"))
+	assert.True(t, ess.IsSliceContainsString(errs, "log: hook panic: func failed: async hook"))
+}
+
 func testPanic(logger *Logger, method, msg string) {
 	defer func() {
 		if r := recover(); r != nil {
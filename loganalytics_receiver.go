@@ -0,0 +1,189 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var _ Receiver = (*LogAnalyticsReceiver)(nil)
+
+// LogAnalyticsReceiver posts the log entry into Azure Monitor Log Analytics
+// workspace using HTTP Data Collector API. Entries are stored as custom log
+// type, Azure appends `_CL` suffix to the log type name.
+type LogAnalyticsReceiver struct {
+	errorReporter
+	url          string
+	workspaceID  string
+	sharedKey    []byte
+	logType      string
+	sender       *httpSender
+	batcher      *batcher
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// LogAnalyticsReceiver methods
+//___________________________________
+
+// Init method initializes the Log Analytics receiver instance.
+func (la *LogAnalyticsReceiver) Init(cfg *config.Config) error {
+	la.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(la.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", la.formatter)
+	}
+
+	la.workspaceID = cfg.StringDefault("log.loganalytics.workspace_id", "")
+	if ess.IsStrEmpty(la.workspaceID) {
+		return errors.New("log: loganalytics workspace_id is required")
+	}
+
+	var err error
+	if la.sharedKey, err = base64.StdEncoding.DecodeString(cfg.StringDefault("log.loganalytics.shared_key", "")); err != nil || len(la.sharedKey) == 0 {
+		return errors.New("log: loganalytics shared_key is required and must be base64 encoded")
+	}
+
+	la.logType = cfg.StringDefault("log.loganalytics.log_type", "AahLogs")
+	if !isLogAnalyticsLogType(la.logType) {
+		return fmt.Errorf("log: loganalytics invalid log_type '%s'", la.logType)
+	}
+	la.url = cfg.StringDefault("log.loganalytics.endpoint", "https://"+la.workspaceID+".ods.opinsights.azure.com") +
+		"/api/logs?api-version=2016-04-01"
+
+	if la.sender, err = newHTTPSender(cfg, "loganalytics"); err != nil {
+		return err
+	}
+
+	flushInterval, err := parseInterval(cfg, "log.loganalytics.flush_interval", "1s")
+	if err != nil {
+		return err
+	}
+	la.batcher = newBatcher(cfg.IntDefault("log.loganalytics.batch_size", 500),
+		cfg.IntDefault("log.loganalytics.queue_size", 5000), flushInterval, la.post)
+
+	la.SetWriter(writerFunc(func(p []byte) (int, error) {
+		la.batcher.add(map[string]interface{}{
+			"timestamp": time.Now().UTC().Format(time.RFC3339Nano),
+			"level":     LevelInfo.String(),
+			"message":   string(bytes.TrimRight(p, "\n")),
+		})
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (la *LogAnalyticsReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	la.flags = flags
+	if la.formatter != jsonFmt {
+		la.isCallerInfo = isCallerInfo(la.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into Log Analytics receiver.
+func (la *LogAnalyticsReceiver) SetWriter(w io.Writer) {
+	la.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (la *LogAnalyticsReceiver) IsCallerInfo() bool {
+	return la.isCallerInfo
+}
+
+// Log method queues the log entry to be posted into Log Analytics workspace.
+func (la *LogAnalyticsReceiver) Log(entry *Entry) {
+	record := make(map[string]interface{}, len(entry.Fields)+5)
+	for k, v := range entry.Fields {
+		record[k] = v
+	}
+	record["timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	record["level"] = entry.Level.String()
+	record["message"] = string(bytes.TrimRight(formatEntry(la.formatter, la.flags, entry), " \n"))
+	if len(entry.File) > 0 {
+		record["file"], record["line"] = entry.File, entry.Line
+	}
+	if !la.batcher.add(record) {
+		la.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (la *LogAnalyticsReceiver) Writer() io.Writer {
+	return la.out
+}
+
+// Close method posts the queued entries and stops the receiver.
+func (la *LogAnalyticsReceiver) Close() {
+	la.batcher.close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// LogAnalyticsReceiver Unexported methods
+//___________________________________
+
+func (la *LogAnalyticsReceiver) post(items []interface{}) {
+	body, err := json.Marshal(items)
+	if err != nil {
+		la.reportError(err, nil)
+		return
+	}
+
+	date := time.Now().UTC().Format(http.TimeFormat)
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Log-Type", la.logType)
+	header.Set("x-ms-date", date)
+	header.Set("time-generated-field", "timestamp")
+	header.Set("Authorization", la.signature(date, len(body)))
+	if _, err = la.sender.send(http.MethodPost, la.url, body, header); err != nil {
+		la.reportError(err, nil)
+	}
+}
+
+// signature method creates the `SharedKey` authorization value for Data
+// Collector API.
+func (la *LogAnalyticsReceiver) signature(date string, contentLength int) string {
+	stringToSign := "POST\n" + strconv.Itoa(contentLength) + "\napplication/json\nx-ms-date:" + date + "\n/api/logs"
+	h := hmac.New(sha256.New, la.sharedKey)
+	_, _ = h.Write([]byte(stringToSign))
+	return "SharedKey " + la.workspaceID + ":" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// isLogAnalyticsLogType method returns true if log type has only letters,
+// numbers and underscore, and it's within 100 characters.
+func isLogAnalyticsLogType(name string) bool {
+	if len(name) == 0 || len(name) > 100 {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
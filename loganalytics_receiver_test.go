@@ -0,0 +1,89 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogAnalyticsLogger(t *testing.T) {
+	sharedKey := base64.StdEncoding.EncodeToString([]byte("workspace-shared-key"))
+	var (
+		mu      sync.Mutex
+		header  http.Header
+		records []map[string]interface{}
+		valid   bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		header = r.Header
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &records)
+
+		h := hmac.New(sha256.New, []byte("workspace-shared-key"))
+		_, _ = h.Write([]byte(fmt.Sprintf("POST\n%d\napplication/json\nx-ms-date:%s\n/api/logs", len(body), r.Header.Get("x-ms-date"))))
+		valid = r.Header.Get("Authorization") == "SharedKey ws-1234:"+base64.StdEncoding.EncodeToString(h.Sum(nil))
+		assert.Equal(t, "2016-04-01", r.URL.Query().Get("api-version"))
+	}))
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "loganalytics"
+    pattern = "%%level:-5 %%message"
+    loganalytics {
+      endpoint = "%s"
+      workspace_id = "ws-1234"
+      shared_key = "%s"
+      log_type = "MyAppLogs"
+      flush_interval = "1h"
+    }
+  }
+  `, server.URL, sharedKey)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("key1", "value1").Info("Yes, I would love to see")
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.receiver.(*LogAnalyticsReceiver).Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, valid)
+	assert.Equal(t, "MyAppLogs", header.Get("Log-Type"))
+	assert.Equal(t, "timestamp", header.Get("time-generated-field"))
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, "INFO  Yes, I would love to see", records[0]["message"])
+	assert.Equal(t, "value1", records[0]["key1"])
+	assert.Equal(t, "ERROR", records[1]["level"])
+}
+
+func TestLogAnalyticsLoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "loganalytics" }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: loganalytics workspace_id is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "loganalytics", loganalytics { workspace_id = "ws", shared_key = "not base64!" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: loganalytics shared_key is required and must be base64 encoded", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "loganalytics", loganalytics { workspace_id = "ws", shared_key = "a2V5", log_type = "my-logs" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: loganalytics invalid log_type 'my-logs'", err.Error())
+}
@@ -0,0 +1,230 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var _ Receiver = (*LokiReceiver)(nil)
+
+// LokiReceiver pushes the log entry into Grafana Loki using HTTP push API.
+// Entries are batched and grouped into streams by labels, label values are
+// taken from entry fields and level.
+type LokiReceiver struct {
+	errorReporter
+	url          string
+	labels       []string
+	staticLabels map[string]string
+	header       http.Header
+	sender       *httpSender
+	batcher      *batcher
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+}
+
+type lokiEntry struct {
+	labels map[string]string
+	ts     time.Time
+	line   string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// LokiReceiver methods
+//___________________________________
+
+// Init method initializes the Loki receiver instance.
+func (l *LokiReceiver) Init(cfg *config.Config) error {
+	l.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(l.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", l.formatter)
+	}
+
+	l.url = cfg.StringDefault("log.loki.url", "http://127.0.0.1:3100/loki/api/v1/push")
+	if labels, found := cfg.StringList("log.loki.labels"); found {
+		l.labels = labels
+	} else {
+		l.labels = []string{"appname", "level", "insname"}
+	}
+
+	l.staticLabels = make(map[string]string)
+	for _, k := range cfg.KeysByPath("log.loki.static_labels") {
+		l.staticLabels[lokiLabelName(k)] = cfg.StringDefault("log.loki.static_labels."+k, "")
+	}
+
+	l.header = http.Header{}
+	l.header.Set("Content-Type", "application/json")
+	if tenant := cfg.StringDefault("log.loki.tenant_id", ""); len(tenant) > 0 {
+		l.header.Set("X-Scope-OrgID", tenant)
+	}
+
+	var err error
+	if l.sender, err = newHTTPSender(cfg, "loki"); err != nil {
+		return err
+	}
+	if username := cfg.StringDefault("log.loki.username", ""); len(username) > 0 {
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(username, cfg.StringDefault("log.loki.password", ""))
+		l.header.Set("Authorization", req.Header.Get("Authorization"))
+	}
+
+	flushInterval, err := parseInterval(cfg, "log.loki.flush_interval", "1s")
+	if err != nil {
+		return err
+	}
+	l.batcher = newBatcher(cfg.IntDefault("log.loki.batch_size", 100),
+		cfg.IntDefault("log.loki.queue_size", 1000), flushInterval, l.push)
+
+	l.SetWriter(writerFunc(func(p []byte) (int, error) {
+		l.batcher.add(&lokiEntry{
+			labels: l.entryLabels(&Entry{Level: LevelInfo}),
+			ts:     time.Now(),
+			line:   string(bytes.TrimRight(p, "\n")),
+		})
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (l *LokiReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	l.flags = flags
+	if l.formatter != jsonFmt {
+		l.isCallerInfo = isCallerInfo(l.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into Loki receiver.
+func (l *LokiReceiver) SetWriter(w io.Writer) {
+	l.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (l *LokiReceiver) IsCallerInfo() bool {
+	return l.isCallerInfo
+}
+
+// Log method queues the log entry to be pushed into Loki.
+func (l *LokiReceiver) Log(entry *Entry) {
+	if !l.batcher.add(&lokiEntry{
+		labels: l.entryLabels(entry),
+		ts:     entry.Time,
+		line:   string(bytes.TrimRight(formatEntry(l.formatter, l.flags, entry), " \n")),
+	}) {
+		l.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (l *LokiReceiver) Writer() io.Writer {
+	return l.out
+}
+
+// Close method pushes the queued entries and stops the receiver.
+func (l *LokiReceiver) Close() {
+	l.batcher.close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// LokiReceiver Unexported methods
+//___________________________________
+
+func (l *LokiReceiver) entryLabels(entry *Entry) map[string]string {
+	labels := make(map[string]string, len(l.staticLabels)+len(l.labels))
+	for k, v := range l.staticLabels {
+		labels[k] = v
+	}
+	for _, name := range l.labels {
+		var v string
+		if name == "level" {
+			v = strings.ToLower(entry.Level.String())
+		} else {
+			v = entry.Fields.str(name)
+		}
+		if len(v) > 0 {
+			labels[lokiLabelName(name)] = v
+		}
+	}
+	return labels
+}
+
+// push method groups the entries into streams by labels and sends it to
+// Loki push API.
+func (l *LokiReceiver) push(items []interface{}) {
+	var streams []*lokiStream
+	byLabels := make(map[string]*lokiStream)
+	for _, item := range items {
+		e := item.(*lokiEntry)
+		key := lokiLabelsKey(e.labels)
+		s, found := byLabels[key]
+		if !found {
+			s = &lokiStream{Stream: e.labels}
+			byLabels[key] = s
+			streams = append(streams, s)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(e.ts.UnixNano(), 10), e.line})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		l.reportError(err, nil)
+		return
+	}
+	if _, err = l.sender.send(http.MethodPost, l.url, body, l.header); err != nil {
+		l.reportError(err, nil)
+	}
+}
+
+func lokiLabelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := acquireBuffer()
+	defer releaseBuffer(buf)
+	for _, k := range keys {
+		_, _ = buf.WriteString(k + "=" + strconv.Quote(labels[k]) + ",")
+	}
+	return buf.String()
+}
+
+// lokiLabelName method sanitizes the label name as per Prometheus data model
+// `[a-zA-Z_][a-zA-Z0-9_]*`.
+func lokiLabelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
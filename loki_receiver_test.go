@@ -0,0 +1,118 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLokiLogger(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		calls   int
+		streams []lokiStream
+		header  http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		header = r.Header
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Streams []lokiStream `json:"streams"`
+		}
+		_ = json.Unmarshal(body, &req)
+		streams = append(streams, req.Streams...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "loki"
+    pattern = "%%level:-5 %%message"
+    loki {
+      url = "%s/loki/api/v1/push"
+      tenant_id = "tenant1"
+      username = "user"
+      password = "pass"
+      batch_size = 10
+      flush_interval = "1h"
+      static_labels {
+        env = "test"
+      }
+      retry {
+        min_backoff = "10ms"
+      }
+    }
+  }
+  `, server.URL)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("appname", "myapp").Info("Yes, I would love to see")
+	logger.WithField("appname", "myapp").Info("Yes, info again")
+	logger.WithFields(Fields{"appname": "myapp", "insname": "node-1"}).Error("Yes, yes, yes - finally an error")
+	logger.receiver.(*LokiReceiver).Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "tenant1", header.Get("X-Scope-OrgID"))
+	assert.Equal(t, "Basic dXNlcjpwYXNz", header.Get("Authorization"))
+
+	assert.Equal(t, 2, len(streams))
+	assert.Equal(t, map[string]string{"env": "test", "appname": "myapp", "level": "info"}, streams[0].Stream)
+	assert.Equal(t, 2, len(streams[0].Values))
+	assert.Equal(t, "INFO  Yes, I would love to see", streams[0].Values[0][1])
+	assert.Equal(t, map[string]string{"env": "test", "appname": "myapp", "insname": "node-1", "level": "error"}, streams[1].Stream)
+}
+
+func TestLokiLoggerNoRetry(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		http.Error(w, "entry out of order", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log { receiver = "loki", loki { url = "%s" } }`, server.URL))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*LokiReceiver)
+
+	_, err = receiver.sender.send(http.MethodPost, server.URL, []byte("{}"), nil)
+	assert.Equal(t, "log: http status 400: entry out of order", err.Error())
+	receiver.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, calls)
+}
+
+func TestLokiLabelName(t *testing.T) {
+	assert.Equal(t, "appname", lokiLabelName("appname"))
+	assert.Equal(t, "service_name", lokiLabelName("service.name"))
+	assert.Equal(t, "_region", lokiLabelName("1region"))
+}
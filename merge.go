@@ -0,0 +1,148 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Field merge modes, it decides the value of key when same key is present
+// in the default fields, context fields and call-site fields.
+const (
+	// FieldMergeOverride context field overrides the call-site field and
+	// call-site field overrides the default field. It's the default mode.
+	FieldMergeOverride = "override"
+
+	// FieldMergeCallSite call-site field overrides the context field and
+	// context field overrides the default field.
+	FieldMergeCallSite = "call_site"
+
+	// FieldMergeKeepFirst earlier value is kept, default field wins.
+	FieldMergeKeepFirst = "keep_first"
+
+	// FieldMergeSuffix earlier value is kept and later values are added with
+	// suffixed key, for e.g.: `key_2`, `key_3`. Fields are merged in the
+	// order of default, context and call-site.
+	FieldMergeSuffix = "suffix"
+)
+
+type fieldMerge uint8
+
+const (
+	mergeOverride fieldMerge = iota
+	mergeCallSite
+	mergeKeepFirst
+	mergeSuffix
+)
+
+var fieldMergeNames = [...]string{
+	mergeOverride:  FieldMergeOverride,
+	mergeCallSite:  FieldMergeCallSite,
+	mergeKeepFirst: FieldMergeKeepFirst,
+	mergeSuffix:    FieldMergeSuffix,
+}
+
+func (fm fieldMerge) String() string {
+	return fieldMergeNames[fm]
+}
+
+func fieldMergeByName(name string) (fieldMerge, error) {
+	for fm, n := range fieldMergeNames {
+		if strings.EqualFold(n, name) {
+			return fieldMerge(fm), nil
+		}
+	}
+	return mergeOverride, fmt.Errorf("log: unsupported field_merge '%s'", name)
+}
+
+// SetFieldMerge method sets the field merge mode of the logger, it can be
+// configured via `log.field_merge`. Default is `override`.
+//
+//	logger.SetFieldMerge(log.FieldMergeSuffix)
+func (l *Logger) SetFieldMerge(mode string) error {
+	fm, err := fieldMergeByName(mode)
+	if err != nil {
+		return err
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.fieldMerge = fm
+	return nil
+}
+
+// FieldMerge method returns the field merge mode of the logger.
+func (l *Logger) FieldMerge() string {
+	l.m.RLock()
+	defer l.m.RUnlock()
+	return l.fieldMerge.String()
+}
+
+// mergeFields method merges the default and context fields with entry
+// fields (call-site) as per merge mode.
+func (e *Entry) mergeFields(fm fieldMerge, defaults, ctx Fields) {
+	if len(defaults) == 0 && len(ctx) == 0 {
+		return
+	}
+
+	switch fm {
+	case mergeOverride:
+		mergeMissing(e.Fields, defaults)
+		e.addFields(ctx)
+	case mergeCallSite:
+		mergeMissing(e.Fields, ctx)
+		mergeMissing(e.Fields, defaults)
+	case mergeKeepFirst:
+		e.addFields(ctx)
+		e.addFields(defaults)
+	case mergeSuffix:
+		fields := make(Fields, len(defaults)+len(ctx)+len(e.Fields))
+		mergeSuffixed(fields, defaults)
+		mergeSuffixed(fields, ctx)
+		mergeSuffixed(fields, e.Fields)
+		e.Fields = fields
+	}
+}
+
+func mergeMissing(dst, src Fields) {
+	for k, v := range src {
+		if _, found := dst[k]; !found {
+			dst[k] = v
+		}
+	}
+}
+
+// mergeSuffixed adds the fields into dst, value of existing key is added
+// with next available suffix, unless it's same value.
+func mergeSuffixed(dst, src Fields) {
+	for k, v := range src {
+		ev, found := dst[k]
+		if !found {
+			dst[k] = v
+			continue
+		}
+		if isSameValue(ev, v) {
+			continue
+		}
+		for n := 2; ; n++ {
+			sk := k + "_" + strconv.Itoa(n)
+			if _, found := dst[sk]; !found {
+				dst[sk] = v
+				break
+			}
+		}
+	}
+}
+
+func isSameValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return reflect.TypeOf(a) == reflect.TypeOf(b) &&
+		reflect.TypeOf(a).Comparable() && a == b
+}
@@ -0,0 +1,63 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogFieldMerge(t *testing.T) {
+	testcases := []struct {
+		mode   string
+		expect string
+	}{
+		{mode: "", expect: "msg fields[region: eu, tier: ctx, zone: a] \n"},
+		{mode: FieldMergeOverride, expect: "msg fields[region: eu, tier: ctx, zone: a] \n"},
+		{mode: FieldMergeCallSite, expect: "msg fields[region: eu, tier: call, zone: a] \n"},
+		{mode: FieldMergeKeepFirst, expect: "msg fields[region: us, tier: default, zone: a] \n"},
+		{mode: FieldMergeSuffix, expect: "msg fields[region: us, region_2: eu, tier: default, tier_2: ctx, tier_3: call, zone: a] \n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run("mode "+tc.mode, func(t *testing.T) {
+			cfgStr := `log { pattern = "%message %fields" }`
+			if len(tc.mode) > 0 {
+				cfgStr = `log { pattern = "%message %fields", field_merge = "` + tc.mode + `" }`
+			}
+			cfg, _ := config.ParseString(cfgStr)
+			logger, err := NewWithContext(cfg, Fields{"tier": "ctx", "zone": "a"})
+			assert.FailNowOnError(t, err, "unexpected error")
+			buf := &bytes.Buffer{}
+			logger.SetWriter(buf)
+			logger.SetDefaultFields(Fields{"tier": "default", "region": "us"})
+
+			logger.WithFields(Fields{"tier": "call", "region": "eu"}).Info("msg")
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	// same value is not suffixed
+	cfg, _ := config.ParseString(`log { pattern = "%message %fields" }`)
+	logger, _ := New(cfg)
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	logger.SetDefaultFields(Fields{"region": "us", "tags": []string{"a"}})
+	assert.Nil(t, logger.SetFieldMerge("SUFFIX"))
+	assert.Equal(t, FieldMergeSuffix, logger.FieldMerge())
+	logger.WithFields(Fields{"region": "us", "tags": []string{"a"}}).Info("msg")
+	assert.Equal(t, "msg fields[region: us, tags: [a], tags_2: [a]] \n", buf.String())
+
+	err := logger.SetFieldMerge("unknown")
+	assert.Equal(t, "log: unsupported field_merge 'unknown'", err.Error())
+	assert.Equal(t, FieldMergeSuffix, logger.FieldMerge())
+
+	cfg, _ = config.ParseString(`log { field_merge = "last" }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unsupported field_merge 'last'", err.Error())
+}
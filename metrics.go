@@ -0,0 +1,213 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Help texts of the metrics.
+const (
+	entriesHelp        = "Total number of log entries by level."
+	droppedHelp        = "Total number of log entries dropped by reason."
+	receiverErrorsHelp = "Total number of receiver write errors."
+	writeDurationHelp  = "Latency of log entry write into receiver."
+)
+
+// Drop reasons of log entries, it's label `reason` of `log_dropped_total`.
+const (
+	dropFilter = iota
+	dropSample
+	dropDedup
+	dropRateLimit
+	dropReasonCount
+)
+
+var dropReasonNames = [dropReasonCount]string{
+	dropFilter:    "filter",
+	dropSample:    "sample",
+	dropDedup:     "dedup",
+	dropRateLimit: "rate_limit",
+}
+
+// writeLatencyBuckets are the upper bounds in seconds of write latency
+// histogram.
+var writeLatencyBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// MetricsHook exposes the logger metrics in Prometheus text exposition
+// format, so error-rate alerting can key off log volume directly. Hook is
+// bound to the logger it's added into. Receiver write errors are counted
+// from the errors reported by receivers. Metrics are:
+//
+//	log_entries_total{level="error"}     counter
+//	log_dropped_total{reason="sample"}   counter, reasons are filter, sample,
+//	                                     dedup, rate_limit and hook_queue
+//	log_receiver_errors_total            counter
+//	log_write_duration_seconds           histogram
+//
+// For e.g.:
+//
+//	metrics := log.NewMetricsHook()
+//	_ = logger.AddEntryHook(metrics)
+//	http.Handle("/metrics", metrics)
+//
+// Build with tag `prometheus` to use the hook as `prometheus.Collector`:
+//
+//	prometheus.MustRegister(metrics)
+type MetricsHook struct {
+	// 64-bit atomic values are kept first for alignment on 32-bit platforms
+	entries [LevelUnknown]int64
+
+	// write latency histogram, bucket counts are not cumulative
+	count   int64
+	sum     int64
+	buckets []int64
+
+	mu     sync.RWMutex
+	logger *Logger
+}
+
+// metricsSnapshot holds the metric values read at a point in time.
+type metricsSnapshot struct {
+	entries     [LevelUnknown]int64
+	drops       [dropReasonCount]int64
+	hookDrops   int64
+	writeErrors int64
+	buckets     []int64
+	count       int64
+	sum         float64
+}
+
+// logCounters holds the counters of dropped entries and receiver write
+// errors of logger.
+type logCounters struct {
+	drops       [dropReasonCount]int64
+	writeErrors int64
+}
+
+// NewMetricsHook method creates the metrics hook.
+func NewMetricsHook() *MetricsHook {
+	return &MetricsHook{buckets: make([]int64, len(writeLatencyBuckets))}
+}
+
+// Levels method returns nil, entries of all levels are counted.
+func (m *MetricsHook) Levels() []string {
+	return nil
+}
+
+// Fire method counts the entry by level.
+func (m *MetricsHook) Fire(e *Entry) {
+	if e.Level < LevelUnknown {
+		atomic.AddInt64(&m.entries[e.Level], 1)
+	}
+}
+
+// ServeHTTP method writes the metrics in Prometheus text exposition format.
+func (m *MetricsHook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo method writes the metrics in Prometheus text exposition format
+// into given writer.
+func (m *MetricsHook) WriteTo(w io.Writer) (int64, error) {
+	s := m.snapshot()
+	buf := &bytes.Buffer{}
+
+	writeMetricHeader(buf, "log_entries_total", "counter", entriesHelp)
+	for _, lvl := range levelsUntil(LevelUnknown - 1) {
+		fmt.Fprintf(buf, "log_entries_total{level=%q} %d\n",
+			strings.ToLower(lvl.String()), s.entries[lvl])
+	}
+
+	writeMetricHeader(buf, "log_dropped_total", "counter", droppedHelp)
+	for i, name := range dropReasonNames {
+		fmt.Fprintf(buf, "log_dropped_total{reason=%q} %d\n", name, s.drops[i])
+	}
+	fmt.Fprintf(buf, "log_dropped_total{reason=\"hook_queue\"} %d\n", s.hookDrops)
+
+	writeMetricHeader(buf, "log_receiver_errors_total", "counter", receiverErrorsHelp)
+	fmt.Fprintf(buf, "log_receiver_errors_total %d\n", s.writeErrors)
+
+	writeMetricHeader(buf, "log_write_duration_seconds", "histogram", writeDurationHelp)
+	for i, le := range writeLatencyBuckets {
+		fmt.Fprintf(buf, "log_write_duration_seconds_bucket{le=%q} %d\n",
+			strconv.FormatFloat(le, 'g', -1, 64), s.buckets[i])
+	}
+	fmt.Fprintf(buf, "log_write_duration_seconds_bucket{le=\"+Inf\"} %d\n", s.count)
+	fmt.Fprintf(buf, "log_write_duration_seconds_sum %s\n",
+		strconv.FormatFloat(s.sum, 'g', -1, 64))
+	fmt.Fprintf(buf, "log_write_duration_seconds_count %d\n", s.count)
+
+	return buf.WriteTo(w)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// snapshot method reads the current metric values, histogram buckets are
+// cumulative.
+func (m *MetricsHook) snapshot() *metricsSnapshot {
+	s := &metricsSnapshot{buckets: make([]int64, len(writeLatencyBuckets))}
+	for i := range s.entries {
+		s.entries[i] = atomic.LoadInt64(&m.entries[i])
+	}
+
+	m.mu.RLock()
+	l := m.logger
+	m.mu.RUnlock()
+	if l != nil {
+		for i := range s.drops {
+			s.drops[i] = atomic.LoadInt64(&l.counters.drops[i])
+		}
+		s.hookDrops = l.HookStats().Dropped
+		s.writeErrors = atomic.LoadInt64(&l.counters.writeErrors)
+	}
+
+	var cumulative int64
+	for i := range writeLatencyBuckets {
+		cumulative += atomic.LoadInt64(&m.buckets[i])
+		s.buckets[i] = cumulative
+	}
+	s.count = atomic.LoadInt64(&m.count)
+	s.sum = time.Duration(atomic.LoadInt64(&m.sum)).Seconds()
+	return s
+}
+
+func (m *MetricsHook) bind(l *Logger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = l
+}
+
+// observe method records the write latency into histogram.
+func (m *MetricsHook) observe(d time.Duration) {
+	seconds := d.Seconds()
+	for i, le := range writeLatencyBuckets {
+		if seconds <= le {
+			atomic.AddInt64(&m.buckets[i], 1)
+			break
+		}
+	}
+	atomic.AddInt64(&m.sum, int64(d))
+	atomic.AddInt64(&m.count, 1)
+}
+
+func (l *Logger) countDrop(reason int) {
+	atomic.AddInt64(&l.counters.drops[reason], 1)
+}
+
+func writeMetricHeader(buf *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build prometheus
+// +build prometheus
+
+package log
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	entriesDesc = prometheus.NewDesc("log_entries_total",
+		entriesHelp, []string{"level"}, nil)
+	droppedDesc = prometheus.NewDesc("log_dropped_total",
+		droppedHelp, []string{"reason"}, nil)
+	receiverErrorsDesc = prometheus.NewDesc("log_receiver_errors_total",
+		receiverErrorsHelp, nil, nil)
+	writeDurationDesc = prometheus.NewDesc("log_write_duration_seconds",
+		writeDurationHelp, nil, nil)
+)
+
+var _ prometheus.Collector = (*MetricsHook)(nil)
+
+// Describe method sends the descriptors of metrics, it implements
+// `prometheus.Collector`.
+func (m *MetricsHook) Describe(ch chan<- *prometheus.Desc) {
+	ch <- entriesDesc
+	ch <- droppedDesc
+	ch <- receiverErrorsDesc
+	ch <- writeDurationDesc
+}
+
+// Collect method sends the current metric values, it implements
+// `prometheus.Collector`.
+func (m *MetricsHook) Collect(ch chan<- prometheus.Metric) {
+	s := m.snapshot()
+
+	for _, lvl := range levelsUntil(LevelUnknown - 1) {
+		ch <- prometheus.MustNewConstMetric(entriesDesc, prometheus.CounterValue,
+			float64(s.entries[lvl]), strings.ToLower(lvl.String()))
+	}
+
+	for i, name := range dropReasonNames {
+		ch <- prometheus.MustNewConstMetric(droppedDesc, prometheus.CounterValue,
+			float64(s.drops[i]), name)
+	}
+	ch <- prometheus.MustNewConstMetric(droppedDesc, prometheus.CounterValue,
+		float64(s.hookDrops), "hook_queue")
+
+	ch <- prometheus.MustNewConstMetric(receiverErrorsDesc, prometheus.CounterValue,
+		float64(s.writeErrors))
+
+	buckets := make(map[float64]uint64, len(writeLatencyBuckets))
+	for i, le := range writeLatencyBuckets {
+		buckets[le] = uint64(s.buckets[i])
+	}
+	ch <- prometheus.MustNewConstHistogram(writeDurationDesc, uint64(s.count), s.sum, buckets)
+}
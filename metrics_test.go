@@ -0,0 +1,70 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogMetricsHook(t *testing.T) {
+	cfg, _ := config.ParseString(`log { level = "debug" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.SetWriter(ioutil.Discard)
+
+	metrics := NewMetricsHook()
+	assert.Nil(t, logger.AddEntryHook(metrics))
+	assert.Nil(t, logger.AddFilter("health", func(e *Entry) bool {
+		return e.Message != "health check"
+	}))
+
+	logger.Info("order placed")
+	logger.Info("order shipped")
+	logger.Error("payment failed")
+	logger.Debug("health check")
+	logger.Trace("not enabled")
+
+	logger.SetWriter(failingWriter{})
+	logger.Warn("not delivered")
+
+	w := httptest.NewRecorder()
+	metrics.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	out := w.Body.String()
+
+	for _, line := range []string{
+		"# TYPE log_entries_total counter",
+		`log_entries_total{level="fatal"} 0`,
+		`log_entries_total{level="error"} 1`,
+		`log_entries_total{level="warn"} 1`,
+		`log_entries_total{level="info"} 2`,
+		`log_entries_total{level="debug"} 0`,
+		`log_dropped_total{reason="filter"} 1`,
+		`log_dropped_total{reason="sample"} 0`,
+		`log_dropped_total{reason="hook_queue"} 0`,
+		"log_receiver_errors_total 1",
+		"# TYPE log_write_duration_seconds histogram",
+		`log_write_duration_seconds_bucket{le="+Inf"} 4`,
+		"log_write_duration_seconds_count 4",
+	} {
+		assert.True(t, strings.Contains(out, line+"\n"))
+	}
+	assert.True(t, strings.Contains(out, `log_write_duration_seconds_bucket{le="0.0001"} `))
+	assert.True(t, strings.Contains(out, "log_write_duration_seconds_sum "))
+
+	// unbound hook
+	buf := &bytes.Buffer{}
+	_, err = NewMetricsHook().WriteTo(buf)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(buf.String(), "log_receiver_errors_total 0\n"))
+}
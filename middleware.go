@@ -0,0 +1,200 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PrincipalFunc type is used to extract the principal of the request, for
+// e.g.: authenticated user ID. It returns empty value if not available.
+type PrincipalFunc func(r *http.Request) string
+
+// Middleware method returns the `net/http` middleware which creates the
+// request-scoped child logger with fields `reqid`, `principal`,
+// `remote_ip`, `method` and `path`, and stores it in the request context.
+// Request ID is taken from request header or trace ID of `traceparent`
+// header otherwise generated by `NewID`, it's also set into response
+// header. Inbound request ID longer than 128 chars or having chars other
+// than alphanumeric and `-_.:` is ignored. On completion entry is logged
+// with fields `status`, `bytes` and `latency`, level is `ERROR` for 5xx,
+// `WARN` for 4xx otherwise `INFO`. Principal func is optional. For e.g.:
+//
+//	http.Handle("/", logger.Middleware(principalFn)(handler))
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		log.FromContext(r.Context()).Info("processing order")
+//	}
+//
+// Request ID header name can be configured, default is `X-Request-Id`.
+//
+//	log {
+//	  http {
+//	    request_id_header = "X-Request-Id"
+//	  }
+//	}
+func (l *Logger) Middleware(principalFn PrincipalFunc) func(http.Handler) http.Handler {
+	hdrName := l.cfg.StringDefault("log.http.request_id_header", HeaderRequestID)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			reqID := RequestIDFromHeader(r.Header, hdrName)
+			if !isValidRequestID(reqID) {
+				reqID = NewID()
+			}
+			w.Header().Set(hdrName, reqID)
+
+			fields := Fields{
+				"reqid":     reqID,
+				"remote_ip": remoteIP(r),
+				"method":    r.Method,
+				"path":      r.URL.Path,
+			}
+			if traceID, _, ok := ParseTraceparent(r.Header.Get(HeaderTraceparent)); ok {
+				fields["trace_id"] = traceID
+			}
+			if principalFn != nil {
+				if principal := principalFn(r); len(principal) > 0 {
+					fields["principal"] = principal
+				}
+			}
+			logger := l.WithFields(fields)
+
+			sw := &statusWriter{ResponseWriter: w}
+			defer func() {
+				// panicking handler is logged as 500 and panic is propagated
+				rec := recover()
+				if rec != nil && sw.status == 0 {
+					sw.status = http.StatusInternalServerError
+				}
+				logCompletion(logger, sw, start)
+				if rec != nil {
+					panic(rec)
+				}
+			}()
+			next.ServeHTTP(sw, r.WithContext(NewContext(r.Context(), logger)))
+		})
+	}
+}
+
+// maxRequestIDLen is the max length of inbound request ID.
+const maxRequestIDLen = 128
+
+// isValidRequestID method returns true if the inbound request ID is safe to
+// echo into response header and log entries.
+func isValidRequestID(reqID string) bool {
+	if len(reqID) == 0 || len(reqID) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(reqID); i++ {
+		c := reqID[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') &&
+			c != '-' && c != '_' && c != '.' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// logCompletion method logs the request completed entry with response
+// status, bytes and latency.
+func logCompletion(logger Loggerer, sw *statusWriter, start time.Time) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	logger = logger.WithFields(Fields{
+		"status":  sw.status,
+		"bytes":   sw.bytes,
+		"latency": time.Since(start),
+	})
+	switch {
+	case sw.status >= http.StatusInternalServerError:
+		logger.Error("request completed")
+	case sw.status >= http.StatusBadRequest:
+		logger.Warn("request completed")
+	default:
+		logger.Info("request completed")
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// statusWriter
+//___________________________________
+
+// statusWriter captures the response status code and bytes written.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.status == 0 {
+		sw.status = code
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	n, err := sw.ResponseWriter.Write(b)
+	sw.bytes += n
+	return n, err
+}
+
+// Flush method flushes the response, if underlying writer supports it.
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack method hijacks the connection, if underlying writer supports it,
+// for e.g.: WebSocket upgrade. Status is `101` unless it's already written.
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := sw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("log: response writer does not support hijack")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && sw.status == 0 {
+		sw.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Push method initiates HTTP/2 server push, if underlying writer supports
+// it.
+func (sw *statusWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := sw.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// remoteIP method returns the client IP address from header
+// `X-Forwarded-For`, `X-Real-Ip` otherwise request remote address.
+func remoteIP(r *http.Request) string {
+	if fwdFor := r.Header.Get("X-Forwarded-For"); len(fwdFor) > 0 {
+		if idx := strings.IndexByte(fwdFor, ','); idx > 0 {
+			fwdFor = fwdFor[:idx]
+		}
+		return strings.TrimSpace(fwdFor)
+	}
+	if realIP := r.Header.Get("X-Real-Ip"); len(realIP) > 0 {
+		return strings.TrimSpace(realIP)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,204 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogMiddleware(t *testing.T) {
+	cfg, _ := config.ParseString(`log { format = "json" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	handler := logger.Middleware(func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("processing")
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	// request ID from header
+	r := httptest.NewRequest(http.MethodGet, "/orders?id=1", nil)
+	r.Header.Set("X-Request-Id", "req-1")
+	r.Header.Set("X-User", "jeeva")
+	r.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, "req-1", w.Header().Get("X-Request-Id"))
+
+	entries := decodeEntries(t, buf)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "processing", entries[0]["message"])
+	assert.Equal(t, "req-1", entries[0]["request_id"])
+	assert.Equal(t, "jeeva", entries[0]["principal"])
+	fields := entries[0]["fields"].(map[string]interface{})
+	assert.Equal(t, "10.0.0.1", fields["remote_ip"])
+	assert.Equal(t, "GET", fields["method"])
+	assert.Equal(t, "/orders", fields["path"])
+
+	assert.Equal(t, "request completed", entries[1]["message"])
+	assert.Equal(t, "INFO", entries[1]["level"])
+	fields = entries[1]["fields"].(map[string]interface{})
+	assert.Equal(t, float64(200), fields["status"])
+	assert.Equal(t, float64(5), fields["bytes"])
+	assert.NotNil(t, fields["latency"])
+
+	// generated request ID
+	buf.Reset()
+	r = httptest.NewRequest(http.MethodPost, "/missing", nil)
+	r.RemoteAddr = "192.168.1.5:5432"
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	reqID := w.Header().Get("X-Request-Id")
+	assert.Equal(t, 36, len(reqID))
+
+	entries = decodeEntries(t, buf)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, reqID, entries[1]["request_id"])
+	assert.Equal(t, "WARN", entries[1]["level"])
+	assert.Nil(t, entries[1]["principal"])
+	fields = entries[1]["fields"].(map[string]interface{})
+	assert.Equal(t, "192.168.1.5", fields["remote_ip"])
+	assert.Equal(t, float64(404), fields["status"])
+}
+
+func TestLogMiddlewareRequestIDHeader(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level %reqid %message"
+    http {
+      request_id_header = "X-Correlation-Id"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	handler := logger.Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Correlation-Id", "corr-1")
+	r.Header.Set("X-Real-Ip", "10.1.1.1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, "corr-1", w.Header().Get("X-Correlation-Id"))
+	assert.Equal(t, "ERROR corr-1 request completed \n", buf.String())
+	assert.Equal(t, "10.1.1.1", remoteIP(r))
+}
+
+func TestLogMiddlewareInvalidRequestID(t *testing.T) {
+	cfg, _ := config.ParseString(`log { pattern = "%reqid %message" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	handler := logger.Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, reqID := range []string{"req 1\ninjected", "<script>", strings.Repeat("a", 129)} {
+		buf.Reset()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Request-Id", reqID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		genID := w.Header().Get("X-Request-Id")
+		assert.NotEqual(t, reqID, genID)
+		assert.True(t, isValidRequestID(genID))
+		assert.Equal(t, genID+" request completed \n", buf.String())
+	}
+
+	assert.True(t, isValidRequestID("req-1_a.b:c"))
+	assert.True(t, isValidRequestID(strings.Repeat("a", 128)))
+	assert.False(t, isValidRequestID(""))
+}
+
+func TestLogMiddlewareHijack(t *testing.T) {
+	cfg, _ := config.ParseString(`log { pattern = "%level %message %fields" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	var hijackErr, pushErr error
+	handler := logger.Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if hijackErr = err; err == nil {
+			_ = conn.Close()
+		}
+		pushErr = w.(http.Pusher).Push("/app.js", nil)
+	}))
+
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.Nil(t, hijackErr)
+	assert.True(t, w.hijacked)
+	assert.Equal(t, http.ErrNotSupported, pushErr)
+	assert.True(t, strings.HasPrefix(buf.String(), "INFO request completed fields[bytes: 0, latency: "))
+	assert.True(t, strings.Contains(buf.String(), "status: 101"))
+
+	// underlying writer doesn't support hijack
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.Equal(t, "log: response writer does not support hijack", hijackErr.Error())
+}
+
+func TestLogMiddlewarePanic(t *testing.T) {
+	cfg, _ := config.ParseString(`log { pattern = "%level %message %fields" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	handler := logger.Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	var rec interface{}
+	func() {
+		defer func() { rec = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	assert.Equal(t, "boom", rec)
+	assert.True(t, strings.HasPrefix(buf.String(), "ERROR request completed fields[bytes: 0, latency: "))
+	assert.True(t, strings.Contains(buf.String(), "status: 500"))
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	conn, peer := net.Pipe()
+	_ = peer.Close()
+	return conn, nil, nil
+}
+
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]interface{}
+		assert.FailNowOnError(t, json.Unmarshal([]byte(line), &m), "unexpected error")
+		entries = append(entries, m)
+	}
+	return entries
+}
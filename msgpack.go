@@ -0,0 +1,308 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// msgpackEncoder is minimal MessagePack encoder which covers the types used
+// by log entry.
+type msgpackEncoder struct {
+	bytes.Buffer
+}
+
+func (e *msgpackEncoder) encode(v interface{}) {
+	switch t := v.(type) {
+	case nil:
+		_ = e.WriteByte(0xc0)
+	case bool:
+		if t {
+			_ = e.WriteByte(0xc3)
+		} else {
+			_ = e.WriteByte(0xc2)
+		}
+	case int:
+		e.int(int64(t))
+	case int8:
+		e.int(int64(t))
+	case int16:
+		e.int(int64(t))
+	case int32:
+		e.int(int64(t))
+	case int64:
+		e.int(t)
+	case uint:
+		e.uint(uint64(t))
+	case uint8:
+		e.uint(uint64(t))
+	case uint16:
+		e.uint(uint64(t))
+	case uint32:
+		e.uint(uint64(t))
+	case uint64:
+		e.uint(t)
+	case float32:
+		e.float(float64(t))
+	case float64:
+		e.float(t)
+	case string:
+		e.string(t)
+	case []byte:
+		e.bin(t)
+	case time.Time:
+		e.eventTime(t)
+	case time.Duration:
+		e.int(int64(t))
+	case []interface{}:
+		e.arrayHeader(len(t))
+		for _, i := range t {
+			e.encode(i)
+		}
+	case []string:
+		e.arrayHeader(len(t))
+		for _, i := range t {
+			e.string(i)
+		}
+	case Fields:
+		e.encode(map[string]interface{}(t))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		e.mapHeader(len(t))
+		for _, k := range keys {
+			e.string(k)
+			e.encode(t[k])
+		}
+	case map[string]string:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		e.mapHeader(len(t))
+		for _, k := range keys {
+			e.string(k)
+			e.string(t[k])
+		}
+	case error:
+		e.string(t.Error())
+	case fmt.Stringer:
+		e.string(t.String())
+	default:
+		e.string(fmt.Sprint(t))
+	}
+}
+
+func (e *msgpackEncoder) int(v int64) {
+	switch {
+	case v >= 0:
+		e.uint(uint64(v))
+	case v >= -32:
+		_ = e.WriteByte(byte(v))
+	case v >= math.MinInt8:
+		_, _ = e.Write([]byte{0xd0, byte(v)})
+	case v >= math.MinInt16:
+		_ = e.WriteByte(0xd1)
+		_ = binary.Write(e, binary.BigEndian, int16(v))
+	case v >= math.MinInt32:
+		_ = e.WriteByte(0xd2)
+		_ = binary.Write(e, binary.BigEndian, int32(v))
+	default:
+		_ = e.WriteByte(0xd3)
+		_ = binary.Write(e, binary.BigEndian, v)
+	}
+}
+
+func (e *msgpackEncoder) uint(v uint64) {
+	switch {
+	case v <= 0x7f:
+		_ = e.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		_, _ = e.Write([]byte{0xcc, byte(v)})
+	case v <= math.MaxUint16:
+		_ = e.WriteByte(0xcd)
+		_ = binary.Write(e, binary.BigEndian, uint16(v))
+	case v <= math.MaxUint32:
+		_ = e.WriteByte(0xce)
+		_ = binary.Write(e, binary.BigEndian, uint32(v))
+	default:
+		_ = e.WriteByte(0xcf)
+		_ = binary.Write(e, binary.BigEndian, v)
+	}
+}
+
+func (e *msgpackEncoder) float(v float64) {
+	_ = e.WriteByte(0xcb)
+	_ = binary.Write(e, binary.BigEndian, math.Float64bits(v))
+}
+
+func (e *msgpackEncoder) string(v string) {
+	l := len(v)
+	switch {
+	case l < 32:
+		_ = e.WriteByte(0xa0 | byte(l))
+	case l <= math.MaxUint8:
+		_, _ = e.Write([]byte{0xd9, byte(l)})
+	case l <= math.MaxUint16:
+		_ = e.WriteByte(0xda)
+		_ = binary.Write(e, binary.BigEndian, uint16(l))
+	default:
+		_ = e.WriteByte(0xdb)
+		_ = binary.Write(e, binary.BigEndian, uint32(l))
+	}
+	_, _ = e.WriteString(v)
+}
+
+func (e *msgpackEncoder) bin(v []byte) {
+	l := len(v)
+	switch {
+	case l <= math.MaxUint8:
+		_, _ = e.Write([]byte{0xc4, byte(l)})
+	case l <= math.MaxUint16:
+		_ = e.WriteByte(0xc5)
+		_ = binary.Write(e, binary.BigEndian, uint16(l))
+	default:
+		_ = e.WriteByte(0xc6)
+		_ = binary.Write(e, binary.BigEndian, uint32(l))
+	}
+	_, _ = e.Write(v)
+}
+
+func (e *msgpackEncoder) arrayHeader(l int) {
+	switch {
+	case l < 16:
+		_ = e.WriteByte(0x90 | byte(l))
+	case l <= math.MaxUint16:
+		_ = e.WriteByte(0xdc)
+		_ = binary.Write(e, binary.BigEndian, uint16(l))
+	default:
+		_ = e.WriteByte(0xdd)
+		_ = binary.Write(e, binary.BigEndian, uint32(l))
+	}
+}
+
+func (e *msgpackEncoder) mapHeader(l int) {
+	switch {
+	case l < 16:
+		_ = e.WriteByte(0x80 | byte(l))
+	case l <= math.MaxUint16:
+		_ = e.WriteByte(0xde)
+		_ = binary.Write(e, binary.BigEndian, uint16(l))
+	default:
+		_ = e.WriteByte(0xdf)
+		_ = binary.Write(e, binary.BigEndian, uint32(l))
+	}
+}
+
+// eventTime method encodes the time as Fluentd EventTime, extension type 0
+// with seconds and nanoseconds as big-endian uint32.
+func (e *msgpackEncoder) eventTime(t time.Time) {
+	_, _ = e.Write([]byte{0xd7, 0x00})
+	_ = binary.Write(e, binary.BigEndian, uint32(t.Unix()))
+	_ = binary.Write(e, binary.BigEndian, uint32(t.Nanosecond()))
+}
+
+// timestamp method encodes the time as MessagePack timestamp extension
+// type -1, in the smallest of 32, 64 or 96 bit format.
+func (e *msgpackEncoder) timestamp(t time.Time) {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	switch {
+	case sec>>34 == 0 && nsec == 0 && sec <= math.MaxUint32:
+		_, _ = e.Write([]byte{0xd6, 0xff})
+		_ = binary.Write(e, binary.BigEndian, uint32(sec))
+	case sec>>34 == 0:
+		_, _ = e.Write([]byte{0xd7, 0xff})
+		_ = binary.Write(e, binary.BigEndian, uint64(nsec)<<34|uint64(sec))
+	default:
+		_, _ = e.Write([]byte{0xc7, 12, 0xff})
+		_ = binary.Write(e, binary.BigEndian, uint32(nsec))
+		_ = binary.Write(e, binary.BigEndian, sec)
+	}
+}
+
+// decodeMsgpackStringMap method decodes the MessagePack map with string key
+// and values, other value types are skipped. It's used for reading
+// acknowledgement responses.
+func decodeMsgpackStringMap(r *bufio.Reader) (map[string]string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var size int
+	switch {
+	case b&0xf0 == 0x80:
+		size = int(b & 0x0f)
+	case b == 0xde:
+		var l uint16
+		err = binary.Read(r, binary.BigEndian, &l)
+		size = int(l)
+	default:
+		return nil, errors.New("log: msgpack value is not a map")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, size)
+	for i := 0; i < size; i++ {
+		k, err := decodeMsgpackString(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := decodeMsgpackString(r)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func decodeMsgpackString(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	var size int
+	switch {
+	case b&0xe0 == 0xa0:
+		size = int(b & 0x1f)
+	case b == 0xd9 || b == 0xc4:
+		var l uint8
+		err = binary.Read(r, binary.BigEndian, &l)
+		size = int(l)
+	case b == 0xda || b == 0xc5:
+		var l uint16
+		err = binary.Read(r, binary.BigEndian, &l)
+		size = int(l)
+	case b == 0xdb || b == 0xc6:
+		var l uint32
+		err = binary.Read(r, binary.BigEndian, &l)
+		size = int(l)
+	default:
+		return "", fmt.Errorf("log: msgpack unexpected type 0x%x, expected string", b)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	v := make([]byte, size)
+	_, err = io.ReadFull(r, v)
+	return string(v), err
+}
@@ -0,0 +1,245 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"aahframework.org/essentials.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestMsgpackEncode(t *testing.T) {
+	now := time.Unix(1500000000, 123456789)
+	testcases := []struct {
+		value  interface{}
+		expect interface{}
+	}{
+		{value: nil, expect: nil},
+		{value: true, expect: true},
+		{value: 7, expect: int64(7)},
+		{value: -7, expect: int64(-7)},
+		{value: -200, expect: int64(-200)},
+		{value: -70000, expect: int64(-70000)},
+		{value: uint16(300), expect: int64(300)},
+		{value: int64(math.MaxUint32) + 1, expect: int64(math.MaxUint32) + 1},
+		{value: 1.5, expect: 1.5},
+		{value: "aah", expect: "aah"},
+		{value: string(make([]byte, 40)), expect: string(make([]byte, 40))},
+		{value: []byte("bin"), expect: "bin"},
+		{value: []string{"a", "b"}, expect: []interface{}{"a", "b"}},
+		{value: errors.New("failed"), expect: "failed"},
+		{value: LevelWarn, expect: "WARN"},
+		{value: now, expect: now},
+		{
+			value:  Fields{"key1": "value1", "key2": 2},
+			expect: map[string]interface{}{"key1": "value1", "key2": int64(2)},
+		},
+	}
+
+	for _, tc := range testcases {
+		e := &msgpackEncoder{}
+		e.encode(tc.value)
+		v, err := decodeTestMsgpack(bufio.NewReader(bytes.NewReader(e.Bytes())))
+		assert.FailNowOnError(t, err, "")
+		assert.Equal(t, fmt.Sprintf("%#v", tc.expect), fmt.Sprintf("%#v", v))
+	}
+}
+
+func TestMsgpackTimestamp(t *testing.T) {
+	for _, ts := range []time.Time{
+		time.Unix(1500000000, 0),
+		time.Unix(1500000000, 123456789),
+		time.Unix(-1, 500),
+	} {
+		e := &msgpackEncoder{}
+		e.timestamp(ts)
+		v, err := decodeTestMsgpack(bufio.NewReader(bytes.NewReader(e.Bytes())))
+		assert.FailNowOnError(t, err, "")
+		assert.True(t, ts.Equal(v.(time.Time)))
+	}
+}
+
+func TestMsgpackFormatter(t *testing.T) {
+	flags, _ := ess.ParseFmtFlag("%level %shortfile %line %message", FmtFlags)
+	entry := &Entry{
+		Level:     LevelInfo,
+		Time:      time.Unix(1467498361, 530000000),
+		Message:   "Yes, I would love to see",
+		RequestID: "40139CA6368607085BF6",
+		File:      "/a/b/c/formatter_test.go",
+		Line:      29,
+		Fields:    Fields{"reqid": "40139CA6368607085BF6", "user": "jeeva"},
+	}
+	b := formatEntry(msgpackFmt, flags, entry)
+	r := bufio.NewReader(bytes.NewReader(append(b, b...)))
+	for i := 0; i < 2; i++ {
+		v, err := decodeTestMsgpack(r)
+		assert.FailNowOnError(t, err, "")
+		assert.Equal(t, fmt.Sprintf("%#v", map[string]interface{}{
+			"time":       entry.Time,
+			"level":      "INFO",
+			"message":    "Yes, I would love to see",
+			"request_id": "40139CA6368607085BF6",
+			"file":       "formatter_test.go",
+			"line":       int64(29),
+			"fields":     map[string]interface{}{"user": "jeeva"},
+		}), fmt.Sprintf("%#v", v))
+	}
+
+	v, err := decodeTestMsgpack(bufio.NewReader(bytes.NewReader(formatEntry(msgpackFmt, nil,
+		&Entry{Level: LevelError, Time: entry.Time}))))
+	assert.FailNowOnError(t, err, "")
+	assert.Equal(t, 2, len(v.(map[string]interface{})))
+}
+
+func TestMsgpackDecodeStringMap(t *testing.T) {
+	e := &msgpackEncoder{}
+	e.encode(map[string]string{"ack": "chunk-id"})
+	m, err := decodeMsgpackStringMap(bufio.NewReader(bytes.NewReader(e.Bytes())))
+	assert.Nil(t, err)
+	assert.Equal(t, "chunk-id", m["ack"])
+
+	_, err = decodeMsgpackStringMap(bufio.NewReader(bytes.NewReader([]byte{0x91, 0x01})))
+	assert.Equal(t, "log: msgpack value is not a map", err.Error())
+
+	_, err = decodeMsgpackStringMap(bufio.NewReader(bytes.NewReader([]byte{0x81, 0x01, 0x01})))
+	assert.Equal(t, "log: msgpack unexpected type 0x1, expected string", err.Error())
+}
+
+// decodeTestMsgpack decodes the MessagePack value, integers are decoded as
+// int64, EventTime and timestamp extensions as time.Time.
+func decodeTestMsgpack(r *bufio.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	readN := func(n int) ([]byte, error) {
+		v := make([]byte, n)
+		_, err := io.ReadFull(r, v)
+		return v, err
+	}
+	readLen := func(n int) (int, error) {
+		v, err := readN(n)
+		if err != nil {
+			return 0, err
+		}
+		var l uint64
+		for _, c := range v {
+			l = l<<8 | uint64(c)
+		}
+		return int(l), nil
+	}
+	readArray := func(size int) (interface{}, error) {
+		a := make([]interface{}, size)
+		for i := range a {
+			if a[i], err = decodeTestMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	}
+	readMap := func(size int) (interface{}, error) {
+		m := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			k, err := decodeTestMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			if m[fmt.Sprint(k)], err = decodeTestMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return readMap(int(b & 0x0f))
+	case b&0xf0 == 0x90:
+		return readArray(int(b & 0x0f))
+	case b&0xe0 == 0xa0:
+		v, err := readN(int(b & 0x1f))
+		return string(v), err
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return b == 0xc3, nil
+	case 0xc4, 0xc5, 0xc6, 0xd9, 0xda, 0xdb:
+		sizes := map[byte]int{0xc4: 1, 0xc5: 2, 0xc6: 4, 0xd9: 1, 0xda: 2, 0xdb: 4}
+		l, err := readLen(sizes[b])
+		if err != nil {
+			return nil, err
+		}
+		v, err := readN(l)
+		return string(v), err
+	case 0xcb:
+		v, err := readN(8)
+		return math.Float64frombits(binary.BigEndian.Uint64(v)), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := readLen(1 << (b - 0xcc))
+		return int64(v), err
+	case 0xd0:
+		v, err := readN(1)
+		return int64(int8(v[0])), err
+	case 0xd1:
+		v, err := readN(2)
+		return int64(int16(binary.BigEndian.Uint16(v))), err
+	case 0xd2:
+		v, err := readN(4)
+		return int64(int32(binary.BigEndian.Uint32(v))), err
+	case 0xd3:
+		v, err := readN(8)
+		return int64(binary.BigEndian.Uint64(v)), err
+	case 0xd6:
+		v, err := readN(5)
+		return time.Unix(int64(binary.BigEndian.Uint32(v[1:])), 0), err
+	case 0xd7:
+		v, err := readN(9)
+		if err != nil {
+			return nil, err
+		}
+		if v[0] == 0xff {
+			ts := binary.BigEndian.Uint64(v[1:])
+			return time.Unix(int64(ts&(1<<34-1)), int64(ts>>34)), nil
+		}
+		return time.Unix(int64(binary.BigEndian.Uint32(v[1:5])), int64(binary.BigEndian.Uint32(v[5:]))), nil
+	case 0xc7:
+		v, err := readN(14)
+		if err != nil {
+			return nil, err
+		}
+		return time.Unix(int64(binary.BigEndian.Uint64(v[6:])), int64(binary.BigEndian.Uint32(v[2:6]))), nil
+	case 0xdc, 0xdd:
+		l, err := readLen(2 << (b - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readArray(l)
+	case 0xde, 0xdf:
+		l, err := readLen(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMap(l)
+	}
+	return nil, fmt.Errorf("unsupported msgpack type 0x%x", b)
+}
@@ -0,0 +1,300 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"aahframework.org/config.v0"
+)
+
+var _ Receiver = (*MultiReceiver)(nil)
+
+// MultiReceiver fans out the log entry to multiple receivers configured under
+// section `log.receivers`. Each receiver can have its own `level`, `pattern`
+// and `format`, otherwise it inherits from `log.*`. Entry is dispatched to
+// the receiver as per its level, independent of the other receivers and
+// logger level. Env variable `AAH_LOG_LEVEL` overrides the level of all
+// receivers and `AAH_LOG_<NAME>_LEVEL` overrides the level of the receiver,
+// for e.g.: `AAH_LOG_CONSOLE_LEVEL`. For e.g.:
+//
+//	log {
+//	  level = "debug"
+//	  receivers {
+//	    console {
+//	      level = "warn"
+//	    }
+//	    file {
+//	      format = "json"
+//	    }
+//	  }
+//	}
+type MultiReceiver struct {
+	mu        sync.RWMutex
+	receivers []*multiReceiverItem
+}
+
+type multiReceiverItem struct {
+	name       string
+	level      level
+	hasPattern bool
+	receiver   Receiver
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MultiReceiver methods
+//___________________________________
+
+// Init method initializes the configured receivers.
+func (m *MultiReceiver) Init(cfg *config.Config) error {
+	names := cfg.KeysByPath("log.receivers")
+	if len(names) == 0 {
+		return errors.New("log: receivers is empty")
+	}
+	sort.Strings(names)
+
+	defaultLevel := cfg.StringDefault("log.level", "DEBUG")
+	envLevel := levelFromEnv("")
+	for _, name := range names {
+		receiver := getReceiverByName(strings.ToUpper(name))
+		if receiver == nil {
+			return fmt.Errorf("log: unknown receiver '%s'", name)
+		}
+
+		keyPrefix := "log.receivers." + name + "."
+		levelName := cfg.StringDefault(keyPrefix+"level", defaultLevel)
+		if len(envLevel) > 0 {
+			levelName = envLevel
+		}
+		if rl := levelFromEnv(name); len(rl) > 0 {
+			levelName = rl
+		}
+		item := &multiReceiverItem{name: name, level: levelByName(levelName), receiver: receiver}
+		if item.level == LevelUnknown {
+			return fmt.Errorf("log: unknown %s level '%s'", name, levelName)
+		}
+
+		rcfg, err := receiverConfig(cfg, name)
+		if err != nil {
+			return err
+		}
+		if err = receiver.Init(rcfg); err != nil {
+			return err
+		}
+
+		if pattern, found := cfg.String(keyPrefix + "pattern"); found {
+			if err = receiver.SetPattern(pattern); err != nil {
+				return err
+			}
+			item.hasPattern = true
+		}
+
+		m.receivers = append(m.receivers, item)
+	}
+
+	return nil
+}
+
+// SetPattern method sets the pattern into receivers, which are not configured
+// with its own pattern.
+func (m *MultiReceiver) SetPattern(pattern string) error {
+	for _, item := range m.receivers {
+		if item.hasPattern {
+			continue
+		}
+		if err := item.receiver.SetPattern(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into all the receivers.
+func (m *MultiReceiver) SetWriter(w io.Writer) {
+	for _, item := range m.receivers {
+		item.receiver.SetWriter(w)
+	}
+}
+
+// IsCallerInfo method returns true if any of the receiver is configured with
+// caller info otherwise false.
+func (m *MultiReceiver) IsCallerInfo() bool {
+	for _, item := range m.receivers {
+		if item.receiver.IsCallerInfo() {
+			return true
+		}
+	}
+	return false
+}
+
+// Log method writes the log entry into receivers as per its level.
+func (m *MultiReceiver) Log(entry *Entry) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, item := range m.receivers {
+		if entry.Level <= item.level || entry.isForced {
+			item.receiver.Log(entry)
+		}
+	}
+}
+
+// Writer method returns the writer which writes into all the receivers
+// writer.
+func (m *MultiReceiver) Writer() io.Writer {
+	writers := make([]io.Writer, 0, len(m.receivers))
+	for _, item := range m.receivers {
+		writers = append(writers, item.receiver.Writer())
+	}
+	return io.MultiWriter(writers...)
+}
+
+// SetLevel method sets the level of the receiver by name.
+func (m *MultiReceiver) SetLevel(name, levelName string) error {
+	lvl := levelByName(levelName)
+	if lvl == LevelUnknown {
+		return fmt.Errorf("log: unknown %s level '%s'", name, levelName)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, item := range m.receivers {
+		if item.name == name {
+			item.level = lvl
+			return nil
+		}
+	}
+	return fmt.Errorf("log: unknown receiver '%s'", name)
+}
+
+// Level method returns the level of the receiver by name otherwise empty.
+func (m *MultiReceiver) Level(name string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, item := range m.receivers {
+		if item.name == name {
+			return item.level.String()
+		}
+	}
+	return ""
+}
+
+// stepLevels method steps the level of each receiver to next verbose or next
+// severe level.
+func (m *MultiReceiver) stepLevels(verbose bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, item := range m.receivers {
+		item.level = nextLevel(item.level, verbose)
+	}
+}
+
+// maxLevel method returns the most verbose level of the receivers.
+func (m *MultiReceiver) maxLevel() level {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	maxLevel := LevelFatal
+	for _, item := range m.receivers {
+		if item.level > maxLevel {
+			maxLevel = item.level
+		}
+	}
+	return maxLevel
+}
+
+// Receiver method returns the receiver by name otherwise nil.
+func (m *MultiReceiver) Receiver(name string) Receiver {
+	for _, item := range m.receivers {
+		if item.name == name {
+			return item.receiver
+		}
+	}
+	return nil
+}
+
+// Flush method flushes the receivers which implements `Flusher`.
+func (m *MultiReceiver) Flush() {
+	for _, item := range m.receivers {
+		if f, ok := item.receiver.(Flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+// Reopen method reopens the receivers which implements `Reopener`, first
+// error is returned.
+func (m *MultiReceiver) Reopen() error {
+	var err error
+	for _, item := range m.receivers {
+		if r, ok := item.receiver.(Reopener); ok {
+			if rerr := r.Reopen(); rerr != nil && err == nil {
+				err = rerr
+			}
+		}
+	}
+	return err
+}
+
+// OnLowDiskSpace method sets the func into receivers which monitors the
+// disk space.
+func (m *MultiReceiver) OnLowDiskSpace(fn DiskSpaceFunc) {
+	for _, item := range m.receivers {
+		if n, ok := item.receiver.(diskSpaceNotifier); ok {
+			n.OnLowDiskSpace(fn)
+		}
+	}
+}
+
+// OnRotate method sets the func into receivers which rotates the log file.
+func (m *MultiReceiver) OnRotate(fn RotateFunc) {
+	for _, item := range m.receivers {
+		if n, ok := item.receiver.(rotateNotifier); ok {
+			n.OnRotate(fn)
+		}
+	}
+}
+
+// OnError method sets the func into receivers which reports the write
+// errors.
+func (m *MultiReceiver) OnError(fn ErrorHandlerFunc) {
+	for _, item := range m.receivers {
+		if n, ok := item.receiver.(errorNotifier); ok {
+			n.OnError(fn)
+		}
+	}
+}
+
+// Close method closes the receivers which implements `Closer`.
+func (m *MultiReceiver) Close() {
+	for _, item := range m.receivers {
+		if c, ok := item.receiver.(Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// receiverConfig method returns the config for the receiver, format is
+// overridden if receiver has its own format.
+func receiverConfig(cfg *config.Config, name string) (*config.Config, error) {
+	format, found := cfg.String("log.receivers." + name + ".format")
+	if !found {
+		return cfg, nil
+	}
+
+	rcfg := config.NewEmpty()
+	if err := rcfg.Merge(cfg); err != nil {
+		return nil, err
+	}
+	rcfg.SetString("log.format", format)
+	return rcfg, nil
+}
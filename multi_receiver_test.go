@@ -0,0 +1,178 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestMultiLogger(t *testing.T) {
+	configStr := `
+  log {
+    level = "debug"
+    pattern = "%level:-5 %message"
+    color = false
+    receivers {
+      console {
+        level = "warn"
+        pattern = "%level:-5 %shortfile %message"
+      }
+      discard {
+        format = "json"
+      }
+    }
+  }
+  `
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*MultiReceiver)
+	assert.Equal(t, 2, len(receiver.receivers))
+	assert.Nil(t, receiver.Receiver("file"))
+
+	// per receiver format is applied on its own config
+	assert.Equal(t, "text", receiver.Receiver("console").(*ConsoleReceiver).formatter)
+	assert.Equal(t, "json", receiver.Receiver("discard").(*DiscardReceiver).formatter)
+	assert.Equal(t, "text", cfg.StringDefault("log.format", "text"))
+
+	// console has its own pattern with caller info
+	assert.True(t, receiver.IsCallerInfo())
+
+	consoleBuf := &bytes.Buffer{}
+	receiver.Receiver("console").SetWriter(consoleBuf)
+
+	logger.Debug("I would like to see this message, debug is useful for dev")
+	logger.Info("Yes, I would love to see")
+	logger.Error("Yes, yes, yes - finally an error")
+	receiver.Close()
+
+	assert.True(t, strings.HasPrefix(consoleBuf.String(), "ERROR "))
+	assert.True(t, strings.HasSuffix(consoleBuf.String(), ".go Yes, yes, yes - finally an error \n"))
+
+	_, _ = logger.ToGoLogger().Writer().Write([]byte("go logger\n"))
+	assert.True(t, strings.HasSuffix(consoleBuf.String(), "error \ngo logger\n"))
+}
+
+func TestMultiLoggerLevelEnv(t *testing.T) {
+	_ = os.Setenv("AAH_LOG_LEVEL", "info")
+	_ = os.Setenv("AAH_LOG_CONSOLE_LEVEL", "error")
+	defer func() {
+		_ = os.Unsetenv("AAH_LOG_LEVEL")
+		_ = os.Unsetenv("AAH_LOG_CONSOLE_LEVEL")
+	}()
+
+	cfg, _ := config.ParseString(`log {
+    level = "warn"
+    receivers {
+      console { level = "debug" }
+      discard { }
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, "INFO", logger.Level())
+	receiver := logger.receiver.(*MultiReceiver)
+	assert.Equal(t, LevelError, receiver.receivers[0].level)
+	assert.Equal(t, LevelInfo, receiver.receivers[1].level)
+
+	// logger env level overrides the receiver configured level
+	_ = os.Unsetenv("AAH_LOG_CONSOLE_LEVEL")
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver = logger.receiver.(*MultiReceiver)
+	assert.Equal(t, LevelInfo, receiver.receivers[0].level)
+	assert.Equal(t, LevelInfo, receiver.receivers[1].level)
+
+	// unknown env level is ignored
+	_ = os.Setenv("AAH_LOG_LEVEL", "verbose")
+	_ = os.Setenv("AAH_LOG_CONSOLE_LEVEL", "verbose")
+	logger, err = New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver = logger.receiver.(*MultiReceiver)
+	assert.Equal(t, LevelDebug, receiver.receivers[0].level)
+	assert.Equal(t, LevelWarn, receiver.receivers[1].level)
+}
+
+func TestMultiLoggerReceiverLevel(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "warn"
+    pattern = "%level:-5 %message"
+    receivers {
+      console { level = "debug" }
+      discard { }
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*MultiReceiver)
+	assert.Equal(t, "DEBUG", receiver.Level("console"))
+	assert.Equal(t, "WARN", receiver.Level("discard"))
+	assert.Equal(t, "", receiver.Level("file"))
+
+	// entry is dispatched as per receiver level, irrespective of logger level
+	buf := &bytes.Buffer{}
+	receiver.Receiver("console").SetWriter(buf)
+	logger.Debug("debug entry")
+	logger.Trace("trace entry")
+	assert.Equal(t, "DEBUG debug entry \n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, logger.SetReceiverLevel("console", "error"))
+	logger.Warn("warn entry")
+	logger.Error("error entry")
+	assert.Equal(t, "ERROR error entry \n", buf.String())
+	assert.Equal(t, LevelWarn, level(logger.maxLevel))
+
+	assert.Equal(t, "log: unknown console level 'verbose'", logger.SetReceiverLevel("console", "verbose").Error())
+	assert.Equal(t, "log: unknown receiver 'file'", logger.SetReceiverLevel("file", "info").Error())
+
+	cfg, _ = config.ParseString(`log { }`)
+	logger, _ = New(cfg)
+	assert.Equal(t, "log: unknown receiver 'console'", logger.SetReceiverLevel("console", "info").Error())
+}
+
+func TestMultiLoggerFormat(t *testing.T) {
+	configStr := `
+  log {
+    color = false
+    receivers {
+      console {
+        format = "json"
+      }
+    }
+  }
+  `
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.Info("Yes, I would love to see")
+	var e Entry
+	assert.FailNowOnError(t, json.Unmarshal(buf.Bytes(), &e), "unexpected error")
+	assert.Equal(t, "Yes, I would love to see", e.Message)
+}
+
+func TestMultiLoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receivers { console { level = "verbose" } } }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: unknown console level 'verbose'", err.Error())
+
+	cfg, _ = config.ParseString(`log { receivers { gelf { } } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unknown receiver 'gelf'", err.Error())
+
+	cfg, _ = config.ParseString(`log { receivers { console { pattern = "%level %unknown" } } }`)
+	_, err = New(cfg)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,399 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var (
+	// ErrNatsAckTimeout returned when JetStream publish acknowledgement is
+	// not received within ack timeout.
+	ErrNatsAckTimeout = errors.New("log: nats jetstream ack timeout")
+
+	_ Receiver = (*NatsReceiver)(nil)
+)
+
+// natsMaxMsgSize is the upper bound of MSG payload size accepted from
+// server.
+const natsMaxMsgSize = 64 << 20
+
+// NatsReceiver publishes the log entry into NATS subject. Subject is
+// templated from entry, for e.g.: `aah.logs.{appname}.{level}`. Optionally
+// publish acknowledgement is awaited for JetStream persistence.
+type NatsReceiver struct {
+	errorReporter
+	servers      []string
+	subject      string
+	name         string
+	user         string
+	password     string
+	token        string
+	jetstream    bool
+	ackTimeout   time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	backoff      time.Duration
+	nextDial     time.Time
+	serverIdx    int
+	conn         net.Conn
+	inbox        string
+	ackSeq       int64
+	acks         chan natsAck
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.Mutex
+	wmu          sync.Mutex
+}
+
+type natsAck struct {
+	subject string
+	payload []byte
+	err     error
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// NatsReceiver methods
+//___________________________________
+
+// Init method initializes the NATS receiver instance.
+func (n *NatsReceiver) Init(cfg *config.Config) error {
+	n.formatter = cfg.StringDefault("log.format", jsonFmt)
+	if !isFormatSupported(n.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", n.formatter)
+	}
+
+	for _, u := range strings.Split(cfg.StringDefault("log.nats.url", "nats://127.0.0.1:4222"), ",") {
+		addr, err := natsAddr(strings.TrimSpace(u))
+		if err != nil {
+			return err
+		}
+		n.servers = append(n.servers, addr)
+	}
+
+	n.subject = cfg.StringDefault("log.nats.subject", "aah.logs")
+	if ess.IsStrEmpty(n.subject) {
+		return errors.New("log: nats subject is required")
+	}
+
+	n.name = cfg.StringDefault("log.nats.name", "aah-log")
+	n.user = cfg.StringDefault("log.nats.user", "")
+	n.password = cfg.StringDefault("log.nats.password", "")
+	n.token = cfg.StringDefault("log.nats.token", "")
+	n.jetstream = cfg.BoolDefault("log.nats.jetstream", false)
+
+	var err error
+	if n.ackTimeout, err = parseDuration(cfg, "log.nats.ack_timeout", "5s"); err != nil {
+		return err
+	}
+	if n.minBackoff, err = parseDuration(cfg, "log.nats.reconnect.min_backoff", "500ms"); err != nil {
+		return err
+	}
+	if n.maxBackoff, err = parseDuration(cfg, "log.nats.reconnect.max_backoff", "30s"); err != nil {
+		return err
+	}
+
+	n.mu = sync.Mutex{}
+	n.inbox = "_INBOX." + strconv.Itoa(os.Getpid()) + "." + strconv.FormatInt(time.Now().UnixNano(), 36)
+	n.SetWriter(writerFunc(func(p []byte) (int, error) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return len(p), n.publish(n.subject, bytes.TrimRight(p, "\n"))
+	}))
+
+	return n.connect()
+}
+
+// SetPattern method initializes the logger format pattern.
+func (n *NatsReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	n.flags = flags
+	if n.formatter != jsonFmt {
+		n.isCallerInfo = isCallerInfo(n.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into NATS receiver.
+func (n *NatsReceiver) SetWriter(w io.Writer) {
+	n.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (n *NatsReceiver) IsCallerInfo() bool {
+	return n.isCallerInfo
+}
+
+// Log method publishes the log entry into NATS subject.
+func (n *NatsReceiver) Log(entry *Entry) {
+	msg := bytes.TrimRight(formatEntry(n.formatter, n.flags, entry), " \n")
+	n.mu.Lock()
+	err := n.publish(expandEntryTemplate(n.subject, entry), msg)
+	n.mu.Unlock()
+	if err != nil {
+		n.reportError(err, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (n *NatsReceiver) Writer() io.Writer {
+	return n.out
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// NatsReceiver Unexported methods
+//___________________________________
+
+func (n *NatsReceiver) publish(subject string, payload []byte) error {
+	if n.conn == nil {
+		if time.Now().Before(n.nextDial) {
+			return ErrNetworkNotConnected
+		}
+		if err := n.connect(); err != nil {
+			return err
+		}
+	}
+
+	var reply string
+	if n.jetstream {
+		n.ackSeq++
+		reply = n.inbox + "." + strconv.FormatInt(n.ackSeq, 10) + space
+	}
+
+	buf := acquireBuffer()
+	defer releaseBuffer(buf)
+	_, _ = fmt.Fprintf(buf, "PUB %s %s%d\r\n", subject, reply, len(payload))
+	_, _ = buf.Write(payload)
+	_, _ = buf.WriteString("\r\n")
+	if err := n.write(buf.Bytes()); err != nil {
+		n.disconnect()
+		return err
+	}
+
+	if n.jetstream {
+		return n.waitAck(strings.TrimSpace(reply))
+	}
+	return nil
+}
+
+// waitAck method waits for JetStream publish acknowledgement of the reply
+// subject, stale acks are skipped.
+func (n *NatsReceiver) waitAck(reply string) error {
+	timer := time.NewTimer(n.ackTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case ack := <-n.acks:
+			if ack.err != nil {
+				return ack.err
+			}
+			if ack.subject != reply {
+				continue
+			}
+			var resp struct {
+				Error *struct {
+					Code        int    `json:"code"`
+					Description string `json:"description"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(ack.payload, &resp); err != nil {
+				return err
+			}
+			if resp.Error != nil {
+				return fmt.Errorf("log: nats jetstream error %d: %s", resp.Error.Code, resp.Error.Description)
+			}
+			return nil
+		case <-timer.C:
+			return ErrNatsAckTimeout
+		}
+	}
+}
+
+func (n *NatsReceiver) connect() error {
+	var lastErr error
+	for i := 0; i < len(n.servers); i++ {
+		addr := n.servers[n.serverIdx%len(n.servers)]
+		n.serverIdx++
+
+		conn, err := net.DialTimeout("tcp", addr, n.ackTimeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if err = n.handshake(conn); err != nil {
+			ess.CloseQuietly(conn)
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+
+	n.scheduleDial()
+	return lastErr
+}
+
+func (n *NatsReceiver) handshake(conn net.Conn) error {
+	_ = conn.SetReadDeadline(time.Now().Add(n.ackTimeout))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("log: nats unexpected server greeting '%s'", strings.TrimSpace(line))
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	opts := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     n.name,
+		"lang":     "go",
+		"version":  Version,
+	}
+	if len(n.user) > 0 {
+		opts["user"], opts["pass"] = n.user, n.password
+	}
+	if len(n.token) > 0 {
+		opts["auth_token"] = n.token
+	}
+	connect, _ := json.Marshal(opts)
+
+	cmd := "CONNECT " + string(connect) + "\r\n"
+	if n.jetstream {
+		cmd += "SUB " + n.inbox + ".* 1\r\n"
+	}
+	cmd += "PING\r\n"
+	if _, err = conn.Write([]byte(cmd)); err != nil {
+		return err
+	}
+
+	// server replies PONG on successful connect, otherwise -ERR
+	_ = conn.SetReadDeadline(time.Now().Add(n.ackTimeout))
+	for {
+		if line, err = r.ReadString('\n'); err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			return fmt.Errorf("log: nats %s", line)
+		}
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	n.conn = conn
+	n.backoff = 0
+	n.acks = make(chan natsAck, 16)
+	go n.readLoop(conn, r, n.acks)
+	return nil
+}
+
+// readLoop method processes the server messages, responds to PING and
+// delivers JetStream acknowledgements.
+func (n *NatsReceiver) readLoop(conn net.Conn, r *bufio.Reader, acks chan<- natsAck) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			n.notify(acks, natsAck{err: err})
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			n.wmu.Lock()
+			_, _ = conn.Write([]byte("PONG\r\n"))
+			n.wmu.Unlock()
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			parts := strings.Fields(line)
+			size, err := strconv.Atoi(parts[len(parts)-1])
+			if err != nil || len(parts) < 4 || size < 0 || size > natsMaxMsgSize {
+				n.notify(acks, natsAck{err: fmt.Errorf("log: nats invalid message %q", line)})
+				ess.CloseQuietly(conn)
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err = io.ReadFull(r, payload); err != nil {
+				n.notify(acks, natsAck{err: err})
+				return
+			}
+			n.notify(acks, natsAck{subject: parts[1], payload: payload[:size]})
+		case strings.HasPrefix(line, "-ERR"):
+			n.notify(acks, natsAck{err: fmt.Errorf("log: nats %s", line)})
+		}
+	}
+}
+
+// notify method delivers the ack without blocking the read loop, it's
+// dropped if nobody is waiting.
+func (n *NatsReceiver) notify(acks chan<- natsAck, ack natsAck) {
+	select {
+	case acks <- ack:
+	default:
+	}
+}
+
+func (n *NatsReceiver) write(p []byte) error {
+	n.wmu.Lock()
+	defer n.wmu.Unlock()
+	_ = n.conn.SetWriteDeadline(time.Now().Add(n.ackTimeout))
+	_, err := n.conn.Write(p)
+	return err
+}
+
+func (n *NatsReceiver) disconnect() {
+	ess.CloseQuietly(n.conn)
+	n.conn = nil
+	n.scheduleDial()
+}
+
+func (n *NatsReceiver) scheduleDial() {
+	if n.backoff == 0 {
+		n.backoff = n.minBackoff
+	} else if n.backoff *= 2; n.backoff > n.maxBackoff {
+		n.backoff = n.maxBackoff
+	}
+	n.nextDial = time.Now().Add(n.backoff)
+}
+
+func natsAddr(v string) (string, error) {
+	if !strings.Contains(v, "://") {
+		v = "nats://" + v
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return "", err
+	}
+	if len(u.Port()) == 0 {
+		return net.JoinHostPort(u.Hostname(), "4222"), nil
+	}
+	return u.Host, nil
+}
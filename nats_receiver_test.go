@@ -0,0 +1,177 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestNatsLogger(t *testing.T) {
+	server := newTestNatsServer(t, false)
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "nats"
+    format = "text"
+    pattern = "%%level:-5 %%message"
+    nats {
+      url = "nats://%s"
+      subject = "aah.logs.{appname}.{level}"
+      token = "s3cr3t"
+    }
+  }
+  `, server.addr)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	connect := readLine(t, server.lines)
+	assert.True(t, strings.HasPrefix(connect, "CONNECT {"))
+	assert.True(t, strings.Contains(connect, `"auth_token":"s3cr3t"`))
+
+	logger.WithField("appname", "my.app").Info("Yes, I would love to see")
+	assert.Equal(t, "PUB aah.logs.my_app.info 30\r\n", readLine(t, server.lines))
+	assert.Equal(t, "INFO  Yes, I would love to see\r\n", readLine(t, server.lines))
+
+	logger.Error("Yes, yes, yes - finally an error")
+	assert.Equal(t, "PUB aah.logs.-.error 38\r\n", readLine(t, server.lines))
+	assert.Equal(t, "ERROR Yes, yes, yes - finally an error\r\n", readLine(t, server.lines))
+
+	logger.WithField("appname", "app\r\nPUB evil 1\r\nx").Info("injected")
+	assert.Equal(t, "PUB aah.logs.app__PUB_evil_1__x.info 14\r\n", readLine(t, server.lines))
+	assert.Equal(t, "INFO  injected\r\n", readLine(t, server.lines))
+}
+
+func TestNatsLoggerJetStream(t *testing.T) {
+	server := newTestNatsServer(t, true)
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "nats"
+    nats {
+      url = "%s"
+      subject = "aah.logs"
+      jetstream = true
+      ack_timeout = "2s"
+    }
+  }
+  `, server.addr)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	_ = readLine(t, server.lines)
+	assert.True(t, strings.HasPrefix(readLine(t, server.lines), "SUB _INBOX."))
+
+	receiver := logger.receiver.(*NatsReceiver)
+	assert.Nil(t, receiver.publish("aah.logs", []byte("message 1")))
+	assert.True(t, strings.HasPrefix(readLine(t, server.lines), "PUB aah.logs _INBOX."))
+	_ = readLine(t, server.lines)
+
+	server.ackErr = true
+	err = receiver.publish("aah.logs", []byte("message 2"))
+	assert.Equal(t, "log: nats jetstream error 503: no stream", err.Error())
+}
+
+func TestNatsLoggerInvalidMessage(t *testing.T) {
+	for _, line := range []string{"MSG _INBOX.1 1 -5\r\n", "MSG _INBOX.1 1 999999999999\r\n", "MSG 5\r\n"} {
+		client, server := net.Pipe()
+		acks := make(chan natsAck, 1)
+		n := &NatsReceiver{}
+		n.readLoop(client, bufio.NewReader(strings.NewReader(line)), acks)
+		ack := <-acks
+		assert.Equal(t, fmt.Sprintf("log: nats invalid message %q", strings.TrimSpace(line)), ack.err.Error())
+		_ = server.Close()
+	}
+}
+
+func TestNatsLoggerConfigErrors(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "nats", nats { subject = "" } }`)
+	logger, err := New(cfg)
+	assert.Nil(t, logger)
+	assert.Equal(t, "log: nats subject is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "nats", format = "xml" }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unsupported format 'xml'", err.Error())
+
+	addr, _ := natsAddr("localhost")
+	assert.Equal(t, "localhost:4222", addr)
+	addr, _ = natsAddr("nats://10.0.0.1:4223")
+	assert.Equal(t, "10.0.0.1:4223", addr)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Test NATS server
+//___________________________________
+
+type testNatsServer struct {
+	ln        net.Listener
+	addr      string
+	jetstream bool
+	ackErr    bool
+	lines     chan string
+}
+
+func newTestNatsServer(t *testing.T, jetstream bool) *testNatsServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+	s := &testNatsServer{ln: ln, addr: ln.Addr().String(), jetstream: jetstream, lines: make(chan string, 100)}
+	go s.serve()
+	return s
+}
+
+func (s *testNatsServer) Close() {
+	_ = s.ln.Close()
+}
+
+func (s *testNatsServer) serve() {
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	_, _ = conn.Write([]byte(`INFO {"server_id":"test","max_payload":1048576}` + "\r\n"))
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(line, "PING"):
+			_, _ = conn.Write([]byte("PONG\r\nPING\r\n"))
+		case strings.HasPrefix(line, "PONG"):
+		case strings.HasPrefix(line, "PUB "):
+			s.lines <- line
+			parts := strings.Fields(line)
+			size, _ := strconv.Atoi(parts[len(parts)-1])
+			payload := make([]byte, size+2)
+			_, _ = io.ReadFull(r, payload)
+			s.lines <- string(payload)
+			if len(parts) == 4 {
+				ack := `{"stream":"LOGS","seq":1}`
+				if s.ackErr {
+					ack = `{"error":{"code":503,"description":"no stream"}}`
+				}
+				_, _ = fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", parts[2], len(ack), ack)
+			}
+		default:
+			s.lines <- line
+		}
+	}
+}
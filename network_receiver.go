@@ -0,0 +1,297 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var (
+	// ErrNetworkNotConnected returned when network receiver connection is down.
+	ErrNetworkNotConnected = errors.New("log: network receiver is not connected")
+
+	// networkProtocols is the list of supported protocols by config section,
+	// first one is default.
+	networkProtocols = map[string][]string{
+		"network": {"tcp", "udp"},
+		"unix":    {"unix", "unixgram"},
+	}
+
+	_ Receiver = (*NetworkReceiver)(nil)
+)
+
+// NetworkReceiver writes the log entry into remote host over TCP or UDP.
+// On connection failure it reconnects in the background with exponential
+// backoff and keeps the entries in bounded in-memory buffer till connection
+// is restored, logging is not blocked on reconnect.
+//
+// For `gelf` format, messages are null byte delimited on stream protocols
+// as per GELF TCP transport.
+type NetworkReceiver struct {
+	errorReporter
+	section      string
+	protocol     string
+	address      string
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	backoff      time.Duration
+	nextDial     time.Time
+	conn         net.Conn
+	dialing      bool
+	closed       bool
+	done         chan struct{}
+	buffer       [][]byte
+	bufferSize   int
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.Mutex
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// NetworkReceiver methods
+//___________________________________
+
+// Init method initializes the network receiver instance.
+func (n *NetworkReceiver) Init(cfg *config.Config) error {
+	n.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(n.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", n.formatter)
+	}
+
+	if ess.IsStrEmpty(n.section) {
+		n.section = "network"
+	}
+	keyPrefix := "log." + n.section
+
+	protocols := networkProtocols[n.section]
+	n.protocol = strings.ToLower(cfg.StringDefault(keyPrefix+".protocol", protocols[0]))
+	if !ess.IsSliceContainsString(protocols, n.protocol) {
+		return fmt.Errorf("log: unsupported %s protocol '%s'", n.section, n.protocol)
+	}
+
+	n.address = cfg.StringDefault(keyPrefix+".address", "")
+	if ess.IsStrEmpty(n.address) {
+		return fmt.Errorf("log: %s address is required", n.section)
+	}
+
+	var err error
+	if n.writeTimeout, err = parseDuration(cfg, keyPrefix+".write_timeout", "5s"); err != nil {
+		return err
+	}
+	if n.minBackoff, err = parseDuration(cfg, keyPrefix+".reconnect.min_backoff", "500ms"); err != nil {
+		return err
+	}
+	if n.maxBackoff, err = parseDuration(cfg, keyPrefix+".reconnect.max_backoff", "30s"); err != nil {
+		return err
+	}
+	n.bufferSize = cfg.IntDefault(keyPrefix+".buffer_size", 1000)
+
+	n.mu = sync.Mutex{}
+	n.done = make(chan struct{})
+	n.SetWriter(writerFunc(n.write))
+
+	// initial connection failure is not fatal, entries are buffered till
+	// remote host becomes reachable
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn, err = net.DialTimeout(n.protocol, n.address, n.writeTimeout); err != nil {
+		n.scheduleDial()
+		n.reconnect()
+	}
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (n *NetworkReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	n.flags = flags
+	if n.formatter != jsonFmt {
+		n.isCallerInfo = isCallerInfo(n.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into network receiver.
+func (n *NetworkReceiver) SetWriter(w io.Writer) {
+	n.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (n *NetworkReceiver) IsCallerInfo() bool {
+	return n.isCallerInfo
+}
+
+// Log method writes the log entry into remote host.
+func (n *NetworkReceiver) Log(entry *Entry) {
+	msg := formatEntry(n.formatter, n.flags, entry)
+	if n.formatter == gelfFmt && n.protocol != "udp" && n.protocol != "unixgram" {
+		// GELF stream transport delimits the messages with null byte
+		msg[len(msg)-1] = 0
+	}
+
+	n.mu.Lock()
+	_, err := n.out.Write(msg)
+	n.mu.Unlock()
+	if err != nil {
+		n.reportError(err, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (n *NetworkReceiver) Writer() io.Writer {
+	return n.out
+}
+
+// Close method stops the reconnect and closes the connection.
+func (n *NetworkReceiver) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	n.closed = true
+	close(n.done)
+	if n.conn != nil {
+		ess.CloseQuietly(n.conn)
+		n.conn = nil
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// NetworkReceiver Unexported methods
+//___________________________________
+
+func (n *NetworkReceiver) write(p []byte) (int, error) {
+	if n.conn == nil {
+		n.enqueue(p)
+		n.reconnect()
+		return 0, ErrNetworkNotConnected
+	}
+
+	if err := n.flush(); err != nil {
+		n.enqueue(p)
+		return 0, err
+	}
+
+	if err := n.send(p); err != nil {
+		n.enqueue(p)
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// reconnect method starts dialing the remote host in the background unless
+// it's already in progress, it's called with lock held.
+func (n *NetworkReceiver) reconnect() {
+	if n.dialing || n.closed {
+		return
+	}
+	n.dialing = true
+	go n.dial()
+}
+
+// dial method dials the remote host as per backoff till it's connected and
+// buffered entries are written.
+func (n *NetworkReceiver) dial() {
+	for {
+		n.mu.Lock()
+		wait := time.Until(n.nextDial)
+		n.mu.Unlock()
+		select {
+		case <-time.After(wait):
+		case <-n.done:
+			return
+		}
+
+		conn, err := net.DialTimeout(n.protocol, n.address, n.writeTimeout)
+		n.mu.Lock()
+		if n.closed {
+			n.mu.Unlock()
+			if err == nil {
+				ess.CloseQuietly(conn)
+			}
+			return
+		}
+		if err != nil {
+			n.scheduleDial()
+			n.mu.Unlock()
+			continue
+		}
+		n.conn = conn
+		n.backoff = 0
+		if n.flush() == nil {
+			n.dialing = false
+			n.mu.Unlock()
+			return
+		}
+		n.mu.Unlock()
+	}
+}
+
+// scheduleDial method computes next reconnect attempt time with exponential
+// backoff bounded by configured maximum.
+func (n *NetworkReceiver) scheduleDial() {
+	if n.backoff == 0 {
+		n.backoff = n.minBackoff
+	} else if n.backoff *= 2; n.backoff > n.maxBackoff {
+		n.backoff = n.maxBackoff
+	}
+	n.nextDial = time.Now().Add(n.backoff)
+}
+
+func (n *NetworkReceiver) send(p []byte) error {
+	_ = n.conn.SetWriteDeadline(time.Now().Add(n.writeTimeout))
+	if _, err := n.conn.Write(p); err != nil {
+		ess.CloseQuietly(n.conn)
+		n.conn = nil
+		n.scheduleDial()
+		n.reconnect()
+		return err
+	}
+	return nil
+}
+
+// flush method writes the buffered entries into connection in the order
+// they were received.
+func (n *NetworkReceiver) flush() error {
+	for len(n.buffer) > 0 {
+		if err := n.send(n.buffer[0]); err != nil {
+			return err
+		}
+		n.buffer[0] = nil
+		n.buffer = n.buffer[1:]
+	}
+	return nil
+}
+
+// enqueue method adds the entry into buffer, oldest entry is dropped when
+// buffer is full.
+func (n *NetworkReceiver) enqueue(p []byte) {
+	if n.bufferSize <= 0 {
+		return
+	}
+	if len(n.buffer) >= n.bufferSize {
+		n.buffer[0] = nil
+		n.buffer = n.buffer[1:]
+	}
+	n.buffer = append(n.buffer, append([]byte(nil), p...))
+}
@@ -0,0 +1,216 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestNetworkLoggerTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+	defer func() { _ = ln.Close() }()
+	lines := acceptLines(ln, 2)
+
+	logger := newNetworkLogger(t, "tcp", ln.Addr().String())
+	logger.Info("Yes, I would love to see")
+	logger.WithField("key1", "value 1").Warn("Yes, yes it's an warning")
+
+	assert.Equal(t, "INFO  Yes, I would love to see \n", readLine(t, lines))
+	assert.Equal(t, "WARN  Yes, yes it's an warning fields[key1: value 1] \n", readLine(t, lines))
+}
+
+func TestNetworkLoggerTCPGELF(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+	defer func() { _ = ln.Close() }()
+
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		for {
+			msg, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			msgs <- msg
+		}
+	}()
+
+	cfg, _ := config.ParseString(fmt.Sprintf(`log {
+    receiver = "network"
+    format = "gelf"
+    network { address = "%s" }
+  }`, ln.Addr().String()))
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.WithField("key1", "value1").Info("first")
+	logger.Warn("second")
+
+	for _, m := range []string{`"short_message":"first"`, `"short_message":"second"`} {
+		select {
+		case msg := <-msgs:
+			assert.True(t, strings.HasPrefix(msg, `{"version":"1.1"`))
+			assert.True(t, strings.Contains(msg, m))
+			assert.True(t, strings.HasSuffix(msg, "}\x00"))
+		case <-time.After(2 * time.Second):
+			t.Fatal("gelf message not received")
+		}
+	}
+}
+
+func TestNetworkLoggerUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen udp")
+	defer func() { _ = pc.Close() }()
+
+	logger := newNetworkLogger(t, "udp", pc.LocalAddr().String())
+	logger.Error("Yes, yes, yes - finally an error")
+
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.FailNowOnError(t, err, "unable to read udp packet")
+	assert.Equal(t, "ERROR Yes, yes, yes - finally an error \n", string(buf[:n]))
+}
+
+func TestNetworkLoggerReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	logger := newNetworkLogger(t, "tcp", addr)
+	logger.Info("buffered message 1")
+	logger.Info("buffered message 2")
+
+	receiver := logger.receiver.(*NetworkReceiver)
+	receiver.mu.Lock()
+	assert.Equal(t, 2, len(receiver.buffer))
+	assert.True(t, receiver.dialing)
+	receiver.mu.Unlock()
+
+	ln, err = net.Listen("tcp", addr)
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+	defer func() { _ = ln.Close() }()
+	lines := acceptLines(ln, 3)
+
+	time.Sleep(5 * time.Millisecond)
+	logger.Info("connected message 3")
+
+	assert.Equal(t, "INFO  buffered message 1 \n", readLine(t, lines))
+	assert.Equal(t, "INFO  buffered message 2 \n", readLine(t, lines))
+	assert.Equal(t, "INFO  connected message 3 \n", readLine(t, lines))
+	receiver.mu.Lock()
+	assert.Equal(t, 0, len(receiver.buffer))
+	receiver.mu.Unlock()
+
+	// closed receiver doesn't reconnect
+	logger.Close()
+	logger.Info("closed message 4")
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	assert.False(t, receiver.dialing)
+	assert.Nil(t, receiver.conn)
+}
+
+func TestNetworkLoggerBufferLimit(t *testing.T) {
+	n := &NetworkReceiver{bufferSize: 2}
+	n.enqueue([]byte("1"))
+	n.enqueue([]byte("2"))
+	n.enqueue([]byte("3"))
+	assert.Equal(t, [][]byte{[]byte("2"), []byte("3")}, n.buffer)
+
+	n.minBackoff, n.maxBackoff = 10*time.Millisecond, 30*time.Millisecond
+	for _, d := range []time.Duration{10, 20, 30, 30} {
+		n.scheduleDial()
+		assert.Equal(t, d*time.Millisecond, n.backoff)
+	}
+}
+
+func TestNetworkLoggerConfigErrors(t *testing.T) {
+	testcases := []struct {
+		cfg string
+		err string
+	}{
+		{cfg: `network { protocol = "sctp" }`, err: "log: unsupported network protocol 'sctp'"},
+		{cfg: `network { protocol = "tcp" }`, err: "log: network address is required"},
+		{cfg: `network { address = "127.0.0.1:5000", write_timeout = "5 sec" }`, err: "log: invalid duration '5 sec' for 'log.network.write_timeout'"},
+		{cfg: `format = "xml"`, err: "log: unsupported format 'xml'"},
+	}
+
+	for _, tc := range testcases {
+		cfg, _ := config.ParseString(`log { receiver = "network"
+		` + tc.cfg + ` }`)
+		logger, err := New(cfg)
+		assert.Nil(t, logger)
+		assert.Equal(t, tc.err, err.Error())
+	}
+}
+
+func newNetworkLogger(t *testing.T, protocol, address string) *Logger {
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "network"
+    level = "debug"
+    pattern = "%%level:-5 %%message %%fields"
+    network {
+      protocol = "%s"
+      address = "%s"
+      reconnect {
+        min_backoff = "1ms"
+        max_backoff = "2ms"
+      }
+    }
+  }
+  `, protocol, address)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	return logger
+}
+
+func acceptLines(ln net.Listener, cnt int) <-chan string {
+	lines := make(chan string, cnt)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		for i := 0; i < cnt; i++ {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- line
+		}
+	}()
+	return lines
+}
+
+func readLine(t *testing.T, lines <-chan string) string {
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for log line")
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Protocol Buffers wire types
+const (
+	protoVarint = 0
+	protoBytes  = 2
+)
+
+// protoEncoder is minimal Protocol Buffers (proto3) encoder which covers the
+// field types used by `LogEntry` message, see `log_entry.proto`.
+type protoEncoder struct {
+	bytes.Buffer
+}
+
+func (e *protoEncoder) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	_, _ = e.Write(b[:n])
+}
+
+func (e *protoEncoder) tag(field int, wireType int) {
+	e.varint(uint64(field<<3 | wireType))
+}
+
+// int64 method writes the int64 field, zero value is omitted as per proto3.
+func (e *protoEncoder) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, protoVarint)
+	e.varint(uint64(v))
+}
+
+// string method writes the string field, empty value is omitted as per proto3.
+func (e *protoEncoder) string(field int, v string) {
+	if len(v) == 0 {
+		return
+	}
+	e.tag(field, protoBytes)
+	e.varint(uint64(len(v)))
+	_, _ = e.WriteString(v)
+}
+
+// bytes method writes the length delimited field, for e.g.: embedded message.
+func (e *protoEncoder) bytes(field int, v []byte) {
+	e.tag(field, protoBytes)
+	e.varint(uint64(len(v)))
+	_, _ = e.Write(v)
+}
+
+// stringMap method writes the `map<string, string>` field, entries are sorted
+// by key.
+func (e *protoEncoder) stringMap(field int, m Fields) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		entry := &protoEncoder{}
+		entry.string(1, k)
+		entry.string(2, fmt.Sprint(m[k]))
+		e.bytes(field, entry.Bytes())
+	}
+}
+
+// encodeProtoEntry method encodes the log entry as `LogEntry` message.
+func encodeProtoEntry(entry *Entry) []byte {
+	e := &protoEncoder{}
+	e.string(1, entry.Level.String())
+	e.int64(2, entry.Time.UnixNano())
+	e.string(3, entry.Message)
+	e.string(4, entry.AppName)
+	e.string(5, entry.InstanceName)
+	e.string(6, entry.RequestID)
+	e.string(7, entry.Principal)
+	e.string(8, entry.File)
+	e.int64(9, int64(entry.Line))
+	e.stringMap(10, entry.Fields)
+	return e.Bytes()
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+)
+
+// maxRateLimitKeys is the count of keys after which idle buckets are removed.
+const maxRateLimitKeys = 4096
+
+// rateLimiter limits the entries using token bucket, it's configured under
+// `log.rate_limit`. Bucket is per value of field `key` if configured
+// otherwise per logger. FATAL and PANIC entries are not limited.
+//
+//	log {
+//	  rate_limit {
+//	    rate = 100 # entries per second
+//	    burst = 200 # default is rate
+//	    key = "reqid"
+//	  }
+//	}
+//
+// Dropped entries are counted and summary entry `suppressed N similar
+// entries` is logged before the next allowed entry of the key and on
+// `Logger.Flush` and `Logger.Close`.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	key     string
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int64
+}
+
+func newRateLimiter(cfg *config.Config) (*rateLimiter, error) {
+	rate := cfg.IntDefault("log.rate_limit.rate", 0)
+	if rate < 1 {
+		return nil, errors.New("log: rate_limit rate must be greater than zero")
+	}
+	burst := cfg.IntDefault("log.rate_limit.burst", rate)
+	if burst < 1 {
+		return nil, errors.New("log: rate_limit burst must be greater than zero")
+	}
+
+	return &rateLimiter{
+		rate:    float64(rate),
+		burst:   float64(burst),
+		key:     cfg.StringDefault("log.rate_limit.key", ""),
+		buckets: make(map[string]*tokenBucket),
+	}, nil
+}
+
+// allow method returns true if entry of the key is allowed and count of
+// entries suppressed since the last allowed entry.
+func (r *rateLimiter) allow(key string, now time.Time) (bool, int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, found := r.buckets[key]
+	if !found {
+		if len(r.buckets) >= maxRateLimitKeys {
+			r.removeIdle(now)
+		}
+		b = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * r.rate
+		if b.tokens > r.burst {
+			b.tokens = r.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		b.suppressed++
+		return false, 0
+	}
+	b.tokens--
+	suppressed := b.suppressed
+	b.suppressed = 0
+	return true, suppressed
+}
+
+// drain method returns the keys with count of suppressed entries and resets
+// the counts, keys are sorted.
+func (r *rateLimiter) drain() ([]string, map[string]int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var keys []string
+	counts := make(map[string]int64)
+	for key, b := range r.buckets {
+		if b.suppressed > 0 {
+			keys = append(keys, key)
+			counts[key] = b.suppressed
+			b.suppressed = 0
+		}
+	}
+	sort.Strings(keys)
+	return keys, counts
+}
+
+// removeIdle method removes the buckets which are refilled completely and
+// have no suppressed entries.
+func (r *rateLimiter) removeIdle(now time.Time) {
+	for key, b := range r.buckets {
+		if b.suppressed == 0 && b.tokens+now.Sub(b.last).Seconds()*r.rate >= r.burst {
+			delete(r.buckets, key)
+		}
+	}
+}
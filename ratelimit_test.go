@@ -0,0 +1,116 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogRateLimit(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %reqid"
+    rate_limit {
+      rate = 1
+      burst = 2
+      key = "reqid"
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	for i := 0; i < 5; i++ {
+		logger.WithField("reqid", "req-1").Infof("entry %d", i)
+	}
+	logger.WithField("reqid", "req-2").Info("other")
+	exit = func(code int) {}
+	logger.Fatalf("not limited")
+	exit = os.Exit
+	assert.Equal(t, "INFO  entry 0 req-1 \nINFO  entry 1 req-1 \nINFO  other req-2 \nFATAL not limited \n", buf.String())
+
+	buf.Reset()
+	logger.Flush()
+	assert.Equal(t, "WARN  suppressed 3 similar entries req-1 \n", buf.String())
+
+	// summary is logged before the next allowed entry
+	buf.Reset()
+	logger.WithField("reqid", "req-2").Info("one")
+	logger.WithField("reqid", "req-2").Info("two")
+	r := logger.limiter
+	r.buckets["req-2"].last = r.buckets["req-2"].last.Add(-time.Second)
+	logger.WithField("reqid", "req-2").Info("three")
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "INFO  one req-2 ", lines[0])
+	assert.Equal(t, "WARN  suppressed 1 similar entries req-2 ", lines[1])
+	assert.Equal(t, "INFO  three req-2 ", lines[2])
+}
+
+func TestLogRateLimitSummaryEntry(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %shortfile %message %fields"
+    key_map = ["suppressed=dropped"]
+    rate_limit {
+      rate = 1
+      burst = 1
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+	hook := &testHook{levels: []string{"warn"}}
+	assert.Nil(t, logger.AddEntryHook(hook))
+
+	for i := 0; i < 3; i++ {
+		logger.Infof("entry %d", i)
+	}
+	buf.Reset()
+	logger.Flush()
+	assert.True(t, strings.Contains(buf.String(), " suppressed 2 similar entries fields["))
+	assert.False(t, strings.HasPrefix(buf.String(), "WARN   suppressed"))
+	assert.True(t, strings.Contains(buf.String(), "dropped: 2"))
+	assert.True(t, strings.Contains(buf.String(), "hooked: true"))
+	assert.Equal(t, []string{"WARN suppressed 2 similar entries "}, hook.entries)
+}
+
+func TestRateLimiter(t *testing.T) {
+	r := &rateLimiter{rate: 10, burst: 1, buckets: make(map[string]*tokenBucket)}
+	now := time.Now()
+	allowed, _ := r.allow("", now)
+	assert.True(t, allowed)
+	allowed, _ = r.allow("", now)
+	assert.False(t, allowed)
+	allowed, _ = r.allow("", now.Add(50*time.Millisecond))
+	assert.False(t, allowed)
+	allowed, suppressed := r.allow("", now.Add(100*time.Millisecond))
+	assert.True(t, allowed)
+	assert.Equal(t, int64(2), suppressed)
+
+	// idle buckets are removed
+	for i := 0; i < maxRateLimitKeys; i++ {
+		r.buckets[strings.Repeat("k", i+1)] = &tokenBucket{tokens: 1, last: now}
+	}
+	_, _ = r.allow("new", now)
+	assert.Equal(t, 2, len(r.buckets))
+}
+
+func TestLogRateLimitConfig(t *testing.T) {
+	for cfgStr, msg := range map[string]string{
+		`rate_limit { key = "reqid" }`:        "log: rate_limit rate must be greater than zero",
+		`rate_limit { rate = 10, burst = 0 }`: "log: rate_limit burst must be greater than zero",
+	} {
+		cfg, _ := config.ParseString(`log { ` + cfgStr + ` }`)
+		_, err := New(cfg)
+		assert.Equal(t, msg, err.Error())
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"aahframework.org/config.v0"
+)
+
+// redactedValue is the replacement of redacted field value.
+const redactedValue = "[REDACTED]"
+
+// redactor replaces the value of matching field keys with `[REDACTED]`
+// before the entry is formatted, it's configured as list `log.redact`. Key
+// is case-insensitive and glob pattern is allowed, nested field is matched
+// by its key and dotted key, for e.g.: `db.password`.
+//
+//	log {
+//	  redact = ["password", "authorization", "card_*"]
+//	}
+type redactor struct {
+	patterns []string
+}
+
+func newRedactor(cfg *config.Config) (*redactor, error) {
+	keys, _ := cfg.StringList("log.redact")
+	r := &redactor{}
+	for _, key := range keys {
+		pattern := strings.ToLower(strings.TrimSpace(key))
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("log: invalid redact key '%s'", key)
+		}
+		r.patterns = append(r.patterns, pattern)
+	}
+	return r, nil
+}
+
+// apply method replaces the values of matching field keys including nested
+// fields and maps, for e.g.: value decoded by `json.Unmarshal`.
+func (r *redactor) apply(fields Fields, prefix string) {
+	for k, v := range fields {
+		key := strings.ToLower(k)
+		if r.match(key) || (len(prefix) > 0 && r.match(prefix+key)) {
+			fields[k] = redactedValue
+			continue
+		}
+		switch nested := v.(type) {
+		case Fields:
+			fields[k] = r.applyCopy(nested, prefix+key+".")
+		case map[string]interface{}:
+			fields[k] = map[string]interface{}(r.applyCopy(Fields(nested), prefix+key+"."))
+		}
+	}
+}
+
+// applyCopy method redacts the copy of nested fields, since nested value is
+// shared with parent entry and caller.
+func (r *redactor) applyCopy(nested Fields, prefix string) Fields {
+	nf := make(Fields, len(nested))
+	nf.addAll(nested)
+	r.apply(nf, prefix)
+	return nf
+}
+
+func (r *redactor) match(key string) bool {
+	for _, pattern := range r.patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogRedact(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    format = "json"
+    redact = ["password", "Authorization", "card_*", "db.dsn"]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.WithFields(Fields{
+		"user":          "jeeva",
+		"Password":      "secret",
+		"authorization": "Bearer token",
+		"card_number":   "4111111111111111",
+	}).Info("login")
+	assert.True(t, strings.Contains(buf.String(), `"user":"jeeva"`))
+	assert.True(t, strings.Contains(buf.String(), `"Password":"[REDACTED]"`))
+	assert.True(t, strings.Contains(buf.String(), `"authorization":"[REDACTED]"`))
+	assert.True(t, strings.Contains(buf.String(), `"card_number":"[REDACTED]"`))
+	assert.False(t, strings.Contains(buf.String(), "secret"))
+
+	// nested fields, parent entry keeps the value
+	buf.Reset()
+	db := logger.WithNamespace("db").WithFields(Fields{"dsn": "postgres://u:p@host", "password": "p", "host": "host"})
+	db.WithField("op", "connect").Info("connected")
+	assert.True(t, strings.Contains(buf.String(), `"db":{"dsn":"[REDACTED]","host":"host","op":"connect","password":"[REDACTED]"}`))
+	assert.Equal(t, "p", db.(*Entry).Fields.str("db.password"))
+
+	// nested plain map, for e.g.: decoded JSON payload, caller keeps the value
+	buf.Reset()
+	var payload map[string]interface{}
+	_ = json.Unmarshal([]byte(`{"user":"jeeva","auth":{"password":"s3cr3t","db":{"dsn":"postgres://u:p@host"}}}`), &payload)
+	logger.WithField("payload", payload).Info("request")
+	assert.True(t, strings.Contains(buf.String(), `"payload":{"auth":{"db":{"dsn":"postgres://u:p@host"},"password":"[REDACTED]"},"user":"jeeva"}`))
+	assert.False(t, strings.Contains(buf.String(), "s3cr3t"))
+	assert.Equal(t, "s3cr3t", payload["auth"].(map[string]interface{})["password"])
+
+	cfg, _ = config.ParseString(`log { redact = ["card_[0-"] }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid redact key 'card_[0-'", err.Error())
+}
@@ -0,0 +1,355 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var _ Receiver = (*RedisReceiver)(nil)
+
+// RedisReceiver appends the log entry into Redis stream using `XADD`
+// command, stream length is capped with `MAXLEN` trimming if configured.
+// On connection failure it reconnects in the background with exponential
+// backoff, entries are dropped till connection is restored.
+type RedisReceiver struct {
+	errorReporter
+	address      string
+	password     string
+	db           int
+	stream       string
+	maxLen       int64
+	approximate  bool
+	timeout      time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	backoff      time.Duration
+	nextDial     time.Time
+	conn         net.Conn
+	reader       *bufio.Reader
+	dialing      bool
+	closed       bool
+	done         chan struct{}
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.Mutex
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// RedisReceiver methods
+//___________________________________
+
+// Init method initializes the Redis stream receiver instance.
+func (r *RedisReceiver) Init(cfg *config.Config) error {
+	r.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(r.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", r.formatter)
+	}
+
+	r.address = cfg.StringDefault("log.redis.address", "127.0.0.1:6379")
+	r.password = cfg.StringDefault("log.redis.password", "")
+	r.db = cfg.IntDefault("log.redis.db", 0)
+	r.stream = cfg.StringDefault("log.redis.stream", "aah:logs")
+	if ess.IsStrEmpty(r.stream) {
+		return errors.New("log: redis stream is required")
+	}
+	r.maxLen = int64(cfg.IntDefault("log.redis.maxlen", 0))
+	r.approximate = cfg.BoolDefault("log.redis.approximate", true)
+
+	var err error
+	if r.timeout, err = parseDuration(cfg, "log.redis.timeout", "5s"); err != nil {
+		return err
+	}
+	if r.minBackoff, err = parseDuration(cfg, "log.redis.reconnect.min_backoff", "500ms"); err != nil {
+		return err
+	}
+	if r.maxBackoff, err = parseDuration(cfg, "log.redis.reconnect.max_backoff", "30s"); err != nil {
+		return err
+	}
+
+	r.mu = sync.Mutex{}
+	r.done = make(chan struct{})
+	r.SetWriter(writerFunc(func(p []byte) (int, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(p), r.xadd([]string{"message", string(bytes.TrimRight(p, "\n"))})
+	}))
+
+	// initial connection failure is not fatal, it's reconnected in the
+	// background
+	conn, reader, err := r.dial()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.scheduleDial()
+		r.reconnect()
+		return nil
+	}
+	r.conn, r.reader = conn, reader
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (r *RedisReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	r.flags = flags
+	if r.formatter != jsonFmt {
+		r.isCallerInfo = isCallerInfo(r.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into Redis receiver.
+func (r *RedisReceiver) SetWriter(w io.Writer) {
+	r.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (r *RedisReceiver) IsCallerInfo() bool {
+	return r.isCallerInfo
+}
+
+// Log method appends the log entry into Redis stream. Stream entry has
+// `level`, `time`, `message` and entry fields as field-value pairs.
+func (r *RedisReceiver) Log(entry *Entry) {
+	msg := bytes.TrimRight(formatEntry(r.formatter, r.flags, entry), " \n")
+	values := []string{
+		"level", entry.Level.String(),
+		"time", entry.Time.Format(time.RFC3339Nano),
+		"message", string(msg),
+	}
+	if len(entry.File) > 0 {
+		values = append(values, "file", entry.File, "line", strconv.Itoa(entry.Line))
+	}
+
+	keys := make([]string, 0, len(entry.Fields))
+	for k := range entry.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values = append(values, k, entry.Fields.str(k))
+	}
+
+	r.mu.Lock()
+	err := r.xadd(values)
+	r.mu.Unlock()
+	if err != nil {
+		r.reportError(err, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (r *RedisReceiver) Writer() io.Writer {
+	return r.out
+}
+
+// Close method stops the reconnect and closes the connection.
+func (r *RedisReceiver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	close(r.done)
+	r.disconnect()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// RedisReceiver Unexported methods
+//___________________________________
+
+func (r *RedisReceiver) xadd(values []string) error {
+	args := []string{"XADD", r.stream}
+	if r.maxLen > 0 {
+		args = append(args, "MAXLEN")
+		if r.approximate {
+			args = append(args, "~")
+		}
+		args = append(args, strconv.FormatInt(r.maxLen, 10))
+	}
+	args = append(append(args, "*"), values...)
+
+	if r.conn == nil {
+		r.reconnect()
+		return ErrNetworkNotConnected
+	}
+	if _, err := r.do(args...); err != nil {
+		// connection might be closed by server, it's reconnected in the
+		// background
+		if _, ok := err.(redisError); !ok {
+			r.disconnect()
+			r.scheduleDial()
+			r.reconnect()
+		}
+		return err
+	}
+	return nil
+}
+
+// dial method connects to Redis server and authenticates the connection.
+func (r *RedisReceiver) dial() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", r.address, r.timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := bufio.NewReader(conn)
+
+	if len(r.password) > 0 {
+		if _, err = r.command(conn, reader, "AUTH", r.password); err != nil {
+			ess.CloseQuietly(conn)
+			return nil, nil, err
+		}
+	}
+	if r.db > 0 {
+		if _, err = r.command(conn, reader, "SELECT", strconv.Itoa(r.db)); err != nil {
+			ess.CloseQuietly(conn)
+			return nil, nil, err
+		}
+	}
+	return conn, reader, nil
+}
+
+// reconnect method starts dialing Redis server in the background unless
+// it's already in progress, it's called with lock held.
+func (r *RedisReceiver) reconnect() {
+	if r.dialing || r.closed {
+		return
+	}
+	r.dialing = true
+	go r.redial()
+}
+
+// redial method dials Redis server as per backoff till it's connected.
+func (r *RedisReceiver) redial() {
+	for {
+		r.mu.Lock()
+		wait := time.Until(r.nextDial)
+		r.mu.Unlock()
+		select {
+		case <-time.After(wait):
+		case <-r.done:
+			return
+		}
+
+		conn, reader, err := r.dial()
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			if err == nil {
+				ess.CloseQuietly(conn)
+			}
+			return
+		}
+		if err != nil {
+			r.scheduleDial()
+			r.mu.Unlock()
+			continue
+		}
+		r.conn, r.reader = conn, reader
+		r.backoff = 0
+		r.dialing = false
+		r.mu.Unlock()
+		return
+	}
+}
+
+// scheduleDial method computes next reconnect attempt time with exponential
+// backoff bounded by configured maximum.
+func (r *RedisReceiver) scheduleDial() {
+	if r.backoff == 0 {
+		r.backoff = r.minBackoff
+	} else if r.backoff *= 2; r.backoff > r.maxBackoff {
+		r.backoff = r.maxBackoff
+	}
+	r.nextDial = time.Now().Add(r.backoff)
+}
+
+func (r *RedisReceiver) disconnect() {
+	if r.conn != nil {
+		ess.CloseQuietly(r.conn)
+		r.conn, r.reader = nil, nil
+	}
+}
+
+// do method sends the command on current connection and returns the reply.
+func (r *RedisReceiver) do(args ...string) (string, error) {
+	return r.command(r.conn, r.reader, args...)
+}
+
+// command method sends the command in RESP format and returns the reply.
+func (r *RedisReceiver) command(conn net.Conn, reader *bufio.Reader, args ...string) (string, error) {
+	buf := acquireBuffer()
+	defer releaseBuffer(buf)
+	_, _ = fmt.Fprintf(buf, "*%d\r\n", len(args))
+	for _, arg := range args {
+		_, _ = fmt.Fprintf(buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(r.timeout))
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		return "", err
+	}
+	return readRedisReply(reader)
+}
+
+// redisError type represents the error reply from Redis server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "log: redis " + string(e)
+}
+
+// readRedisReply method reads simple string, error, integer and bulk string
+// replies.
+func readRedisReply(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) == 0 {
+		return "", errors.New("log: redis empty reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", redisError(line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return "", err
+		}
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return "", err
+		}
+		return string(b[:size]), nil
+	default:
+		return "", fmt.Errorf("log: redis unexpected reply '%s'", line)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestRedisLogger(t *testing.T) {
+	commands := newTestRedisServer(t, "127.0.0.1:0")
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "redis"
+    pattern = "%%message"
+    redis {
+      address = "%s"
+      password = "s3cr3t"
+      db = 2
+      stream = "myapp:logs"
+      maxlen = 1000
+    }
+  }
+  `, <-commands)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	assert.Equal(t, "AUTH s3cr3t", <-commands)
+	assert.Equal(t, "SELECT 2", <-commands)
+
+	logger.WithField("reqid", "40139CA6368607085BF6").Info("Yes, I would love to see")
+	cmd := <-commands
+	assert.True(t, strings.HasPrefix(cmd, "XADD myapp:logs MAXLEN ~ 1000 * level INFO time "))
+	assert.True(t, strings.HasSuffix(cmd, " message Yes, I would love to see reqid 40139CA6368607085BF6"))
+
+	_, err = fmt.Fprint(logger.ToGoLogger().Writer(), "standard logger message\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "XADD myapp:logs MAXLEN ~ 1000 * message standard logger message", <-commands)
+}
+
+func TestRedisLoggerReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "redis"
+    pattern = "%%message"
+    redis {
+      address = "%s"
+      reconnect {
+        min_backoff = "1ms"
+        max_backoff = "2ms"
+      }
+    }
+  }
+  `, addr)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer logger.Close()
+
+	var (
+		mu       sync.Mutex
+		reported []error
+	)
+	logger.SetErrorHandler(func(err error, e *Entry) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	})
+	logger.Info("dropped message")
+	mu.Lock()
+	assert.Equal(t, []error{ErrNetworkNotConnected}, reported)
+	mu.Unlock()
+
+	receiver := logger.receiver.(*RedisReceiver)
+	commands := newTestRedisServer(t, addr)
+	<-commands
+	connected := func() bool {
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		return receiver.conn != nil
+	}
+	for i := 0; i < 100 && !connected(); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	logger.Info("connected message")
+	assert.True(t, strings.HasSuffix(<-commands, " message connected message"))
+}
+
+func TestRedisLoggerConfigErrors(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "redis", redis { stream = "" } }`)
+	logger, err := New(cfg)
+	assert.Nil(t, logger)
+	assert.Equal(t, "log: redis stream is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "redis", format = "xml" }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unsupported format 'xml'", err.Error())
+
+	_, err = readRedisReply(bufio.NewReader(strings.NewReader("-ERR unknown command\r\n")))
+	assert.Equal(t, "log: redis ERR unknown command", err.Error())
+}
+
+// newTestRedisServer starts the minimal RESP server, first value on the
+// channel is server address followed by received commands.
+func newTestRedisServer(t *testing.T, addr string) <-chan string {
+	ln, err := net.Listen("tcp", addr)
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+
+	commands := make(chan string, 10)
+	commands <- ln.Addr().String()
+	go func() {
+		defer func() { _ = ln.Close() }()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cnt, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			args := make([]string, 0, cnt)
+			for i := 0; i < cnt; i++ {
+				line, _ = r.ReadString('\n')
+				size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+				arg := make([]byte, size+2)
+				_, _ = io.ReadFull(r, arg)
+				args = append(args, string(arg[:size]))
+			}
+			commands <- strings.Join(args, " ")
+
+			if args[0] == "XADD" {
+				_, _ = conn.Write([]byte("$15\r\n1526919030474-0\r\n"))
+			} else {
+				_, _ = conn.Write([]byte("+OK\r\n"))
+			}
+		}
+	}()
+	return commands
+}
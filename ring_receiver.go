@@ -0,0 +1,157 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var _ Receiver = (*RingReceiver)(nil)
+
+// RingReceiver keeps the last `log.ring.size` (default 1000) log entries in
+// memory, oldest entry is overwritten when it's full. Recent entries can be
+// attached to crash report or exposed on debug endpoint using `Entries` and
+// `Dump` methods.
+type RingReceiver struct {
+	entries      []*Entry
+	next         int
+	count        int
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.RWMutex
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// RingReceiver methods
+//___________________________________
+
+// Init method initializes the ring buffer receiver instance.
+func (r *RingReceiver) Init(cfg *config.Config) error {
+	r.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(r.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", r.formatter)
+	}
+
+	size := cfg.IntDefault("log.ring.size", 1000)
+	if size <= 0 {
+		return fmt.Errorf("log: invalid ring size '%d'", size)
+	}
+	r.entries = make([]*Entry, size)
+
+	r.SetWriter(writerFunc(func(p []byte) (int, error) {
+		r.add(&Entry{
+			Level:   LevelInfo,
+			Time:    time.Now(),
+			Message: string(bytes.TrimRight(p, "\n")),
+			Fields:  Fields{},
+		})
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (r *RingReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	r.flags = flags
+	if r.formatter != jsonFmt {
+		r.isCallerInfo = isCallerInfo(r.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into ring buffer receiver.
+func (r *RingReceiver) SetWriter(w io.Writer) {
+	r.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (r *RingReceiver) IsCallerInfo() bool {
+	return r.isCallerInfo
+}
+
+// Log method adds the log entry into ring buffer.
+func (r *RingReceiver) Log(entry *Entry) {
+	e := *entry
+	e.logger = nil
+	e.Fields = make(Fields, len(entry.Fields))
+	for k, v := range entry.Fields {
+		e.Fields[k] = v
+	}
+	r.add(&e)
+}
+
+// Writer method returns the current log writer.
+func (r *RingReceiver) Writer() io.Writer {
+	return r.out
+}
+
+// Entries method returns the copy of buffered log entries, oldest first.
+func (r *RingReceiver) Entries() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]Entry, 0, r.count)
+	start := (r.next - r.count + len(r.entries)) % len(r.entries)
+	for i := 0; i < r.count; i++ {
+		src := r.entries[(start+i)%len(r.entries)]
+		e := *src
+		e.Fields = make(Fields, len(src.Fields))
+		for k, v := range src.Fields {
+			e.Fields[k] = v
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// Dump method writes the buffered log entries into given writer as per
+// log format and pattern, oldest first.
+func (r *RingReceiver) Dump(w io.Writer) error {
+	for _, e := range r.Entries() {
+		if _, err := w.Write(formatEntry(r.formatter, r.flags, &e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset method clears the ring buffer.
+func (r *RingReceiver) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.entries {
+		r.entries[i] = nil
+	}
+	r.next, r.count = 0, 0
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// RingReceiver Unexported methods
+//___________________________________
+
+func (r *RingReceiver) add(e *Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.count < len(r.entries) {
+		r.count++
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestRingLogger(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "ring", pattern = "%level:-5 %message", ring { size = 3 } }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*RingReceiver)
+	assert.Equal(t, 0, len(receiver.Entries()))
+
+	logger.Debug("I would like to see this message, debug is useful for dev")
+	logger.WithField("key1", "value1").Info("Yes, I would love to see")
+	logger.Warn("Yes, yes it's an warning")
+	logger.Error("Yes, yes, yes - finally an error")
+
+	entries := receiver.Entries()
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, LevelInfo, entries[0].Level)
+	assert.Equal(t, "value1", entries[0].Fields["key1"])
+	assert.Equal(t, "Yes, yes, yes - finally an error", entries[2].Message)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, receiver.Dump(buf))
+	assert.Equal(t, "INFO  Yes, I would love to see \nWARN  Yes, yes it's an warning \nERROR Yes, yes, yes - finally an error \n", buf.String())
+
+	receiver.Reset()
+	assert.Equal(t, 0, len(receiver.Entries()))
+
+	cfg, _ = config.ParseString(`log { receiver = "ring", ring { size = 0 } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid ring size '0'", err.Error())
+}
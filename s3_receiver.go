@@ -0,0 +1,325 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const (
+	s3ChunkPrefix = "chunk-"
+	s3ChunkExt    = ".log"
+)
+
+var _ Receiver = (*S3Receiver)(nil)
+
+// S3Receiver spools the log entries into local chunk files and periodically
+// uploads them as gzip compressed objects into S3 compatible object storage.
+// Chunk is uploaded when it reaches chunk size or on upload interval, chunks
+// failed to upload are retried on next interval and left over chunks of
+// previous run are uploaded on start.
+//
+// Object key is created from `log.s3.key` template, supported tokens are
+// `{date}`, `{hour}`, `{instance}` and `{timestamp}` (chunk created time
+// in UTC). Set `log.s3.endpoint` for S3 compatible storage, for e.g.: MinIO,
+// then path style URL is used.
+type S3Receiver struct {
+	errorReporter
+	bucket       string
+	region       string
+	endpoint     string
+	keyTmpl      string
+	instance     string
+	spoolDir     string
+	chunkSize    int64
+	chunk        *os.File
+	chunkBytes   int64
+	creds        *awsCredentialChain
+	sender       *httpSender
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.Mutex
+	uploadMu     sync.Mutex
+	done         chan struct{}
+	wg           sync.WaitGroup
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// S3Receiver methods
+//___________________________________
+
+// Init method initializes the S3 receiver instance.
+func (s *S3Receiver) Init(cfg *config.Config) error {
+	s.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(s.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", s.formatter)
+	}
+
+	s.bucket = cfg.StringDefault("log.s3.bucket", "")
+	if ess.IsStrEmpty(s.bucket) {
+		return errors.New("log: s3 bucket is required")
+	}
+
+	s.region = cfg.StringDefault("log.s3.region", os.Getenv("AWS_REGION"))
+	if ess.IsStrEmpty(s.region) {
+		s.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if ess.IsStrEmpty(s.region) {
+		return errors.New("log: s3 region is required")
+	}
+	s.endpoint = strings.TrimSuffix(cfg.StringDefault("log.s3.endpoint", ""), "/")
+
+	hostname, _ := os.Hostname()
+	s.instance = cfg.StringDefault("log.s3.instance", hostname)
+	s.keyTmpl = strings.TrimPrefix(cfg.StringDefault("log.s3.key", "{date}/{hour}/{instance}-{timestamp}.log.gz"), "/")
+	s.spoolDir = cfg.StringDefault("log.s3.spool_dir", filepath.Join(os.TempDir(), "aah-log-s3"))
+	if err := ess.MkDirAll(s.spoolDir, filePermission); err != nil {
+		return err
+	}
+
+	// chunks left open by previous run are ready to upload
+	openChunks, _ := filepath.Glob(filepath.Join(s.spoolDir, s3ChunkPrefix+"*.tmp"))
+	for _, name := range openChunks {
+		_ = os.Rename(name, strings.TrimSuffix(name, ".tmp")+s3ChunkExt)
+	}
+
+	chunkSize, err := ess.StrToBytes(cfg.StringDefault("log.s3.chunk_size", "5mb"))
+	if err != nil {
+		return err
+	}
+	s.chunkSize = chunkSize
+
+	uploadInterval, err := parseInterval(cfg, "log.s3.upload_interval", "5m")
+	if err != nil {
+		return err
+	}
+
+	if s.sender, err = newHTTPSender(cfg, "s3"); err != nil {
+		return err
+	}
+	s.creds = newAWSCredentialChain(cfg, "s3")
+
+	s.SetWriter(writerFunc(func(p []byte) (int, error) {
+		if err := s.write(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}))
+
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go s.run(uploadInterval)
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (s *S3Receiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	s.flags = flags
+	if s.formatter != jsonFmt {
+		s.isCallerInfo = isCallerInfo(s.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into S3 receiver.
+func (s *S3Receiver) SetWriter(w io.Writer) {
+	s.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (s *S3Receiver) IsCallerInfo() bool {
+	return s.isCallerInfo
+}
+
+// Log method writes the log entry into current chunk.
+func (s *S3Receiver) Log(entry *Entry) {
+	if err := s.write(formatEntry(s.formatter, s.flags, entry)); err != nil {
+		s.reportError(err, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (s *S3Receiver) Writer() io.Writer {
+	return s.out
+}
+
+// Upload method uploads the current and pending chunks into S3.
+func (s *S3Receiver) Upload() error {
+	s.mu.Lock()
+	s.closeChunk()
+	s.mu.Unlock()
+	return s.upload()
+}
+
+// Close method uploads the chunks and stops the receiver.
+func (s *S3Receiver) Close() {
+	close(s.done)
+	s.wg.Wait()
+	if err := s.Upload(); err != nil {
+		s.reportError(err, nil)
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// S3Receiver Unexported methods
+//___________________________________
+
+func (s *S3Receiver) run(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// left over chunks of previous run
+	if err := s.upload(); err != nil {
+		s.reportError(err, nil)
+	}
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Upload(); err != nil {
+				s.reportError(err, nil)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// write method writes into current chunk, it returns the error of chunk
+// create or write.
+func (s *S3Receiver) write(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.chunk == nil {
+		name := filepath.Join(s.spoolDir, s3ChunkPrefix+strconv.FormatInt(time.Now().UnixNano(), 10)+".tmp")
+		file, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePermission)
+		if err != nil {
+			return err
+		}
+		s.chunk, s.chunkBytes = file, 0
+	}
+
+	n, err := s.chunk.Write(p)
+	if s.chunkBytes += int64(n); s.chunkBytes >= s.chunkSize {
+		s.closeChunk()
+		go func() {
+			if err := s.upload(); err != nil {
+				s.reportError(err, nil)
+			}
+		}()
+	}
+	return err
+}
+
+// closeChunk method closes the current chunk and marks it ready to upload.
+func (s *S3Receiver) closeChunk() {
+	if s.chunk == nil {
+		return
+	}
+	name := s.chunk.Name()
+	ess.CloseQuietly(s.chunk)
+	s.chunk = nil
+	_ = os.Rename(name, strings.TrimSuffix(name, ".tmp")+s3ChunkExt)
+}
+
+// upload method uploads the ready chunks in the created order, uploaded
+// chunks are removed from spool directory.
+func (s *S3Receiver) upload() error {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+
+	chunks, err := filepath.Glob(filepath.Join(s.spoolDir, s3ChunkPrefix+"*"+s3ChunkExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(chunks)
+
+	for _, chunk := range chunks {
+		if err = s.uploadChunk(chunk); err != nil {
+			return err
+		}
+		_ = os.Remove(chunk)
+	}
+	return nil
+}
+
+func (s *S3Receiver) uploadChunk(chunk string) error {
+	data, err := ioutil.ReadFile(chunk)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	_, _ = gw.Write(data)
+	if err = gw.Close(); err != nil {
+		return err
+	}
+	body := buf.Bytes()
+
+	nano, _ := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(chunk), s3ChunkPrefix), s3ChunkExt), 10, 64)
+	rawurl := s.objectURL(s.objectKey(time.Unix(0, nano)))
+
+	creds, err := s.creds.Get()
+	if err != nil {
+		return err
+	}
+
+	bodyHash := sha256.Sum256(body)
+	header := http.Header{}
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+	header.Set("Content-Encoding", "gzip")
+	header.Set("X-Amz-Content-Sha256", hex.EncodeToString(bodyHash[:]))
+	if err = awsSignV4(http.MethodPut, rawurl, body, header, creds, s.region, "s3", time.Now()); err != nil {
+		return err
+	}
+
+	_, err = s.sender.send(http.MethodPut, rawurl, body, header)
+	return err
+}
+
+func (s *S3Receiver) objectKey(t time.Time) string {
+	t = t.UTC()
+	return strings.NewReplacer(
+		"{date}", t.Format("2006-01-02"),
+		"{hour}", t.Format("15"),
+		"{instance}", s.instance,
+		"{timestamp}", t.Format("20060102T150405.000000000Z"),
+	).Replace(s.keyTmpl)
+}
+
+func (s *S3Receiver) objectURL(key string) string {
+	if len(s.endpoint) > 0 {
+		return s.endpoint + "/" + s.bucket + "/" + key
+	}
+	return "https://" + s.bucket + ".s3." + s.region + ".amazonaws.com/" + key
+}
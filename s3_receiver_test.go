@@ -0,0 +1,117 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestS3Logger(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		objects = make(map[string]string)
+		status  = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		gr, err := gzip.NewReader(r.Body)
+		assert.FailNowOnError(t, err, "unexpected error")
+		data, _ := ioutil.ReadAll(gr)
+		objects[r.URL.Path] = string(data)
+	}))
+	defer server.Close()
+
+	spoolDir, _ := ioutil.TempDir("", "aah-log-s3")
+	defer func() { _ = os.RemoveAll(spoolDir) }()
+
+	// chunk left open by previous run
+	created := time.Date(2017, 6, 2, 10, 30, 0, 0, time.UTC)
+	_ = ioutil.WriteFile(filepath.Join(spoolDir, fmt.Sprintf("chunk-%d.tmp", created.UnixNano())),
+		[]byte("INFO  From previous run\n"), 0644)
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "s3"
+    pattern = "%%level:-5 %%message"
+    s3 {
+      endpoint = "%s"
+      bucket = "logs"
+      region = "us-east-1"
+      instance = "node-1"
+      key = "app/{date}/{hour}/{instance}-{timestamp}.log.gz"
+      spool_dir = "%s"
+      upload_interval = "1h"
+      access_key_id = "AKID"
+      secret_access_key = "secret"
+    }
+  }
+  `, server.URL, filepath.ToSlash(spoolDir))
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*S3Receiver)
+
+	logger.Info("Yes, I would love to see")
+	mu.Lock()
+	status = http.StatusForbidden
+	mu.Unlock()
+	assert.NotNil(t, receiver.Upload())
+
+	// failed chunk is kept in spool directory
+	chunks, _ := filepath.Glob(filepath.Join(spoolDir, "chunk-*.log"))
+	assert.True(t, len(chunks) > 0)
+
+	mu.Lock()
+	status = http.StatusOK
+	mu.Unlock()
+	logger.Error("Yes, yes, yes - finally an error")
+	receiver.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "INFO  From previous run\n", objects["/logs/app/2017-06-02/10/node-1-20170602T103000.000000000Z.log.gz"])
+	var content []string
+	for key, data := range objects {
+		assert.True(t, strings.HasPrefix(key, "/logs/app/"))
+		content = append(content, data)
+	}
+	assert.Equal(t, 3, len(objects))
+	assert.True(t, strings.Contains(strings.Join(content, ""), "INFO  Yes, I would love to see \n"))
+	assert.True(t, strings.Contains(strings.Join(content, ""), "ERROR Yes, yes, yes - finally an error \n"))
+
+	chunks, _ = filepath.Glob(filepath.Join(spoolDir, "chunk-*"))
+	assert.Equal(t, 0, len(chunks))
+}
+
+func TestS3LoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "s3", s3 { region = "us-east-1" } }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: s3 bucket is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "s3", s3 { bucket = "logs", region = "us-east-1", chunk_size = "5 apples" } }`)
+	_, err = New(cfg)
+	assert.NotNil(t, err)
+}
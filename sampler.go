@@ -0,0 +1,71 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+
+	"aahframework.org/config.v0"
+)
+
+// sampler keeps 1 in N entries of the level configured under
+// `log.sampling`, levels which are not configured are kept as-is.
+//
+//	log {
+//	  sampling {
+//	    # every - keeps every N-th entry (default)
+//	    # random - keeps the entry with probability of 1/N
+//	    mode = "every"
+//	    debug = 100
+//	    trace = 1000
+//	  }
+//	}
+type sampler struct {
+	isRandom bool
+	rates    [LevelUnknown]uint64
+	counts   [LevelUnknown]uint64
+}
+
+func newSampler(cfg *config.Config) (*sampler, error) {
+	s := &sampler{}
+	switch mode := cfg.StringDefault("log.sampling.mode", "every"); mode {
+	case "every":
+	case "random":
+		s.isRandom = true
+	default:
+		return nil, fmt.Errorf("log: unsupported sampling mode '%s'", mode)
+	}
+
+	for _, name := range cfg.KeysByPath("log.sampling") {
+		if name == "mode" {
+			continue
+		}
+		lvl := levelByName(name)
+		if lvl == LevelUnknown {
+			return nil, fmt.Errorf("log: unknown sampling level '%s'", name)
+		}
+		rate := cfg.IntDefault("log.sampling."+name, 0)
+		if rate < 1 {
+			return nil, fmt.Errorf("log: sampling rate of level '%s' must be greater than zero", name)
+		}
+		s.rates[lvl] = uint64(rate)
+	}
+	return s, nil
+}
+
+// sample method returns the sampling rate of the level and true if the
+// entry has to be kept.
+func (s *sampler) sample(lvl level) (uint64, bool) {
+	if lvl >= LevelUnknown || s.rates[lvl] <= 1 {
+		return 0, true
+	}
+	rate := s.rates[lvl]
+	if s.isRandom {
+		return rate, rand.Int63n(int64(rate)) == 0
+	}
+	return rate, (atomic.AddUint64(&s.counts[lvl], 1)-1)%rate == 0
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogSampling(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%level:-5 %message %fields"
+    sampling {
+      debug = 3
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	for i := 0; i < 7; i++ {
+		logger.Debugf("debug %d", i)
+		logger.Errorf("error %d", i)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 10, len(lines))
+	for i, msg := range map[int]string{0: "DEBUG debug 0", 4: "DEBUG debug 3", 8: "DEBUG debug 6"} {
+		assert.True(t, strings.HasPrefix(lines[i], msg+" fields["))
+		assert.True(t, strings.Contains(lines[i], "sampled: true"))
+		assert.True(t, strings.Contains(lines[i], "sample_rate: 3"))
+	}
+	assert.Equal(t, "ERROR error 0", strings.TrimSpace(lines[1]))
+	assert.Equal(t, "ERROR error 2", strings.TrimSpace(lines[3]))
+}
+
+func TestLogSamplingRandom(t *testing.T) {
+	cfg, _ := config.ParseString(`log { sampling { mode = "random", info = 4 } }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	kept := 0
+	for i := 0; i < 4000; i++ {
+		if _, keep := logger.sampler.sample(LevelInfo); keep {
+			kept++
+		}
+	}
+	assert.True(t, kept > 800 && kept < 1200)
+
+	rate, keep := logger.sampler.sample(LevelError)
+	assert.Equal(t, uint64(0), rate)
+	assert.True(t, keep)
+}
+
+func TestLogSamplingConfig(t *testing.T) {
+	for cfgStr, msg := range map[string]string{
+		`sampling { mode = "first" }`: "log: unsupported sampling mode 'first'",
+		`sampling { verbose = 10 }`:   "log: unknown sampling level 'verbose'",
+		`sampling { debug = 0 }`:      "log: sampling rate of level 'debug' must be greater than zero",
+	} {
+		cfg, _ := config.ParseString(`log { ` + cfgStr + ` }`)
+		_, err := New(cfg)
+		assert.Equal(t, msg, err.Error())
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"aahframework.org/config.v0"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// levelSchedule changes the logger level by time window, it's configured
+// as list `log.level_schedule`. Window is `<HH:MM>-<HH:MM> [days] <level>`,
+// days is optional, for e.g. `mon-fri` or `sat,sun`. Window ends before the
+// end time and overnight window is matched against the day it starts. First
+// matching window is applied, otherwise `log.level`. Time is in local time
+// zone. For e.g.:
+//
+//	log {
+//	  level = "warn"
+//	  level_schedule = [
+//	    "09:00-18:00 mon-fri debug",
+//	    "22:00-06:00 error"
+//	  ]
+//	}
+//
+// Schedule is checked every minute, level set via `Logger.SetLevel` is
+// kept until the next window change. It's not supported with
+// `log.receivers`, since each receiver has its own level.
+type levelSchedule struct {
+	windows   []*scheduleWindow
+	base      level
+	scheduled level
+	done      chan struct{}
+}
+
+type scheduleWindow struct {
+	start int
+	end   int
+	days  [7]bool
+	level level
+}
+
+func newLevelSchedule(cfg *config.Config, base level) (*levelSchedule, error) {
+	exprs, _ := cfg.StringList("log.level_schedule")
+	s := &levelSchedule{base: base, scheduled: LevelUnknown}
+	for _, expr := range exprs {
+		w, err := parseScheduleWindow(expr)
+		if err != nil {
+			return nil, err
+		}
+		s.windows = append(s.windows, w)
+	}
+	return s, nil
+}
+
+func parseScheduleWindow(expr string) (*scheduleWindow, error) {
+	parts := strings.Fields(expr)
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("log: invalid level schedule '%s'", expr)
+	}
+
+	w := &scheduleWindow{level: levelByName(parts[len(parts)-1])}
+	if w.level == LevelUnknown {
+		return nil, fmt.Errorf("log: unknown level '%s' in level schedule '%s'", parts[len(parts)-1], expr)
+	}
+
+	times := strings.Split(parts[0], "-")
+	if len(times) != 2 {
+		return nil, fmt.Errorf("log: invalid level schedule '%s'", expr)
+	}
+	for i, v := range times {
+		t, err := time.Parse("15:04", v)
+		if err != nil {
+			return nil, fmt.Errorf("log: invalid time '%s' in level schedule '%s'", v, expr)
+		}
+		if i == 0 {
+			w.start = t.Hour()*60 + t.Minute()
+		} else {
+			w.end = t.Hour()*60 + t.Minute()
+		}
+	}
+
+	if len(parts) == 2 {
+		for i := range w.days {
+			w.days[i] = true
+		}
+		return w, nil
+	}
+	for _, d := range strings.Split(strings.ToLower(parts[1]), ",") {
+		bounds := strings.SplitN(d, "-", 2)
+		from, found := weekdays[bounds[0]]
+		if !found {
+			return nil, fmt.Errorf("log: invalid day '%s' in level schedule '%s'", bounds[0], expr)
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, found = weekdays[bounds[1]]; !found {
+				return nil, fmt.Errorf("log: invalid day '%s' in level schedule '%s'", bounds[1], expr)
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			w.days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return w, nil
+}
+
+// level method returns the level of first matching window for given time
+// otherwise base level.
+func (s *levelSchedule) level(t time.Time) level {
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range s.windows {
+		if w.match(t.Weekday(), minute) {
+			return w.level
+		}
+	}
+	return s.base
+}
+
+func (w *scheduleWindow) match(day time.Weekday, minute int) bool {
+	if w.start <= w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+
+	// overnight window, after midnight belongs to the previous day
+	if minute >= w.start {
+		return w.days[day]
+	}
+	return minute < w.end && w.days[(day+6)%7]
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Logger unexported methods
+//___________________________________
+
+// applySchedule method sets the logger level if scheduled level of given
+// time is changed since the last check.
+func (l *Logger) applySchedule(t time.Time) {
+	lvl := l.schedule.level(t)
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	if lvl == l.schedule.scheduled {
+		return
+	}
+	l.schedule.scheduled = lvl
+	atomic.StoreInt32(&l.level, int32(lvl))
+	l.setMaxLevel()
+}
+
+func (l *Logger) runSchedule(done chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case t := <-ticker.C:
+			l.applySchedule(t)
+		case <-done:
+			return
+		}
+	}
+}
+
+// stopSchedule method stops the level schedule if any.
+func (l *Logger) stopSchedule() {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.schedule != nil && l.schedule.done != nil {
+		close(l.schedule.done)
+		l.schedule.done = nil
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogLevelSchedule(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "warn"
+    level_schedule = [
+      "09:00-18:00 mon-fri debug",
+      "22:00-06:00 sat,sun error"
+    ]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer logger.Close()
+
+	// 2026-10-12 is Monday
+	at := func(day int, clock string) time.Time {
+		t, _ := time.ParseInLocation("2006-01-02 15:04", fmt.Sprintf("2026-10-%d %s", day, clock), time.Local)
+		return t
+	}
+	for _, tc := range []struct {
+		t     time.Time
+		level string
+	}{
+		{at(12, "09:00"), "DEBUG"},
+		{at(16, "17:59"), "DEBUG"},
+		{at(16, "18:00"), "WARN"},
+		{at(17, "10:00"), "WARN"},
+		{at(17, "23:30"), "ERROR"},
+		{at(18, "05:59"), "ERROR"},
+		{at(19, "01:00"), "ERROR"},
+		{at(20, "01:00"), "WARN"},
+	} {
+		logger.applySchedule(tc.t)
+		assert.Equal(t, tc.level, logger.Level())
+	}
+
+	// level set in between is kept until the window change
+	_ = logger.SetLevel("info")
+	logger.applySchedule(at(20, "02:00"))
+	assert.Equal(t, "INFO", logger.Level())
+	logger.applySchedule(at(20, "09:30"))
+	assert.Equal(t, "DEBUG", logger.Level())
+	assert.True(t, logger.IsLevelDebug())
+}
+
+func TestLogLevelScheduleRace(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "warn"
+    level_schedule = ["09:00-18:00 debug"]
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer logger.Close()
+	logger.SetWriter(ioutil.Discard)
+
+	base := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logger.applySchedule(base.Add(time.Duration(i%24) * time.Hour))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		logger.Debug("Yes, I would love to see")
+		_ = logger.Level()
+	}
+	wg.Wait()
+}
+
+func TestLogLevelScheduleReceivers(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level_schedule = ["09:00-18:00 debug"]
+    receivers {
+      console { level = "warn" }
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.Nil(t, logger)
+	assert.Equal(t, "log: level schedule is not supported with receivers", err.Error())
+}
+
+func TestLevelScheduleParse(t *testing.T) {
+	w, err := parseScheduleWindow("08:30-17:00 fri-mon info")
+	assert.Nil(t, err)
+	assert.Equal(t, 510, w.start)
+	assert.Equal(t, 1020, w.end)
+	assert.Equal(t, [7]bool{true, true, false, false, false, true, true}, w.days)
+
+	for expr, msg := range map[string]string{
+		"debug":                    "log: invalid level schedule 'debug'",
+		"09:00 debug":              "log: invalid level schedule '09:00 debug'",
+		"09:00-25:00 debug":        "log: invalid time '25:00' in level schedule '09:00-25:00 debug'",
+		"09:00-18:00 verbose":      "log: unknown level 'verbose' in level schedule '09:00-18:00 verbose'",
+		"09:00-18:00 mon-xyz info": "log: invalid day 'xyz' in level schedule '09:00-18:00 mon-xyz info'",
+		"09:00-18:00 mon fri info": "log: invalid level schedule '09:00-18:00 mon fri info'",
+	} {
+		_, err = parseScheduleWindow(expr)
+		assert.Equal(t, msg, err.Error())
+	}
+
+	cfg, _ := config.ParseString(`log {
+    level_schedule = ["09:00 debug"]
+  }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: invalid level schedule '09:00 debug'", err.Error())
+}
@@ -0,0 +1,301 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var (
+	levelToSentryLevel = map[level]string{
+		LevelFatal: "fatal",
+		LevelPanic: "fatal",
+		LevelError: "error",
+		LevelWarn:  "warning",
+		LevelInfo:  "info",
+		LevelDebug: "debug",
+		LevelTrace: "debug",
+	}
+
+	// logPkgPath is the package path as it appears in runtime function name,
+	// dots of last path element are escaped
+	logPkgPath = funcPkgPath(reflect.TypeOf(Entry{}).PkgPath())
+
+	_ Receiver = (*SentryReceiver)(nil)
+)
+
+// SentryReceiver sends the log entry of configured level and above (default
+// is `ERROR`) into Sentry as event with stack trace. Fields configured as
+// tags are sent as Sentry tags and rest of the fields as extra.
+type SentryReceiver struct {
+	errorReporter
+	storeURL    string
+	auth        string
+	level       level
+	environment string
+	release     string
+	serverName  string
+	tags        []string
+	sender      *httpSender
+	batcher     *batcher
+	out         io.Writer
+}
+
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger"`
+	Platform    string                 `json:"platform"`
+	Message     string                 `json:"message"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	User        map[string]string      `json:"user,omitempty"`
+	Stacktrace  *sentryStacktrace      `json:"stacktrace,omitempty"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// SentryReceiver methods
+//___________________________________
+
+// Init method initializes the Sentry receiver instance.
+func (s *SentryReceiver) Init(cfg *config.Config) error {
+	dsn := cfg.StringDefault("log.sentry.dsn", os.Getenv("SENTRY_DSN"))
+	if ess.IsStrEmpty(dsn) {
+		return errors.New("log: sentry dsn is required")
+	}
+	var err error
+	if s.storeURL, s.auth, err = parseSentryDSN(dsn); err != nil {
+		return err
+	}
+
+	levelName := cfg.StringDefault("log.sentry.level", "error")
+	if s.level = levelByName(levelName); s.level == LevelUnknown {
+		return fmt.Errorf("log: unknown sentry level '%s'", levelName)
+	}
+
+	hostname, _ := os.Hostname()
+	s.environment = cfg.StringDefault("log.sentry.environment", os.Getenv("SENTRY_ENVIRONMENT"))
+	s.release = cfg.StringDefault("log.sentry.release", os.Getenv("SENTRY_RELEASE"))
+	s.serverName = cfg.StringDefault("log.sentry.server_name", hostname)
+	if tags, found := cfg.StringList("log.sentry.tags"); found {
+		s.tags = tags
+	} else {
+		s.tags = []string{"appname", "insname", "reqid"}
+	}
+
+	if s.sender, err = newHTTPSender(cfg, "sentry"); err != nil {
+		return err
+	}
+
+	flushInterval, err := parseInterval(cfg, "log.sentry.flush_interval", "1s")
+	if err != nil {
+		return err
+	}
+	s.batcher = newBatcher(cfg.IntDefault("log.sentry.batch_size", 10),
+		cfg.IntDefault("log.sentry.queue_size", 100), flushInterval, s.send)
+
+	// standard logger writes are not sent to Sentry
+	s.SetWriter(ioutil.Discard)
+	return nil
+}
+
+// SetPattern method is no-op for Sentry receiver, event message is always
+// the log message.
+func (s *SentryReceiver) SetPattern(pattern string) error {
+	_, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	return err
+}
+
+// SetWriter method sets the given writer into Sentry receiver.
+func (s *SentryReceiver) SetWriter(w io.Writer) {
+	s.out = w
+}
+
+// IsCallerInfo method returns false, Sentry receiver captures the stack
+// trace by itself.
+func (s *SentryReceiver) IsCallerInfo() bool {
+	return false
+}
+
+// Log method queues the log entry as Sentry event if entry level is
+// configured level or above.
+func (s *SentryReceiver) Log(entry *Entry) {
+	if entry.Level > s.level {
+		return
+	}
+
+	event := &sentryEvent{
+		EventID:     sentryEventID(),
+		Timestamp:   entry.Time.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		Level:       levelToSentryLevel[builtinLevel(entry.Level)],
+		Logger:      "aah",
+		Platform:    "go",
+		Message:     entry.Message,
+		Environment: s.environment,
+		Release:     s.release,
+		ServerName:  s.serverName,
+		Tags:        make(map[string]string),
+		Extra:       make(map[string]interface{}),
+		Stacktrace:  sentryCaptureStack(),
+	}
+	for k, v := range entry.Fields {
+		if k == "principal" {
+			event.User = map[string]string{"id": fmt.Sprint(v)}
+		} else if ess.IsSliceContainsString(s.tags, k) {
+			event.Tags[k] = fmt.Sprint(v)
+		} else {
+			event.Extra[k] = v
+		}
+	}
+
+	// fatal exits and panic unwinds the process, so it's sent right away
+	if entry.Level == LevelFatal || entry.Level == LevelPanic {
+		s.send([]interface{}{event})
+		return
+	}
+	if !s.batcher.add(event) {
+		s.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (s *SentryReceiver) Writer() io.Writer {
+	return s.out
+}
+
+// Close method sends the queued events and stops the receiver.
+func (s *SentryReceiver) Close() {
+	s.batcher.close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// SentryReceiver Unexported methods
+//___________________________________
+
+func (s *SentryReceiver) send(items []interface{}) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Sentry-Auth", s.auth)
+	for _, item := range items {
+		body, err := json.Marshal(item)
+		if err != nil {
+			s.reportError(err, nil)
+			continue
+		}
+		if _, err = s.sender.send(http.MethodPost, s.storeURL, body, header); err != nil {
+			s.reportError(err, nil)
+		}
+	}
+}
+
+// parseSentryDSN method parses the DSN `{scheme}://{key}[:{secret}]@{host}/{path}{project_id}`
+// and returns store API URL and auth header value.
+func parseSentryDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", err
+	}
+	if u.User == nil || len(u.User.Username()) == 0 {
+		return "", "", fmt.Errorf("log: sentry dsn '%s' missing public key", dsn)
+	}
+	projectID := path.Base(u.Path)
+	if len(projectID) == 0 || projectID == "/" || projectID == "." {
+		return "", "", fmt.Errorf("log: sentry dsn '%s' missing project id", dsn)
+	}
+
+	auth := "Sentry sentry_version=7, sentry_client=aah-log/" + Version + ", sentry_key=" + u.User.Username()
+	if secret, found := u.User.Password(); found {
+		auth += ", sentry_secret=" + secret
+	}
+	storeURL := u.Scheme + "://" + u.Host + strings.TrimSuffix(path.Dir(u.Path), "/") + "/api/" + projectID + "/store/"
+	return storeURL, auth, nil
+}
+
+// sentryCaptureStack method captures the stack trace of the log caller, log
+// package frames are skipped. Frames are ordered from oldest to newest as
+// Sentry expects.
+func sentryCaptureStack() *sentryStacktrace {
+	pc := make([]uintptr, 64)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	var st []sentryFrame
+	for {
+		frame, more := frames.Next()
+		pkg, fn := splitFuncName(frame.Function)
+		if !(pkg == logPkgPath && !strings.HasSuffix(frame.File, "_test.go")) && len(frame.Function) > 0 {
+			st = append([]sentryFrame{{
+				Function: fn,
+				Module:   strings.Replace(pkg, "%2e", ".", -1),
+				Filename: path.Base(frame.File),
+				AbsPath:  frame.File,
+				Lineno:   frame.Line,
+				InApp:    !strings.HasPrefix(frame.File, runtime.GOROOT()),
+			}}, st...)
+		}
+		if !more {
+			break
+		}
+	}
+	if len(st) == 0 {
+		return nil
+	}
+	return &sentryStacktrace{Frames: st}
+}
+
+// splitFuncName method splits the runtime function name into package path
+// and function name.
+func splitFuncName(name string) (string, string) {
+	lastSlash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[lastSlash+1:], "."); dot >= 0 {
+		idx := lastSlash + 1 + dot
+		return name[:idx], name[idx+1:]
+	}
+	return "", name
+}
+
+func funcPkgPath(pkg string) string {
+	lastSlash := strings.LastIndex(pkg, "/")
+	return pkg[:lastSlash+1] + strings.Replace(pkg[lastSlash+1:], ".", "%2e", -1)
+}
+
+func sentryEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
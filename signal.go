@@ -0,0 +1,112 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sort"
+	"sync/atomic"
+	"syscall"
+)
+
+// watchLevelSignals method steps the logger level on level signals, it's
+// enabled by config `log.level_signals = true`. SIGUSR1 makes the logger
+// one level more verbose (for e.g. INFO to DEBUG) and SIGUSR2 one level
+// less verbose, custom levels are stepped too. It's not supported on
+// Windows.
+func (l *Logger) watchLevelSignals() {
+	sig := make(chan os.Signal, 1)
+	if !notifyLevelSignals(sig) {
+		return
+	}
+	l.levelSig = sig
+	go func() {
+		for s := range sig {
+			l.stepLevel(isVerboseSignal(s))
+		}
+	}()
+}
+
+// stopLevelSignals method stops watching the level signals.
+func (l *Logger) stopLevelSignals() {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.levelSig != nil {
+		signal.Stop(l.levelSig)
+		close(l.levelSig)
+		l.levelSig = nil
+	}
+}
+
+// watchReopenSignal method reopens the log files on SIGHUP, it's registered
+// once per logger if any of the receivers writes into file. It's enabled by
+// default, disable it with `log.reopen_on_sighup = false` if application
+// handles SIGHUP by itself.
+func (l *Logger) watchReopenSignal() {
+	if !isReopener(l.receiver) {
+		return
+	}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	l.reopenSig = sig
+	go func() {
+		for range sig {
+			_ = l.Reopen()
+		}
+	}()
+}
+
+// stopReopenSignal method stops watching SIGHUP.
+func (l *Logger) stopReopenSignal() {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.reopenSig != nil {
+		signal.Stop(l.reopenSig)
+		close(l.reopenSig)
+		l.reopenSig = nil
+	}
+}
+
+// isReopener method returns true if receiver or any of the multiple
+// receivers implements `Reopener`.
+func isReopener(r Receiver) bool {
+	if m, ok := r.(*MultiReceiver); ok {
+		for _, item := range m.receivers {
+			if isReopener(item.receiver) {
+				return true
+			}
+		}
+		return false
+	}
+	_, ok := r.(Reopener)
+	return ok
+}
+
+// stepLevel method sets the logger level to next verbose or next severe
+// level of the current level, it stays at the bound. For `log.receivers`
+// level of each receiver is stepped.
+func (l *Logger) stepLevel(verbose bool) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	atomic.StoreInt32(&l.level, int32(nextLevel(l.currentLevel(), verbose)))
+	if m, ok := l.receiver.(*MultiReceiver); ok {
+		m.stepLevels(verbose)
+	}
+	l.setMaxLevel()
+}
+
+// nextLevel method returns the next verbose or next severe level of given
+// level, it stays at the bound.
+func nextLevel(lvl level, verbose bool) level {
+	levels := levelsUntil(LevelUnknown - 1)
+	idx := sort.Search(len(levels), func(i int) bool { return levels[i] >= lvl })
+	if verbose && idx < len(levels)-1 {
+		idx++
+	} else if !verbose && idx > 0 {
+		idx--
+	}
+	return levels[idx]
+}
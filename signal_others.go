@@ -0,0 +1,24 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyLevelSignals method relays SIGUSR1 and SIGUSR2 to given channel.
+func notifyLevelSignals(c chan os.Signal) bool {
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+	return true
+}
+
+func isVerboseSignal(s os.Signal) bool {
+	return s == syscall.SIGUSR1
+}
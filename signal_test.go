@@ -0,0 +1,133 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"io/ioutil"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogLevelSignals(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "info"
+    level_signals = true
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.NotNil(t, logger.levelSig)
+
+	currentLevel := func() string {
+		logger.m.RLock()
+		defer logger.m.RUnlock()
+		return logger.Level()
+	}
+	waitLevel := func(expected string) {
+		for i := 0; i < 100 && currentLevel() != expected; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, expected, currentLevel())
+	}
+
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitLevel("DEBUG")
+
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitLevel("INFO")
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitLevel("WARN")
+
+	logger.Close()
+	assert.Nil(t, logger.levelSig)
+}
+
+func TestLogStepLevel(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "trace"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Nil(t, logger.levelSig)
+
+	// Stays at the bound
+	logger.stepLevel(true)
+	assert.Equal(t, "TRACE", logger.Level())
+
+	_ = logger.SetLevel("fatal")
+	logger.stepLevel(false)
+	assert.Equal(t, "FATAL", logger.Level())
+	logger.stepLevel(true)
+	assert.Equal(t, "PANIC", logger.Level())
+
+	// Custom level is stepped too
+	defer levelTab.Store(loadLevels())
+	assert.Nil(t, AddLevel("NOTICE", 35))
+	_ = logger.SetLevel("warn")
+	logger.stepLevel(true)
+	assert.Equal(t, "NOTICE", logger.Level())
+	logger.stepLevel(true)
+	assert.Equal(t, "INFO", logger.Level())
+}
+
+func TestLogStepLevelRace(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "info"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.SetWriter(ioutil.Discard)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logger.stepLevel(i%2 == 0)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		logger.Debug("Yes, I would love to see")
+		_ = logger.IsLevelDebug()
+	}
+	wg.Wait()
+	assert.Equal(t, "INFO", logger.Level())
+}
+
+func TestLogStepLevelReceivers(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    receivers {
+      console {
+        level = "warn"
+      }
+      discard {
+        level = "error"
+      }
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	m := logger.receiver.(*MultiReceiver)
+	assert.Equal(t, LevelWarn, level(logger.maxLevel))
+
+	logger.stepLevel(true)
+	assert.Equal(t, "INFO", m.Level("console"))
+	assert.Equal(t, "WARN", m.Level("discard"))
+	assert.Equal(t, LevelInfo, level(logger.maxLevel))
+	assert.True(t, logger.isMaxLevel(LevelInfo))
+
+	logger.stepLevel(false)
+	logger.stepLevel(false)
+	assert.Equal(t, "ERROR", m.Level("console"))
+	assert.Equal(t, "PANIC", m.Level("discard"))
+	assert.False(t, logger.isMaxLevel(LevelWarn))
+}
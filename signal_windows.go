@@ -0,0 +1,20 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package log
+
+import "os"
+
+// notifyLevelSignals method returns false, Windows doesn't have SIGUSR1
+// and SIGUSR2.
+func notifyLevelSignals(c chan os.Signal) bool {
+	return false
+}
+
+func isVerboseSignal(s os.Signal) bool {
+	return false
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"strings"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+// spillover writes the full value of fields which exceeds the
+// `log.truncate.field` limit into separate receiver, so that one giant
+// payload doesn't break the line-based pipelines. Main entry gets the
+// truncated value and field `spillover_ref`, the same reference ID is
+// logged with full values. For e.g.:
+//
+//	log {
+//	  truncate {
+//	    field = 1024
+//	    spillover {
+//	      # default receiver is file and format is json
+//	      file = "logs/large-values.log"
+//	    }
+//	  }
+//	}
+type spillover struct {
+	receiver Receiver
+}
+
+func newSpillover(cfg *config.Config) (*spillover, error) {
+	name := cfg.StringDefault("log.truncate.spillover.receiver", "file")
+	receiver := getReceiverByName(strings.ToUpper(name))
+	if receiver == nil {
+		return nil, fmt.Errorf("log: unknown spillover receiver '%s'", name)
+	}
+
+	rcfg := config.NewEmpty()
+	rcfg.SetString("log.format", cfg.StringDefault("log.truncate.spillover.format", "json"))
+	if file := cfg.StringDefault("log.truncate.spillover.file", ""); !ess.IsStrEmpty(file) {
+		rcfg.SetString("log.file", file)
+	}
+	if err := receiver.Init(rcfg); err != nil {
+		return nil, err
+	}
+	return &spillover{receiver: receiver}, nil
+}
+
+// log method writes the full values with reference ID of main entry.
+func (s *spillover) log(e *Entry, ref string, values Fields) {
+	se := acquireEntry(e.logger)
+	se.Time = e.Time
+	se.Level = e.Level
+	se.Message = "spillover"
+	se.AppName = e.AppName
+	se.InstanceName = e.InstanceName
+	se.RequestID = e.RequestID
+	se.Principal = e.Principal
+	se.Env = e.Env
+	se.addFields(values)
+	se.Fields["spillover_ref"] = ref
+	s.receiver.Log(se)
+	releaseEntry(se)
+}
+
+func (s *spillover) flush() {
+	if f, ok := s.receiver.(Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (s *spillover) close() {
+	if c, ok := s.receiver.(Closer); ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogTruncateSpillover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spillover")
+	assert.FailNowOnError(t, err, "unexpected error")
+	defer os.RemoveAll(dir)
+
+	spillFile := filepath.Join(dir, "large-values.log")
+	cfg, _ := config.ParseString(`log {
+    format = "json"
+    truncate {
+      field = 8
+      spillover {
+        file = "` + filepath.ToSlash(spillFile) + `"
+      }
+    }
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	payload := strings.Repeat("x", 100)
+	logger.WithFields(Fields{"reqid": "req-1", "payload": payload, "body": []byte(payload), "id": 7}).Info("received")
+	logger.WithField("payload", "small").Info("received")
+	logger.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	var main map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &main))
+	fields := main["fields"].(map[string]interface{})
+	assert.Equal(t, "xxxxxxxx...", fields["payload"])
+	assert.Equal(t, "xxxxxxxx...", fields["body"])
+	assert.Equal(t, true, fields["truncated"])
+	ref, _ := fields["spillover_ref"].(string)
+	assert.Equal(t, 16, len(ref))
+	assert.False(t, strings.Contains(lines[1], "spillover_ref"))
+
+	b, err := ioutil.ReadFile(spillFile)
+	assert.FailNowOnError(t, err, "unexpected error")
+	spilled := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, 1, len(spilled))
+	var spill map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(spilled[0]), &spill))
+	assert.Equal(t, "spillover", spill["message"])
+	assert.Equal(t, "req-1", spill["request_id"])
+	sfields := spill["fields"].(map[string]interface{})
+	assert.Equal(t, ref, sfields["spillover_ref"])
+	assert.Equal(t, payload, sfields["payload"])
+	assert.Equal(t, payload, sfields["body"])
+	assert.Nil(t, sfields["id"])
+
+	// unknown receiver
+	cfg, _ = config.ParseString(`log {
+    truncate {
+      field = 8
+      spillover { receiver = "unknown" }
+    }
+  }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: unknown spillover receiver 'unknown'", err.Error())
+}
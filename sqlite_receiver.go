@@ -0,0 +1,161 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"aahframework.org/config.v0"
+)
+
+var _ Receiver = (*SQLiteReceiver)(nil)
+
+// SQLiteReceiver stores the log entries into local SQLite database file and
+// provides `Query` method to search the log history. Useful for desktop and
+// CLI application. Application has to import the SQLite driver, default
+// driver name is `sqlite3` (github.com/mattn/go-sqlite3). Field match uses
+// SQLite JSON1 function `json_extract`.
+//
+// Configuration is same as `DatabaseReceiver` under `log.sqlite`, database
+// file is `log.sqlite.file` and table is created if not exists.
+type SQLiteReceiver struct {
+	*DatabaseReceiver
+}
+
+// LogQuery holds the criteria to query the log entries from SQLiteReceiver.
+// Zero value of criteria is not applied.
+type LogQuery struct {
+	// Level matches the entries of given level name and above, for e.g.:
+	// `warn` matches WARN, ERROR, PANIC and FATAL entries.
+	Level string
+
+	// From and To is the time range, both inclusive.
+	From time.Time
+	To   time.Time
+
+	// Fields matches the entries having all the given field values.
+	Fields Fields
+
+	// Limit is max no. of entries to return, most recent entries first.
+	Limit int
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// SQLiteReceiver methods
+//___________________________________
+
+// Init method initializes the SQLite receiver instance.
+func (s *SQLiteReceiver) Init(cfg *config.Config) error {
+	s.DatabaseReceiver = &DatabaseReceiver{}
+	if err := s.init(cfg, "sqlite", cfg.StringDefault("log.sqlite.driver", "sqlite3"),
+		cfg.StringDefault("log.sqlite.file", "aah-log.db"), true); err != nil {
+		return err
+	}
+
+	if cfg.BoolDefault("log.sqlite.create_table", true) {
+		if _, err := s.db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s (%s)",
+			s.table, s.columns[1], s.table, s.columns[1])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Query method returns the stored log entries matching the given criteria.
+// Entries still in the queue are not returned, use `Flush` beforehand.
+func (s *SQLiteReceiver) Query(q LogQuery) ([]*Entry, error) {
+	query, args, err := s.buildQuerySQL(q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []*Entry
+	for rows.Next() {
+		var levelName, message, fields string
+		e := &Entry{}
+		if err = rows.Scan(&levelName, &e.Time, &message, &fields); err != nil {
+			return nil, err
+		}
+		e.Level, e.Message = levelByName(levelName), message
+		if err = json.Unmarshal([]byte(fields), &e.Fields); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// Flush method inserts the queued entries into database.
+func (s *SQLiteReceiver) Flush() {
+	s.batcher.flush()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// SQLiteReceiver Unexported methods
+//___________________________________
+
+func (s *SQLiteReceiver) buildQuerySQL(q LogQuery) (string, []interface{}, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+
+	if len(q.Level) > 0 {
+		queryLevel := levelByName(q.Level)
+		if queryLevel == LevelUnknown {
+			return "", nil, fmt.Errorf("log: unknown query level '%s'", q.Level)
+		}
+		var names []string
+		for _, lvl := range levelsUntil(queryLevel) {
+			names = append(names, "?")
+			args = append(args, lvl.String())
+		}
+		where = append(where, fmt.Sprintf("%s IN (%s)", s.columns[0], strings.Join(names, ", ")))
+	}
+
+	if !q.From.IsZero() {
+		where = append(where, s.columns[1]+" >= ?")
+		args = append(args, q.From.UTC())
+	}
+
+	if !q.To.IsZero() {
+		where = append(where, s.columns[1]+" <= ?")
+		args = append(args, q.To.UTC())
+	}
+
+	// sorted, to have the predictable query
+	keys := make([]string, 0, len(q.Fields))
+	for k := range q.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		where = append(where, fmt.Sprintf("json_extract(%s, ?) = ?", s.columns[3]))
+		args = append(args, `$."`+strings.Replace(k, `"`, `\"`, -1)+`"`, q.Fields[k])
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(s.columns, ", "), s.table)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY " + s.columns[1] + " DESC"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	return query, args, nil
+}
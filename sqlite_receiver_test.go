@@ -0,0 +1,73 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestSQLiteLogger(t *testing.T) {
+	testDB.reset()
+	cfg, _ := config.ParseString(`log { receiver = "sqlite", sqlite { driver = "aahlogtest", file = "app-log.db", flush_interval = "1h" } }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*SQLiteReceiver)
+	defer receiver.Close()
+
+	logger.WithField("user", "jeeva").Warn("Yes, yes it's an warning")
+	receiver.Flush()
+
+	testDB.mu.Lock()
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS aah_logs (level VARCHAR(8) NOT NULL, time TIMESTAMP NOT NULL, message TEXT, fields TEXT)", testDB.queries[0])
+	assert.Equal(t, "CREATE INDEX IF NOT EXISTS aah_logs_time_idx ON aah_logs (time)", testDB.queries[1])
+	assert.Equal(t, 1, len(testDB.rows))
+	assert.Equal(t, "WARN", testDB.rows[0][0])
+
+	now := time.Date(2017, 6, 2, 10, 30, 0, 0, time.UTC)
+	testDB.result = [][]driver.Value{
+		{"ERROR", now, "Yes, yes, yes - finally an error", `{"user":"jeeva"}`},
+		{"WARN", now.Add(-time.Minute), "Yes, yes it's an warning", `{"user":"jeeva"}`},
+	}
+	testDB.mu.Unlock()
+
+	entries, err := receiver.Query(LogQuery{
+		Level:  "warn",
+		From:   now.Add(-time.Hour),
+		To:     now,
+		Fields: Fields{"user": "jeeva"},
+		Limit:  10,
+	})
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, LevelError, entries[0].Level)
+	assert.Equal(t, now, entries[0].Time)
+	assert.Equal(t, "Yes, yes, yes - finally an error", entries[0].Message)
+	assert.Equal(t, "jeeva", entries[1].Fields["user"])
+
+	testDB.mu.Lock()
+	defer testDB.mu.Unlock()
+	assert.Equal(t, "SELECT level, time, message, fields FROM aah_logs WHERE level IN (?, ?, ?, ?) "+
+		"AND time >= ? AND time <= ? AND json_extract(fields, ?) = ? ORDER BY time DESC LIMIT 10",
+		testDB.queries[len(testDB.queries)-1])
+	assert.Equal(t, []driver.Value{"FATAL", "PANIC", "ERROR", "WARN",
+		now.Add(-time.Hour), now, `$."user"`, "jeeva"}, testDB.args)
+}
+
+func TestSQLiteLoggerQueryError(t *testing.T) {
+	testDB.reset()
+	cfg, _ := config.ParseString(`log { receiver = "sqlite", sqlite { driver = "aahlogtest" } }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*SQLiteReceiver)
+	defer receiver.Close()
+
+	_, err = receiver.Query(LogQuery{Level: "verbose"})
+	assert.Equal(t, "log: unknown query level 'verbose'", err.Error())
+}
@@ -0,0 +1,282 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const (
+	rfc3164 = "3164"
+	rfc5424 = "5424"
+)
+
+var (
+	// syslogFacilities is the list of syslog facility names and its code
+	syslogFacilities = map[string]int{
+		"kern":     0,
+		"user":     1,
+		"mail":     2,
+		"daemon":   3,
+		"auth":     4,
+		"syslog":   5,
+		"lpr":      6,
+		"news":     7,
+		"uucp":     8,
+		"cron":     9,
+		"authpriv": 10,
+		"ftp":      11,
+		"local0":   16,
+		"local1":   17,
+		"local2":   18,
+		"local3":   19,
+		"local4":   20,
+		"local5":   21,
+		"local6":   22,
+		"local7":   23,
+	}
+
+	// syslogSeverities is the list of syslog severity names and its code
+	syslogSeverities = map[string]int{
+		"emerg":   0,
+		"alert":   1,
+		"crit":    2,
+		"err":     3,
+		"warning": 4,
+		"notice":  5,
+		"info":    6,
+		"debug":   7,
+	}
+
+	// levelToSyslogSeverity is default mapping of aah log level to syslog severity
+	levelToSyslogSeverity = map[level]string{
+		LevelFatal: "crit",
+		LevelPanic: "alert",
+		LevelError: "err",
+		LevelWarn:  "warning",
+		LevelInfo:  "info",
+		LevelDebug: "debug",
+		LevelTrace: "debug",
+	}
+
+	// syslogLocalAddrs is the list of well-known local syslog socket paths
+	syslogLocalAddrs = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+	_ Receiver = (*SyslogReceiver)(nil)
+)
+
+// SyslogReceiver writes the log entry into syslog daemon. It supports local
+// unix socket and remote UDP/TCP targets with RFC 3164 or RFC 5424 framing.
+type SyslogReceiver struct {
+	errorReporter
+	network      string
+	address      string
+	rfc          string
+	tag          string
+	hostname     string
+	facility     int
+	severities   map[level]int
+	conn         net.Conn
+	isStream     bool
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.Mutex
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// SyslogReceiver methods
+//___________________________________
+
+// Init method initializes the syslog receiver instance.
+func (s *SyslogReceiver) Init(cfg *config.Config) error {
+	s.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(s.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", s.formatter)
+	}
+
+	s.network = strings.ToLower(cfg.StringDefault("log.syslog.network", ""))
+	s.address = cfg.StringDefault("log.syslog.address", "")
+	if !ess.IsStrEmpty(s.network) && ess.IsStrEmpty(s.address) {
+		return fmt.Errorf("log: syslog address is required for network '%s'", s.network)
+	}
+
+	s.rfc = cfg.StringDefault("log.syslog.rfc", rfc5424)
+	if !(s.rfc == rfc3164 || s.rfc == rfc5424) {
+		return fmt.Errorf("log: unsupported syslog rfc '%s'", s.rfc)
+	}
+
+	facility := strings.ToLower(cfg.StringDefault("log.syslog.facility", "local0"))
+	code, found := syslogFacilities[facility]
+	if !found {
+		return fmt.Errorf("log: unknown syslog facility '%s'", facility)
+	}
+	s.facility = code
+
+	s.severities = make(map[level]int)
+	for lvl, name := range levelToSyslogSeverity {
+		name = strings.ToLower(cfg.StringDefault("log.syslog.severity."+strings.ToLower(lvl.String()), name))
+		code, found := syslogSeverities[name]
+		if !found {
+			return fmt.Errorf("log: unknown syslog severity '%s'", name)
+		}
+		s.severities[lvl] = code
+	}
+
+	s.tag = cfg.StringDefault("log.syslog.tag", filepath.Base(os.Args[0]))
+	s.hostname = cfg.StringDefault("log.syslog.hostname", "")
+	if ess.IsStrEmpty(s.hostname) {
+		s.hostname, _ = os.Hostname()
+	}
+
+	s.mu = sync.Mutex{}
+
+	return s.connect()
+}
+
+// SetPattern method initializes the logger format pattern.
+func (s *SyslogReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	s.flags = flags
+	if s.formatter != jsonFmt {
+		s.isCallerInfo = isCallerInfo(s.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into syslog receiver.
+func (s *SyslogReceiver) SetWriter(w io.Writer) {
+	s.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (s *SyslogReceiver) IsCallerInfo() bool {
+	return s.isCallerInfo
+}
+
+// Log method writes the log entry into syslog daemon.
+func (s *SyslogReceiver) Log(entry *Entry) {
+	s.mu.Lock()
+	err := s.write(s.frame(entry))
+	s.mu.Unlock()
+	if err != nil {
+		s.reportError(err, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (s *SyslogReceiver) Writer() io.Writer {
+	return s.out
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// SyslogReceiver Unexported methods
+//___________________________________
+
+// write method writes the message into daemon, connection might be dropped
+// by daemon, so it's reconnected and retried once.
+func (s *SyslogReceiver) write(msg []byte) error {
+	_, err := s.out.Write(msg)
+	if err != nil && s.conn != nil && s.out == s.conn {
+		ess.CloseQuietly(s.conn)
+		if err = s.connect(); err == nil {
+			_, err = s.out.Write(msg)
+		}
+	}
+	return err
+}
+
+func (s *SyslogReceiver) connect() error {
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	if ess.IsStrEmpty(s.network) {
+		conn, err = s.dialLocal()
+	} else {
+		conn, err = net.DialTimeout(s.network, s.address, 5*time.Second)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	network := conn.RemoteAddr().Network()
+	s.isStream = network == "tcp" || network == "unix"
+	s.SetWriter(conn)
+	return nil
+}
+
+func (s *SyslogReceiver) dialLocal() (net.Conn, error) {
+	addrs := syslogLocalAddrs
+	if !ess.IsStrEmpty(s.address) {
+		addrs = []string{s.address}
+	}
+
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, addr := range addrs {
+			if conn, err := net.Dial(network, addr); err == nil {
+				return conn, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("log: unable to connect local syslog daemon %v", addrs)
+}
+
+// frame method composes syslog message as per configured RFC.
+// 	RFC 3164: <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG
+// 	RFC 5424: <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+func (s *SyslogReceiver) frame(entry *Entry) []byte {
+	buf := acquireBuffer()
+	defer releaseBuffer(buf)
+
+	pri := s.facility*8 + s.severities[builtinLevel(entry.Level)]
+	msg := bytes.TrimRight(formatEntry(s.formatter, s.flags, entry), " \n")
+	if s.rfc == rfc3164 {
+		_, _ = fmt.Fprintf(buf, "<%d>%s %s %s[%d]: ", pri, entry.Time.Format(time.Stamp),
+			s.hostname, s.tag, os.Getpid())
+	} else {
+		_, _ = fmt.Fprintf(buf, "<%d>1 %s %s %s %d - - ", pri, entry.Time.Format(time.RFC3339Nano),
+			nilValue(s.hostname), nilValue(s.tag), os.Getpid())
+	}
+	_, _ = buf.Write(msg)
+
+	if !s.isStream {
+		return append([]byte(nil), buf.Bytes()...)
+	}
+
+	// RFC 6587 framing for stream transports, octet counting for RFC 5424
+	// and non-transparent (newline) framing for RFC 3164.
+	if s.rfc == rfc5424 {
+		return append([]byte(strconv.Itoa(buf.Len())+space), buf.Bytes()...)
+	}
+	return append(append([]byte(nil), buf.Bytes()...), '\n')
+}
+
+func nilValue(v string) string {
+	if ess.IsStrEmpty(v) {
+		return "-"
+	}
+	return strings.Replace(v, space, "_", -1)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestSyslogLoggerUDP5424(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen udp")
+	defer func() { _ = pc.Close() }()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "syslog"
+    level = "debug"
+    pattern = "%%level:-5 %%message"
+    syslog {
+      network = "udp"
+      address = "%s"
+      facility = "local1"
+      tag = "aahapp"
+      hostname = "aah-host"
+    }
+  }
+  `, pc.LocalAddr().String())
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.Info("Yes, I would love to see")
+	msg := readSyslogPacket(t, pc)
+	assert.True(t, strings.HasPrefix(msg, "<142>1 "))
+	assert.True(t, strings.Contains(msg, " aah-host aahapp "))
+	assert.True(t, strings.HasSuffix(msg, " - - INFO  Yes, I would love to see"))
+
+	logger.Error("Yes, yes, yes - finally an error")
+	assert.True(t, strings.HasPrefix(readSyslogPacket(t, pc), "<139>1 "))
+}
+
+func TestSyslogLoggerTCP3164(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.FailNowOnError(t, err, "unable to listen tcp")
+	defer func() { _ = ln.Close() }()
+
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			line, _ := r.ReadString('\n')
+			lines <- line
+		}
+	}()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "syslog"
+    level = "debug"
+    pattern = "%%message"
+    syslog {
+      network = "tcp"
+      address = "%s"
+      rfc = "3164"
+      tag = "aahapp"
+      hostname = "aah-host"
+      severity {
+        warn = "notice"
+      }
+    }
+  }
+  `, ln.Addr().String())
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.Warn("Yes, yes it's an warning")
+	logger.Debug("I would like to see this message, debug is useful for dev")
+
+	line := <-lines
+	assert.True(t, strings.HasPrefix(line, "<133>"))
+	assert.True(t, strings.Contains(line, " aah-host aahapp["))
+	assert.True(t, strings.HasSuffix(line, "]: Yes, yes it's an warning\n"))
+	assert.True(t, strings.HasPrefix(<-lines, "<135>"))
+}
+
+func TestSyslogLoggerConfigErrors(t *testing.T) {
+	testcases := []struct {
+		cfg string
+		err string
+	}{
+		{cfg: `syslog { network = "udp" }`, err: "log: syslog address is required for network 'udp'"},
+		{cfg: `syslog { network = "udp", address = "127.0.0.1:514", rfc = "1234" }`, err: "log: unsupported syslog rfc '1234'"},
+		{cfg: `syslog { network = "udp", address = "127.0.0.1:514", facility = "myfacility" }`, err: "log: unknown syslog facility 'myfacility'"},
+		{cfg: `syslog { network = "udp", address = "127.0.0.1:514", severity { info = "loud" } }`, err: "log: unknown syslog severity 'loud'"},
+		{cfg: `format = "xml"`, err: "log: unsupported format 'xml'"},
+	}
+
+	for _, tc := range testcases {
+		cfg, _ := config.ParseString(`log { receiver = "syslog"
+		` + tc.cfg + ` }`)
+		logger, err := New(cfg)
+		assert.Nil(t, logger)
+		assert.Equal(t, tc.err, err.Error())
+	}
+}
+
+func readSyslogPacket(t *testing.T, pc net.PacketConn) string {
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.FailNowOnError(t, err, "unable to read syslog packet")
+	return string(buf[:n])
+}
@@ -0,0 +1,25 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"aahframework.org/config.v0"
+)
+
+var _ Receiver = (*UnixReceiver)(nil)
+
+// UnixReceiver writes the log entry into Unix domain socket, stream (`unix`)
+// or datagram (`unixgram`). It's handy for sidecar log collectors which
+// exposes local socket. It has same reconnect and buffering behavior of
+// `NetworkReceiver`, configured under `log.unix` section.
+type UnixReceiver struct {
+	NetworkReceiver
+}
+
+// Init method initializes the unix socket receiver instance.
+func (u *UnixReceiver) Init(cfg *config.Config) error {
+	u.section = "unix"
+	return u.NetworkReceiver.Init(cfg)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestUnixLoggerStream(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "aahlog")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	socket := filepath.Join(dir, "collector.sock")
+	ln, err := net.Listen("unix", socket)
+	assert.FailNowOnError(t, err, "unable to listen unix")
+	defer func() { _ = ln.Close() }()
+	lines := acceptLines(ln, 1)
+
+	logger := newUnixLogger(t, "unix", socket)
+	logger.Info("Yes, I would love to see")
+	assert.Equal(t, "INFO  Yes, I would love to see \n", readLine(t, lines))
+}
+
+func TestUnixLoggerDatagram(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "aahlog")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	socket := filepath.Join(dir, "collector.sock")
+	pc, err := net.ListenPacket("unixgram", socket)
+	assert.FailNowOnError(t, err, "unable to listen unixgram")
+	defer func() { _ = pc.Close() }()
+
+	logger := newUnixLogger(t, "unixgram", socket)
+	logger.Warn("Yes, yes it's an warning")
+
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.FailNowOnError(t, err, "unable to read unixgram packet")
+	assert.Equal(t, "WARN  Yes, yes it's an warning \n", string(buf[:n]))
+}
+
+func TestUnixLoggerConfigErrors(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "unix", unix { protocol = "tcp" } }`)
+	logger, err := New(cfg)
+	assert.Nil(t, logger)
+	assert.Equal(t, "log: unsupported unix protocol 'tcp'", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "unix" }`)
+	logger, err = New(cfg)
+	assert.Nil(t, logger)
+	assert.Equal(t, "log: unix address is required", err.Error())
+}
+
+func newUnixLogger(t *testing.T, protocol, socket string) *Logger {
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "unix"
+    pattern = "%%level:-5 %%message"
+    unix {
+      protocol = "%s"
+      address = "%s"
+    }
+  }
+  `, protocol, socket)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	return logger
+}
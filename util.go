@@ -5,10 +5,24 @@
 package log
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"aahframework.org/config.v0"
 	"aahframework.org/essentials.v0"
 )
 
@@ -32,25 +46,200 @@ var (
 		LevelDebug: "DEBUG",
 		LevelTrace: "TRACE",
 	}
+
+	// levelTab holds the current `*levelTable`, custom level is added on a
+	// copy and stored, so level lookup on each entry is lock free.
+	levelTab      atomic.Value
+	levelMu       sync.Mutex
+	builtinLevels = &levelTable{byName: levelNameToLevel, byValue: levelToLevelName}
+
+	// receiverFactories are the receivers resolvable by name from config,
+	// name is in upper case
+	receiverFactories = map[string]ReceiverFactory{
+		"FILE":          func() Receiver { return &FileReceiver{} },
+		"CONSOLE":       func() Receiver { return &ConsoleReceiver{} },
+		"SYSLOG":        func() Receiver { return &SyslogReceiver{} },
+		"JOURNAL":       func() Receiver { return &JournalReceiver{} },
+		"NETWORK":       func() Receiver { return &NetworkReceiver{} },
+		"UNIX":          func() Receiver { return &UnixReceiver{} },
+		"KAFKA":         func() Receiver { return &KafkaReceiver{} },
+		"NATS":          func() Receiver { return &NatsReceiver{} },
+		"REDIS":         func() Receiver { return &RedisReceiver{} },
+		"FLUENT":        func() Receiver { return &FluentReceiver{} },
+		"LOKI":          func() Receiver { return &LokiReceiver{} },
+		"ELASTICSEARCH": func() Receiver { return &ElasticsearchReceiver{} },
+		"CLOUDWATCH":    func() Receiver { return &CloudWatchReceiver{} },
+		"CLOUDLOGGING":  func() Receiver { return &CloudLoggingReceiver{} },
+		"LOGANALYTICS":  func() Receiver { return &LogAnalyticsReceiver{} },
+		"SENTRY":        func() Receiver { return &SentryReceiver{} },
+		"WEBHOOK":       func() Receiver { return &WebhookReceiver{} },
+		"EMAIL":         func() Receiver { return &EmailReceiver{} },
+		"DATABASE":      func() Receiver { return &DatabaseReceiver{} },
+		"SQLITE":        func() Receiver { return &SQLiteReceiver{} },
+		"S3":            func() Receiver { return &S3Receiver{} },
+		"DISCARD":       func() Receiver { return &DiscardReceiver{} },
+		"HTTP":          func() Receiver { return &HTTPReceiver{} },
+		"RING":          func() Receiver { return &RingReceiver{} },
+		"WEBSOCKET":     func() Receiver { return &WebSocketReceiver{} },
+		"GRPC":          func() Receiver { return &GRPCReceiver{} },
+	}
+	receiverMu = &sync.RWMutex{}
+
+	// formatters are the formatters resolvable by name from config
+	formatters = map[string]Formatter{
+		textFmt:     FormatterFunc(textFormatter),
+		jsonFmt:     FormatterFunc(jsonFormatter),
+		logfmtFmt:   FormatterFunc(logfmtFormatter),
+		prettyFmt:   FormatterFunc(prettyJSONFormatter),
+		cefFmt:      FormatterFunc(cefFormatter),
+		ecsFmt:      FormatterFunc(ecsFormatter),
+		gelfFmt:     FormatterFunc(gelfFormatter),
+		rfc5424Fmt:  FormatterFunc(rfc5424Formatter),
+		msgpackFmt:  FormatterFunc(msgpackFormatter),
+		protoFmt:    FormatterFunc(protobufFormatter),
+		cborFmt:     FormatterFunc(cborFormatter),
+		csvFmt:      FormatterFunc(csvFormatter),
+		gcpFmt:      FormatterFunc(gcpFormatter),
+		otelFmt:     FormatterFunc(otelFormatter),
+		clfFmt:      FormatterFunc(clfFormatter),
+		combinedFmt: FormatterFunc(combinedFormatter),
+	}
+	formatterMu = &sync.RWMutex{}
+
+	// compressors are the rotated file compressors resolvable by name from config
+	compressors = map[string]*compressor{
+		"gzip": {ext: ".gz", fn: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		}},
+	}
+	compressorMu = &sync.RWMutex{}
+
+	// keyProviders are the encryption key providers resolvable by name from
+	// config
+	keyProviders  = make(map[string]KeyFunc)
+	keyProviderMu = &sync.RWMutex{}
 )
 
+// compressor holds the registered compressor func with file extension.
+type compressor struct {
+	ext string
+	fn  CompressorFunc
+}
+
+// levelTable holds the level name and value mappings, it's read-only once
+// stored into `levelTab`.
+type levelTable struct {
+	byName  map[string]level
+	byValue map[level]string
+}
+
+// errorReporter is embedded into the receivers to report the delivery
+// errors to the func set by `OnError`.
+type errorReporter struct {
+	errMu   sync.RWMutex
+	errorFn ErrorHandlerFunc
+}
+
+// OnError method sets the func, which is called when entry delivery fails.
+func (r *errorReporter) OnError(fn ErrorHandlerFunc) {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	r.errorFn = fn
+}
+
+// reportError method calls the error func, if it's set. Entry is nil for
+// the failure of batch delivery.
+func (r *errorReporter) reportError(err error, e *Entry) {
+	r.errMu.RLock()
+	fn := r.errorFn
+	r.errMu.RUnlock()
+	if fn != nil {
+		fn(err, e)
+	}
+}
+
+// writerFunc type is an adapter to allow the use of ordinary function
+// as `io.Writer`.
+type writerFunc func(p []byte) (int, error)
+
+// Write method calls fn(p).
+func (fn writerFunc) Write(p []byte) (int, error) {
+	return fn(p)
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Unexported methods
 //___________________________________
 
 // String level string interface.
 func (l level) String() string {
-	return levelToLevelName[l]
+	return loadLevels().byValue[l]
 }
 
 func levelByName(name string) level {
-	if level, ok := levelNameToLevel[strings.ToUpper(name)]; ok {
+	if level, ok := loadLevels().byName[strings.ToUpper(name)]; ok {
 		return level
 	}
 
 	return LevelUnknown
 }
 
+// loadLevels method returns the current level table.
+func loadLevels() *levelTable {
+	if t, ok := levelTab.Load().(*levelTable); ok {
+		return t
+	}
+	return builtinLevels
+}
+
+// levelsUntil method returns the levels including custom levels, which are
+// at or above the given level, most severe first.
+func levelsUntil(lvl level) []level {
+	var levels []level
+	for l := range loadLevels().byValue {
+		if l <= lvl {
+			levels = append(levels, l)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+	return levels
+}
+
+// builtinLevel method returns the given level if it's built-in otherwise
+// next less severe built-in level of the custom level, it's used for
+// severity mapping of formatters and receivers.
+func builtinLevel(lvl level) level {
+	switch {
+	case lvl == LevelUnknown:
+		return lvl
+	case lvl >= LevelTrace:
+		return LevelTrace
+	}
+	return (lvl + 9) / 10 * 10
+}
+
+// levelFromEnv method returns the level name from env variable
+// `AAH_LOG_LEVEL` for logger or `AAH_LOG_<NAME>_LEVEL` for the receiver name,
+// characters other than letters and digits are replaced with `_`. Unknown
+// level is reported on os.Stderr and ignored, so configured level is used.
+func levelFromEnv(receiver string) string {
+	key := "AAH_LOG_LEVEL"
+	if len(receiver) > 0 {
+		key = "AAH_LOG_" + strings.Map(func(r rune) rune {
+			if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return '_'
+		}, strings.ToUpper(receiver)) + "_LEVEL"
+	}
+	name := strings.TrimSpace(os.Getenv(key))
+	if len(name) > 0 && levelByName(name) == LevelUnknown {
+		fmt.Fprintf(os.Stderr, "log: unknown level '%s' in env variable '%s', ignored\n", name, key)
+		return ""
+	}
+	return name
+}
+
 func isFmtFlagExists(flags []ess.FmtFlagPart, flag ess.FmtFlag) bool {
 	for _, f := range flags {
 		if f.Flag == flag {
@@ -88,6 +277,31 @@ func fetchCallerInfo() (string, int) {
 	}
 }
 
+// fetchCallerPackage method returns the package path of the caller outside
+// of log pkg, for e.g.: `aahframework.org/security`.
+func fetchCallerPackage() string {
+	pc := make([]uintptr, 8)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.File, "aahframework.org/log") || !more {
+			return packageName(frame.Function)
+		}
+	}
+}
+
+// packageName method returns the package path from the function name, for
+// e.g.: `aahframework.org/security.(*Manager).Init`. Dot in the last path
+// element is escaped as `%2e` by the compiler.
+func packageName(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	if i := strings.IndexByte(fn[slash+1:], '.'); i >= 0 {
+		fn = fn[:slash+1+i]
+	}
+	return strings.Replace(fn, "%2e", ".", -1)
+}
+
 // isCallerInfo method to identify to fetch caller or not.
 func isCallerInfo(flags []ess.FmtFlagPart) bool {
 	return (isFmtFlagExists(flags, FmtFlagShortfile) ||
@@ -95,15 +309,44 @@ func isCallerInfo(flags []ess.FmtFlagPart) bool {
 		isFmtFlagExists(flags, FmtFlagLine))
 }
 
+// isGoroutineIDPattern method returns true if pattern has `goid` flag.
+func isGoroutineIDPattern(pattern string) bool {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	return err == nil && isFmtFlagExists(flags, FmtFlagGoroutineID)
+}
+
+// goroutineID method returns the current goroutine ID parsed from stack
+// header `goroutine 18 [running]:`.
+func goroutineID() uint64 {
+	b := make([]byte, 64)
+	b = b[:runtime.Stack(b, false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		id, _ := strconv.ParseUint(string(b[:i]), 10, 64)
+		return id
+	}
+	return 0
+}
+
+func getCompressorByName(name string) *compressor {
+	compressorMu.RLock()
+	defer compressorMu.RUnlock()
+	return compressors[name]
+}
+
+func getKeyProviderByName(name string) KeyFunc {
+	keyProviderMu.RLock()
+	defer keyProviderMu.RUnlock()
+	return keyProviders[name]
+}
+
 func getReceiverByName(name string) Receiver {
-	switch name {
-	case "FILE":
-		return &FileReceiver{}
-	case "CONSOLE":
-		return &ConsoleReceiver{}
-	default:
-		return nil
+	receiverMu.RLock()
+	defer receiverMu.RUnlock()
+	if factory, found := receiverFactories[name]; found {
+		return factory()
 	}
+	return nil
 }
 
 func formatTime(t time.Time) string {
@@ -112,3 +355,84 @@ func formatTime(t time.Time) string {
 	}
 	return t.Format(time.RFC3339)
 }
+
+// truncateString method returns the string truncated to given max bytes on
+// UTF-8 character boundary with `...` marker.
+func truncateString(s string, max int) string {
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max] + "..."
+}
+
+// randomID method returns the hex encoded random ID of given bytes.
+func randomID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// parseDuration method parses the duration value of given config key,
+// default value is used if key not exists.
+func parseDuration(cfg *config.Config, key, defaultValue string) (time.Duration, error) {
+	value := cfg.StringDefault(key, defaultValue)
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("log: invalid duration '%s' for '%s'", value, key)
+	}
+	return d, nil
+}
+
+// parseInterval method parses the ticker interval of given key, it has to
+// be positive.
+func parseInterval(cfg *config.Config, key, defaultValue string) (time.Duration, error) {
+	d, err := parseDuration(cfg, key, defaultValue)
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("log: invalid interval '%s' for '%s'", cfg.StringDefault(key, defaultValue), key)
+	}
+	return d, err
+}
+
+// tokenRune method replaces the rune which is not allowed in the dot
+// separated token, i.e. separators, wildcards, whitespace and control
+// characters. Otherwise field value could inject protocol commands.
+func tokenRune(r rune) rune {
+	switch {
+	case r == '.', r == '*', r == '>', r == utf8.RuneError,
+		unicode.IsSpace(r), unicode.IsControl(r):
+		return '_'
+	}
+	return r
+}
+
+// expandEntryTemplate method resolves the placeholders `{level}` and
+// `{<field name>}` from entry. Resolved value is made safe to use as dot
+// separated token, for e.g.: NATS subject, Fluentd tag.
+func expandEntryTemplate(tmpl string, entry *Entry) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+
+	var buf bytes.Buffer
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		end := strings.IndexByte(tmpl, '}')
+		if start == -1 || end < start {
+			buf.WriteString(tmpl)
+			return buf.String()
+		}
+		buf.WriteString(tmpl[:start])
+
+		var value string
+		if key := tmpl[start+1 : end]; key == "level" {
+			value = strings.ToLower(entry.Level.String())
+		} else {
+			value = entry.Fields.str(key)
+		}
+		if len(value) == 0 {
+			value = "-"
+		}
+		buf.WriteString(strings.Map(tokenRune, value))
+		tmpl = tmpl[end+1:]
+	}
+}
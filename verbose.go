@@ -0,0 +1,78 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import "sync/atomic"
+
+// Verbose type is returned by `Logger.V`, it logs the DEBUG and TRACE
+// entries only if its verbosity is enabled. For e.g.:
+//
+//	logger.V(2).Trace("cache lookup ", key)
+//
+//	if v := logger.V(4); v.Enabled() {
+//		v.Tracef("cache entries: %v", dumpEntries())
+//	}
+type Verbose struct {
+	logger  *Logger
+	enabled bool
+}
+
+// V method returns the `Verbose` of given verbosity, it's enabled if
+// verbosity is at or below the logger verbosity `log.verbosity` (default
+// 0). It's for gradual detail of DEBUG and TRACE entries, for e.g.: 1 to 5.
+func (l *Logger) V(verbosity int) Verbose {
+	return Verbose{logger: l, enabled: int32(verbosity) <= atomic.LoadInt32(&l.verbosity)}
+}
+
+// Verbosity method returns the logger verbosity.
+func (l *Logger) Verbosity() int {
+	return int(atomic.LoadInt32(&l.verbosity))
+}
+
+// SetVerbosity method sets the logger verbosity for `Logger.V`.
+func (l *Logger) SetVerbosity(verbosity int) {
+	atomic.StoreInt32(&l.verbosity, int32(verbosity))
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Verbose methods
+//___________________________________
+
+// Enabled method returns true if verbosity is enabled.
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+// Debug logs message as `DEBUG` if verbosity is enabled. Arguments handled
+// in the mananer of `fmt.Print`.
+func (v Verbose) Debug(args ...interface{}) {
+	if v.enabled {
+		v.logger.Debug(args...)
+	}
+}
+
+// Debugf logs message as `DEBUG` if verbosity is enabled. Arguments handled
+// in the mananer of `fmt.Printf`.
+func (v Verbose) Debugf(format string, args ...interface{}) {
+	if v.enabled {
+		v.logger.Debugf(format, args...)
+	}
+}
+
+// Trace logs message as `TRACE` if verbosity is enabled. Arguments handled
+// in the mananer of `fmt.Print`.
+func (v Verbose) Trace(args ...interface{}) {
+	if v.enabled {
+		v.logger.Trace(args...)
+	}
+}
+
+// Tracef logs message as `TRACE` if verbosity is enabled. Arguments handled
+// in the mananer of `fmt.Printf`.
+func (v Verbose) Tracef(format string, args ...interface{}) {
+	if v.enabled {
+		v.logger.Tracef(format, args...)
+	}
+}
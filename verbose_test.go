@@ -0,0 +1,70 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogVerbosity(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    level = "trace"
+    pattern = "%level:-5 %message"
+    verbosity = 2
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, 2, logger.Verbosity())
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.V(1).Debug("v1 debug")
+	logger.V(2).Tracef("v%d trace", 2)
+	logger.V(3).Trace("v3 trace")
+	logger.V(3).Debugf("v%d debug", 3)
+	assert.False(t, logger.V(5).Enabled())
+	assert.True(t, logger.V(0).Enabled())
+	assert.Equal(t, "DEBUG v1 debug \nTRACE v2 trace \n", buf.String())
+
+	buf.Reset()
+	logger.SetVerbosity(5)
+	logger.V(5).Trace("v5 trace")
+	logger.V(5).Debug("v5 debug")
+	assert.Equal(t, "TRACE v5 trace \nDEBUG v5 debug \n", buf.String())
+
+	// logger level is still applied
+	buf.Reset()
+	_ = logger.SetLevel("debug")
+	logger.V(1).Trace("not logged")
+	assert.Equal(t, "", buf.String())
+}
+
+func TestLogVerbosityRace(t *testing.T) {
+	cfg, _ := config.ParseString(`log { level = "trace" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	logger.SetWriter(ioutil.Discard)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logger.SetVerbosity(i % 5)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		logger.V(2).Trace("verbose trace")
+	}
+	wg.Wait()
+	logger.SetVerbosity(3)
+	assert.Equal(t, 3, logger.Verbosity())
+}
@@ -0,0 +1,251 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var (
+	// webhookPresets are the body templates and default URL of well-known
+	// webhook services.
+	webhookPresets = map[string]struct {
+		url      string
+		template string
+	}{
+		"slack":   {template: `{"text":{{json .Formatted}}}`},
+		"teams":   {template: `{"text":{{json .Formatted}}}`},
+		"discord": {template: `{"content":{{json .Formatted}}}`},
+		"pagerduty": {
+			url: "https://events.pagerduty.com/v2/enqueue",
+			template: `{"routing_key":{{json .Params.routing_key}},"event_action":"trigger",` +
+				`"payload":{"summary":{{json .Message}},"severity":{{json .Severity}},"source":{{json .Hostname}},` +
+				`"timestamp":{{json .Time}},"custom_details":{{json .Fields}}}}`,
+		},
+	}
+
+	webhookDefaultTemplate = `{"level":{{json .Level}},"time":{{json .Time}},"message":{{json .Message}},"fields":{{json .Fields}}}`
+
+	levelToWebhookSeverity = map[level]string{
+		LevelFatal: "critical",
+		LevelPanic: "critical",
+		LevelError: "error",
+		LevelWarn:  "warning",
+		LevelInfo:  "info",
+		LevelDebug: "info",
+		LevelTrace: "info",
+	}
+
+	_ Receiver = (*WebhookReceiver)(nil)
+)
+
+// WebhookReceiver posts the log entry of configured level and above to the
+// webhook URL. Request body is created from template, presets are available
+// for Slack, Microsoft Teams, Discord and PagerDuty Events API v2.
+//
+// Template data has `Level`, `Severity`, `Time`, `Message`, `Formatted` (entry
+// formatted with log pattern), `Fields`, `Hostname` and `Params` (values of
+// `log.webhook.params`). Template func `json` encodes the value as JSON.
+type WebhookReceiver struct {
+	errorReporter
+	url          string
+	level        level
+	tmpl         *template.Template
+	header       http.Header
+	params       map[string]string
+	hostname     string
+	sender       *httpSender
+	batcher      *batcher
+	out          io.Writer
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+}
+
+type webhookData struct {
+	Level     string
+	Severity  string
+	Time      string
+	Message   string
+	Formatted string
+	Fields    Fields
+	Hostname  string
+	Params    map[string]string
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// WebhookReceiver methods
+//___________________________________
+
+// Init method initializes the webhook receiver instance.
+func (wh *WebhookReceiver) Init(cfg *config.Config) error {
+	body := webhookDefaultTemplate
+	if name := strings.ToLower(cfg.StringDefault("log.webhook.preset", "")); len(name) > 0 {
+		preset, found := webhookPresets[name]
+		if !found {
+			return fmt.Errorf("log: unsupported webhook preset '%s'", name)
+		}
+		wh.url, body = preset.url, preset.template
+	}
+	body = cfg.StringDefault("log.webhook.template", body)
+
+	wh.url = cfg.StringDefault("log.webhook.url", wh.url)
+	if ess.IsStrEmpty(wh.url) {
+		return errors.New("log: webhook url is required")
+	}
+
+	levelName := cfg.StringDefault("log.webhook.level", "trace")
+	if wh.level = levelByName(levelName); wh.level == LevelUnknown {
+		return fmt.Errorf("log: unknown webhook level '%s'", levelName)
+	}
+
+	var err error
+	wh.tmpl, err = template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(body)
+	if err != nil {
+		return fmt.Errorf("log: webhook template: %s", err)
+	}
+
+	wh.header = http.Header{}
+	wh.header.Set("Content-Type", cfg.StringDefault("log.webhook.content_type", "application/json"))
+	for _, k := range cfg.KeysByPath("log.webhook.headers") {
+		wh.header.Set(k, cfg.StringDefault("log.webhook.headers."+k, ""))
+	}
+	wh.params = make(map[string]string)
+	for _, k := range cfg.KeysByPath("log.webhook.params") {
+		wh.params[k] = cfg.StringDefault("log.webhook.params."+k, "")
+	}
+	wh.hostname, _ = os.Hostname()
+
+	if wh.sender, err = newHTTPSender(cfg, "webhook"); err != nil {
+		return err
+	}
+
+	flushInterval, err := parseInterval(cfg, "log.webhook.flush_interval", "1s")
+	if err != nil {
+		return err
+	}
+	wh.batcher = newBatcher(cfg.IntDefault("log.webhook.batch_size", 10),
+		cfg.IntDefault("log.webhook.queue_size", 100), flushInterval, wh.post)
+
+	wh.SetWriter(writerFunc(func(p []byte) (int, error) {
+		msg := string(bytes.TrimRight(p, "\n"))
+		if b, err := wh.render(&webhookData{
+			Level:     LevelInfo.String(),
+			Severity:  levelToWebhookSeverity[LevelInfo],
+			Time:      time.Now().Format(time.RFC3339),
+			Message:   msg,
+			Formatted: msg,
+			Fields:    Fields{},
+		}); err == nil {
+			wh.batcher.add(b)
+		}
+		return len(p), nil
+	}))
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (wh *WebhookReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	wh.flags = flags
+	wh.isCallerInfo = isCallerInfo(wh.flags)
+	return nil
+}
+
+// SetWriter method sets the given writer into webhook receiver.
+func (wh *WebhookReceiver) SetWriter(w io.Writer) {
+	wh.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (wh *WebhookReceiver) IsCallerInfo() bool {
+	return wh.isCallerInfo
+}
+
+// Log method posts the log entry to webhook if entry level is configured
+// level or above.
+func (wh *WebhookReceiver) Log(entry *Entry) {
+	if entry.Level > wh.level {
+		return
+	}
+
+	fields := make(Fields, len(entry.Fields))
+	for k, v := range entry.Fields {
+		fields[k] = v
+	}
+	body, err := wh.render(&webhookData{
+		Level:     entry.Level.String(),
+		Severity:  levelToWebhookSeverity[builtinLevel(entry.Level)],
+		Time:      entry.Time.Format(time.RFC3339),
+		Message:   entry.Message,
+		Formatted: string(bytes.TrimRight(textFormatter(wh.flags, entry), " \n")),
+		Fields:    fields,
+	})
+	if err != nil {
+		wh.reportError(err, entry)
+		return
+	}
+
+	// fatal exits and panic unwinds the process, so it's posted right away
+	if entry.Level == LevelFatal || entry.Level == LevelPanic {
+		wh.post([]interface{}{body})
+		return
+	}
+	if !wh.batcher.add(body) {
+		wh.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (wh *WebhookReceiver) Writer() io.Writer {
+	return wh.out
+}
+
+// Close method posts the queued entries and stops the receiver.
+func (wh *WebhookReceiver) Close() {
+	wh.batcher.close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// WebhookReceiver Unexported methods
+//___________________________________
+
+func (wh *WebhookReceiver) render(data *webhookData) ([]byte, error) {
+	data.Hostname, data.Params = wh.hostname, wh.params
+	buf := &bytes.Buffer{}
+	if err := wh.tmpl.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (wh *WebhookReceiver) post(items []interface{}) {
+	for _, item := range items {
+		if _, err := wh.sender.send(http.MethodPost, wh.url, item.([]byte), wh.header); err != nil {
+			wh.reportError(err, nil)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestWebhookLoggerSlack(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+		token  string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		token = r.Header.Get("X-Token")
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "webhook"
+    pattern = "%%level:-5 %%message"
+    webhook {
+      url = "%s"
+      preset = "slack"
+      level = "warn"
+      flush_interval = "1h"
+      headers {
+        X-Token = "secret"
+      }
+    }
+  }
+  `, server.URL)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.Info("Yes, I would love to see")
+	logger.Warn(`Yes, yes it's an "warning"`)
+	logger.Error("Yes, yes, yes - finally an error")
+	logger.receiver.(*WebhookReceiver).Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "secret", token)
+	assert.Equal(t, []string{
+		`{"text":"WARN  Yes, yes it's an \"warning\""}`,
+		`{"text":"ERROR Yes, yes, yes - finally an error"}`,
+	}, bodies)
+}
+
+func TestWebhookLoggerPagerDuty(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		body map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	configStr := fmt.Sprintf(`
+  log {
+    receiver = "webhook"
+    webhook {
+      url = "%s"
+      preset = "pagerduty"
+      level = "error"
+      params {
+        routing_key = "R0UT1NGK3Y"
+      }
+    }
+  }
+  `, server.URL)
+	cfg, _ := config.ParseString(configStr)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+
+	logger.WithField("key1", "value1").Error("Database is unreachable")
+	logger.receiver.(*WebhookReceiver).Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "R0UT1NGK3Y", body["routing_key"])
+	assert.Equal(t, "trigger", body["event_action"])
+	payload := body["payload"].(map[string]interface{})
+	assert.Equal(t, "Database is unreachable", payload["summary"])
+	assert.Equal(t, "error", payload["severity"])
+	assert.Equal(t, map[string]interface{}{"key1": "value1"}, payload["custom_details"])
+}
+
+func TestWebhookLoggerConfig(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "webhook", webhook { url = "http://localhost", preset = "irc" } }`)
+	_, err := New(cfg)
+	assert.Equal(t, "log: unsupported webhook preset 'irc'", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "webhook", webhook { preset = "slack" } }`)
+	_, err = New(cfg)
+	assert.Equal(t, "log: webhook url is required", err.Error())
+
+	cfg, _ = config.ParseString(`log { receiver = "webhook", webhook { url = "http://localhost", template = "{{.Level" } }`)
+	_, err = New(cfg)
+	assert.NotNil(t, err)
+}
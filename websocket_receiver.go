@@ -0,0 +1,354 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+// WebSocket frame opcodes, RFC 6455
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+
+	wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+var _ Receiver = (*WebSocketReceiver)(nil)
+
+// WebSocketReceiver broadcasts the formatted log entry to the connected
+// WebSocket clients, it's a `http.Handler` to be mounted on the application
+// admin route. Client can filter the entries by level using query parameter
+// `level`, for e.g.: `/logs?level=warn`. Set `log.websocket.address` to serve
+// it on its own listener at `log.websocket.path` (default `/logs`).
+//
+// Each client has a send queue of `log.websocket.queue_size`, entries are
+// dropped for the slow client when its queue is full and `ErrQueueFull` is
+// reported.
+type WebSocketReceiver struct {
+	errorReporter
+	queueSize    int
+	clients      map[*wsClient]struct{}
+	server       *http.Server
+	out          io.Writer
+	formatter    string
+	flags        []ess.FmtFlagPart
+	isCallerInfo bool
+	mu           sync.RWMutex
+}
+
+type wsClient struct {
+	conn  net.Conn
+	level level
+	send  chan []byte
+	done  chan struct{}
+	once  sync.Once
+	mu    sync.Mutex
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// WebSocketReceiver methods
+//___________________________________
+
+// Init method initializes the WebSocket receiver instance.
+func (ws *WebSocketReceiver) Init(cfg *config.Config) error {
+	ws.formatter = cfg.StringDefault("log.format", "text")
+	if !isFormatSupported(ws.formatter) {
+		return fmt.Errorf("log: unsupported format '%s'", ws.formatter)
+	}
+
+	ws.queueSize = cfg.IntDefault("log.websocket.queue_size", 100)
+	ws.clients = make(map[*wsClient]struct{})
+
+	ws.SetWriter(writerFunc(func(p []byte) (int, error) {
+		ws.broadcast(LevelInfo, p)
+		return len(p), nil
+	}))
+
+	if address := cfg.StringDefault("log.websocket.address", ""); len(address) > 0 {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+		mux := http.NewServeMux()
+		mux.Handle(cfg.StringDefault("log.websocket.path", "/logs"), ws)
+		ws.server = &http.Server{Handler: mux}
+		go func() { _ = ws.server.Serve(listener) }()
+	}
+
+	return nil
+}
+
+// SetPattern method initializes the logger format pattern.
+func (ws *WebSocketReceiver) SetPattern(pattern string) error {
+	flags, err := ess.ParseFmtFlag(pattern, FmtFlags)
+	if err != nil {
+		return err
+	}
+	ws.flags = flags
+	if ws.formatter != jsonFmt {
+		ws.isCallerInfo = isCallerInfo(ws.flags)
+	}
+	return nil
+}
+
+// SetWriter method sets the given writer into WebSocket receiver.
+func (ws *WebSocketReceiver) SetWriter(w io.Writer) {
+	ws.out = w
+}
+
+// IsCallerInfo method returns true if log receiver is configured with caller info
+// otherwise false.
+func (ws *WebSocketReceiver) IsCallerInfo() bool {
+	return ws.isCallerInfo
+}
+
+// Log method broadcasts the log entry to the connected clients.
+func (ws *WebSocketReceiver) Log(entry *Entry) {
+	ws.mu.RLock()
+	n := len(ws.clients)
+	ws.mu.RUnlock()
+	if n == 0 {
+		return
+	}
+	if !ws.broadcast(entry.Level, formatEntry(ws.formatter, ws.flags, entry)) {
+		ws.reportError(ErrQueueFull, entry)
+	}
+}
+
+// Writer method returns the current log writer.
+func (ws *WebSocketReceiver) Writer() io.Writer {
+	return ws.out
+}
+
+// ServeHTTP method upgrades the request to WebSocket connection and streams
+// the log entries to it.
+func (ws *WebSocketReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || len(key) == 0 {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return
+	}
+
+	lvl := LevelTrace
+	if levelName := r.URL.Query().Get("level"); len(levelName) > 0 {
+		if lvl = levelByName(levelName); lvl == LevelUnknown {
+			http.Error(w, fmt.Sprintf("unknown level '%s'", levelName), http.StatusBadRequest)
+			return
+		}
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket is not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+
+	c := &wsClient{
+		conn:  conn,
+		level: lvl,
+		send:  make(chan []byte, ws.queueSize),
+		done:  make(chan struct{}),
+	}
+
+	// handshake response is written by the client lock, entries are queued
+	// from here on
+	c.mu.Lock()
+	ws.mu.Lock()
+	ws.clients[c] = struct{}{}
+	ws.mu.Unlock()
+	_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAcceptKey(key) + "\r\n\r\n")
+	err = rw.Flush()
+	c.mu.Unlock()
+	if err != nil {
+		ws.remove(c)
+		return
+	}
+
+	go ws.writeLoop(c)
+	ws.readLoop(c, rw.Reader)
+}
+
+// Close method disconnects the clients and stops the listener if any.
+func (ws *WebSocketReceiver) Close() {
+	if ws.server != nil {
+		_ = ws.server.Close()
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for c := range ws.clients {
+		_ = c.writeFrame(wsOpClose, nil)
+		c.close()
+		delete(ws.clients, c)
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// WebSocketReceiver Unexported methods
+//___________________________________
+
+// broadcast method queues the message to the clients of level, returns
+// false if it's dropped for any slow client.
+func (ws *WebSocketReceiver) broadcast(lvl level, msg []byte) bool {
+	msg = bytes.TrimRight(msg, " \n")
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	queued := true
+	for c := range ws.clients {
+		if lvl > c.level {
+			continue
+		}
+		select {
+		case c.send <- append([]byte{}, msg...):
+		default:
+			// slow client, entry is dropped
+			queued = false
+		}
+	}
+	return queued
+}
+
+func (ws *WebSocketReceiver) writeLoop(c *wsClient) {
+	for {
+		select {
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := c.writeFrame(wsOpText, msg); err != nil {
+				ws.remove(c)
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// readLoop method reads the client frames to respond ping and close,
+// messages from client are discarded.
+func (ws *WebSocketReceiver) readLoop(c *wsClient, r *bufio.Reader) {
+	defer ws.remove(c)
+	for {
+		opcode, payload, err := wsReadFrame(r)
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case wsOpClose:
+			_ = c.writeFrame(wsOpClose, nil)
+			return
+		case wsOpPing:
+			_ = c.writeFrame(wsOpPong, payload)
+		}
+	}
+}
+
+func (ws *WebSocketReceiver) remove(c *wsClient) {
+	ws.mu.Lock()
+	delete(ws.clients, c)
+	ws.mu.Unlock()
+	c.close()
+}
+
+func (c *wsClient) close() {
+	c.once.Do(func() {
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
+
+// writeFrame method writes the unmasked final frame.
+func (c *wsClient) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// wsReadFrame method reads the frame and unmasks the payload.
+func wsReadFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > 1<<20 {
+		return 0, nil, fmt.Errorf("log: websocket frame too large %d", length)
+	}
+
+	var mask [4]byte
+	masked := header[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return header[0] & 0x0F, payload, nil
+}
+
+func wsAcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
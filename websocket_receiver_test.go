@@ -0,0 +1,89 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestWebSocketLogger(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "websocket", pattern = "%level:-5 %message" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	receiver := logger.receiver.(*WebSocketReceiver)
+
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	// no clients, nothing to broadcast
+	logger.Info("Yes, I would love to see")
+
+	conn, r := testWebSocketDial(t, server.URL, "/logs?level=warn")
+	defer func() { _ = conn.Close() }()
+
+	logger.Info("Yes, I would love to see")
+	logger.Warn("Yes, yes it's an warning")
+	logger.Error("Yes, yes, yes - finally an error")
+
+	opcode, payload, err := wsReadFrame(r)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, byte(wsOpText), opcode)
+	assert.Equal(t, "WARN  Yes, yes it's an warning", string(payload))
+	_, payload, _ = wsReadFrame(r)
+	assert.Equal(t, "ERROR Yes, yes, yes - finally an error", string(payload))
+
+	// masked ping from client
+	_, _ = conn.Write([]byte{0x80 | wsOpPing, 0x80 | 2, 1, 2, 3, 4, 'h' ^ 1, 'i' ^ 2})
+	opcode, payload, _ = wsReadFrame(r)
+	assert.Equal(t, byte(wsOpPong), opcode)
+	assert.Equal(t, "hi", string(payload))
+
+	receiver.Close()
+	opcode, _, _ = wsReadFrame(r)
+	assert.Equal(t, byte(wsOpClose), opcode)
+}
+
+func TestWebSocketLoggerUpgrade(t *testing.T) {
+	cfg, _ := config.ParseString(`log { receiver = "websocket" }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	server := httptest.NewServer(logger.receiver.(*WebSocketReceiver))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?level=verbose", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	res, err = http.DefaultClient.Do(req)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", wsAcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func testWebSocketDial(t *testing.T, serverURL, path string) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(serverURL, "http://"))
+	assert.FailNowOnError(t, err, "unexpected error")
+	_, _ = conn.Write([]byte("GET " + path + " HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\n" +
+		"Connection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+
+	r := bufio.NewReader(conn)
+	res, err := http.ReadResponse(r, nil)
+	assert.FailNowOnError(t, err, "unexpected error")
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", res.Header.Get("Sec-WebSocket-Accept"))
+	return conn, r
+}
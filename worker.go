@@ -0,0 +1,49 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+// ForWorker method returns the child logger with field `worker` of given
+// ID, it's for fan-out work so the entries of concurrent workers can be
+// traced. For e.g.:
+//
+//	reqLogger := logger.WithField("reqid", reqID)
+//	for i, item := range items {
+//		go process(reqLogger.ForWorker(i), item)
+//	}
+func (l *Logger) ForWorker(id interface{}) Loggerer {
+	return l.WithField("worker", id)
+}
+
+// WorkerFunc method returns the func for `errgroup.Group.Go` which calls
+// the given func with worker logger of given ID. For e.g.:
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	for i, item := range items {
+//		item := item
+//		g.Go(reqLogger.WorkerFunc(i, func(wl log.Loggerer) error {
+//			return process(ctx, wl, item)
+//		}))
+//	}
+func (l *Logger) WorkerFunc(id interface{}, fn func(logger Loggerer) error) func() error {
+	wl := l.ForWorker(id)
+	return func() error {
+		return fn(wl)
+	}
+}
+
+// ForWorker method returns the child logger with field `worker` of given
+// ID, it has the fields of entry, for e.g.: request fields.
+func (e *Entry) ForWorker(id interface{}) Loggerer {
+	return e.WithField("worker", id)
+}
+
+// WorkerFunc method returns the func for `errgroup.Group.Go` which calls
+// the given func with worker logger of given ID.
+func (e *Entry) WorkerFunc(id interface{}, fn func(logger Loggerer) error) func() error {
+	wl := e.ForWorker(id)
+	return func() error {
+		return fn(wl)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Jeevanandam M (https://github.com/jeevatkm)
+// go-aah/log source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestLogForWorker(t *testing.T) {
+	cfg, _ := config.ParseString(`log {
+    pattern = "%reqid %message %fields"
+  }`)
+	logger, err := New(cfg)
+	assert.FailNowOnError(t, err, "unexpected error")
+	buf := &bytes.Buffer{}
+	logger.SetWriter(buf)
+
+	logger.ForWorker(1).Info("started")
+	assert.Equal(t, "started fields[worker: 1] \n", buf.String())
+
+	// request fields are kept
+	buf.Reset()
+	reqLogger := logger.WithField("reqid", "req-1")
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		funcs []func() error
+	)
+	for i := 0; i < 3; i++ {
+		funcs = append(funcs, reqLogger.WorkerFunc(i, func(wl Loggerer) error {
+			mu.Lock()
+			defer mu.Unlock()
+			wl.Info("processed")
+			if wl.(*Entry).Fields["worker"] == 2 {
+				return errors.New("failed")
+			}
+			return nil
+		}))
+	}
+	errs := make([]error, len(funcs))
+	for i, fn := range funcs {
+		wg.Add(1)
+		go func(i int, fn func() error) {
+			defer wg.Done()
+			errs[i] = fn()
+		}(i, fn)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	sort.Strings(lines)
+	assert.Equal(t, []string{
+		"req-1 processed fields[worker: 0]",
+		"req-1 processed fields[worker: 1]",
+		"req-1 processed fields[worker: 2]",
+	}, lines)
+	assert.Nil(t, errs[0])
+	assert.Equal(t, "failed", errs[2].Error())
+
+	assert.NotNil(t, logger.WorkerFunc("w", func(wl Loggerer) error { return nil }))
+}